@@ -2,33 +2,39 @@ package ai_helper
 
 import (
 	"bcca_crawler/api"
+	"bcca_crawler/crawler"
+	"bcca_crawler/ctcae"
+	"bcca_crawler/docstore"
+	rules "bcca_crawler/eligibility"
 	"bcca_crawler/internal/config"
+	"bcca_crawler/prompts"
+	"bcca_crawler/usage"
 
 	"bcca_crawler/internal/database"
+	"bcca_crawler/medications"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"google.golang.org/genai"
 )
 
 type ProtocolPayload struct {
-	ProtocolSummary             api.SummaryProtocol                `json:"summary_protocol"`
-	ProtocolEligibilityCriteria []api.ProtocolEligibilityCriterion `json:"protocol_eligibility_criteria"`
-	ProtocolPrecautions         []api.ProtocolPrecaution           `json:"protocol_precautions"`
-	ProtocolCautions            []api.ProtocolCaution              `json:"protocol_cautions"`
-	TestGroups                  []api.TestGroup                    `json:"test_groups"`
-	PrescriptionGroups          []api.PrescriptionGroup            `json:"prescription_groups"`
-	ProtocolCycles              []api.ProtocolCycle                `json:"protocol_cycles"`
-	Toxicities                  []api.Toxicity                     `json:"toxicities"`
-	Physicians                  []api.Physician                    `json:"physicians"`
-	ArticleReferences           []api.ArticleReference             `json:"article_references"`
+	ProtocolSummary             api.SummaryProtocol                `json:"summary_protocol" schema:"required"`
+	ProtocolEligibilityCriteria []api.ProtocolEligibilityCriterion `json:"protocol_eligibility_criteria" desc:"Inclusion and exclusion criteria for the protocol." schema:"required"`
+	ProtocolPrecautions         []api.ProtocolPrecaution           `json:"protocol_precautions" desc:"Precautions to be taken during the protocol." schema:"required"`
+	ProtocolCautions            []api.ProtocolCaution              `json:"protocol_cautions" desc:"Cautions to be observed during the protocol." schema:"required"`
+	TestGroups                  []api.TestGroup                    `json:"test_groups" desc:"Categories of lab (or other) tests required or suggested for monitoring prior or during the administration of this protocol." schema:"required"`
+	PrescriptionGroups          []api.PrescriptionGroup            `json:"prescription_groups" desc:"Categories of supportive or premedication prescriptions used within the protocol." schema:"required"`
+	ProtocolCycles              []api.ProtocolCycle                `json:"protocol_cycles" desc:"Details of treatment cycles within the protocol." schema:"required"`
+	Toxicities                  []api.Toxicity                     `json:"toxicities" desc:"Information on potential toxicities and their management." schema:"required"`
+	Physicians                  []api.Physician                    `json:"physicians" desc:"List of physicians associated with the protocol." schema:"required"`
+	ArticleReferences           []api.ArticleReference             `json:"article_references" desc:"References to scientific articles relevant to the protocol." schema:"required"`
 }
 
 type Medication struct {
@@ -39,19 +45,14 @@ type Medication struct {
 	ModificationCategory []api.ModificationCategory `json:"modification_category"`
 }
 
-const ai_prompt = `You are a medical oncologist tasked with analyzing the joined PDF document and extracting structured information in JSON format.
-### Task:
-1. Parse the provided PDF thoroughly.
-2. Extract all relevant information, and complete the JSON object according to the provided schema.
-3. Ensure that the extracted information is accurate and complete it to the best of your expertise knowledge.
-3. Toxicities should be defined using the CTCAE v5 terminology. Generate only a toxicity with adjustment if there are suggested guidances.
-4. Each tests should be a single entity.
-5. Return the completed JSON object, ensuring all fields are validated for data type and consistency.`
+// DefaultModel is used when GEMINI_MODEL is not set.
+const DefaultModel = "gemini-2.5-flash"
 
 type Session struct {
 	ctx    context.Context
 	client *genai.Client
 	model  string
+	cfg    *config.Config
 }
 
 func NewSession(ctx context.Context, s *config.Config) (*Session, error) {
@@ -64,87 +65,186 @@ func NewSession(ctx context.Context, s *config.Config) (*Session, error) {
 		return nil, fmt.Errorf("error creating AI client: %v", err)
 	}
 
-	model := "gemini-2.5-flash"
-
 	return &Session{
 		ctx:    ctx,
 		client: client,
-		model:  model,
+		model:  Model(s),
+		cfg:    s,
 	}, nil
 }
 
-func retry[T any](attempts int, sleep time.Duration, fn func() (T, error)) (T, error) {
-	var zero T
-	for i := 0; i < attempts; i++ {
-		result, err := fn()
-		if err == nil {
-			return result, nil
-		}
-		time.Sleep(sleep * time.Duration(i+1)) // linear backoff
+// Model is the model extractions use.
+func Model(s *config.Config) string {
+	if s.GeminiModel != "" {
+		return s.GeminiModel
 	}
-	return zero, fmt.Errorf("after %d attempts, failed", attempts)
+	return DefaultModel
 }
 
-func RunAllLinks(s *config.Config, links []string) {
-	ctx := context.Background()
-	var wg sync.WaitGroup
+// Extract runs the model over a protocol PDF with a prompt version and
+// returns what it read, without saving anything. The eval command scores it
+// against gold files.
+func Extract(ctx context.Context, s *config.Config, pdf []byte, prompt database.PromptVersion, vars prompts.Vars) (ProtocolPayload, error) {
+	session, err := NewSession(ctx, s)
+	if err != nil {
+		return ProtocolPayload{}, err
+	}
+	text, err := prompts.Render(prompt, vars)
+	if err != nil {
+		return ProtocolPayload{}, err
+	}
+	schema, err := ProtocolSchema()
+	if err != nil {
+		return ProtocolPayload{}, err
+	}
+	return pass[ProtocolPayload](ctx, session, pdf, "extraction", prompt, text, schema)
+}
 
-	concurrencyLimit := 5
-	sem := make(chan struct{}, concurrencyLimit)
+// Mode is the extraction mode set with EXTRACTION_MODE, single unless it
+// is sectioned.
+func Mode(s *config.Config) database.ExtractionModeEnum {
+	if database.ExtractionModeEnum(s.ExtractionMode) == database.ExtractionModeEnumSectioned {
+		return database.ExtractionModeEnumSectioned
+	}
+	return database.ExtractionModeEnumSingle
+}
 
-	for _, link := range links {
-		wg.Add(1)
-		go func(l string) {
-			defer wg.Done()
-			if err := GetAiData(ctx, s, l, sem); err != nil {
-				fmt.Println("Error processing", l, ":", err)
-			}
-		}(link)
+// extractionPrompts are the prompt versions of an extraction: the whole
+// protocol prompt, or the overview and section prompts when sectioned.
+type extractionPrompts struct {
+	Mode    database.ExtractionModeEnum
+	Prompt  database.PromptVersion
+	Section database.PromptVersion
+}
+
+// activePrompts returns the active prompts of the configured mode.
+func activePrompts(s *config.Config, ctx context.Context) (extractionPrompts, error) {
+	p := extractionPrompts{Mode: Mode(s)}
+	var err error
+	if p.Mode == database.ExtractionModeEnumSectioned {
+		if p.Prompt, err = prompts.Active(s, ctx, prompts.Overview); err != nil {
+			return p, err
+		}
+		p.Section, err = prompts.Active(s, ctx, prompts.Section)
+		return p, err
 	}
-	wg.Wait()
+	p.Prompt, err = prompts.Active(s, ctx, prompts.Extraction)
+	return p, err
 }
 
-func GetAiData(ctx context.Context, s *config.Config, link string, sem chan struct{}) error {
-	sem <- struct{}{}        // acquire semaphore slot
-	defer func() { <-sem }() // release slot
+// recordedPrompts returns the prompts an earlier extraction used.
+func recordedPrompts(s *config.Config, ctx context.Context, e database.Extraction) (extractionPrompts, error) {
+	p := extractionPrompts{Mode: e.Mode}
+	var err error
+	if p.Prompt, err = s.Db.GetPromptVersionByID(ctx, e.PromptVersionID); err != nil {
+		return p, fmt.Errorf("error getting prompt version: %s, with error: %v", e.PromptVersionID, err)
+	}
+	if e.Mode == database.ExtractionModeEnumSectioned {
+		if !e.SectionPromptVersionID.Valid {
+			return p, fmt.Errorf("sectioned extraction %s has no section prompt", e.ID)
+		}
+		if p.Section, err = s.Db.GetPromptVersionByID(ctx, e.SectionPromptVersionID.UUID); err != nil {
+			return p, fmt.Errorf("error getting prompt version: %s, with error: %v", e.SectionPromptVersionID.UUID, err)
+		}
+	}
+	return p, nil
+}
 
-	// Access your API key as an environment variable
-	session, err := NewSession(ctx, s)
-	if err != nil {
-		return fmt.Errorf("error creating AI client: %v", err)
+// extractWith extracts a protocol with the given prompts. The returned
+// record holds what produced the payload, for the extractions table.
+func extractWith(ctx context.Context, s *config.Config, pdf []byte, p extractionPrompts, tumorGroup string) (ProtocolPayload, database.CreateExtractionParams, error) {
+	record := database.CreateExtractionParams{
+		Model:           Model(s),
+		Mode:            p.Mode,
+		PromptVersionID: p.Prompt.ID,
+		Conflicts:       json.RawMessage("[]"),
+	}
+
+	if p.Mode == database.ExtractionModeEnumSectioned {
+		payload, conflicts, err := ExtractSectioned(ctx, s, pdf, p.Prompt, p.Section, tumorGroup)
+		if err != nil {
+			return ProtocolPayload{}, record, err
+		}
+		for _, c := range conflicts {
+			fmt.Printf("Extraction conflict in %s: %s %s\n", c.Section, c.Item, c.Detail)
+		}
+		if len(conflicts) > 0 {
+			if record.Conflicts, err = json.Marshal(conflicts); err != nil {
+				return ProtocolPayload{}, record, err
+			}
+		}
+		record.SectionPromptVersionID = uuid.NullUUID{UUID: p.Section.ID, Valid: true}
+		return payload, record, nil
 	}
+
+	payload, err := Extract(ctx, s, pdf, p.Prompt, prompts.Vars{TumorGroup: tumorGroup})
+	return payload, record, err
+}
+
+// ExtractProtocol downloads a protocol PDF, extracts it with the active
+// prompts in the configured mode and saves the result, recording the prompt
+// versions and model. It is run by the extract jobs of the job queue.
+func ExtractProtocol(ctx context.Context, s *config.Config, link string, tumorGroup string) error {
 	fmt.Println("Getting PDF...")
-	pdfBytes, err := downloadPDF(link)
+	doc, err := crawler.Download(link)
 	if err != nil {
 		return err
 	}
 	fmt.Println("PDF downloaded.")
-	//schema for the AI model
-	schema := protocolDataSchema()
 
-	//config
-	config := &genai.GenerateContentConfig{
-		ResponseMIMEType: "application/json",
-		ResponseSchema:   schema,
+	// keep the exact file the extraction is made from
+	source, err := api.StoreDocument(s, ctx, doc.Body, docstore.Meta{URL: doc.URL, ContentType: doc.ContentType, FetchedAt: doc.FetchedAt, Headers: doc.Header})
+	if err != nil {
+		return err
 	}
 
-	parts := []*genai.Part{
-		genai.NewPartFromText(ai_prompt),
-		genai.NewPartFromBytes(pdfBytes, "application/pdf"),
+	p, err := activePrompts(s, ctx)
+	if err != nil {
+		return err
 	}
-
-	contents := []*genai.Content{
-		genai.NewContentFromParts(parts, genai.RoleUser),
+	payload, record, err := extractWith(ctx, s, doc.Body, p, tumorGroup)
+	if err != nil {
+		return fmt.Errorf("analyze failed for %s: %w", link, err)
 	}
+	return saveProtocol(ctx, s, payload, record, source.Hash, link)
+}
 
-	payload, err := retry(3, 2*time.Second, func() (ProtocolPayload, error) {
-		return handleRequest(ctx, *session, session.model, contents, config)
-	})
+// ReplayExtraction runs an earlier extraction again from the cached model
+// responses, with the same prompt versions and model, and saves it with the
+// current ingestion code. It fails with ErrNotCached rather than pay for a
+// response that is not cached.
+func ReplayExtraction(ctx context.Context, s *config.Config, e database.Extraction) error {
+	if s.Documents == nil {
+		return fmt.Errorf("no document store configured")
+	}
+	body, _, err := s.Documents.Open(ctx, e.DocumentHash)
+	if err != nil {
+		return fmt.Errorf("error opening document: %s, with error: %v", e.DocumentHash, err)
+	}
+	pdf, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return fmt.Errorf("error reading document: %s, with error: %v", e.DocumentHash, err)
+	}
 
+	p, err := recordedPrompts(s, ctx, e)
 	if err != nil {
-		return fmt.Errorf("analyze failed for %s: %w", link, err)
+		return err
 	}
+	replay := *s
+	replay.GeminiModel = e.Model
+	replay.CacheOnly = true
+	replay.NoCache = false
+	payload, record, err := extractWith(ctx, &replay, pdf, p, "")
+	if err != nil {
+		return fmt.Errorf("replay failed for %s: %w", e.DocumentHash, err)
+	}
+	return saveProtocol(ctx, s, payload, record, e.DocumentHash, e.DocumentHash)
+}
 
+// saveProtocol saves an extracted protocol and links it to its source
+// document and extraction record. source names the document in errors.
+func saveProtocol(ctx context.Context, s *config.Config, payload ProtocolPayload, record database.CreateExtractionParams, documentHash string, source string) error {
 	api.PrintStruct(payload)
 
 	protocol, err := s.Db.CreateProtocolbyScraping(ctx, database.CreateProtocolbyScrapingParams{
@@ -170,6 +270,27 @@ func GetAiData(ctx context.Context, s *config.Config, link string, sem chan stru
 		}
 	}
 
+	err = s.Db.AddProtocolSource(ctx, database.AddProtocolSourceParams{
+		ProtocolID:   protocol.ID,
+		DocumentHash: documentHash,
+		RevisedOn:    payload.ProtocolSummary.RevisedOn,
+	})
+	if err != nil {
+		fmt.Println("Error linking protocol source: ", err)
+		return err
+	}
+
+	record.ProtocolID = protocol.ID
+	record.DocumentHash = documentHash
+	extraction, err := s.Db.CreateExtraction(ctx, record)
+	if err != nil {
+		return fmt.Errorf("error recording extraction: %s, with error: %v", source, err)
+	}
+	sources, err := newProvenance(ctx, s, protocol.ID, extraction.ID)
+	if err != nil {
+		return err
+	}
+
 	for _, article := range payload.ArticleReferences {
 		articleRef, err := s.Db.CreateArticleReference(ctx, database.CreateArticleReferenceParams{
 			Title:   article.Title,
@@ -262,6 +383,25 @@ func GetAiData(ctx context.Context, s *config.Config, link string, sem chan stru
 			ProtocolID: protocol.ID,
 			CriteriaID: elig.ID,
 		})
+		if err := sources.record(ctx, database.ProvenanceEntityEnumCriterion, elig.ID, eligibility.Source); err != nil {
+			return err
+		}
+
+		// keep an editor's rule over a generated one
+		if rule, err := rules.Parse(eligibility.Rule); eligibility.Rule != "" && err == nil {
+			existing, err := s.Db.GetEligibilityRule(ctx, elig.ID)
+			if err != nil || existing.Author != database.RuleAuthorEnumEditor {
+				_, err = s.Db.UpsertEligibilityRule(ctx, database.UpsertEligibilityRuleParams{
+					CriteriaID: elig.ID,
+					Expression: rule.String(),
+					Author:     database.RuleAuthorEnumAi,
+				})
+				if err != nil {
+					fmt.Println("Error saving eligibility rule: ", err)
+					return err
+				}
+			}
+		}
 
 	}
 
@@ -385,6 +525,9 @@ func GetAiData(ctx context.Context, s *config.Config, link string, sem chan stru
 			if err != nil {
 				return err
 			}
+			if err := sources.record(ctx, database.ProvenanceEntityEnumTest, added.ID, test.Source); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -420,21 +563,16 @@ func GetAiData(ctx context.Context, s *config.Config, link string, sem chan stru
 
 		for _, px := range medGroup.Prescriptions {
 
-			med, err := s.Db.AddMedication(ctx, database.AddMedicationParams{
+			// the same drug under another spelling or name is reused
+			med, err := medications.Resolve(s, ctx, medications.Incoming{
 				Name:           px.MedicationName,
 				Description:    px.MedicationDescription,
 				Category:       px.MedicationCategory,
 				AlternateNames: px.MedicationAlternates,
 			})
 			if err != nil {
-				if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
-					// Duplicate key value violation
-					med, err = s.Db.GetMedicationByName(ctx, px.MedicationName)
-					if err != nil {
-						fmt.Println("Error getting medication: ", err)
-						return err
-					}
-				}
+				fmt.Println("Error resolving medication: ", err)
+				return err
 			}
 
 			added, err := s.Db.UpsertPrescription(ctx, database.UpsertPrescriptionParams{
@@ -477,6 +615,9 @@ func GetAiData(ctx context.Context, s *config.Config, link string, sem chan stru
 			if err != nil {
 				return err
 			}
+			if err := sources.record(ctx, database.ProvenanceEntityEnumPrescription, added.ID, px.Source); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -511,21 +652,16 @@ func GetAiData(ctx context.Context, s *config.Config, link string, sem chan stru
 
 		for _, tx := range cycle.Treatments {
 
-			med, err := s.Db.AddMedication(ctx, database.AddMedicationParams{
+			// the same drug under another spelling or name is reused
+			med, err := medications.Resolve(s, ctx, medications.Incoming{
 				Name:           tx.MedicationName,
 				Description:    tx.MedicationDescription,
 				Category:       tx.MedicationCategory,
 				AlternateNames: tx.MedicationAlternates,
 			})
 			if err != nil {
-				if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
-					// Duplicate key value violation
-					med, err = s.Db.GetMedicationByName(ctx, tx.MedicationName)
-					if err != nil {
-						fmt.Println("Error getting medication: ", err)
-						return err
-					}
-				}
+				fmt.Println("Error resolving medication: ", err)
+				return err
 			}
 
 			added, err := s.Db.UpsertProtocolTreatment(ctx, database.UpsertProtocolTreatmentParams{
@@ -566,6 +702,9 @@ func GetAiData(ctx context.Context, s *config.Config, link string, sem chan stru
 			if err != nil {
 				return err
 			}
+			if err := sources.record(ctx, database.ProvenanceEntityEnumTreatment, added.ID, tx.Source); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -573,31 +712,26 @@ func GetAiData(ctx context.Context, s *config.Config, link string, sem chan stru
 
 	for _, tox := range payload.Toxicities {
 
-		toxicity, err := s.Db.AddToxicity(ctx, database.AddToxicityParams{
+		resolved, err := ctcae.Resolve(s, ctx, ctcae.Incoming{
 			Title:       tox.Title,
 			Description: tox.Description,
 			Category:    tox.Category,
 		})
-
 		if err != nil {
-			if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
-				// Duplicate key value violation
-				toxicity, err = s.Db.GetToxicityByName(ctx, tox.Title)
-
-				if err != nil {
-					fmt.Println("Error getting toxicity: ", err)
-					return err
-				}
-			} else {
-				fmt.Println("Error creating toxicity: ", err)
-				return err
-			}
+			fmt.Println("Error creating toxicity: ", err)
+			return err
 		}
+		toxicity := resolved.Toxicity
 
 		for _, mod := range tox.Modifications {
+			// the dictionary wording of a grade wins over the model's
+			description := mod.GradeDescription
+			if d, ok := resolved.Grades[database.GradeEnum(mod.Grade)]; ok {
+				description = d
+			}
 			grade, err := s.Db.AddToxicityGrade(ctx, database.AddToxicityGradeParams{
 				Grade:       database.GradeEnum(mod.Grade),
-				Description: mod.GradeDescription,
+				Description: description,
 				ToxicityID:  toxicity.ID,
 			})
 			if err != nil {
@@ -626,6 +760,8 @@ func GetAiData(ctx context.Context, s *config.Config, link string, sem chan stru
 
 			if err != nil {
 				fmt.Println("Error creating toxicity modification: ", err)
+			} else if err := sources.record(ctx, database.ProvenanceEntityEnumToxicityAdjustment, grade.ID, mod.Source); err != nil {
+				return err
 			}
 		}
 
@@ -656,17 +792,6 @@ func spinner(done chan bool) {
 	}
 }
 
-// Function to download PDF content
-func downloadPDF(url string) ([]byte, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	return io.ReadAll(resp.Body)
-}
-
 // Function to extract JSON data from the string content between backticks
 func extractJSON(contentStr string) (string, error) {
 	start := strings.Index(contentStr, "```json")
@@ -682,25 +807,45 @@ func extractJSON(contentStr string) (string, error) {
 	return contentStr[start+7 : start+7+end], nil
 }
 
-// Function to handle each request, send it, and parse the response
-func handleRequest(ctx context.Context, s Session, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (payload ProtocolPayload, err error) {
+// handleRequest makes one call to the model and records its usage with the
+// call's purpose, document and attempt. A failure is classified for the
+// retry policy.
+func handleRequest[T any](ctx context.Context, s Session, model string, contents []*genai.Content, config *genai.GenerateContentConfig, call usage.Call) (payload T, raw string, cerr *CallError) {
+	call.Model = model
+	call.Outcome = database.LlmCallOutcomeEnumSuccess
+	start := time.Now()
+	defer func() {
+		call.Latency = time.Since(start)
+		if cerr != nil {
+			call.Err = cerr
+		}
+		usage.Record(s.cfg, ctx, call)
+	}()
 
 	response, err := s.client.Models.GenerateContent(ctx, model, contents, config)
 	if err != nil {
 		fmt.Printf("Request failed: %v\n", err)
-		return
+		call.Outcome = database.LlmCallOutcomeEnumApiError
+		return payload, "", classify(err)
 	}
-
-	for _, c := range response.Candidates {
-		for _, part := range c.Content.Parts {
-			// Parse the extracted JSON data
-			err = json.Unmarshal([]byte(part.Text), &payload)
-			if err != nil {
-				fmt.Printf("Request - Error unmarshaling JSON: %v\n", err)
-				return ProtocolPayload{}, err
-			}
-		}
+	if u := response.UsageMetadata; u != nil {
+		call.PromptTokens = u.PromptTokenCount
+		call.ResponseTokens = u.CandidatesTokenCount + u.ThoughtsTokenCount
+		call.TotalTokens = u.TotalTokenCount
 	}
 
-	return payload, nil
+	raw, cerr = checkResponse(response)
+	if cerr != nil {
+		fmt.Printf("Request - %s: %s\n", cerr.Class, cerr.Err)
+		call.Outcome = database.LlmCallOutcomeEnumInvalidResponse
+		return payload, raw, cerr
+	}
+	// Parse the extracted JSON data
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+		fmt.Printf("Request - Error unmarshaling JSON: %v\n", err)
+		call.Outcome = database.LlmCallOutcomeEnumInvalidResponse
+		var zero T
+		return zero, raw, &CallError{Class: ClassParse, Err: err.Error(), raw: raw}
+	}
+	return payload, raw, nil
 }
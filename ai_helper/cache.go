@@ -0,0 +1,158 @@
+package ai_helper
+
+import (
+	"bcca_crawler/docstore"
+	"bcca_crawler/internal/database"
+	"bcca_crawler/schema"
+	"bcca_crawler/usage"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"google.golang.org/genai"
+)
+
+// ErrNotCached is returned by a cache-only session, i.e. a replay, for a
+// response that is not in the cache.
+var ErrNotCached = errors.New("response not cached")
+
+type answer[T any] struct {
+	payload T
+	raw     string
+}
+
+// pass asks the model to fill a schema from the PDF, unless the response to
+// the same document, prompt version, model and schema is cached. NoCache
+// skips the lookup and replaces the cached response; CacheOnly never calls
+// the model.
+func pass[T any](ctx context.Context, session *Session, pdf []byte, purpose string, prompt database.PromptVersion, text string, s *schema.Schema) (T, error) {
+	var zero T
+	key, err := cacheKey(session, docstore.Hash(pdf), prompt, s)
+	if err != nil {
+		return zero, err
+	}
+
+	if !session.cfg.NoCache {
+		if payload, ok := lookup[T](ctx, session, key); ok {
+			fmt.Printf("Using cached %s response for %s\n", purpose, key.DocumentHash)
+			return payload, nil
+		}
+	}
+	if session.cfg.CacheOnly {
+		return zero, fmt.Errorf("%s of %s with %s: %w", purpose, key.DocumentHash, key.Model, ErrNotCached)
+	}
+
+	config := &genai.GenerateContentConfig{
+		ResponseMIMEType: "application/json",
+		ResponseSchema:   s.Genai(),
+	}
+	parts := []*genai.Part{
+		genai.NewPartFromText(text),
+		genai.NewPartFromBytes(pdf, "application/pdf"),
+	}
+	request := []*genai.Content{
+		genai.NewContentFromParts(parts, genai.RoleUser),
+	}
+	call := usage.Call{Purpose: purpose, DocumentHash: key.DocumentHash}
+	a, err := retry(ctx, func(attempt int, last *CallError) (answer[T], *CallError) {
+		if attempt == 1 {
+			if err := checkPDF(pdf); err != nil {
+				return answer[T]{}, err
+			}
+		}
+		call.Attempt = attempt
+		payload, raw, err := handleRequest[T](ctx, *session, session.model, reprompt(request, last), config, call)
+		return answer[T]{payload, raw}, err
+	})
+	if err != nil {
+		return zero, err
+	}
+
+	store(ctx, session, key, purpose, a)
+	return a.payload, nil
+}
+
+// reprompt adds the failure of the last attempt to the request when the
+// model can do better knowing it: the JSON error of an unparsable answer, or
+// that the answer was too long.
+func reprompt(request []*genai.Content, last *CallError) []*genai.Content {
+	if last == nil {
+		return request
+	}
+	switch last.Class {
+	case ClassParse:
+		if last.raw == "" {
+			return request
+		}
+		return append(request[:len(request):len(request)],
+			genai.NewContentFromText(last.raw, genai.RoleModel),
+			genai.NewContentFromText(fmt.Sprintf("That response is not valid JSON for the schema: %s. Reply with the complete corrected JSON only.", last.Err), genai.RoleUser),
+		)
+	case ClassTruncated:
+		// same turn, one more instruction
+		first := *request[0]
+		first.Parts = append(first.Parts[:len(first.Parts):len(first.Parts)],
+			genai.NewPartFromText("A previous answer was cut off at the output token limit. Keep every description short so the whole answer fits."))
+		return append([]*genai.Content{&first}, request[1:]...)
+	}
+	return request
+}
+
+func cacheKey(session *Session, document string, prompt database.PromptVersion, s *schema.Schema) (database.GetCachedResponseParams, error) {
+	data, err := s.JSON()
+	if err != nil {
+		return database.GetCachedResponseParams{}, err
+	}
+	sum := sha256.Sum256(data)
+	return database.GetCachedResponseParams{
+		DocumentHash:    document,
+		PromptVersionID: prompt.ID,
+		Model:           session.model,
+		SchemaHash:      hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// lookup returns the cached payload. A cache that cannot be read, or holds
+// a payload the type no longer decodes, is a miss.
+func lookup[T any](ctx context.Context, session *Session, key database.GetCachedResponseParams) (T, bool) {
+	var payload T
+	cached, err := session.cfg.Db.GetCachedResponse(ctx, key)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("error reading extraction cache: %v", err)
+		}
+		return payload, false
+	}
+	if err := json.Unmarshal(cached.Payload, &payload); err != nil {
+		log.Printf("error decoding cached response %s: %v", cached.ID, err)
+		return payload, false
+	}
+	return payload, true
+}
+
+// store caches a response. A failure is only logged; the response was
+// already paid for and is used either way.
+func store[T any](ctx context.Context, session *Session, key database.GetCachedResponseParams, purpose string, a answer[T]) {
+	payload, err := json.Marshal(a.payload)
+	if err != nil {
+		log.Printf("error encoding %s response for the cache: %v", purpose, err)
+		return
+	}
+	err = session.cfg.Db.SaveCachedResponse(context.WithoutCancel(ctx), database.SaveCachedResponseParams{
+		DocumentHash:    key.DocumentHash,
+		PromptVersionID: key.PromptVersionID,
+		Model:           key.Model,
+		SchemaHash:      key.SchemaHash,
+		Purpose:         purpose,
+		RawResponse:     a.raw,
+		Payload:         payload,
+	})
+	if err != nil {
+		log.Printf("error saving %s response to the cache: %v", purpose, err)
+	}
+}
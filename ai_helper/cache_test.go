@@ -0,0 +1,48 @@
+package ai_helper
+
+import (
+	"bcca_crawler/internal/database"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// TestCacheKey checks every pass of an extraction gets its own cache entry:
+// the section passes share a prompt version but not a schema.
+func TestCacheKey(t *testing.T) {
+	session := &Session{model: "gemini-2.0-flash"}
+	prompt := database.PromptVersion{ID: uuid.New()}
+
+	seen := map[database.GetCachedResponseParams]string{}
+	for _, sec := range Sections {
+		s, err := sectionSchema(sec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := cacheKey(session, "doc", prompt, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := cacheKey(session, "doc", prompt, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != again {
+			t.Errorf("%s: cache key changed between calls", sec.Name)
+		}
+		if other, ok := seen[key]; ok {
+			t.Errorf("%s and %s have the same cache key", other, sec.Name)
+		}
+		seen[key] = sec.Name
+	}
+
+	s, err := ProtocolSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := cacheKey(session, "doc", prompt, s)
+	other, _ := cacheKey(&Session{model: "gemini-2.5-pro"}, "doc", prompt, s)
+	if key == other {
+		t.Error("models share a cache key")
+	}
+}
@@ -0,0 +1,261 @@
+package ai_helper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// ErrorClass is the kind of failure of a model call, which decides whether
+// and how it is retried.
+type ErrorClass string
+
+const (
+	ClassRateLimit      ErrorClass = "rate_limit"      // too many requests for now, retried after the delay the API asks for
+	ClassQuota          ErrorClass = "quota"           // the daily or billing quota is used up
+	ClassSafety         ErrorClass = "safety"          // the prompt or the response was blocked
+	ClassParse          ErrorClass = "parse"           // the response is not JSON of the schema
+	ClassTruncated      ErrorClass = "truncated"       // the response hit the output token limit
+	ClassNetwork        ErrorClass = "network"         // no answer: connection errors, timeouts and 5xx
+	ClassInvalidPDF     ErrorClass = "invalid_pdf"     // the document is not a PDF the model can read
+	ClassInvalidRequest ErrorClass = "invalid_request" // any other 4xx, e.g. a bad key or model name
+)
+
+// policy is how often a class of failure is attempted. Waits start at
+// backoff and double, or follow the delay the API asks for when it is
+// longer. Parse failures and truncated responses are retried at once with
+// the problem added to the prompt.
+type policy struct {
+	attempts int
+	backoff  time.Duration
+}
+
+var policies = map[ErrorClass]policy{
+	ClassRateLimit: {attempts: 5, backoff: 5 * time.Second},
+	ClassNetwork:   {attempts: 4, backoff: 2 * time.Second},
+	ClassParse:     {attempts: 3},
+	ClassTruncated: {attempts: 2},
+	// the others fail the same way every time
+}
+
+const (
+	// maxAttempts caps the calls of one pass whatever the mix of failures.
+	maxAttempts = 8
+	// maxRetryAfter is the longest delay a rate limit is waited out; a
+	// longer one is treated as a used-up quota.
+	maxRetryAfter = 2 * time.Minute
+)
+
+// CallError is the failure of one model call.
+type CallError struct {
+	Attempt    int           `json:"attempt"`
+	Class      ErrorClass    `json:"class"`
+	Err        string        `json:"error"`
+	RetryAfter time.Duration `json:"-"`
+	// RetryAfterSeconds is RetryAfter for the job record.
+	RetryAfterSeconds float64 `json:"retry_after_seconds,omitempty"`
+
+	// raw is the response a parse failure is about, for the re-prompt.
+	raw string
+}
+
+func (e *CallError) Error() string {
+	return fmt.Sprintf("attempt %d: %s: %s", e.Attempt, e.Class, e.Err)
+}
+
+// CallsError is returned when a pass gives up. It keeps the error of every
+// call, oldest first.
+type CallsError struct {
+	Calls []*CallError
+}
+
+func (e *CallsError) Error() string {
+	msgs := make([]string, len(e.Calls))
+	for i, c := range e.Calls {
+		msgs[i] = c.Error()
+	}
+	return fmt.Sprintf("after %d attempts: %s", len(e.Calls), strings.Join(msgs, "; "))
+}
+
+// Last is the failure the pass gave up on.
+func (e *CallsError) Last() *CallError {
+	return e.Calls[len(e.Calls)-1]
+}
+
+// Permanent reports a failure that retrying the job cannot fix. A used-up
+// quota or a rate limit is not: the job is tried again later.
+func (e *CallsError) Permanent() bool {
+	switch e.Last().Class {
+	case ClassSafety, ClassInvalidPDF, ClassInvalidRequest:
+		return true
+	}
+	return false
+}
+
+// Calls returns the model call errors within err, e.g. for a job record.
+func Calls(err error) []*CallError {
+	var calls *CallsError
+	if errors.As(err, &calls) {
+		return calls.Calls
+	}
+	return nil
+}
+
+// classify turns the error of a GenerateContent call into a CallError.
+func classify(err error) *CallError {
+	e := &CallError{Class: ClassNetwork, Err: err.Error()}
+
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		// no status from the API: the request or its answer got lost
+		return e
+	}
+
+	switch {
+	case apiErr.Code == 429 || apiErr.Status == "RESOURCE_EXHAUSTED":
+		e.Class = ClassRateLimit
+		e.RetryAfter = retryDelay(apiErr.Details)
+		if dailyQuota(apiErr.Details) || e.RetryAfter > maxRetryAfter {
+			e.Class = ClassQuota
+		}
+	case apiErr.Code >= 500:
+		e.Class = ClassNetwork
+	case apiErr.Code == 400 && mentionsDocument(apiErr.Message):
+		e.Class = ClassInvalidPDF
+	default:
+		e.Class = ClassInvalidRequest
+	}
+	e.RetryAfterSeconds = e.RetryAfter.Seconds()
+	return e
+}
+
+// checkResponse classifies a response that came back without a usable
+// answer. text is the JSON of the answer.
+func checkResponse(response *genai.GenerateContentResponse) (text string, err *CallError) {
+	if f := response.PromptFeedback; f != nil && f.BlockReason != "" {
+		return "", &CallError{Class: ClassSafety, Err: fmt.Sprintf("prompt blocked: %s %s", f.BlockReason, f.BlockReasonMessage)}
+	}
+	if len(response.Candidates) == 0 {
+		return "", &CallError{Class: ClassParse, Err: "no candidates in the response"}
+	}
+	switch reason := response.Candidates[0].FinishReason; reason {
+	case genai.FinishReasonMaxTokens:
+		return response.Text(), &CallError{Class: ClassTruncated, Err: "response cut off at the output token limit"}
+	case genai.FinishReasonSafety, genai.FinishReasonRecitation, genai.FinishReasonBlocklist,
+		genai.FinishReasonProhibitedContent, genai.FinishReasonSPII:
+		return "", &CallError{Class: ClassSafety, Err: fmt.Sprintf("response blocked: %s", reason)}
+	}
+	text = response.Text()
+	if strings.TrimSpace(text) == "" {
+		return "", &CallError{Class: ClassParse, Err: "empty response"}
+	}
+	return text, nil
+}
+
+// checkPDF rejects a document that is not a PDF before it is sent.
+func checkPDF(pdf []byte) *CallError {
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		return &CallError{Class: ClassInvalidPDF, Err: "document does not start with a PDF header"}
+	}
+	return nil
+}
+
+// mentionsDocument reports a 400 about the attached document rather than
+// the request.
+func mentionsDocument(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, s := range []string{"pdf", "document", "no pages", "mime", "inline_data", "unable to process input"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryDelay reads the google.rpc.RetryInfo detail of a 429, e.g.
+// {"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "37s"}.
+func retryDelay(details []map[string]any) time.Duration {
+	for _, d := range details {
+		if t, _ := d["@type"].(string); !strings.HasSuffix(t, "google.rpc.RetryInfo") {
+			continue
+		}
+		delay, _ := d["retryDelay"].(string)
+		if wait, err := time.ParseDuration(delay); err == nil {
+			return wait
+		}
+	}
+	return 0
+}
+
+// dailyQuota reports a google.rpc.QuotaFailure for a per-day quota, which
+// no wait within a job fixes.
+func dailyQuota(details []map[string]any) bool {
+	for _, d := range details {
+		if t, _ := d["@type"].(string); !strings.HasSuffix(t, "google.rpc.QuotaFailure") {
+			continue
+		}
+		violations, _ := d["violations"].([]any)
+		for _, v := range violations {
+			m, _ := v.(map[string]any)
+			id, _ := m["quotaId"].(string)
+			if strings.Contains(id, "PerDay") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// retry calls fn until it succeeds or the policy of its failure runs out.
+// fn gets the previous failure, if any, to adjust the request to it.
+func retry[T any](ctx context.Context, fn func(attempt int, last *CallError) (T, *CallError)) (T, error) {
+	var zero T
+	var calls []*CallError
+	failures := map[ErrorClass]int{}
+	var last *CallError
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		result, err := fn(attempt, last)
+		if err == nil {
+			return result, nil
+		}
+		err.Attempt = attempt
+		calls = append(calls, err)
+		last = err
+
+		failures[err.Class]++
+		p, ok := policies[err.Class]
+		if !ok || failures[err.Class] >= p.attempts {
+			break
+		}
+		wait := p.backoff << (failures[err.Class] - 1)
+		if err.RetryAfter > wait {
+			wait = err.RetryAfter
+		}
+		if wait > 0 {
+			fmt.Printf("Attempt %d failed (%s), retrying in %s\n", attempt, err.Class, wait)
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return zero, err
+		}
+	}
+	return zero, &CallsError{Calls: calls}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,138 @@
+package ai_helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestClassify(t *testing.T) {
+	retryInfo := map[string]any{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "37s"}
+	for _, tc := range []struct {
+		name       string
+		err        error
+		class      ErrorClass
+		retryAfter time.Duration
+	}{
+		{"rate limit", genai.APIError{Code: 429, Status: "RESOURCE_EXHAUSTED", Details: []map[string]any{retryInfo}}, ClassRateLimit, 37 * time.Second},
+		{"daily quota", genai.APIError{Code: 429, Details: []map[string]any{
+			{"@type": "type.googleapis.com/google.rpc.QuotaFailure", "violations": []any{map[string]any{"quotaId": "GenerateRequestsPerDayPerProjectPerModel-FreeTier"}}},
+			retryInfo,
+		}}, ClassQuota, 37 * time.Second},
+		{"long retry delay", genai.APIError{Code: 429, Details: []map[string]any{{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "3600s"}}}, ClassQuota, time.Hour},
+		{"unavailable", genai.APIError{Code: 503, Status: "UNAVAILABLE"}, ClassNetwork, 0},
+		{"bad document", genai.APIError{Code: 400, Message: "The document has no pages."}, ClassInvalidPDF, 0},
+		{"bad key", genai.APIError{Code: 400, Message: "API key not valid."}, ClassInvalidRequest, 0},
+		{"wrapped", fmt.Errorf("generate: %w", genai.APIError{Code: 404, Message: "model not found"}), ClassInvalidRequest, 0},
+		{"connection", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, ClassNetwork, 0},
+	} {
+		got := classify(tc.err)
+		if got.Class != tc.class || got.RetryAfter != tc.retryAfter {
+			t.Errorf("%s: got %s after %s, want %s after %s", tc.name, got.Class, got.RetryAfter, tc.class, tc.retryAfter)
+		}
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	text := func(s string) []*genai.Candidate {
+		return []*genai.Candidate{{Content: genai.NewContentFromText(s, genai.RoleModel), FinishReason: genai.FinishReasonStop}}
+	}
+	for _, tc := range []struct {
+		name     string
+		response *genai.GenerateContentResponse
+		class    ErrorClass
+	}{
+		{"ok", &genai.GenerateContentResponse{Candidates: text(`{}`)}, ""},
+		{"prompt blocked", &genai.GenerateContentResponse{PromptFeedback: &genai.GenerateContentResponsePromptFeedback{BlockReason: genai.BlockedReasonSafety}}, ClassSafety},
+		{"no candidates", &genai.GenerateContentResponse{}, ClassParse},
+		{"empty", &genai.GenerateContentResponse{Candidates: text(" ")}, ClassParse},
+		{"truncated", &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{FinishReason: genai.FinishReasonMaxTokens}}}, ClassTruncated},
+		{"recitation", &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{FinishReason: genai.FinishReasonRecitation}}}, ClassSafety},
+	} {
+		_, err := checkResponse(tc.response)
+		var got ErrorClass
+		if err != nil {
+			got = err.Class
+		}
+		if got != tc.class {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.class)
+		}
+	}
+}
+
+// TestRetry checks the policies that do not wait: parse failures are
+// retried with the previous failure at hand, invalid input is not retried,
+// and every failure is kept.
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+
+	var seen []*CallError
+	_, err := retry(ctx, func(attempt int, last *CallError) (int, *CallError) {
+		seen = append(seen, last)
+		return 0, &CallError{Class: ClassParse, Err: "unexpected end of JSON input", raw: `{"a":`}
+	})
+	calls := Calls(err)
+	if len(calls) != policies[ClassParse].attempts {
+		t.Fatalf("parse failures: %d calls, want %d: %v", len(calls), policies[ClassParse].attempts, err)
+	}
+	if seen[0] != nil || seen[1] != calls[0] {
+		t.Error("fn did not get the previous failure")
+	}
+	for i, c := range calls {
+		if c.Attempt != i+1 {
+			t.Errorf("call %d recorded as attempt %d", i+1, c.Attempt)
+		}
+	}
+
+	n := 0
+	_, err = retry(ctx, func(attempt int, last *CallError) (int, *CallError) {
+		n++
+		return 0, &CallError{Class: ClassInvalidPDF, Err: "not a PDF"}
+	})
+	var ce *CallsError
+	if n != 1 || !errors.As(err, &ce) || !ce.Permanent() {
+		t.Errorf("invalid PDF: %d calls, error %v, want 1 permanent", n, err)
+	}
+
+	got, err := retry(ctx, func(attempt int, last *CallError) (int, *CallError) {
+		if attempt == 1 {
+			return 0, &CallError{Class: ClassTruncated, Err: "cut off"}
+		}
+		return attempt, nil
+	})
+	if err != nil || got != 2 {
+		t.Errorf("truncated then ok: got %d, %v", got, err)
+	}
+}
+
+func TestReprompt(t *testing.T) {
+	request := []*genai.Content{genai.NewContentFromParts([]*genai.Part{
+		genai.NewPartFromText("prompt"),
+		genai.NewPartFromBytes([]byte("%PDF-1.7"), "application/pdf"),
+	}, genai.RoleUser)}
+
+	if got := reprompt(request, nil); len(got) != 1 {
+		t.Errorf("first attempt changed the request")
+	}
+	if got := reprompt(request, &CallError{Class: ClassNetwork}); len(got) != 1 || len(got[0].Parts) != 2 {
+		t.Errorf("network failure changed the request")
+	}
+
+	got := reprompt(request, &CallError{Class: ClassParse, Err: "bad", raw: `{"a":`})
+	if len(got) != 3 || got[1].Role != genai.RoleModel || got[2].Role != genai.RoleUser {
+		t.Errorf("parse failure: got %d contents, want the answer and the correction", len(got))
+	}
+
+	got = reprompt(request, &CallError{Class: ClassTruncated})
+	if len(got) != 1 || len(got[0].Parts) != 3 {
+		t.Errorf("truncated: want one more part in the same turn")
+	}
+	if len(request[0].Parts) != 2 {
+		t.Errorf("reprompt modified the original request")
+	}
+}
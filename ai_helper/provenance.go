@@ -0,0 +1,68 @@
+package ai_helper
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// maxQuote caps a stored quote; the schema asks for 200 characters, a model
+// that copies a whole paragraph is cut.
+const maxQuote = 300
+
+// provenance records where the model read the items of one extraction of a
+// protocol.
+type provenance struct {
+	s            *config.Config
+	protocolID   uuid.UUID
+	extractionID uuid.NullUUID
+}
+
+// newProvenance starts recording the sources of an extraction, dropping
+// those of the protocol's earlier extractions: an item the new extraction
+// leaves out or gives no source for has none.
+func newProvenance(ctx context.Context, s *config.Config, protocolID uuid.UUID, extractionID uuid.UUID) (provenance, error) {
+	if err := s.Db.DeleteProtocolProvenance(ctx, protocolID); err != nil {
+		return provenance{}, fmt.Errorf("error clearing provenance: %s, with error: %v", protocolID, err)
+	}
+	return provenance{s: s, protocolID: protocolID, extractionID: uuid.NullUUID{UUID: extractionID, Valid: true}}, nil
+}
+
+// record saves the source of an item.
+func (p provenance) record(ctx context.Context, entity database.ProvenanceEntityEnum, id uuid.UUID, src *api.Source) error {
+	if src == nil || id == uuid.Nil {
+		return nil
+	}
+	quote := cleanQuote(src.Quote)
+	if src.Page <= 0 && quote == "" {
+		return nil
+	}
+	err := p.s.Db.UpsertProvenance(ctx, database.UpsertProvenanceParams{
+		ProtocolID:   p.protocolID,
+		EntityType:   entity,
+		EntityID:     id,
+		Page:         sql.NullInt32{Int32: src.Page, Valid: src.Page > 0},
+		Quote:        quote,
+		ExtractionID: p.extractionID,
+	})
+	if err != nil {
+		return fmt.Errorf("error recording provenance of %s: %s, with error: %v", entity, id, err)
+	}
+	return nil
+}
+
+// cleanQuote collapses the whitespace of a quote and cuts it to maxQuote
+// characters.
+func cleanQuote(quote string) string {
+	quote = strings.Join(strings.Fields(quote), " ")
+	if runes := []rune(quote); len(runes) > maxQuote {
+		quote = string(runes[:maxQuote])
+	}
+	return quote
+}
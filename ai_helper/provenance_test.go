@@ -0,0 +1,124 @@
+package ai_helper
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+func TestCleanQuote(t *testing.T) {
+	if got, want := cleanQuote("  ANC < 1.0\n  x 10⁹/L:\tdelay "), "ANC < 1.0 x 10⁹/L: delay"; got != want {
+		t.Errorf("cleanQuote = %q, want %q", got, want)
+	}
+	long := cleanQuote(strings.Repeat("é", maxQuote+10))
+	if utf8.RuneCountInString(long) != maxQuote || !utf8.ValidString(long) {
+		t.Errorf("long quote cut to %d characters", utf8.RuneCountInString(long))
+	}
+}
+
+// provenanceTable keeps the provenance rows the queries write, standing in
+// for the database.
+type provenanceTable struct {
+	rows []database.UpsertProvenanceParams
+}
+
+func (t *provenanceTable) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	name := strings.Fields(strings.TrimPrefix(query, "-- name: "))[0]
+	switch name {
+	case "DeleteProtocolProvenance":
+		kept := t.rows[:0]
+		for _, row := range t.rows {
+			if row.ProtocolID != args[0].(uuid.UUID) {
+				kept = append(kept, row)
+			}
+		}
+		t.rows = kept
+	case "UpsertProvenance":
+		row := database.UpsertProvenanceParams{
+			ProtocolID:   args[0].(uuid.UUID),
+			EntityType:   args[1].(database.ProvenanceEntityEnum),
+			EntityID:     args[2].(uuid.UUID),
+			Page:         args[3].(sql.NullInt32),
+			Quote:        args[4].(string),
+			ExtractionID: args[5].(uuid.NullUUID),
+		}
+		for i, old := range t.rows {
+			if old.ProtocolID == row.ProtocolID && old.EntityType == row.EntityType && old.EntityID == row.EntityID {
+				t.rows[i] = row
+				return driver.RowsAffected(1), nil
+			}
+		}
+		t.rows = append(t.rows, row)
+	default:
+		return nil, fmt.Errorf("unexpected query %s", name)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (t *provenanceTable) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (t *provenanceTable) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (t *provenanceTable) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func TestReextractionReplacesProvenance(t *testing.T) {
+	ctx := context.Background()
+	table := &provenanceTable{}
+	s := &config.Config{Db: database.New(table)}
+	protocol, other := uuid.New(), uuid.New()
+	kept, dropped, unsourced := uuid.New(), uuid.New(), uuid.New()
+
+	first, err := newProvenance(ctx, s, protocol, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []uuid.UUID{kept, dropped, unsourced} {
+		if err := first.record(ctx, database.ProvenanceEntityEnumTest, id, &api.Source{Page: 2, Quote: "CBC & diff"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	elsewhere, _ := newProvenance(ctx, s, other, uuid.New())
+	elsewhere.record(ctx, database.ProvenanceEntityEnumTest, kept, &api.Source{Page: 1, Quote: "CBC"})
+
+	// the next extraction keeps one item, moved to another page, drops one
+	// and gives no source for the last
+	secondID := uuid.New()
+	second, err := newProvenance(ctx, s, protocol, secondID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.record(ctx, database.ProvenanceEntityEnumTest, kept, &api.Source{Page: 3, Quote: "CBC & diff"})
+	second.record(ctx, database.ProvenanceEntityEnumTest, unsourced, nil)
+
+	var rows []database.UpsertProvenanceParams
+	for _, row := range table.rows {
+		if row.ProtocolID == protocol {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) != 1 {
+		t.Fatalf("protocol has %d provenance rows, want 1: %+v", len(rows), rows)
+	}
+	if got := rows[0]; got.EntityID != kept || got.Page.Int32 != 3 || got.ExtractionID.UUID != secondID {
+		t.Errorf("provenance = %+v, want page 3 of the second extraction", got)
+	}
+	if len(table.rows) != 2 {
+		t.Errorf("%d rows in all, want the other protocol's kept", len(table.rows))
+	}
+}
@@ -0,0 +1,202 @@
+package ai_helper
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/schema"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden protocol schema")
+
+const goldenSchema = "testdata/protocol_schema.json"
+
+// TestProtocolSchemaGolden compares the generated JSON Schema with the
+// reviewed copy in testdata, so a change to the api types that changes what
+// the model is asked for shows up in review. Run with -update after an
+// intended change.
+func TestProtocolSchemaGolden(t *testing.T) {
+	s, err := ProtocolSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	if *update {
+		if err := os.WriteFile(goldenSchema, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(goldenSchema)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("generated schema does not match %s, run with -update if the change is intended\ngot:\n%s", goldenSchema, got)
+	}
+}
+
+// TestProtocolSchemaDecodes fills every property of the schema and checks
+// the response decodes into ProtocolPayload without unknown fields and
+// encodes back to the same values.
+func TestProtocolSchemaDecodes(t *testing.T) {
+	s, err := ProtocolSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample, err := json.Marshal(example(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(sample))
+	dec.DisallowUnknownFields()
+	var payload ProtocolPayload
+	if err := dec.Decode(&payload); err != nil {
+		t.Fatalf("decoding a response that follows the schema: %v", err)
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want, got any
+	if err := json.Unmarshal(sample, &want); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(encoded, &got); err != nil {
+		t.Fatal(err)
+	}
+	if path := missing(want, got, "$"); path != "" {
+		t.Errorf("%s is in the schema but lost when decoded into ProtocolPayload", path)
+	}
+}
+
+// TestSchemaEnumsMatchDatabase checks the enums shared with the database
+// against the migrations.
+func TestSchemaEnumsMatchDatabase(t *testing.T) {
+	enums := sqlEnums(t)
+	for name, values := range map[string][]string{
+		"tumor_group_enum":        api.TumorGroups,
+		"prescription_route_enum": api.PrescriptionRoutes,
+	} {
+		got := append([]string(nil), values...)
+		want := append([]string(nil), enums[name]...)
+		sort.Strings(got)
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s is %v in the migrations but %v in the api package", name, want, got)
+		}
+	}
+}
+
+// example returns a value for every property of s.
+func example(s *schema.Schema) any {
+	switch s.Type {
+	case schema.TypeObject:
+		obj := map[string]any{}
+		for _, p := range s.Properties {
+			obj[p.Name] = example(p.Schema)
+		}
+		return obj
+	case schema.TypeArray:
+		n := 1
+		if s.MinItems != nil && *s.MinItems > 1 {
+			n = int(*s.MinItems)
+		}
+		items := make([]any, n)
+		for i := range items {
+			items[i] = example(s.Items)
+		}
+		return items
+	case schema.TypeString:
+		if len(s.Enum) > 0 {
+			return s.Enum[len(s.Enum)-1]
+		}
+		return "text"
+	case schema.TypeInteger:
+		return 2
+	case schema.TypeNumber:
+		return 2.5
+	case schema.TypeBoolean:
+		return true
+	}
+	return nil
+}
+
+// missing returns the path of the first value of want that got lacks.
+func missing(want, got any, path string) string {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return path
+		}
+		for k, v := range w {
+			if p := missing(v, g[k], path+"."+k); p != "" {
+				return p
+			}
+		}
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			return path
+		}
+		for i := range w {
+			if p := missing(w[i], g[i], path+"[]"); p != "" {
+				return p
+			}
+		}
+	default:
+		if !reflect.DeepEqual(want, got) {
+			return path
+		}
+	}
+	return ""
+}
+
+var (
+	createEnum = regexp.MustCompile(`(?is)CREATE TYPE\s+(\w+)\s+AS ENUM\s*\(([^)]*)\)`)
+	addValue   = regexp.MustCompile(`(?i)ALTER TYPE\s+(\w+)\s+ADD VALUE\s+(?:IF NOT EXISTS\s+)?'([^']*)'`)
+	quoted     = regexp.MustCompile(`'([^']*)'`)
+)
+
+// sqlEnums reads the enum types the migrations create and extend.
+func sqlEnums(t *testing.T) map[string][]string {
+	t.Helper()
+	files, err := filepath.Glob("../sql/schema/*.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations found: %v", err)
+	}
+	enums := map[string][]string{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// only the up migration
+		up, _, _ := strings.Cut(string(data), "-- +goose Down")
+		for _, m := range createEnum.FindAllStringSubmatch(up, -1) {
+			for _, v := range quoted.FindAllStringSubmatch(m[2], -1) {
+				enums[m[1]] = append(enums[m[1]], v[1])
+			}
+		}
+		for _, m := range addValue.FindAllStringSubmatch(up, -1) {
+			enums[m[1]] = append(enums[m[1]], m[2])
+		}
+	}
+	return enums
+}
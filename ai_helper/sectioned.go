@@ -0,0 +1,248 @@
+package ai_helper
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"bcca_crawler/prompts"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Section is one pass of a sectioned extraction after the overview. It
+// fills the listed properties of ProtocolPayload from a schema holding only
+// those.
+type Section struct {
+	Name   string
+	Fields []string
+}
+
+// Sections are the passes of a sectioned extraction, run side by side once
+// the overview has found where each section is.
+var Sections = []Section{
+	{Name: "cycles", Fields: []string{"protocol_cycles"}},
+	{Name: "prescriptions", Fields: []string{"prescription_groups"}},
+	{Name: "tests", Fields: []string{"test_groups"}},
+	{Name: "toxicities", Fields: []string{"toxicities"}},
+	{Name: "eligibility", Fields: []string{"protocol_eligibility_criteria", "protocol_precautions", "protocol_cautions"}},
+}
+
+// overviewFields are the properties of ProtocolPayload the overview pass
+// fills itself.
+var overviewFields = []string{"summary_protocol", "physicians", "article_references"}
+
+// SectionLocation is where the overview pass found a section.
+type SectionLocation struct {
+	Section  string   `json:"section" desc:"The section of the protocol." schema:"required,enum=extraction_section"`
+	Headings []string `json:"headings" desc:"Headings the section appears under in the document." schema:"required"`
+	Pages    string   `json:"pages" desc:"Pages the section spans (e.g., '3-5')." schema:"required"`
+}
+
+type overviewResponse struct {
+	ProtocolPayload
+	SectionMap []SectionLocation `json:"section_map" desc:"Where each section of the protocol is found in the document." schema:"required"`
+}
+
+// Conflict is something the passes of a sectioned extraction disagree on.
+// The merged payload keeps the first value; conflicts are recorded with the
+// extraction for review.
+type Conflict struct {
+	Section string `json:"section"`
+	Item    string `json:"item,omitempty"`
+	Detail  string `json:"detail"`
+}
+
+// ExtractSectioned reads a protocol in several calls, for protocols too long
+// to extract in one: an overview pass for the summary and a map of the
+// sections, then a focused pass per section with its own schema. The
+// results are merged into one payload.
+func ExtractSectioned(ctx context.Context, s *config.Config, pdf []byte, overview, section database.PromptVersion, tumorGroup string) (ProtocolPayload, []Conflict, error) {
+	session, err := NewSession(ctx, s)
+	if err != nil {
+		return ProtocolPayload{}, nil, err
+	}
+
+	text, err := prompts.Render(overview, prompts.Vars{TumorGroup: tumorGroup})
+	if err != nil {
+		return ProtocolPayload{}, nil, err
+	}
+	oschema, err := overviewSchema()
+	if err != nil {
+		return ProtocolPayload{}, nil, err
+	}
+	outline, err := pass[overviewResponse](ctx, session, pdf, "overview", overview, text, oschema)
+	if err != nil {
+		return ProtocolPayload{}, nil, fmt.Errorf("overview pass failed: %w", err)
+	}
+
+	results := make([]ProtocolPayload, len(Sections))
+	errs := make([]error, len(Sections))
+	var wg sync.WaitGroup
+	for i, sec := range Sections {
+		text, err := prompts.Render(section, prompts.Vars{
+			TumorGroup: tumorGroup,
+			Section:    sec.Name,
+			Location:   location(outline.SectionMap, sec.Name),
+		})
+		if err != nil {
+			return ProtocolPayload{}, nil, err
+		}
+		sschema, err := sectionSchema(sec)
+		if err != nil {
+			return ProtocolPayload{}, nil, err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = pass[ProtocolPayload](ctx, session, pdf, "section:"+sec.Name, section, text, sschema)
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return ProtocolPayload{}, nil, fmt.Errorf("%s pass failed: %w", Sections[i].Name, err)
+		}
+	}
+
+	payload, conflicts := merge(outline, results)
+	return payload, conflicts, nil
+}
+
+// location describes where the overview found a section, for the prompt of
+// its pass, or is empty when it did not.
+func location(sectionMap []SectionLocation, section string) string {
+	var parts []string
+	for _, loc := range sectionMap {
+		if loc.Section != section {
+			continue
+		}
+		where := "on pages " + loc.Pages
+		if len(loc.Headings) > 0 {
+			where += " under " + `"` + strings.Join(loc.Headings, `", "`) + `"`
+		}
+		parts = append(parts, where)
+	}
+	return strings.Join(parts, ", and ")
+}
+
+// merge assembles the payload from the overview and the section passes, in
+// the order of Sections, and reports what they disagree on.
+func merge(outline overviewResponse, results []ProtocolPayload) (ProtocolPayload, []Conflict) {
+	var payload ProtocolPayload
+	copyFields(&payload, outline.ProtocolPayload, overviewFields)
+
+	var conflicts []Conflict
+	for i, sec := range Sections {
+		copyFields(&payload, results[i], sec.Fields)
+		if location(outline.SectionMap, sec.Name) != "" && emptyFields(results[i], sec.Fields) {
+			conflicts = append(conflicts, Conflict{Section: sec.Name, Detail: "the overview found this section but its pass returned nothing"})
+		}
+	}
+
+	var dup []Conflict
+	payload.Toxicities, dup = distinctToxicities(payload.Toxicities)
+	conflicts = append(conflicts, dup...)
+	conflicts = append(conflicts, medicationConflicts(payload)...)
+	return payload, conflicts
+}
+
+// distinctToxicities drops a toxicity repeated as is, wherever it was read,
+// and keeps the first of two with the same title but different content.
+func distinctToxicities(toxicities []api.Toxicity) ([]api.Toxicity, []Conflict) {
+	var out []api.Toxicity
+	var conflicts []Conflict
+	seen := map[string]int{}
+	for _, tox := range toxicities {
+		key := strings.ToLower(strings.TrimSpace(tox.Title))
+		if i, ok := seen[key]; ok {
+			if !reflect.DeepEqual(withoutSources(out[i]), withoutSources(tox)) {
+				conflicts = append(conflicts, Conflict{Section: "toxicities", Item: tox.Title, Detail: "listed twice with different content, the first is kept"})
+			}
+			continue
+		}
+		seen[key] = len(out)
+		out = append(out, tox)
+	}
+	return out, conflicts
+}
+
+func withoutSources(tox api.Toxicity) api.Toxicity {
+	mods := make([]api.ToxicityModification, len(tox.Modifications))
+	for i, mod := range tox.Modifications {
+		mod.Source = nil
+		mods[i] = mod
+	}
+	tox.Modifications = mods
+	return tox
+}
+
+// medicationConflicts reports medications the cycles pass took as a
+// treatment and the prescriptions pass as a prescription.
+func medicationConflicts(p ProtocolPayload) []Conflict {
+	treatments := map[string]api.Treatment{}
+	for _, cycle := range p.ProtocolCycles {
+		for _, tx := range cycle.Treatments {
+			key := strings.ToLower(strings.TrimSpace(tx.MedicationName))
+			if _, ok := treatments[key]; !ok {
+				treatments[key] = tx
+			}
+		}
+	}
+	var conflicts []Conflict
+	reported := map[string]bool{}
+	for _, group := range p.PrescriptionGroups {
+		for _, px := range group.Prescriptions {
+			key := strings.ToLower(strings.TrimSpace(px.MedicationName))
+			tx, ok := treatments[key]
+			if !ok || reported[key] {
+				continue
+			}
+			reported[key] = true
+			conflicts = append(conflicts, Conflict{
+				Section: "prescriptions",
+				Item:    px.MedicationName,
+				Detail:  fmt.Sprintf("also a treatment of the cycles (%s %s as a treatment, %s %s as a prescription)", tx.Dose, tx.Route, px.Dose, px.Route),
+			})
+		}
+	}
+	return conflicts
+}
+
+// copyFields sets the properties of dst named by their json names to those
+// of src.
+func copyFields(dst *ProtocolPayload, src ProtocolPayload, fields []string) {
+	d, sv := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src)
+	for i := 0; i < d.NumField(); i++ {
+		if contains(fields, jsonName(d.Type().Field(i))) {
+			d.Field(i).Set(sv.Field(i))
+		}
+	}
+}
+
+// emptyFields reports whether all the named properties of p are empty.
+func emptyFields(p ProtocolPayload, fields []string) bool {
+	v := reflect.ValueOf(p)
+	for i := 0; i < v.NumField(); i++ {
+		if contains(fields, jsonName(v.Type().Field(i))) && !v.Field(i).IsZero() && !(v.Field(i).Kind() == reflect.Slice && v.Field(i).Len() == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return name
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package ai_helper
+
+import (
+	"bcca_crawler/api"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// TestSectionsCoverPayload checks every property of the protocol schema is
+// filled by exactly one pass of a sectioned extraction, so a field added to
+// ProtocolPayload cannot be left out of it.
+func TestSectionsCoverPayload(t *testing.T) {
+	s, err := ProtocolSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := map[string]string{}
+	add := func(pass string, fields []string) {
+		for _, f := range fields {
+			if s.Property(f) == nil {
+				t.Errorf("%s pass fills %s, which is not in the schema", pass, f)
+			}
+			if prev, ok := owner[f]; ok {
+				t.Errorf("%s is filled by both the %s and %s passes", f, prev, pass)
+			}
+			owner[f] = pass
+		}
+	}
+	add("overview", overviewFields)
+	for _, sec := range Sections {
+		add(sec.Name, sec.Fields)
+	}
+	for _, p := range s.Properties {
+		if _, ok := owner[p.Name]; !ok {
+			t.Errorf("%s is not filled by any pass", p.Name)
+		}
+	}
+}
+
+// TestPassSchemasDecode checks a response following the schema of each pass
+// decodes without unknown fields.
+func TestPassSchemasDecode(t *testing.T) {
+	o, err := overviewSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeStrict(t, "overview", example(o), &overviewResponse{})
+	for _, sec := range Sections {
+		s, err := sectionSchema(sec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(s.Properties) != len(sec.Fields) {
+			t.Errorf("%s schema has %d properties, want %d", sec.Name, len(s.Properties), len(sec.Fields))
+		}
+		decodeStrict(t, sec.Name, example(s), &ProtocolPayload{})
+	}
+}
+
+func decodeStrict(t *testing.T, name string, sample any, v any) {
+	t.Helper()
+	data, err := json.Marshal(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		t.Errorf("decoding a %s response: %v", name, err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	outline := overviewResponse{
+		ProtocolPayload: ProtocolPayload{ProtocolSummary: api.SummaryProtocol{Code: "LYCHOP"}},
+		SectionMap: []SectionLocation{
+			{Section: "toxicities", Pages: "4-5", Headings: []string{"Dose modifications"}},
+			{Section: "tests", Pages: "2"},
+		},
+	}
+	neutropenia := api.Toxicity{Title: "Neutropenia", Category: "Hematologic", Modifications: []api.ToxicityModification{
+		{Grade: "3", Adjustment: "Delay", Source: &api.Source{Page: 4, Quote: "ANC < 1.0: delay"}},
+	}}
+	// the same toxicity read again in a summary table
+	again := neutropenia
+	again.Modifications = []api.ToxicityModification{{Grade: "3", Adjustment: "Delay", Source: &api.Source{Page: 5}}}
+	results := make([]ProtocolPayload, len(Sections))
+	for i, sec := range Sections {
+		switch sec.Name {
+		case "cycles":
+			results[i].ProtocolCycles = []api.ProtocolCycle{{Cycle: "Cycle 1+", Treatments: []api.Treatment{{MedicationName: "Prednisone", Dose: "100 mg"}}}}
+		case "prescriptions":
+			results[i].PrescriptionGroups = []api.PrescriptionGroup{{Prescriptions: []api.Prescription{
+				{MedicationName: "prednisone", Dose: "100 mg"},
+				{MedicationName: "Ondansetron", Dose: "8 mg"},
+			}}}
+		case "toxicities":
+			// also leaks a field of another pass, which is ignored
+			results[i].Toxicities = []api.Toxicity{neutropenia, again, {Title: "neutropenia", Category: "Other"}}
+			results[i].Physicians = []api.Physician{{LastName: "Smith"}}
+		}
+	}
+
+	payload, conflicts := merge(outline, results)
+	if payload.ProtocolSummary.Code != "LYCHOP" {
+		t.Errorf("summary not taken from the overview: %+v", payload.ProtocolSummary)
+	}
+	if len(payload.Physicians) != 0 {
+		t.Errorf("physicians taken from the toxicities pass: %+v", payload.Physicians)
+	}
+	if len(payload.ProtocolCycles) != 1 || len(payload.PrescriptionGroups) != 1 {
+		t.Errorf("sections not merged: %+v", payload)
+	}
+	if len(payload.Toxicities) != 1 || payload.Toxicities[0].Category != "Hematologic" {
+		t.Errorf("toxicities = %+v, want the first neutropenia only", payload.Toxicities)
+	}
+
+	want := map[string]int{"tests": 1, "toxicities": 1, "prescriptions": 1}
+	got := map[string]int{}
+	for _, c := range conflicts {
+		got[c.Section]++
+	}
+	for section, n := range want {
+		if got[section] != n {
+			t.Errorf("%d %s conflicts, want %d: %+v", got[section], section, n, conflicts)
+		}
+	}
+	if len(conflicts) != 3 {
+		t.Errorf("got %d conflicts, want 3: %+v", len(conflicts), conflicts)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	sectionMap := []SectionLocation{
+		{Section: "toxicities", Pages: "4-5", Headings: []string{"Dose modifications", "Precautions"}},
+		{Section: "toxicities", Pages: "9"},
+	}
+	want := `on pages 4-5 under "Dose modifications", "Precautions", and on pages 9`
+	if got := location(sectionMap, "toxicities"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := location(sectionMap, "tests"); got != "" {
+		t.Errorf("got %q for a section the overview did not find", got)
+	}
+}
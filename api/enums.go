@@ -0,0 +1,28 @@
+package api
+
+// TumorGroups are the values of tumor_group_enum (004_types.sql).
+var TumorGroups = []string{"breast", "lung", "gastrointestinal", "genitourinary", "head_and_neck", "gynecology", "sarcoma", "leukemia", "bmt", "neuro-oncology", "ocular", "skin", "unknown_primary", "lymphoma", "myeloma", "unknown"}
+
+// PrescriptionRoutes are the values of prescription_route_enum.
+var PrescriptionRoutes = []string{"oral", "iv", "im", "sc", "topical", "inhalation", "unknown"}
+
+var MedicationCategories = []string{"Antibiotic", "Antiemetic", "Antifungal", "Antihypertensive", "Antineoplastic", "Antipyretic", "Antiviral", "Bronchodilator", "Diuretic", "Immunosuppressant", "Narcotic", "NSAID", "Steroid", "Other"}
+
+var ToxicityCategories = []string{"Hematologic", "Neurologic", "Gastrointestinal", "Dermatologic", "Hepatic", "Renal", "Pulmonary", "Cardiovascular", "Endocrine", "Metabolic", "Immune", "Other"}
+
+// SchemaEnums are the enums the schema tags of the extraction types refer
+// to by name.
+var SchemaEnums = map[string][]string{
+	"tumor_group":         TumorGroups,
+	"prescription_route":  PrescriptionRoutes,
+	"medication_category": MedicationCategories,
+	"toxicity_category":   ToxicityCategories,
+}
+
+func valueSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
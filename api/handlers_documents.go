@@ -0,0 +1,92 @@
+package api
+
+import (
+	"bcca_crawler/docstore"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"bcca_crawler/internal/json_utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// StoreDocument saves a downloaded document in the document store and
+// records its URL, fetch time and headers in source_documents.
+func StoreDocument(c *config.Config, ctx context.Context, body []byte, meta docstore.Meta) (docstore.Meta, error) {
+	if c.Documents == nil {
+		return docstore.Meta{}, fmt.Errorf("no document store configured")
+	}
+	stored, err := c.Documents.Put(ctx, body, meta)
+	if err != nil {
+		return docstore.Meta{}, fmt.Errorf("error storing document: %s, with error: %v", meta.URL, err)
+	}
+
+	// the row keeps the latest fetch, the store keeps the first
+	headers := []byte("{}")
+	if meta.Headers != nil {
+		if headers, err = json.Marshal(meta.Headers); err != nil {
+			return docstore.Meta{}, err
+		}
+	}
+	fetchedAt := meta.FetchedAt
+	if fetchedAt.IsZero() {
+		fetchedAt = stored.FetchedAt
+	}
+	_, err = c.Db.UpsertSourceDocument(ctx, database.UpsertSourceDocumentParams{
+		Hash:        stored.Hash,
+		Url:         meta.URL,
+		ContentType: stored.ContentType,
+		SizeBytes:   stored.Size,
+		FetchedAt:   fetchedAt,
+		Headers:     headers,
+	})
+	if err != nil {
+		return docstore.Meta{}, fmt.Errorf("error recording document: %s, with error: %v", stored.Hash, err)
+	}
+	return stored, nil
+}
+
+// HandleGetDocument serves a stored source document by its SHA-256 so
+// reviewers can open the exact file that produced the data.
+func HandleGetDocument(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	hash := strings.ToLower(mux.Vars(r)["hash"])
+	if !docstore.ValidHash(hash) {
+		json_utils.RespondWithError(w, http.StatusBadRequest, "hash is not a valid sha256")
+		return
+	}
+	if c.Documents == nil {
+		json_utils.RespondWithError(w, http.StatusInternalServerError, "no document store configured")
+		return
+	}
+
+	body, meta, err := c.Documents.Open(r.Context(), hash)
+	if errors.Is(err, docstore.ErrNotFound) {
+		json_utils.RespondWithError(w, http.StatusNotFound, "document not found")
+		return
+	}
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error opening document: %s, with error: %v", hash, err))
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", meta.ContentType)
+	w.Header().Set("ETag", `"`+hash+`"`)
+	// content addressed, so the response never changes
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Source-Url", meta.URL)
+	w.Header().Set("X-Fetched-At", meta.FetchedAt.UTC().Format(time.RFC3339))
+	if r.Header.Get("If-None-Match") == `"`+hash+`"` {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, body)
+}
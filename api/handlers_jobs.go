@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JobResp struct {
+	ID          uuid.UUID       `json:"id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Kind        string          `json:"kind"`
+	Target      string          `json:"target"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int32           `json:"attempts"`
+	MaxAttempts int32           `json:"max_attempts"`
+	LastError   string          `json:"last_error"`
+	RunAt       time.Time       `json:"run_at"`
+	StartedAt   *time.Time      `json:"started_at"`
+	FinishedAt  *time.Time      `json:"finished_at"`
+	LockedBy    string          `json:"locked_by"`
+	// the error of every failed attempt, with the model calls it made
+	AttemptErrors json.RawMessage `json:"attempt_errors"`
+}
+
+func MapJob(src database.Job) JobResp {
+	resp := JobResp{
+		ID:            src.ID,
+		CreatedAt:     src.CreatedAt,
+		UpdatedAt:     src.UpdatedAt,
+		Kind:          string(src.Kind),
+		Target:        src.Target,
+		Payload:       src.Payload,
+		Status:        string(src.Status),
+		Attempts:      src.Attempts,
+		MaxAttempts:   src.MaxAttempts,
+		LastError:     src.LastError,
+		RunAt:         src.RunAt,
+		LockedBy:      src.LockedBy,
+		AttemptErrors: src.AttemptErrors,
+	}
+	if src.StartedAt.Valid {
+		resp.StartedAt = &src.StartedAt.Time
+	}
+	if src.FinishedAt.Valid {
+		resp.FinishedAt = &src.FinishedAt.Time
+	}
+	return resp
+}
+
+// HandleGetJobs lists the job queue, newest first,
+// query = status=pending|running|succeeded|dead|cancelled|all, limit (default 50)
+func HandleGetJobs(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandleGetWithQ(c, w, r, getJobs)
+}
+
+func HandleGetJob(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandleGet(c, w, r, getJob)
+}
+
+// HandleRetryJob puts a dead or cancelled job back in the queue with its
+// attempts reset.
+func HandleRetryJob(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandleModify(c, w, r, retryJob)
+}
+
+// HandleCancelJob cancels a pending or running job; a running job is
+// stopped at its worker's next lease renewal.
+func HandleCancelJob(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandleModify(c, w, r, cancelJob)
+}
+
+func getJobs(c *config.Config, ctx context.Context, ids IDs, query url.Values) ([]JobResp, error) {
+	status := query.Get("status")
+	if status == "" {
+		status = "all"
+	}
+	limit := 50
+	if l := query.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > 500 {
+			return nil, fmt.Errorf("limit must be between 1 and 500")
+		}
+		limit = n
+	}
+	items, err := c.Db.ListJobs(ctx, database.ListJobsParams{Status: status, RowLimit: int32(limit)})
+	if err != nil {
+		return nil, fmt.Errorf("error getting jobs: %s, with error: %v", status, err)
+	}
+	return MapAll(items, MapJob), nil
+}
+
+func getJob(c *config.Config, ctx context.Context, ids IDs) (JobResp, error) {
+	job, err := c.Db.GetJob(ctx, ids.ID)
+	if err != nil {
+		return JobResp{}, fmt.Errorf("error getting job: %s, with error: %v", ids.ID.String(), err)
+	}
+	return MapJob(job), nil
+}
+
+func retryJob(c *config.Config, ctx context.Context, ids IDs) (string, error) {
+	job, err := c.Db.RetryJob(ctx, ids.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("job %s not found, or not dead or cancelled", ids.ID.String())
+	}
+	if err != nil {
+		return "", fmt.Errorf("error retrying job: %s, with error: %v", ids.ID.String(), err)
+	}
+	return fmt.Sprintf("Job %s is %s", job.ID, job.Status), nil
+}
+
+func cancelJob(c *config.Config, ctx context.Context, ids IDs) (string, error) {
+	job, err := c.Db.CancelJob(ctx, ids.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("job %s not found, or already finished", ids.ID.String())
+	}
+	if err != nil {
+		return "", fmt.Errorf("error cancelling job: %s, with error: %v", ids.ID.String(), err)
+	}
+	return fmt.Sprintf("Job %s is %s", job.ID, job.Status), nil
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"bcca_crawler/prompts"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PromptVersionReq struct {
+	Template string `json:"template" validate:"required"`
+	Notes    string `json:"notes" validate:"max=1000"`
+	Activate bool   `json:"activate"`
+}
+
+type ActivatePromptReq struct {
+	Version int32 `json:"version" validate:"required,min=1"`
+}
+
+type PromptVersionResp struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Version   int32     `json:"version"`
+	Template  string    `json:"template"`
+	Notes     string    `json:"notes"`
+}
+
+type PromptResp struct {
+	ID            uuid.UUID           `json:"id"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+	Name          string              `json:"name"`
+	Description   string              `json:"description"`
+	ActiveVersion *int32              `json:"active_version"`
+	Versions      []PromptVersionResp `json:"versions,omitempty"`
+}
+
+type ExtractionResp struct {
+	ID                   uuid.UUID       `json:"id"`
+	CreatedAt            time.Time       `json:"created_at"`
+	DocumentHash         string          `json:"document_hash"`
+	Model                string          `json:"model"`
+	Mode                 string          `json:"mode"`
+	PromptName           string          `json:"prompt_name"`
+	PromptVersion        int32           `json:"prompt_version"`
+	SectionPromptName    string          `json:"section_prompt_name,omitempty"`
+	SectionPromptVersion int32           `json:"section_prompt_version,omitempty"`
+	Conflicts            json.RawMessage `json:"conflicts"`
+}
+
+func MapPrompt(src database.Prompt) PromptResp {
+	resp := PromptResp{
+		ID:          src.ID,
+		CreatedAt:   src.CreatedAt,
+		UpdatedAt:   src.UpdatedAt,
+		Name:        src.Name,
+		Description: src.Description,
+	}
+	if src.ActiveVersion.Valid {
+		resp.ActiveVersion = &src.ActiveVersion.Int32
+	}
+	return resp
+}
+
+func MapPromptVersion(src database.PromptVersion) PromptVersionResp {
+	return PromptVersionResp{
+		ID:        src.ID,
+		CreatedAt: src.CreatedAt,
+		Version:   src.Version,
+		Template:  src.Template,
+		Notes:     src.Notes,
+	}
+}
+
+func MapExtraction(src database.GetProtocolExtractionsRow) ExtractionResp {
+	return ExtractionResp{
+		ID:                   src.ID,
+		CreatedAt:            src.CreatedAt,
+		DocumentHash:         src.DocumentHash,
+		Model:                src.Model,
+		Mode:                 string(src.Mode),
+		PromptName:           src.PromptName,
+		PromptVersion:        src.PromptVersion,
+		SectionPromptName:    src.SectionPromptName.String,
+		SectionPromptVersion: src.SectionPromptVersion.Int32,
+		Conflicts:            src.Conflicts,
+	}
+}
+
+func HandleGetPrompts(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandleGet(c, w, r, getPrompts)
+}
+
+// HandleGetPrompt returns a prompt with all its versions, newest first.
+func HandleGetPrompt(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandleGet(c, w, r, getPrompt)
+}
+
+// HandleCreatePromptVersion adds the next version of a prompt. Versions are
+// never edited; a change is a new version.
+func HandleCreatePromptVersion(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandlePost(c, w, r, createPromptVersion)
+}
+
+// HandleActivatePrompt points a prompt at one of its versions, for rolling a
+// change out or back.
+func HandleActivatePrompt(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandlePost(c, w, r, activatePrompt)
+}
+
+// HandleGetProtocolExtractions lists the extractions of a protocol with the
+// prompt versions and model each one used, and the conflicts between the
+// passes of a sectioned one.
+func HandleGetProtocolExtractions(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandleGet(c, w, r, getProtocolExtractions)
+}
+
+func getPrompts(c *config.Config, ctx context.Context, ids IDs) ([]PromptResp, error) {
+	items, err := c.Db.GetPrompts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting prompts: %v", err)
+	}
+	return MapAll(items, MapPrompt), nil
+}
+
+func getPrompt(c *config.Config, ctx context.Context, ids IDs) (PromptResp, error) {
+	item, err := c.Db.GetPrompt(ctx, ids.ID)
+	if err != nil {
+		return PromptResp{}, promptError("getting", ids.ID, err)
+	}
+	versions, err := c.Db.GetPromptVersions(ctx, ids.ID)
+	if err != nil {
+		return PromptResp{}, promptError("getting versions of", ids.ID, err)
+	}
+	resp := MapPrompt(item)
+	resp.Versions = MapAll(versions, MapPromptVersion)
+	return resp, nil
+}
+
+func createPromptVersion(c *config.Config, ctx context.Context, req PromptVersionReq, ids IDs) (PromptVersionResp, error) {
+	if err := prompts.Check(req.Template); err != nil {
+		return PromptVersionResp{}, fmt.Errorf("invalid template: %v", err)
+	}
+	if _, err := c.Db.GetPrompt(ctx, ids.ID); err != nil {
+		return PromptVersionResp{}, promptError("adding a version to", ids.ID, err)
+	}
+	item, err := c.Db.CreatePromptVersion(ctx, database.CreatePromptVersionParams{
+		PromptID: ids.ID,
+		Template: req.Template,
+		Notes:    req.Notes,
+	})
+	if err != nil {
+		return PromptVersionResp{}, promptError("adding a version to", ids.ID, err)
+	}
+	if req.Activate {
+		if _, err := activatePrompt(c, ctx, ActivatePromptReq{Version: item.Version}, ids); err != nil {
+			return PromptVersionResp{}, err
+		}
+	}
+	return MapPromptVersion(item), nil
+}
+
+func activatePrompt(c *config.Config, ctx context.Context, req ActivatePromptReq, ids IDs) (PromptResp, error) {
+	n, err := c.Db.SetActivePromptVersion(ctx, database.SetActivePromptVersionParams{Version: req.Version, ID: ids.ID})
+	if err != nil {
+		return PromptResp{}, promptError("activating", ids.ID, err)
+	}
+	if n == 0 {
+		return PromptResp{}, fmt.Errorf("prompt %s has no version %d", ids.ID.String(), req.Version)
+	}
+	item, err := c.Db.GetPrompt(ctx, ids.ID)
+	if err != nil {
+		return PromptResp{}, promptError("getting", ids.ID, err)
+	}
+	return MapPrompt(item), nil
+}
+
+func getProtocolExtractions(c *config.Config, ctx context.Context, ids IDs) ([]ExtractionResp, error) {
+	items, err := c.Db.GetProtocolExtractions(ctx, ids.ProtocolID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting extractions: %s, with error: %v", ids.ProtocolID.String(), err)
+	}
+	return MapAll(items, MapExtraction), nil
+}
+
+func promptError(action string, id uuid.UUID, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("prompt %s not found", id.String())
+	}
+	return fmt.Errorf("error %s prompt: %s, with error: %v", action, id.String(), err)
+}
@@ -185,6 +185,14 @@ func HandleGetProtocolSummary(c *config.Config, w http.ResponseWriter, r *http.R
 		return
 	}
 
+	if includes(r, "provenance") {
+		response.Provenance, err = GetProtocolProvenance(c, r.Context(), response.ProtocolSummary.ID)
+		if err != nil {
+			json_utils.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error getting provenance: %s", ids.ProtocolID.String()))
+			return
+		}
+	}
+
 	json_utils.RespondWithJSON(w, http.StatusOK, response)
 }
 
@@ -201,6 +209,14 @@ func HandleGetProtocolSummaryCode(c *config.Config, w http.ResponseWriter, r *ht
 		return
 	}
 
+	if includes(r, "provenance") {
+		response.Provenance, err = GetProtocolProvenance(c, r.Context(), response.ProtocolSummary.ID)
+		if err != nil {
+			json_utils.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error getting provenance: %s", code))
+			return
+		}
+	}
+
 	json_utils.RespondWithJSON(w, http.StatusOK, response)
 }
 
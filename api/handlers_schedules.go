@@ -0,0 +1,150 @@
+package api
+
+import (
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"bcca_crawler/scheduler"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ScheduleReq struct {
+	Name   string `json:"name" validate:"required,min=1,max=100"`
+	Cron   string `json:"cron" validate:"required,max=100"`
+	Action string `json:"action" validate:"required,oneof=discover crawl"`
+	Target string `json:"target" validate:"omitempty,url"`
+}
+
+type ScheduleResp struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Name      string     `json:"name"`
+	Cron      string     `json:"cron"`
+	Action    string     `json:"action"`
+	Target    string     `json:"target"`
+	Paused    bool       `json:"paused"`
+	NextRunAt *time.Time `json:"next_run_at"`
+	LastRunAt *time.Time `json:"last_run_at"`
+	LastError string     `json:"last_error"`
+}
+
+func MapSchedule(src database.Schedule) ScheduleResp {
+	resp := ScheduleResp{
+		ID:        src.ID,
+		CreatedAt: src.CreatedAt,
+		UpdatedAt: src.UpdatedAt,
+		Name:      src.Name,
+		Cron:      src.Cron,
+		Action:    string(src.Action),
+		Target:    src.Target,
+		Paused:    src.Paused,
+		LastError: src.LastError,
+	}
+	if src.NextRunAt.Valid {
+		resp.NextRunAt = &src.NextRunAt.Time
+	}
+	if src.LastRunAt.Valid {
+		resp.LastRunAt = &src.LastRunAt.Time
+	}
+	return resp
+}
+
+func HandleGetSchedules(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandleGet(c, w, r, getSchedules)
+}
+
+func HandleCreateSchedule(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandlePost(c, w, r, createSchedule)
+}
+
+func HandlePauseSchedule(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandleModify(c, w, r, pauseSchedule)
+}
+
+// HandleResumeSchedule unpauses a schedule from its next occurrence; runs
+// missed while paused are skipped.
+func HandleResumeSchedule(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandleModify(c, w, r, resumeSchedule)
+}
+
+// HandleTriggerSchedule queues a schedule's jobs now, without moving its
+// next run.
+func HandleTriggerSchedule(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandleModify(c, w, r, triggerSchedule)
+}
+
+func getSchedules(c *config.Config, ctx context.Context, ids IDs) ([]ScheduleResp, error) {
+	items, err := c.Db.GetSchedules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting schedules: %v", err)
+	}
+	return MapAll(items, MapSchedule), nil
+}
+
+func createSchedule(c *config.Config, ctx context.Context, req ScheduleReq, ids IDs) (ScheduleResp, error) {
+	next, err := scheduler.NextRun(req.Cron)
+	if err != nil {
+		return ScheduleResp{}, err
+	}
+	item, err := c.Db.CreateSchedule(ctx, database.CreateScheduleParams{
+		Name:      req.Name,
+		Cron:      req.Cron,
+		Action:    database.ScheduleActionEnum(req.Action),
+		Target:    req.Target,
+		NextRunAt: next,
+	})
+	if err != nil {
+		return ScheduleResp{}, fmt.Errorf("error creating schedule: %s, with error: %v", req.Name, err)
+	}
+	return MapSchedule(item), nil
+}
+
+func pauseSchedule(c *config.Config, ctx context.Context, ids IDs) (string, error) {
+	item, err := c.Db.SetSchedulePaused(ctx, database.SetSchedulePausedParams{Paused: true, ID: ids.ID})
+	if err != nil {
+		return "", scheduleError("pausing", ids.ID, err)
+	}
+	return fmt.Sprintf("Schedule %s paused", item.Name), nil
+}
+
+func resumeSchedule(c *config.Config, ctx context.Context, ids IDs) (string, error) {
+	item, err := c.Db.GetSchedule(ctx, ids.ID)
+	if err != nil {
+		return "", scheduleError("resuming", ids.ID, err)
+	}
+	next, err := scheduler.NextRun(item.Cron)
+	if err != nil {
+		return "", err
+	}
+	item, err = c.Db.SetSchedulePaused(ctx, database.SetSchedulePausedParams{
+		Paused:    false,
+		NextRunAt: sql.NullTime{Time: next, Valid: true},
+		ID:        ids.ID,
+	})
+	if err != nil {
+		return "", scheduleError("resuming", ids.ID, err)
+	}
+	return fmt.Sprintf("Schedule %s resumed, next run at %s", item.Name, next.Format(time.RFC3339)), nil
+}
+
+func triggerSchedule(c *config.Config, ctx context.Context, ids IDs) (string, error) {
+	queued, err := scheduler.Trigger(c, ctx, ids.ID)
+	if err != nil {
+		return "", scheduleError("triggering", ids.ID, err)
+	}
+	return fmt.Sprintf("Schedule %s triggered, %d job(s) queued", ids.ID, queued), nil
+}
+
+func scheduleError(action string, id uuid.UUID, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("schedule %s not found", id.String())
+	}
+	return fmt.Errorf("error %s schedule: %s, with error: %v", action, id.String(), err)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"bcca_crawler/usage"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type LLMUsageRow struct {
+	Period         time.Time `json:"period"`
+	Model          string    `json:"model"`
+	Calls          int32     `json:"calls"`
+	Failed         int32     `json:"failed"`
+	Documents      int32     `json:"documents"`
+	PromptTokens   int64     `json:"prompt_tokens"`
+	ResponseTokens int64     `json:"response_tokens"`
+	TotalTokens    int64     `json:"total_tokens"`
+	CostUSD        float64   `json:"cost_usd"`
+	AvgLatencyMs   float64   `json:"avg_latency_ms"`
+}
+
+type LLMBudgetResp struct {
+	Daily        float64 `json:"daily"`
+	DailySpent   float64 `json:"daily_spent"`
+	Monthly      float64 `json:"monthly"`
+	MonthlySpent float64 `json:"monthly_spent"`
+}
+
+type LLMUsageResp struct {
+	Period string        `json:"period"`
+	Budget LLMBudgetResp `json:"budget"`
+	Usage  []LLMUsageRow `json:"usage"`
+}
+
+func MapLLMUsage(src database.GetLLMUsageRow) LLMUsageRow {
+	return LLMUsageRow{
+		Period:         src.Period,
+		Model:          src.Model,
+		Calls:          src.Calls,
+		Failed:         src.Failed,
+		Documents:      src.Documents,
+		PromptTokens:   src.PromptTokens,
+		ResponseTokens: src.ResponseTokens,
+		TotalTokens:    src.TotalTokens,
+		CostUSD:        src.CostUsd,
+		AvgLatencyMs:   src.AvgLatencyMs,
+	}
+}
+
+// HandleGetLLMUsage reports the calls to the model by day or month and
+// model, with the spend against the budget,
+// query = period=day|month (default day), last = number of periods (default 30 days or 12 months)
+func HandleGetLLMUsage(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	HandleGetWithQ(c, w, r, getLLMUsage)
+}
+
+func getLLMUsage(c *config.Config, ctx context.Context, ids IDs, query url.Values) (LLMUsageResp, error) {
+	period := query.Get("period")
+	if period == "" {
+		period = "day"
+	}
+	if period != "day" && period != "month" {
+		return LLMUsageResp{}, fmt.Errorf("period must be day or month")
+	}
+	last := 30
+	if period == "month" {
+		last = 12
+	}
+	if l := query.Get("last"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > 366 {
+			return LLMUsageResp{}, fmt.Errorf("last must be between 1 and 366")
+		}
+		last = n
+	}
+
+	day, _, month, _ := usage.Periods(time.Now())
+	since := day.AddDate(0, 0, 1-last)
+	if period == "month" {
+		since = month.AddDate(0, 1-last, 0)
+	}
+	items, err := c.Db.GetLLMUsage(ctx, database.GetLLMUsageParams{Period: period, Since: since})
+	if err != nil {
+		return LLMUsageResp{}, fmt.Errorf("error getting LLM usage: %v", err)
+	}
+	daily, err := c.Db.GetLLMSpend(ctx, day)
+	if err != nil {
+		return LLMUsageResp{}, fmt.Errorf("error getting LLM spend: %v", err)
+	}
+	monthly, err := c.Db.GetLLMSpend(ctx, month)
+	if err != nil {
+		return LLMUsageResp{}, fmt.Errorf("error getting LLM spend: %v", err)
+	}
+
+	return LLMUsageResp{
+		Period: period,
+		Budget: LLMBudgetResp{
+			Daily:        c.LLMBudget.Daily,
+			DailySpent:   daily.Spent,
+			Monthly:      c.LLMBudget.Monthly,
+			MonthlySpent: monthly.Spent,
+		},
+		Usage: MapAll(items, MapLLMUsage),
+	}, nil
+}
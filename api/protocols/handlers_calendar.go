@@ -0,0 +1,137 @@
+package protocols
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/calendar"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/json_utils"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HandleGetProtocolCalendar builds a treatment calendar for the protocol.
+// Query parameters: start (YYYY-MM-DD, required), cycles, shift
+// (forward|backward|nearest|none), weekends=open, holidays (dates or "bc"),
+// baseline_lead, followup_lead and format (json|ics).
+func HandleGetProtocolCalendar(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	ids, err := api.ParseAndValidateID(r)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	opts, err := parseCalendarOptions(query)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cal, err := getProtocolCalendar(c, r.Context(), ids, opts)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if strings.ToLower(query.Get("format")) != "ics" {
+		json_utils.RespondWithJSON(w, http.StatusOK, cal)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := calendar.WriteICS(&buf, cal, time.Now()); err != nil {
+		json_utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", strings.ToLower(cal.ProtocolCode)+"_calendar.ics"))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+func parseCalendarOptions(query url.Values) (calendar.Options, error) {
+	opts := calendar.Options{
+		Cycles:           1,
+		BaselineLeadDays: calendar.DefaultBaselineLeadDays,
+		FollowupLeadDays: calendar.DefaultFollowupLeadDays,
+	}
+
+	start, err := time.Parse(calendar.DateLayout, query.Get("start"))
+	if err != nil {
+		return opts, fmt.Errorf("start must be a date formatted as YYYY-MM-DD")
+	}
+	opts.Start = start
+
+	if v := query.Get("cycles"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			return opts, fmt.Errorf("cycles must be between 1 and 100")
+		}
+		opts.Cycles = n
+	}
+
+	opts.Shift, err = calendar.ParseShiftRule(query.Get("shift"))
+	if err != nil {
+		return opts, err
+	}
+	opts.OpenWeekends = strings.ToLower(query.Get("weekends")) == "open"
+
+	for key, target := range map[string]*int{"baseline_lead": &opts.BaselineLeadDays, "followup_lead": &opts.FollowupLeadDays} {
+		if v := query.Get(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 || n > 60 {
+				return opts, fmt.Errorf("%s must be a number of days between 0 and 60", key)
+			}
+			*target = n
+		}
+	}
+
+	// a year of margin covers the longest regimens we lay out
+	opts.Holidays, err = calendar.ParseHolidays(query.Get("holidays"), start.AddDate(0, 0, -60), start.AddDate(1, 0, 0).AddDate(0, 0, 7*opts.Cycles))
+	if err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+func getProtocolCalendar(c *config.Config, ctx context.Context, ids api.IDs, opts calendar.Options) (calendar.Calendar, error) {
+	protocol, err := c.Db.GetProtocolByID(ctx, ids.ProtocolID)
+	if err != nil {
+		return calendar.Calendar{}, fmt.Errorf("error getting protocol: %s, with error: %v", ids.ProtocolID.String(), err)
+	}
+
+	cycles, err := api.GetProtocolCycles(c, ctx, ids.ProtocolID)
+	if err != nil {
+		return calendar.Calendar{}, fmt.Errorf("error getting protocol cycles: %w", err)
+	}
+	if len(cycles) == 0 {
+		return calendar.Calendar{}, fmt.Errorf("protocol %s has no cycles", protocol.Code)
+	}
+
+	groups, err := api.GetProtocolTests(c, ctx, ids.ProtocolID)
+	if err != nil {
+		return calendar.Calendar{}, fmt.Errorf("error getting protocol tests: %w", err)
+	}
+
+	labs := make([]calendar.LabGroup, 0, len(groups))
+	for _, g := range groups {
+		tests := make([]string, 0, len(g.Tests))
+		for _, t := range g.Tests {
+			tests = append(tests, t.Name)
+		}
+		labs = append(labs, calendar.LabGroup{Category: g.Category, Tests: tests, Comments: g.Comments})
+	}
+
+	return calendar.Generate(calendar.Protocol{
+		Code:      protocol.Code,
+		Name:      protocol.Name,
+		Templates: CycleTemplates(cycles),
+		Labs:      labs,
+	}, opts)
+}
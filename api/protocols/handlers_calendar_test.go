@@ -0,0 +1,40 @@
+package protocols
+
+import (
+	"bcca_crawler/calendar"
+	"net/url"
+	"testing"
+)
+
+func TestParseCalendarLeadDays(t *testing.T) {
+	tests := []struct {
+		query              string
+		baseline, followup int
+		wantErr            bool
+	}{
+		{"start=2026-03-02", calendar.DefaultBaselineLeadDays, calendar.DefaultFollowupLeadDays, false},
+		{"start=2026-03-02&baseline_lead=0&followup_lead=0", 0, 0, false},
+		{"start=2026-03-02&baseline_lead=14", 14, calendar.DefaultFollowupLeadDays, false},
+		{"start=2026-03-02&baseline_lead=-1", 0, 0, true},
+		{"start=2026-03-02&followup_lead=-3", 0, 0, true},
+		{"start=2026-03-02&followup_lead=61", 0, 0, true},
+		{"start=2026-03-02&baseline_lead=week", 0, 0, true},
+	}
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		opts, err := parseCalendarOptions(query)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: no error", tt.query)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		if opts.BaselineLeadDays != tt.baseline || opts.FollowupLeadDays != tt.followup {
+			t.Errorf("%s: lead days %d and %d, want %d and %d", tt.query, opts.BaselineLeadDays, opts.FollowupLeadDays, tt.baseline, tt.followup)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package protocols
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/crawler"
+	"bcca_crawler/docstore"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"bcca_crawler/internal/json_utils"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+func HandleGetProtocolDocuments(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGet(c, w, r, getProtocolDocuments)
+}
+
+func HandleGetProtocolSources(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGet(c, w, r, getProtocolSources)
+}
+
+func getProtocolDocuments(c *config.Config, ctx context.Context, ids api.IDs) ([]ProtocolDocumentResp, error) {
+	items, err := c.Db.GetProtocolDocuments(ctx, ids.ProtocolID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting protocol documents: %s, with error: %v", ids.ProtocolID.String(), err)
+	}
+	return api.MapAll(items, MapProtocolDocument), nil
+}
+
+func getProtocolSources(c *config.Config, ctx context.Context, ids api.IDs) ([]ProtocolSourceResp, error) {
+	items, err := c.Db.GetProtocolSources(ctx, ids.ProtocolID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting protocol sources: %s, with error: %v", ids.ProtocolID.String(), err)
+	}
+	return api.MapAll(items, MapProtocolSource), nil
+}
+
+// AttachDocuments downloads every link found under a protocol on the
+// website, keeps a copy in the document store, or in the local archive when
+// the store fails, and records it against the protocol with the same code.
+// The protocol and patient handout links are copied onto the protocol and
+// PPO links are added to protocol_ppos. Download failures are recorded
+// without a copy and reported after the other links are processed.
+func AttachDocuments(c *config.Config, ctx context.Context, wp crawler.WebProtocol) (int, error) {
+	code := strings.TrimSpace(wp.Code)
+	protocol, err := c.Db.GetProtocolByCode(ctx, code)
+	if err != nil {
+		return 0, fmt.Errorf("error getting protocol: %s, with error: %v", code, err)
+	}
+
+	saved := 0
+	failed := []string{}
+	links := database.UpdateProtocolSourceLinksParams{ID: protocol.ID}
+	for _, link := range wp.ClassifiedLinks() {
+		params := database.UpsertProtocolDocumentParams{
+			ProtocolID: protocol.ID,
+			Kind:       database.DocumentKindEnum(link.Kind),
+			Title:      link.Text,
+			Url:        link.Href,
+		}
+
+		doc, err := crawler.Download(link.Href)
+		if err == nil {
+			params, err = keepCopy(c, ctx, params, code, link.Kind, doc)
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", link.Href, err))
+		} else {
+			params.FetchedAt = sql.NullTime{Time: doc.FetchedAt, Valid: true}
+		}
+
+		if _, err := c.Db.UpsertProtocolDocument(ctx, params); err != nil {
+			return saved, fmt.Errorf("error saving document: %s, with error: %v", link.Href, err)
+		}
+		saved++
+
+		switch link.Kind {
+		case crawler.LinkProtocol:
+			if links.ProtocolUrl == "" {
+				links.ProtocolUrl = link.Href
+			}
+		case crawler.LinkPatientHandout:
+			if links.PatientHandoutUrl == "" {
+				links.PatientHandoutUrl = link.Href
+			}
+		case crawler.LinkPPO:
+			title := link.Text
+			if title == "" {
+				title = code + " PPO"
+			}
+			err := c.Db.UpsertLinkedPPO(ctx, database.UpsertLinkedPPOParams{ProtocolID: protocol.ID, Title: title, Url: link.Href})
+			if err != nil {
+				return saved, fmt.Errorf("error saving PPO link: %s, with error: %v", link.Href, err)
+			}
+		}
+	}
+
+	if err := c.Db.UpdateProtocolSourceLinks(ctx, links); err != nil {
+		return saved, fmt.Errorf("error updating protocol links: %s, with error: %v", code, err)
+	}
+	if len(failed) > 0 {
+		return saved, fmt.Errorf("could not download %d document(s) of %s: %s", len(failed), code, strings.Join(failed, "; "))
+	}
+	return saved, nil
+}
+
+// keepCopy saves a downloaded document in the document store. When the
+// store cannot take it, e.g. the bucket is unreachable, the copy goes to the
+// local archive instead so the link stays usable.
+func keepCopy(c *config.Config, ctx context.Context, params database.UpsertProtocolDocumentParams, code string, kind crawler.LinkKind, doc crawler.Document) (database.UpsertProtocolDocumentParams, error) {
+	stored, err := api.StoreDocument(c, ctx, doc.Body, docstore.Meta{URL: doc.URL, ContentType: doc.ContentType, FetchedAt: doc.FetchedAt, Headers: doc.Header})
+	if err == nil {
+		params.DocumentHash = sql.NullString{String: stored.Hash, Valid: true}
+		params.ContentType = stored.ContentType
+		params.SizeBytes = stored.Size
+		return params, nil
+	}
+	fmt.Printf("Archiving %s locally: %v\n", doc.URL, err)
+	path, archiveErr := crawler.Archive(c.ArchiveDir, code, kind, doc)
+	if archiveErr != nil {
+		return params, fmt.Errorf("%v; error archiving: %v", err, archiveErr)
+	}
+	params.ArchivePath = path
+	params.ContentType = doc.ContentType
+	params.SizeBytes = int64(len(doc.Body))
+	return params, nil
+}
+
+// HandleGetProtocolDocumentArchive serves the local archive copy of a
+// crawled document the document store could not take.
+func HandleGetProtocolDocumentArchive(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	ids, err := api.ParseAndValidateID(r)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	doc, err := c.Db.GetProtocolDocument(r.Context(), database.GetProtocolDocumentParams{ID: ids.ID, ProtocolID: ids.ProtocolID})
+	if errors.Is(err, sql.ErrNoRows) {
+		json_utils.RespondWithError(w, http.StatusNotFound, "document not found")
+		return
+	}
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error getting document: %s, with error: %v", ids.ID.String(), err))
+		return
+	}
+	if doc.ArchivePath == "" || !insideDir(c.ArchiveDir, doc.ArchivePath) {
+		json_utils.RespondWithError(w, http.StatusNotFound, "document has no archive copy")
+		return
+	}
+
+	if doc.ContentType != "" {
+		w.Header().Set("Content-Type", doc.ContentType)
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filepath.Base(doc.ArchivePath)))
+	http.ServeFile(w, r, doc.ArchivePath)
+}
+
+func insideDir(dir, path string) bool {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, target)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,269 @@
+package protocols
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/dosing"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type CumulativeDoseResp struct {
+	ProtocolID    uuid.UUID `json:"protocol_id"`
+	PlannedCycles int       `json:"planned_cycles"`
+	dosing.Projection
+}
+
+func HandleGetDoseLimits(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGet(c, w, r, getDoseLimits)
+}
+
+func HandleGetDoseLimitByID(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGet(c, w, r, getDoseLimitByID)
+}
+
+func HandleUpsertDoseLimit(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleUpsert(c, w, r, upsertDoseLimit)
+}
+
+func HandleDeleteDoseLimit(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleModify(c, w, r, deleteDoseLimit)
+}
+
+func HandleGetDoseClasses(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGet(c, w, r, getDoseClasses)
+}
+
+func HandleGetDoseClassByID(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGet(c, w, r, getDoseClassByID)
+}
+
+func HandleUpsertDoseClass(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleUpsert(c, w, r, upsertDoseClass)
+}
+
+func HandleDeleteDoseClass(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleModify(c, w, r, deleteDoseClass)
+}
+
+func HandleProjectCumulativeDose(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandlePost(c, w, r, projectCumulativeDose)
+}
+
+func getDoseLimits(c *config.Config, ctx context.Context, ids api.IDs) ([]DoseLimitResp, error) {
+	items, err := c.Db.GetMedicationDoseLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return api.MapAll(items, MapDoseLimit), nil
+}
+
+func getDoseLimitByID(c *config.Config, ctx context.Context, ids api.IDs) (DoseLimitResp, error) {
+	item, err := c.Db.GetMedicationDoseLimitByID(ctx, ids.ID)
+	if err != nil {
+		return DoseLimitResp{}, err
+	}
+	return MapDoseLimit(item), nil
+}
+
+func upsertDoseLimit(c *config.Config, ctx context.Context, req DoseLimitReq, ids api.IDs) error {
+	mid, err := uuid.Parse(req.MedicationID)
+	if err != nil {
+		return fmt.Errorf("medication ID: %s is not a valid UUID", req.MedicationID)
+	}
+
+	classID := uuid.NullUUID{}
+	if req.EquivalenceClassID != "" {
+		cid, err := uuid.Parse(req.EquivalenceClassID)
+		if err != nil {
+			return fmt.Errorf("equivalence class ID: %s is not a valid UUID", req.EquivalenceClassID)
+		}
+		classID = uuid.NullUUID{UUID: cid, Valid: true}
+	}
+
+	if req.WarningThreshold == 0 {
+		req.WarningThreshold = 0.8
+	}
+	if req.EquivalenceFactor == 0 {
+		req.EquivalenceFactor = 1
+	}
+
+	_, err = c.Db.UpsertMedicationDoseLimit(ctx, database.UpsertMedicationDoseLimitParams{
+		MedicationID:       mid,
+		MaxCumulativeDose:  req.MaxCumulativeDose,
+		DoseBasis:          database.DoseBasisEnum(strings.ToLower(req.DoseBasis)),
+		WarningThreshold:   req.WarningThreshold,
+		EquivalenceClassID: classID,
+		EquivalenceFactor:  req.EquivalenceFactor,
+		Notes:              req.Notes,
+	})
+	if err != nil {
+		return fmt.Errorf("error upserting dose limit for medication: %s with error:%s", req.MedicationID, err.Error())
+	}
+	return nil
+}
+
+func deleteDoseLimit(c *config.Config, ctx context.Context, ids api.IDs) (string, error) {
+	err := c.Db.RemoveMedicationDoseLimit(ctx, ids.ID)
+	if err != nil {
+		return "", fmt.Errorf("error deleting dose limit: %s, with error: %v", ids.ID.String(), err)
+	}
+	return fmt.Sprintf("Dose limit %s deleted.", ids.ID.String()), nil
+}
+
+func getDoseClasses(c *config.Config, ctx context.Context, ids api.IDs) ([]DoseClassResp, error) {
+	items, err := c.Db.GetDoseEquivalenceClasses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return api.MapAll(items, MapDoseClass), nil
+}
+
+func getDoseClassByID(c *config.Config, ctx context.Context, ids api.IDs) (DoseClassResp, error) {
+	item, err := c.Db.GetDoseEquivalenceClassByID(ctx, ids.ID)
+	if err != nil {
+		return DoseClassResp{}, err
+	}
+	return MapDoseClass(item), nil
+}
+
+func upsertDoseClass(c *config.Config, ctx context.Context, req DoseClassReq, ids api.IDs) error {
+	if req.WarningThreshold == 0 {
+		req.WarningThreshold = 0.8
+	}
+
+	_, err := c.Db.UpsertDoseEquivalenceClass(ctx, database.UpsertDoseEquivalenceClassParams{
+		ID:                api.ParseOrNilUUID(req.ID),
+		Name:              strings.ToLower(req.Name),
+		ReferenceDrug:     req.ReferenceDrug,
+		MaxCumulativeDose: req.MaxCumulativeDose,
+		DoseBasis:         database.DoseBasisEnum(strings.ToLower(req.DoseBasis)),
+		WarningThreshold:  req.WarningThreshold,
+		Notes:             req.Notes,
+	})
+	if err != nil {
+		return fmt.Errorf("error upserting dose class: %s with error:%s", req.Name, err.Error())
+	}
+	return nil
+}
+
+func deleteDoseClass(c *config.Config, ctx context.Context, ids api.IDs) (string, error) {
+	err := c.Db.RemoveDoseEquivalenceClass(ctx, ids.ID)
+	if err != nil {
+		return "", fmt.Errorf("error deleting dose class: %s, with error: %v", ids.ID.String(), err)
+	}
+	return fmt.Sprintf("Dose class %s deleted.", ids.ID.String()), nil
+}
+
+// CycleTemplates converts the protocol cycles into the planning view used by
+// the dosing package.
+func CycleTemplates(cycles []api.ProtocolCycle) []dosing.CycleTemplate {
+	templates := make([]dosing.CycleTemplate, 0, len(cycles))
+	for _, cycle := range cycles {
+		template := dosing.CycleTemplate{Label: cycle.Cycle, Duration: cycle.CycleDuration}
+		for _, tx := range cycle.Treatments {
+			template.Treatments = append(template.Treatments, dosing.TemplateTreatment{
+				MedicationID:   tx.MedicationID.String(),
+				MedicationName: tx.MedicationName,
+				AlternateNames: tx.MedicationAlternates,
+				Dose:           tx.Dose,
+				Route:          string(tx.Route),
+				Frequency:      tx.Frequency,
+			})
+		}
+		templates = append(templates, template)
+	}
+	return templates
+}
+
+// LoadDoseLimits reads every medication and class limit in the dosing package's format.
+func LoadDoseLimits(c *config.Config, ctx context.Context) ([]dosing.MedicationLimit, []dosing.ClassLimit, error) {
+	limits, err := c.Db.GetMedicationDoseLimits(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting dose limits: %w", err)
+	}
+	classes, err := c.Db.GetDoseEquivalenceClasses(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting dose classes: %w", err)
+	}
+
+	medLimits := make([]dosing.MedicationLimit, 0, len(limits))
+	for _, l := range limits {
+		classID := ""
+		if l.EquivalenceClassID.Valid {
+			classID = l.EquivalenceClassID.UUID.String()
+		}
+		medLimits = append(medLimits, dosing.MedicationLimit{
+			MedicationID:      l.MedicationID.String(),
+			Name:              l.MedicationName,
+			AlternateNames:    l.MedicationAlternateNames,
+			MaxDose:           l.MaxCumulativeDose,
+			Basis:             dosing.Basis(l.DoseBasis),
+			WarningThreshold:  l.WarningThreshold,
+			ClassID:           classID,
+			EquivalenceFactor: l.EquivalenceFactor,
+		})
+	}
+
+	classLimits := make([]dosing.ClassLimit, 0, len(classes))
+	for _, cl := range classes {
+		classLimits = append(classLimits, dosing.ClassLimit{
+			ID:               cl.ID.String(),
+			Name:             cl.Name,
+			ReferenceDrug:    cl.ReferenceDrug,
+			MaxDose:          cl.MaxCumulativeDose,
+			Basis:            dosing.Basis(cl.DoseBasis),
+			WarningThreshold: cl.WarningThreshold,
+		})
+	}
+
+	return medLimits, classLimits, nil
+}
+
+func projectCumulativeDose(c *config.Config, ctx context.Context, req CumulativeDoseReq, ids api.IDs) (CumulativeDoseResp, error) {
+	cycles, err := api.GetProtocolCycles(c, ctx, ids.ProtocolID)
+	if err != nil {
+		return CumulativeDoseResp{}, fmt.Errorf("error getting protocol cycles: %w", err)
+	}
+	if len(cycles) == 0 {
+		return CumulativeDoseResp{}, fmt.Errorf("protocol %s has no cycles", ids.ProtocolID.String())
+	}
+
+	medLimits, classLimits, err := LoadDoseLimits(c, ctx)
+	if err != nil {
+		return CumulativeDoseResp{}, err
+	}
+
+	plan, unresolved := dosing.BuildPlan(CycleTemplates(cycles), req.PlannedCycles)
+	if req.DosePercent > 0 {
+		for i := range plan {
+			for j := range plan[i].Doses {
+				plan[i].Doses[j].Amount = plan[i].Doses[j].Amount * req.DosePercent / 100
+			}
+		}
+	}
+
+	prior := make([]dosing.Exposure, 0, len(req.PriorExposure))
+	for _, e := range req.PriorExposure {
+		prior = append(prior, dosing.Exposure{
+			MedicationID: e.MedicationID,
+			Name:         e.MedicationName,
+			Amount:       e.Amount,
+			Basis:        dosing.Basis(strings.ToLower(e.DoseBasis)),
+		})
+	}
+
+	projection := dosing.Project(plan, prior, medLimits, classLimits, dosing.Patient{BSA: req.BSA, WeightKg: req.WeightKg})
+	projection.Unresolved = append(unresolved, projection.Unresolved...)
+
+	return CumulativeDoseResp{
+		ProtocolID:    ids.ProtocolID,
+		PlannedCycles: req.PlannedCycles,
+		Projection:    projection,
+	}, nil
+}
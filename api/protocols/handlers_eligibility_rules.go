@@ -0,0 +1,155 @@
+package protocols
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/eligibility"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"bcca_crawler/internal/json_utils"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+func HandleGetEligibilityRules(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGet(c, w, r, getEligibilityRules)
+}
+
+func HandleGetEligibilityRule(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGet(c, w, r, getEligibilityRule)
+}
+
+func HandleDeleteEligibilityRule(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleModify(c, w, r, deleteEligibilityRule)
+}
+
+func HandleScreenEligibility(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandlePost(c, w, r, screenEligibility)
+}
+
+// HandleUpsertEligibilityRule sets the rule of the criterion in the path.
+// Rules that don't parse are rejected with the parser's message.
+func HandleUpsertEligibilityRule(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	ids, err := api.ParseAndValidateID(r)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req EligibilityRuleReq
+	if err := api.UnmarshalAndValidatePayload(c, r, &req); err != nil {
+		json_utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule, err := eligibility.Parse(req.Expression)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("invalid rule: %v", err))
+		return
+	}
+
+	saved, err := SaveEligibilityRule(c, r.Context(), ids, rule, req.Author)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	json_utils.RespondWithJSON(w, http.StatusOK, saved)
+}
+
+// SaveEligibilityRule stores a parsed rule for a criterion. Author defaults
+// to editor.
+func SaveEligibilityRule(c *config.Config, ctx context.Context, ids api.IDs, rule *eligibility.Rule, author string) (EligibilityRuleResp, error) {
+	if author == "" {
+		author = string(database.RuleAuthorEnumEditor)
+	}
+	item, err := c.Db.UpsertEligibilityRule(ctx, database.UpsertEligibilityRuleParams{
+		CriteriaID: ids.ID,
+		Expression: rule.String(),
+		Author:     database.RuleAuthorEnum(strings.ToLower(author)),
+	})
+	if err != nil {
+		return EligibilityRuleResp{}, fmt.Errorf("error saving eligibility rule: %s, with error: %v", ids.ID.String(), err)
+	}
+	return MapEligibilityRule(item), nil
+}
+
+func getEligibilityRules(c *config.Config, ctx context.Context, ids api.IDs) ([]EligibilityRuleResp, error) {
+	items, err := c.Db.GetEligibilityRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return api.MapAll(items, MapEligibilityRuleWithCriterion), nil
+}
+
+func getEligibilityRule(c *config.Config, ctx context.Context, ids api.IDs) (EligibilityRuleResp, error) {
+	item, err := c.Db.GetEligibilityRule(ctx, ids.ID)
+	if err != nil {
+		return EligibilityRuleResp{}, fmt.Errorf("error getting eligibility rule: %s, with error: %v", ids.ID.String(), err)
+	}
+	return MapEligibilityRule(item), nil
+}
+
+func deleteEligibilityRule(c *config.Config, ctx context.Context, ids api.IDs) (string, error) {
+	if err := c.Db.RemoveEligibilityRule(ctx, ids.ID); err != nil {
+		return "", fmt.Errorf("error deleting eligibility rule: %s, with error: %v", ids.ID.String(), err)
+	}
+	return fmt.Sprintf("Eligibility rule %s deleted.", ids.ID.String()), nil
+}
+
+// screenEligibility returns a verdict for every criterion of every protocol.
+func screenEligibility(c *config.Config, ctx context.Context, req EligibilityScreenReq, ids api.IDs) (EligibilityScreenResp, error) {
+	tumorGroup := ""
+	if req.RestrictToTumorGroup {
+		tumorGroup = req.TumorGroup
+	}
+
+	rows, err := c.Db.GetEligibilityScreeningCriteria(ctx, tumorGroup)
+	if err != nil {
+		return EligibilityScreenResp{}, fmt.Errorf("error getting eligibility criteria: %w", err)
+	}
+
+	protocols := []eligibility.Protocol{}
+	index := map[string]int{}
+	for _, row := range rows {
+		id := row.ProtocolID.String()
+		i, ok := index[id]
+		if !ok {
+			i = len(protocols)
+			index[id] = i
+			protocols = append(protocols, eligibility.Protocol{
+				ID:         id,
+				Code:       row.Code,
+				Name:       row.Name,
+				TumorGroup: row.TumorGroup,
+				Criteria:   []eligibility.Criterion{},
+			})
+		}
+		if !row.CriteriaID.Valid {
+			continue
+		}
+		protocols[i].Criteria = append(protocols[i].Criteria, eligibility.Criterion{
+			ID:          row.CriteriaID.UUID.String(),
+			Type:        row.Type,
+			Description: row.Description,
+			Expression:  row.Expression,
+		})
+	}
+
+	results := eligibility.Screen(protocols, eligibility.Profile{
+		Age:            req.Age,
+		ECOG:           req.ECOG,
+		Sex:            req.Sex,
+		TumorGroup:     req.TumorGroup,
+		Diagnosis:      req.Diagnosis,
+		Labs:           req.Labs,
+		PriorTherapies: req.PriorTherapies,
+		Flags:          req.Flags,
+	})
+
+	resp := EligibilityScreenResp{Counts: map[string]int{}, Protocols: results}
+	for _, r := range results {
+		resp.Counts[string(r.Status)]++
+	}
+	return resp, nil
+}
@@ -0,0 +1,145 @@
+package protocols
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/dosing"
+	"bcca_crawler/emetogenic"
+	"bcca_crawler/internal/config"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+func HandleGetProtocolEmetogenicRisk(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGet(c, w, r, getProtocolEmetogenicRisk)
+}
+
+func HandleGetEmetogenicAudit(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGetWithQ(c, w, r, getEmetogenicAudit)
+}
+
+func getProtocolEmetogenicRisk(c *config.Config, ctx context.Context, ids api.IDs) (EmetogenicRiskResp, error) {
+	protocol, err := c.Db.GetProtocolByID(ctx, ids.ProtocolID)
+	if err != nil {
+		return EmetogenicRiskResp{}, fmt.Errorf("error getting protocol: %s, with error: %v", ids.ProtocolID.String(), err)
+	}
+	return assessEmetogenicRisk(c, ctx, protocol.ID, protocol.Code)
+}
+
+// getEmetogenicAudit assesses every protocol, query = mismatched=true to
+// only list the protocols whose premedication doesn't follow the guidelines.
+func getEmetogenicAudit(c *config.Config, ctx context.Context, ids api.IDs, query url.Values) (EmetogenicAuditResp, error) {
+	onlyMismatched := strings.ToLower(query.Get("mismatched")) == "true"
+
+	protocols, err := c.Db.GetProtocolCodes(ctx)
+	if err != nil {
+		return EmetogenicAuditResp{}, fmt.Errorf("error getting protocols: %w", err)
+	}
+
+	resp := EmetogenicAuditResp{Protocols: []EmetogenicRiskResp{}, Counts: map[string]int{}}
+	for _, p := range protocols {
+		risk, err := assessEmetogenicRisk(c, ctx, p.ID, p.Code)
+		if err != nil {
+			return EmetogenicAuditResp{}, err
+		}
+		resp.Total++
+		resp.Counts[string(risk.Audit.Status)]++
+		if onlyMismatched && !risk.Audit.Mismatch {
+			continue
+		}
+		resp.Protocols = append(resp.Protocols, risk)
+	}
+	return resp, nil
+}
+
+func assessEmetogenicRisk(c *config.Config, ctx context.Context, protocolID uuid.UUID, code string) (EmetogenicRiskResp, error) {
+	cycles, err := api.GetProtocolCycles(c, ctx, protocolID)
+	if err != nil {
+		return EmetogenicRiskResp{}, fmt.Errorf("error getting protocol cycles: %w", err)
+	}
+	templates := CycleTemplates(cycles)
+
+	meds, err := c.Db.GetProtocolMedicationNames(ctx, protocolID)
+	if err != nil {
+		return EmetogenicRiskResp{}, fmt.Errorf("error getting protocol medications: %w", err)
+	}
+
+	medIDs := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	for _, m := range meds {
+		if !seen[m.ID] {
+			seen[m.ID] = true
+			medIDs = append(medIDs, m.ID)
+		}
+	}
+	levelRows, err := c.Db.GetMedicationEmetogenicLevels(ctx, medIDs)
+	if err != nil {
+		return EmetogenicRiskResp{}, fmt.Errorf("error getting emetogenic levels: %w", err)
+	}
+	levels := map[string]emetogenic.Level{}
+	for _, row := range levelRows {
+		levels[row.ID.String()] = emetogenic.ParseLevel(string(row.EmetogenicLevel))
+	}
+
+	assessment := emetogenic.Assess(emetogenicDays(templates, levels))
+
+	premeds := []emetogenic.Agent{}
+	for _, m := range meds {
+		if m.Source != "premed" {
+			continue
+		}
+		premeds = append(premeds, emetogenic.Agent{
+			MedicationID:   m.ID.String(),
+			Name:           m.Name,
+			AlternateNames: m.AlternateNames,
+			Level:          levels[m.ID.String()],
+		})
+	}
+
+	return EmetogenicRiskResp{
+		ProtocolID:   protocolID,
+		ProtocolCode: code,
+		Assessment:   assessment,
+		Premeds:      premeds,
+		Audit:        emetogenic.Compare(assessment.Level, premeds),
+	}, nil
+}
+
+// emetogenicDays groups each cycle's treatments by administration day.
+func emetogenicDays(templates []dosing.CycleTemplate, levels map[string]emetogenic.Level) []emetogenic.Day {
+	days := []emetogenic.Day{}
+	for _, t := range templates {
+		byDay := map[int][]emetogenic.Agent{}
+		for _, tx := range t.Treatments {
+			level, ok := levels[tx.MedicationID]
+			if !ok {
+				level = emetogenic.LevelUnknown
+			}
+			administered, _ := dosing.ParseDays(tx.Frequency)
+			for _, d := range administered {
+				byDay[d] = append(byDay[d], emetogenic.Agent{
+					MedicationID:   tx.MedicationID,
+					Name:           tx.MedicationName,
+					AlternateNames: tx.AlternateNames,
+					Dose:           tx.Dose,
+					Level:          level,
+				})
+			}
+		}
+
+		order := make([]int, 0, len(byDay))
+		for d := range byDay {
+			order = append(order, d)
+		}
+		sort.Ints(order)
+		for _, d := range order {
+			days = append(days, emetogenic.Day{Cycle: t.Label, Day: d, Agents: byDay[d]})
+		}
+	}
+	return days
+}
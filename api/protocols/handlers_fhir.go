@@ -0,0 +1,128 @@
+package protocols
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/fhir"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/json_utils"
+	"bcca_crawler/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HandleGetProtocolFHIR exports the protocol as a FHIR R4 Bundle.
+func HandleGetProtocolFHIR(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	ids, err := api.ParseAndValidateID(r)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	protocol, err := getFHIRProtocol(c, r.Context(), ids)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	data, err := json.Marshal(fhir.Build(protocol, time.Now()))
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/fhir+json; charset=utf-8")
+	if strings.ToLower(r.URL.Query().Get("download")) == "true" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", strings.ToLower(protocol.Code)+"_fhir.json"))
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+func getFHIRProtocol(c *config.Config, ctx context.Context, ids api.IDs) (fhir.Protocol, error) {
+	protocol, err := c.Db.GetProtocolByID(ctx, ids.ProtocolID)
+	if err != nil {
+		return fhir.Protocol{}, fmt.Errorf("error getting protocol: %s, with error: %v", ids.ProtocolID.String(), err)
+	}
+
+	cycles, err := api.GetProtocolCycles(c, ctx, ids.ProtocolID)
+	if err != nil {
+		return fhir.Protocol{}, fmt.Errorf("error getting protocol cycles: %w", err)
+	}
+	medGroups, err := api.GetProtocolMeds(c, ctx, ids.ProtocolID)
+	if err != nil {
+		return fhir.Protocol{}, fmt.Errorf("error getting protocol prescriptions: %w", err)
+	}
+	testGroups, err := api.GetProtocolTests(c, ctx, ids.ProtocolID)
+	if err != nil {
+		return fhir.Protocol{}, fmt.Errorf("error getting protocol tests: %w", err)
+	}
+
+	return fhir.Protocol{
+		ID:                protocol.ID.String(),
+		Code:              protocol.Code,
+		Name:              protocol.Name,
+		TumorGroup:        protocol.TumorGroup,
+		RevisedOn:         protocol.RevisedOn,
+		ProtocolURL:       protocol.ProtocolUrl,
+		PatientHandoutURL: protocol.PatientHandoutUrl,
+		Cycles:            api.MapAll(cycles, mapFHIRCycle),
+		MedGroups:         api.MapAll(medGroups, mapFHIRMedGroup),
+		LabGroups:         api.MapAll(testGroups, mapFHIRLabGroup),
+	}, nil
+}
+
+func mapFHIRCycle(src api.ProtocolCycle) fhir.Cycle {
+	meds := make([]fhir.Medication, 0, len(src.Treatments))
+	for _, tx := range src.Treatments {
+		meds = append(meds, fhir.Medication{
+			ID:             tx.ID.String(),
+			MedicationID:   tx.MedicationID.String(),
+			Name:           tx.MedicationName,
+			Category:       tx.MedicationCategory,
+			AlternateNames: tx.MedicationAlternates,
+			Dose:           tx.Dose,
+			Route:          string(tx.Route),
+			Frequency:      tx.Frequency,
+			Duration:       tx.Duration,
+			Instructions:   tx.AdministrationGuide,
+		})
+	}
+	return fhir.Cycle{ID: src.ID.String(), Label: src.Cycle, Duration: src.CycleDuration, Medications: meds}
+}
+
+func mapFHIRMedGroup(src models.ProtocolMedGroup) fhir.MedGroup {
+	meds := make([]fhir.Medication, 0, len(src.Medications))
+	for _, m := range src.Medications {
+		meds = append(meds, fhir.Medication{
+			ID:             m.ID.String(),
+			MedicationID:   m.MedicationID.String(),
+			Name:           m.MedicationName,
+			Category:       m.MedicationCategory,
+			AlternateNames: m.MedicationAlternates,
+			Dose:           m.Dose,
+			Route:          m.Route,
+			Frequency:      m.Frequency,
+			Duration:       m.Duration,
+			Instructions:   m.Instructions,
+		})
+	}
+	return fhir.MedGroup{ID: src.ID.String(), Category: src.Category, Comments: src.Comments, Medications: meds}
+}
+
+func mapFHIRLabGroup(src models.ProtocolTestGroup) fhir.LabGroup {
+	tests := make([]fhir.Lab, 0, len(src.Tests))
+	for _, t := range src.Tests {
+		tests = append(tests, fhir.Lab{
+			ID:          t.ID.String(),
+			Name:        t.Name,
+			Description: t.Description,
+			Unit:        t.Unit,
+			LowerLimit:  t.LowerLimit,
+			UpperLimit:  t.UpperLimit,
+		})
+	}
+	return fhir.LabGroup{ID: src.ID.String(), Category: src.Category, Comments: src.Comments, Tests: tests}
+}
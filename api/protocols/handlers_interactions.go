@@ -0,0 +1,185 @@
+package protocols
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/interactions"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func HandleGetInteractions(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGetWithQ(c, w, r, getInteractions)
+}
+
+func HandleGetInteractionByID(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGet(c, w, r, getInteractionByID)
+}
+
+func HandleUpsertInteraction(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleUpsert(c, w, r, upsertInteraction)
+}
+
+func HandleDeleteInteraction(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleModify(c, w, r, deleteInteraction)
+}
+
+func HandleGetProtocolInteractions(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGetWithQ(c, w, r, getProtocolInteractions)
+}
+
+func getInteractions(c *config.Config, ctx context.Context, ids api.IDs, query url.Values) ([]InteractionResp, error) {
+	items, err := c.Db.GetInteractions(ctx, interactions.Normalize(query.Get("drug")))
+	if err != nil {
+		return nil, err
+	}
+	return api.MapAll(items, MapInteraction), nil
+}
+
+func getInteractionByID(c *config.Config, ctx context.Context, ids api.IDs) (InteractionResp, error) {
+	item, err := c.Db.GetInteractionByID(ctx, ids.ID)
+	if err != nil {
+		return InteractionResp{}, err
+	}
+	return MapInteraction(item), nil
+}
+
+func upsertInteraction(c *config.Config, ctx context.Context, req InteractionReq, ids api.IDs) error {
+	record := interactions.Record{
+		DrugA:       req.DrugA,
+		DrugB:       req.DrugB,
+		Severity:    interactions.Severity(strings.ToLower(req.Severity)),
+		Description: req.Description,
+		Management:  req.Management,
+		Source:      req.Source,
+	}
+	return saveInteraction(c.Db, ctx, record)
+}
+
+func deleteInteraction(c *config.Config, ctx context.Context, ids api.IDs) (string, error) {
+	err := c.Db.RemoveInteraction(ctx, ids.ID)
+	if err != nil {
+		return "", fmt.Errorf("error deleting interaction: %s, with error: %v", ids.ID.String(), err)
+	}
+	return fmt.Sprintf("Interaction %s deleted.", ids.ID.String()), nil
+}
+
+func saveInteraction(q *database.Queries, ctx context.Context, r interactions.Record) error {
+	a, b := interactions.Pair(r.DrugA, r.DrugB)
+	if a == b {
+		return fmt.Errorf("an interaction needs two different drugs, got: %s", a)
+	}
+	_, err := q.UpsertInteraction(ctx, database.UpsertInteractionParams{
+		DrugA:       a,
+		DrugB:       b,
+		Severity:    database.InteractionSeverityEnum(r.Severity),
+		Description: r.Description,
+		Management:  r.Management,
+		Source:      r.Source,
+	})
+	if err != nil {
+		return fmt.Errorf("error upserting interaction: %s / %s with error:%s", a, b, err.Error())
+	}
+	return nil
+}
+
+// ImportInteractions saves every record of a dataset in one transaction,
+// replacing the existing entry of a pair. A pair listed more than once is
+// saved once, with its most serious record. It returns how many records were
+// saved; on error nothing is.
+func ImportInteractions(c *config.Config, ctx context.Context, records []interactions.Record) (int, error) {
+	records = interactions.Collapse(records)
+	tx, err := c.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+	q := c.Db.WithTx(tx)
+
+	for _, r := range records {
+		if err := saveInteraction(q, ctx, r); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing import: %v", err)
+	}
+	return len(records), nil
+}
+
+// ProtocolDrugs lists the treatment, premed and support medications of a protocol.
+func ProtocolDrugs(c *config.Config, ctx context.Context, ids api.IDs) ([]interactions.Drug, error) {
+	meds, err := c.Db.GetProtocolMedicationNames(ctx, ids.ProtocolID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting protocol medications: %w", err)
+	}
+
+	drugs := []interactions.Drug{}
+	seen := map[string]bool{}
+	for _, m := range meds {
+		// a drug given as treatment and as support medication is checked once
+		if seen[m.ID.String()] {
+			continue
+		}
+		seen[m.ID.String()] = true
+		drugs = append(drugs, interactions.Drug{
+			ID:             m.ID.String(),
+			Name:           m.Name,
+			AlternateNames: m.AlternateNames,
+			Source:         m.Source,
+		})
+	}
+	return drugs, nil
+}
+
+// getProtocolInteractions checks the protocol's medications against each
+// other and against the comma separated home_meds query parameter.
+func getProtocolInteractions(c *config.Config, ctx context.Context, ids api.IDs, query url.Values) (ProtocolInteractionsResp, error) {
+	drugs, err := ProtocolDrugs(c, ctx, ids)
+	if err != nil {
+		return ProtocolInteractionsResp{}, err
+	}
+
+	home := []interactions.Drug{}
+	for _, value := range query["home_meds"] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				home = append(home, interactions.Drug{Name: name, Source: interactions.ScopeHome})
+			}
+		}
+	}
+
+	items, err := c.Db.GetInteractionsForNames(ctx, interactions.Names(drugs, home))
+	if err != nil {
+		return ProtocolInteractionsResp{}, fmt.Errorf("error getting interactions: %w", err)
+	}
+	records := make([]interactions.Record, 0, len(items))
+	for _, i := range items {
+		records = append(records, interactions.Record{
+			DrugA:       i.DrugA,
+			DrugB:       i.DrugB,
+			Severity:    interactions.Severity(i.Severity),
+			Description: i.Description,
+			Management:  i.Management,
+			Source:      i.Source,
+		})
+	}
+
+	hits := interactions.Check(drugs, home, records)
+	counts := map[string]int{}
+	for _, h := range hits {
+		counts[string(h.Severity)]++
+	}
+
+	return ProtocolInteractionsResp{
+		ProtocolID:      ids.ProtocolID,
+		Medications:     drugs,
+		HomeMedications: home,
+		Interactions:    hits,
+		Counts:          counts,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package protocols
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/crawler"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// HandleGetProtocolInventory lists the protocols found on the website,
+// query = status=active|retired|all (default all).
+func HandleGetProtocolInventory(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGetWithQ(c, w, r, getProtocolInventory)
+}
+
+func getProtocolInventory(c *config.Config, ctx context.Context, ids api.IDs, query url.Values) ([]ProtocolListingResp, error) {
+	status := strings.ToLower(query.Get("status"))
+	switch status {
+	case "":
+		status = "all"
+	case "all", "active", "retired":
+	default:
+		return nil, fmt.Errorf("status must be active, retired or all")
+	}
+	items, err := c.Db.GetProtocolListings(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("error getting protocol inventory: %s, with error: %v", status, err)
+	}
+	return api.MapAll(items, MapProtocolListing), nil
+}
+
+// SaveInventory records a discovery crawl: every listed protocol is marked
+// seen and its tumor group copied onto the protocol. Listings the crawl did
+// not find are retired, but only when every page was read, so a site
+// outage does not retire the whole catalogue.
+func SaveInventory(c *config.Config, ctx context.Context, inv crawler.Inventory) (InventoryResult, error) {
+	result := InventoryResult{
+		Pages:      len(inv.Pages),
+		Incomplete: len(inv.Errors) > 0 || len(inv.Protocols) == 0,
+		Errors:     inv.Errors,
+		Warnings:   inv.Warnings,
+		Missing:    []ProtocolListingResp{},
+		Retired:    []ProtocolListingResp{},
+	}
+
+	codes := make([]string, 0, len(inv.Protocols))
+	for _, item := range inv.Protocols {
+		listing, err := c.Db.UpsertProtocolListing(ctx, database.UpsertProtocolListingParams{
+			Code:        item.Code,
+			TumorGroup:  item.TumorGroup,
+			Description: item.Description,
+			PageUrl:     item.PageURL,
+		})
+		if err != nil {
+			return result, fmt.Errorf("error saving protocol listing: %s, with error: %v", item.Code, err)
+		}
+		codes = append(codes, item.Code)
+		result.Listed++
+
+		if !listing.ProtocolID.Valid {
+			result.Missing = append(result.Missing, MapProtocolListing(listing))
+			continue
+		}
+		if item.TumorGroup != "unknown" {
+			err := c.Db.UpdateProtocolTumorGroup(ctx, database.UpdateProtocolTumorGroupParams{TumorGroup: item.TumorGroup, Code: item.Code})
+			if err != nil {
+				return result, fmt.Errorf("error updating tumor group: %s, with error: %v", item.Code, err)
+			}
+		}
+	}
+
+	if result.Incomplete {
+		return result, nil
+	}
+	retired, err := c.Db.RetireMissingListings(ctx, codes)
+	if err != nil {
+		return result, fmt.Errorf("error retiring protocol listings: %v", err)
+	}
+	result.Retired = api.MapAll(retired, MapProtocolListing)
+	return result, nil
+}
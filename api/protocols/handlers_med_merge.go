@@ -0,0 +1,79 @@
+package protocols
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/medications"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type MedMergeProposalResp struct {
+	Survivor   MedicationResp   `json:"survivor"`
+	Duplicates []MedicationResp `json:"duplicates"`
+	Reasons    []string         `json:"reasons"`
+}
+
+type MedMergeReq struct {
+	DuplicateIDs []string `json:"duplicate_ids" validate:"required,min=1,dive,uuid"`
+}
+
+type MedMergeResp struct {
+	Survivor      MedicationResp `json:"survivor"`
+	Merged        []uuid.UUID    `json:"merged"`
+	Prescriptions int64          `json:"prescriptions"`
+	Treatments    int64          `json:"treatments"`
+	Modifications int64          `json:"modifications"`
+	DoseLimits    int64          `json:"dose_limits"`
+}
+
+// HandleGetMedDuplicates proposes merges for medications that look like one
+// drug: the same name in another case or spacing, synonyms, or a near
+// spelling. Nothing is merged.
+func HandleGetMedDuplicates(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGet(c, w, r, getMedDuplicates)
+}
+
+// HandleMergeMeds merges the medications of the body into the medication of
+// the path, re-pointing their prescriptions, treatments and modifications.
+func HandleMergeMeds(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandlePost(c, w, r, mergeMeds)
+}
+
+func getMedDuplicates(c *config.Config, ctx context.Context, ids api.IDs) ([]MedMergeProposalResp, error) {
+	items, err := c.Db.GetMedications(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting medications: %v", err)
+	}
+	return api.MapAll(medications.Propose(items), MapMedMergeProposal), nil
+}
+
+func mergeMeds(c *config.Config, ctx context.Context, req MedMergeReq, ids api.IDs) (MedMergeResp, error) {
+	duplicates := make([]uuid.UUID, 0, len(req.DuplicateIDs))
+	for _, id := range req.DuplicateIDs {
+		duplicates = append(duplicates, api.ParseOrNilUUID(id))
+	}
+	result, err := medications.Merge(c, ctx, ids.ID, duplicates)
+	if err != nil {
+		return MedMergeResp{}, fmt.Errorf("error merging into medication: %s, with error: %v", ids.ID.String(), err)
+	}
+	return MedMergeResp{
+		Survivor:      MapMedication(result.Survivor),
+		Merged:        result.Merged,
+		Prescriptions: result.Prescriptions,
+		Treatments:    result.Treatments,
+		Modifications: result.Modifications,
+		DoseLimits:    result.DoseLimits,
+	}, nil
+}
+
+func MapMedMergeProposal(src medications.Proposal) MedMergeProposalResp {
+	return MedMergeProposalResp{
+		Survivor:   MapMedication(src.Survivor),
+		Duplicates: api.MapAll(src.Duplicates, MapMedication),
+		Reasons:    src.Reasons,
+	}
+}
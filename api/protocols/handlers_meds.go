@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -65,12 +66,22 @@ func upsertMed(c *config.Config, ctx context.Context, req MedReq, ids api.IDs) e
 		AlternateNames: req.AlternateNames,
 	}
 
-	_, err := c.Db.UpsertMedication(ctx, medication)
+	saved, err := c.Db.UpsertMedication(ctx, medication)
 
 	if err != nil {
 		return fmt.Errorf("error upserting medication: %s with error:%s", req.ID, err.Error())
 	}
 
+	if req.EmetogenicLevel != "" {
+		err = c.Db.UpdateMedicationEmetogenicLevel(ctx, database.UpdateMedicationEmetogenicLevelParams{
+			ID:              saved.ID,
+			EmetogenicLevel: database.EmetogenicLevelEnum(strings.ToLower(req.EmetogenicLevel)),
+		})
+		if err != nil {
+			return fmt.Errorf("error setting emetogenic level for medication: %s with error:%s", saved.ID.String(), err.Error())
+		}
+	}
+
 	return nil
 }
 
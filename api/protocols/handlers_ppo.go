@@ -0,0 +1,243 @@
+package protocols
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"bcca_crawler/internal/json_utils"
+	"bcca_crawler/models"
+	"bcca_crawler/ppo"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// HandleGetProtocolPPO serves the pre-printed order sheet of a protocol as
+// PDF (default) or HTML with format=html. The copy stored in protocol_ppos is
+// served while it matches the protocol's revision; otherwise the sheet is
+// rendered and stored first.
+func HandleGetProtocolPPO(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	ids, format, err := parsePPORequest(r)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	protocol, err := c.Db.GetProtocolByID(r.Context(), ids.ProtocolID)
+	if errors.Is(err, sql.ErrNoRows) {
+		json_utils.RespondWithError(w, http.StatusNotFound, "protocol not found")
+		return
+	}
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error getting protocol: %s, with error: %v", ids.ProtocolID.String(), err))
+		return
+	}
+
+	stored, err := c.Db.GetRenderedPPO(r.Context(), database.GetRenderedPPOParams{ProtocolID: ids.ProtocolID, Format: format})
+	if err == nil && stored.ProtocolRevisedOn == protocol.RevisedOn {
+		writePPO(w, stored)
+		return
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		json_utils.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error getting stored PPO: %s, with error: %v", protocol.Code, err))
+		return
+	}
+
+	stored, err = storePPO(c, r.Context(), ids, format)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writePPO(w, stored)
+}
+
+// HandleStorePPO renders the order sheet again and stores it, whether or
+// not the stored copy is current, so a change of layout reaches every
+// protocol.
+func HandleStorePPO(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	ids, format, err := parsePPORequest(r)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	stored, err := storePPO(c, r.Context(), ids, format)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writePPO(w, stored)
+}
+
+// HandleGetStoredPPO serves a stored order sheet by the content hash its url
+// names, so the link keeps pointing at that exact document.
+func HandleGetStoredPPO(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	ids, err := api.ParseAndValidateID(r)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	hash := strings.ToLower(mux.Vars(r)["hash"])
+
+	stored, err := c.Db.GetRenderedPPOByHash(r.Context(), database.GetRenderedPPOByHashParams{ProtocolID: ids.ProtocolID, ContentHash: hash})
+	if errors.Is(err, sql.ErrNoRows) {
+		json_utils.RespondWithError(w, http.StatusNotFound, "stored PPO not found")
+		return
+	}
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error getting stored PPO: %s, with error: %v", hash, err))
+		return
+	}
+	writePPO(w, stored)
+}
+
+func parsePPORequest(r *http.Request) (api.IDs, database.PpoFormatEnum, error) {
+	ids, err := api.ParseAndValidateID(r)
+	if err != nil {
+		return ids, "", err
+	}
+
+	format := database.PpoFormatEnum(strings.ToLower(r.URL.Query().Get("format")))
+	switch format {
+	case "":
+		format = database.PpoFormatEnumPdf
+	case database.PpoFormatEnumPdf, database.PpoFormatEnumHtml:
+	default:
+		return ids, "", fmt.Errorf("format must be pdf or html")
+	}
+	return ids, format, nil
+}
+
+// storePPO renders the order sheet and upserts it into protocol_ppos. The
+// stored url names the content hash, so it identifies this copy rather than
+// the renderer.
+func storePPO(c *config.Config, ctx context.Context, ids api.IDs, format database.PpoFormatEnum) (database.ProtocolPpo, error) {
+	protocol, err := getPPOProtocol(c, ctx, ids)
+	if err != nil {
+		return database.ProtocolPpo{}, err
+	}
+
+	var buf bytes.Buffer
+	sheet := ppo.Build(protocol)
+	if format == database.PpoFormatEnumHtml {
+		err = ppo.RenderHTML(&buf, sheet)
+	} else {
+		err = ppo.RenderPDF(&buf, sheet)
+	}
+	if err != nil {
+		return database.ProtocolPpo{}, fmt.Errorf("error rendering PPO: %s, with error: %v", protocol.Code, err)
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	stored := database.ProtocolPpo{
+		ProtocolID:        ids.ProtocolID,
+		Format:            format,
+		Rendered:          true,
+		Title:             protocol.Code + " PPO",
+		Content:           buf.Bytes(),
+		ContentHash:       hex.EncodeToString(sum[:]),
+		ProtocolRevisedOn: protocol.RevisedOn,
+	}
+	stored.Url = storedPPOURL(ids.ProtocolID, stored.ContentHash)
+
+	err = c.Db.UpsertRenderedPPO(ctx, database.UpsertRenderedPPOParams{
+		ProtocolID:        stored.ProtocolID,
+		Format:            stored.Format,
+		Title:             stored.Title,
+		Url:               stored.Url,
+		Content:           stored.Content,
+		ContentHash:       stored.ContentHash,
+		ProtocolRevisedOn: stored.ProtocolRevisedOn,
+	})
+	if err != nil {
+		return database.ProtocolPpo{}, fmt.Errorf("error storing PPO: %s, with error: %v", protocol.Code, err)
+	}
+	return stored, nil
+}
+
+func storedPPOURL(protocolID uuid.UUID, hash string) string {
+	return fmt.Sprintf("/api/v1/protocols/%s/ppo/%s", protocolID.String(), hash)
+}
+
+func writePPO(w http.ResponseWriter, p database.ProtocolPpo) {
+	filename := strings.ToLower(strings.ReplaceAll(p.Title, " ", "_")) + "." + string(p.Format)
+	if p.Format == database.PpoFormatEnumHtml {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/pdf")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	w.Header().Set("Content-Location", p.Url)
+	w.WriteHeader(http.StatusOK)
+	w.Write(p.Content)
+}
+
+func getPPOProtocol(c *config.Config, ctx context.Context, ids api.IDs) (ppo.Protocol, error) {
+	protocol, err := c.Db.GetProtocolByID(ctx, ids.ProtocolID)
+	if err != nil {
+		return ppo.Protocol{}, fmt.Errorf("error getting protocol: %s, with error: %v", ids.ProtocolID.String(), err)
+	}
+
+	cycles, err := api.GetProtocolCycles(c, ctx, ids.ProtocolID)
+	if err != nil {
+		return ppo.Protocol{}, fmt.Errorf("error getting protocol cycles: %w", err)
+	}
+	medGroups, err := api.GetProtocolMeds(c, ctx, ids.ProtocolID)
+	if err != nil {
+		return ppo.Protocol{}, fmt.Errorf("error getting protocol prescriptions: %w", err)
+	}
+	testGroups, err := api.GetProtocolTests(c, ctx, ids.ProtocolID)
+	if err != nil {
+		return ppo.Protocol{}, fmt.Errorf("error getting protocol tests: %w", err)
+	}
+
+	p := ppo.Protocol{
+		Code:       protocol.Code,
+		Name:       protocol.Name,
+		TumorGroup: protocol.TumorGroup,
+		RevisedOn:  protocol.RevisedOn,
+		Templates:  CycleTemplates(cycles),
+		Labs:       api.MapAll(testGroups, mapPPOLabGroup),
+	}
+	for _, g := range medGroups {
+		switch strings.ToLower(g.Category) {
+		case "premed":
+			p.Premeds = append(p.Premeds, mapPPOMedications(g)...)
+		case "support":
+			p.Support = append(p.Support, mapPPOMedications(g)...)
+		}
+	}
+	return p, nil
+}
+
+func mapPPOMedications(src models.ProtocolMedGroup) []ppo.Medication {
+	meds := make([]ppo.Medication, 0, len(src.Medications))
+	for _, m := range src.Medications {
+		meds = append(meds, ppo.Medication{
+			Name:         m.MedicationName,
+			Dose:         m.Dose,
+			Route:        m.Route,
+			Frequency:    m.Frequency,
+			Duration:     m.Duration,
+			Instructions: m.Instructions,
+		})
+	}
+	return meds
+}
+
+func mapPPOLabGroup(src models.ProtocolTestGroup) ppo.LabGroup {
+	tests := make([]string, 0, len(src.Tests))
+	for _, t := range src.Tests {
+		tests = append(tests, t.Name)
+	}
+	return ppo.LabGroup{Category: src.Category, Comments: src.Comments, Tests: tests}
+}
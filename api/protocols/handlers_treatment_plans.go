@@ -0,0 +1,399 @@
+package protocols
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/calendar"
+	"bcca_crawler/dosing"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func HandleGetTreatmentPlans(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGetWithQ(c, w, r, getTreatmentPlans)
+}
+
+func HandleGetTreatmentPlanByID(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGet(c, w, r, getTreatmentPlanByID)
+}
+
+func HandleCreateTreatmentPlan(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandlePost(c, w, r, createTreatmentPlan)
+}
+
+func HandleUpdateTreatmentPlan(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandlePost(c, w, r, updateTreatmentPlan)
+}
+
+func HandleDeleteTreatmentPlan(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleModify(c, w, r, deleteTreatmentPlan)
+}
+
+func HandleGetTreatmentPlanCycles(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGet(c, w, r, getTreatmentPlanCycles)
+}
+
+func HandleRecordTreatmentPlanCycle(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandlePost(c, w, r, recordTreatmentPlanCycle)
+}
+
+func HandleDeleteTreatmentPlanCycle(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleModify(c, w, r, deleteTreatmentPlanCycle)
+}
+
+func HandleGetTreatmentPlanProgress(c *config.Config, w http.ResponseWriter, r *http.Request) {
+	api.HandleGet(c, w, r, getTreatmentPlanProgress)
+}
+
+func getTreatmentPlans(c *config.Config, ctx context.Context, ids api.IDs, query url.Values) ([]TreatmentPlanResp, error) {
+	items, err := c.Db.GetTreatmentPlans(ctx, strings.TrimSpace(query.Get("patient_ref")))
+	if err != nil {
+		return nil, err
+	}
+	return api.MapAll(items, MapTreatmentPlan), nil
+}
+
+func getTreatmentPlanByID(c *config.Config, ctx context.Context, ids api.IDs) (TreatmentPlanResp, error) {
+	item, err := getTreatmentPlan(c, ctx, ids.ID)
+	if err != nil {
+		return TreatmentPlanResp{}, err
+	}
+	return MapTreatmentPlan(item), nil
+}
+
+func createTreatmentPlan(c *config.Config, ctx context.Context, req TreatmentPlanReq, ids api.IDs) (TreatmentPlanResp, error) {
+	start, err := time.Parse(calendar.DateLayout, req.StartDate)
+	if err != nil {
+		return TreatmentPlanResp{}, api.WrapError(http.StatusBadRequest, fmt.Sprintf("start date: %s is not a valid date", req.StartDate), err)
+	}
+	if req.BSA == 0 {
+		req.BSA = dosing.MostellerBSA(req.HeightCm, req.WeightKg)
+	}
+	baseline, err := baselineOrEmpty(req.Baseline)
+	if err != nil {
+		return TreatmentPlanResp{}, api.WrapError(http.StatusBadRequest, err.Error(), err)
+	}
+
+	plan, err := c.Db.CreateTreatmentPlan(ctx, database.CreateTreatmentPlanParams{
+		PatientRef:    strings.TrimSpace(req.PatientRef),
+		StartDate:     start,
+		PlannedCycles: int32(req.PlannedCycles),
+		HeightCm:      req.HeightCm,
+		WeightKg:      req.WeightKg,
+		Bsa:           req.BSA,
+		Baseline:      baseline,
+		Notes:         req.Notes,
+		ProtocolID:    api.ParseOrNilUUID(req.ProtocolID),
+	})
+	// the plan is inserted from the protocol row, so a missing protocol
+	// inserts nothing
+	if errors.Is(err, sql.ErrNoRows) || isForeignKeyViolation(err) {
+		return TreatmentPlanResp{}, api.WrapError(http.StatusNotFound, fmt.Sprintf("protocol: %s not found", req.ProtocolID), err)
+	}
+	if err != nil {
+		return TreatmentPlanResp{}, fmt.Errorf("error creating treatment plan with error: %v", err)
+	}
+	return MapTreatmentPlan(plan), nil
+}
+
+func updateTreatmentPlan(c *config.Config, ctx context.Context, req TreatmentPlanUpdateReq, ids api.IDs) (TreatmentPlanResp, error) {
+	plan, err := getTreatmentPlan(c, ctx, ids.ID)
+	if err != nil {
+		return TreatmentPlanResp{}, err
+	}
+
+	params := database.UpdateTreatmentPlanParams{
+		Status:        plan.Status,
+		PlannedCycles: plan.PlannedCycles,
+		HeightCm:      plan.HeightCm,
+		WeightKg:      plan.WeightKg,
+		Bsa:           plan.Bsa,
+		Baseline:      plan.Baseline,
+		Notes:         plan.Notes,
+		ID:            plan.ID,
+	}
+	if req.Status != "" {
+		params.Status = database.PlanStatusEnum(strings.ToLower(req.Status))
+	}
+	if req.PlannedCycles != 0 {
+		params.PlannedCycles = int32(req.PlannedCycles)
+	}
+	if req.HeightCm != 0 {
+		params.HeightCm = req.HeightCm
+	}
+	if req.WeightKg != 0 {
+		params.WeightKg = req.WeightKg
+	}
+	switch {
+	case req.BSA != 0:
+		params.Bsa = req.BSA
+	case req.HeightCm != 0 || req.WeightKg != 0:
+		params.Bsa = dosing.MostellerBSA(params.HeightCm, params.WeightKg)
+	}
+	if len(req.Baseline) > 0 {
+		params.Baseline, err = baselineOrEmpty(req.Baseline)
+		if err != nil {
+			return TreatmentPlanResp{}, api.WrapError(http.StatusBadRequest, err.Error(), err)
+		}
+	}
+	if req.Notes != nil {
+		params.Notes = *req.Notes
+	}
+
+	updated, err := c.Db.UpdateTreatmentPlan(ctx, params)
+	if err != nil {
+		return TreatmentPlanResp{}, fmt.Errorf("error updating treatment plan: %s, with error: %v", ids.ID.String(), err)
+	}
+	return MapTreatmentPlan(updated), nil
+}
+
+func deleteTreatmentPlan(c *config.Config, ctx context.Context, ids api.IDs) (string, error) {
+	err := c.Db.RemoveTreatmentPlan(ctx, ids.ID)
+	if err != nil {
+		return "", fmt.Errorf("error deleting treatment plan: %s, with error: %v", ids.ID.String(), err)
+	}
+	return fmt.Sprintf("Treatment plan %s deleted.", ids.ID.String()), nil
+}
+
+func getTreatmentPlanCycles(c *config.Config, ctx context.Context, ids api.IDs) ([]PlanCycleResp, error) {
+	items, err := c.Db.GetTreatmentPlanCycles(ctx, ids.ID)
+	if err != nil {
+		return nil, err
+	}
+	return api.MapAll(items, MapPlanCycle), nil
+}
+
+func recordTreatmentPlanCycle(c *config.Config, ctx context.Context, req PlanCycleReq, ids api.IDs) (PlanCycleResp, error) {
+	plan, err := getTreatmentPlan(c, ctx, ids.ID)
+	if err != nil {
+		return PlanCycleResp{}, err
+	}
+	if req.CycleNumber > int(plan.PlannedCycles) {
+		err := fmt.Errorf("cycle %d is past the %d planned cycles", req.CycleNumber, plan.PlannedCycles)
+		return PlanCycleResp{}, api.WrapError(http.StatusBadRequest, err.Error(), err)
+	}
+
+	params, err := planCycleParams(req)
+	if err != nil {
+		return PlanCycleResp{}, api.WrapError(http.StatusBadRequest, err.Error(), err)
+	}
+	params.PlanID = plan.ID
+
+	item, err := c.Db.AddTreatmentPlanCycle(ctx, params)
+	if isForeignKeyViolation(err) {
+		return PlanCycleResp{}, api.WrapError(http.StatusBadRequest, "toxicity_grade_id or test_id does not exist", err)
+	}
+	if err != nil {
+		return PlanCycleResp{}, fmt.Errorf("error recording cycle %d for treatment plan: %s, with error: %v", req.CycleNumber, plan.ID.String(), err)
+	}
+
+	return PlanCycleResp{
+		ID:              item.ID,
+		CreatedAt:       item.CreatedAt,
+		UpdatedAt:       item.UpdatedAt,
+		CycleNumber:     item.CycleNumber,
+		Status:          string(item.Status),
+		RecordedOn:      item.RecordedOn.Format(calendar.DateLayout),
+		DelayDays:       item.DelayDays,
+		DosePercent:     item.DosePercent,
+		Reason:          item.Reason,
+		ToxicityGradeID: nullableID(item.ToxicityGradeID),
+		TestID:          nullableID(item.TestID),
+		LabResult:       item.LabResult,
+		Notes:           item.Notes,
+	}, nil
+}
+
+func deleteTreatmentPlanCycle(c *config.Config, ctx context.Context, ids api.IDs) (string, error) {
+	err := c.Db.RemoveTreatmentPlanCycle(ctx, database.RemoveTreatmentPlanCycleParams{
+		ID:     ids.CycleID,
+		PlanID: ids.ID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error deleting treatment plan cycle: %s, with error: %v", ids.CycleID.String(), err)
+	}
+	return fmt.Sprintf("Treatment plan cycle %s deleted.", ids.CycleID.String()), nil
+}
+
+func getTreatmentPlanProgress(c *config.Config, ctx context.Context, ids api.IDs) (TreatmentPlanProgress, error) {
+	plan, err := getTreatmentPlan(c, ctx, ids.ID)
+	if err != nil {
+		return TreatmentPlanProgress{}, err
+	}
+	history, err := c.Db.GetTreatmentPlanCycles(ctx, plan.ID)
+	if err != nil {
+		return TreatmentPlanProgress{}, fmt.Errorf("error getting treatment plan cycles: %s, with error: %v", ids.ID.String(), err)
+	}
+	cycles, err := api.GetProtocolCycles(c, ctx, plan.ProtocolID)
+	if err != nil {
+		return TreatmentPlanProgress{}, fmt.Errorf("error getting protocol cycles: %w", err)
+	}
+
+	return planProgress(plan, history, CycleTemplates(cycles)), nil
+}
+
+// planProgress summarises the recorded cycles of a plan. A cycle counts as
+// completed once it has been given, at full or reduced dose.
+func planProgress(plan database.TreatmentPlan, history []database.GetTreatmentPlanCyclesRow, templates []dosing.CycleTemplate) TreatmentPlanProgress {
+	progress := TreatmentPlanProgress{
+		Plan:               MapTreatmentPlan(plan),
+		CurrentDosePercent: 100,
+		History:            api.MapAll(history, MapPlanCycle),
+	}
+
+	completed := map[int32]bool{}
+	var last *database.GetTreatmentPlanCyclesRow
+	for i, h := range history {
+		switch h.Status {
+		case database.PlanCycleStatusEnumDelayed:
+			progress.Delays++
+			progress.TotalDelayDays += int(h.DelayDays)
+		case database.PlanCycleStatusEnumDoseReduced:
+			progress.DoseReductions++
+			fallthrough
+		case database.PlanCycleStatusEnumGiven:
+			completed[h.CycleNumber] = true
+			if last == nil || h.CycleNumber > last.CycleNumber || (h.CycleNumber == last.CycleNumber && !h.RecordedOn.Before(last.RecordedOn)) {
+				last = &history[i]
+			}
+		}
+	}
+
+	progress.CyclesCompleted = len(completed)
+	progress.CyclesRemaining = max(int(plan.PlannedCycles)-progress.CyclesCompleted, 0)
+	if plan.PlannedCycles > 0 {
+		progress.PercentComplete = math.Round(float64(progress.CyclesCompleted)/float64(plan.PlannedCycles)*1000) / 10
+	}
+
+	due := plan.StartDate
+	progress.NextCycle = 1
+	if last != nil {
+		progress.CurrentDosePercent = last.DosePercent
+		progress.NextCycle = int(last.CycleNumber) + 1
+		length := calendar.DefaultCycleLength
+		if template, ok := dosing.SelectCycle(templates, int(last.CycleNumber)); ok {
+			if days, ok := dosing.ParseDurationDays(template.Duration); ok {
+				length = days
+			}
+		}
+		due = last.RecordedOn.AddDate(0, 0, length)
+	}
+	if progress.NextCycle > int(plan.PlannedCycles) || plan.Status == database.PlanStatusEnumCompleted || plan.Status == database.PlanStatusEnumDiscontinued {
+		progress.NextCycle = 0
+		return progress
+	}
+
+	for _, h := range history {
+		if h.Status == database.PlanCycleStatusEnumDelayed && int(h.CycleNumber) == progress.NextCycle {
+			due = due.AddDate(0, 0, int(h.DelayDays))
+		}
+	}
+	progress.NextDueDate = due.Format(calendar.DateLayout)
+	return progress
+}
+
+// planCycleParams checks a recorded cycle against its status. Delays and dose
+// reductions must point at the toxicity grade or lab test that triggered
+// them; the reason only adds words to that link.
+func planCycleParams(req PlanCycleReq) (database.AddTreatmentPlanCycleParams, error) {
+	recordedOn, err := time.Parse(calendar.DateLayout, req.RecordedOn)
+	if err != nil {
+		return database.AddTreatmentPlanCycleParams{}, fmt.Errorf("recorded on: %s is not a valid date", req.RecordedOn)
+	}
+
+	status := database.PlanCycleStatusEnum(strings.ToLower(req.Status))
+	if req.DosePercent == 0 {
+		req.DosePercent = 100
+	}
+	switch status {
+	case database.PlanCycleStatusEnumGiven:
+		if req.DelayDays != 0 {
+			return database.AddTreatmentPlanCycleParams{}, fmt.Errorf("a given cycle cannot have delay_days, record it as delayed")
+		}
+	case database.PlanCycleStatusEnumDelayed:
+		if req.DelayDays == 0 {
+			return database.AddTreatmentPlanCycleParams{}, fmt.Errorf("a delayed cycle needs delay_days")
+		}
+	case database.PlanCycleStatusEnumDoseReduced:
+		if req.DosePercent >= 100 {
+			return database.AddTreatmentPlanCycleParams{}, fmt.Errorf("a dose reduced cycle needs a dose_percent below 100")
+		}
+	default:
+		return database.AddTreatmentPlanCycleParams{}, fmt.Errorf("unknown cycle status: %s", req.Status)
+	}
+	if status != database.PlanCycleStatusEnumGiven && req.ToxicityGradeID == "" && req.TestID == "" {
+		return database.AddTreatmentPlanCycleParams{}, fmt.Errorf("a %s cycle needs the toxicity_grade_id or test_id behind it", status)
+	}
+	if req.LabResult != "" && req.TestID == "" {
+		return database.AddTreatmentPlanCycleParams{}, fmt.Errorf("a lab_result needs the test_id it was measured for")
+	}
+
+	toxicityGradeID := uuid.NullUUID{}
+	if req.ToxicityGradeID != "" {
+		toxicityGradeID = uuid.NullUUID{UUID: api.ParseOrNilUUID(req.ToxicityGradeID), Valid: true}
+	}
+	testID := uuid.NullUUID{}
+	if req.TestID != "" {
+		testID = uuid.NullUUID{UUID: api.ParseOrNilUUID(req.TestID), Valid: true}
+	}
+
+	return database.AddTreatmentPlanCycleParams{
+		CycleNumber:     int32(req.CycleNumber),
+		Status:          status,
+		RecordedOn:      recordedOn,
+		DelayDays:       int32(req.DelayDays),
+		DosePercent:     req.DosePercent,
+		Reason:          req.Reason,
+		ToxicityGradeID: toxicityGradeID,
+		TestID:          testID,
+		LabResult:       req.LabResult,
+		Notes:           req.Notes,
+	}, nil
+}
+
+func getTreatmentPlan(c *config.Config, ctx context.Context, id uuid.UUID) (database.TreatmentPlan, error) {
+	plan, err := c.Db.GetTreatmentPlanByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return plan, api.WrapError(http.StatusNotFound, fmt.Sprintf("treatment plan: %s not found", id.String()), err)
+	}
+	if err != nil {
+		return plan, fmt.Errorf("error getting treatment plan: %s, with error: %v", id.String(), err)
+	}
+	return plan, nil
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
+
+// baselineOrEmpty keeps the baseline parameters as a JSON object.
+func baselineOrEmpty(raw json.RawMessage) (json.RawMessage, error) {
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" || trimmed == "null" {
+		return json.RawMessage("{}"), nil
+	}
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil, fmt.Errorf("baseline must be a JSON object")
+	}
+	return raw, nil
+}
+
+func nullableID(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
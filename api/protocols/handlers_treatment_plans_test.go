@@ -0,0 +1,173 @@
+package protocols
+
+import (
+	"bcca_crawler/dosing"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func TestPlanCycleParams(t *testing.T) {
+	grade := uuid.NewString()
+	test := uuid.NewString()
+	tests := []struct {
+		name    string
+		req     PlanCycleReq
+		wantErr string
+	}{
+		{"given", PlanCycleReq{CycleNumber: 1, Status: "Given", RecordedOn: "2026-03-02"}, ""},
+		{"given late", PlanCycleReq{CycleNumber: 1, Status: "given", RecordedOn: "2026-03-02", DelayDays: 7}, "record it as delayed"},
+		{"delayed by a toxicity", PlanCycleReq{CycleNumber: 2, Status: "delayed", RecordedOn: "2026-03-23", DelayDays: 7, ToxicityGradeID: grade}, ""},
+		{"delayed by a lab result", PlanCycleReq{CycleNumber: 2, Status: "delayed", RecordedOn: "2026-03-23", DelayDays: 7, TestID: test, LabResult: "ANC 0.8"}, ""},
+		{"delayed without days", PlanCycleReq{CycleNumber: 2, Status: "delayed", RecordedOn: "2026-03-23", ToxicityGradeID: grade}, "needs delay_days"},
+		{"delayed for words only", PlanCycleReq{CycleNumber: 2, Status: "delayed", RecordedOn: "2026-03-23", DelayDays: 7, Reason: "neutropenia"}, "toxicity_grade_id or test_id"},
+		{"dose reduced", PlanCycleReq{CycleNumber: 3, Status: "dose_reduced", RecordedOn: "2026-04-20", DosePercent: 80, ToxicityGradeID: grade}, ""},
+		{"dose reduced at full dose", PlanCycleReq{CycleNumber: 3, Status: "dose_reduced", RecordedOn: "2026-04-20", ToxicityGradeID: grade}, "below 100"},
+		{"dose reduced without a link", PlanCycleReq{CycleNumber: 3, Status: "dose_reduced", RecordedOn: "2026-04-20", DosePercent: 80}, "toxicity_grade_id or test_id"},
+		{"lab result without its test", PlanCycleReq{CycleNumber: 3, Status: "dose_reduced", RecordedOn: "2026-04-20", DosePercent: 80, ToxicityGradeID: grade, LabResult: "ANC 0.8"}, "needs the test_id"},
+		{"bad date", PlanCycleReq{CycleNumber: 1, Status: "given", RecordedOn: "02/03/2026"}, "not a valid date"},
+		{"unknown status", PlanCycleReq{CycleNumber: 1, Status: "skipped", RecordedOn: "2026-03-02"}, "unknown cycle status"},
+	}
+	for _, tt := range tests {
+		params, err := planCycleParams(tt.req)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if string(params.Status) != strings.ToLower(tt.req.Status) || params.ToxicityGradeID.Valid != (tt.req.ToxicityGradeID != "") || params.TestID.Valid != (tt.req.TestID != "") {
+			t.Errorf("%s: params = %+v", tt.name, params)
+		}
+	}
+
+	params, _ := planCycleParams(PlanCycleReq{CycleNumber: 1, Status: "given", RecordedOn: "2026-03-02"})
+	if params.DosePercent != 100 || params.RecordedOn.Format("2006-01-02") != "2026-03-02" {
+		t.Errorf("given cycle params = %+v", params)
+	}
+}
+
+func TestPlanProgress(t *testing.T) {
+	day := func(d string) time.Time {
+		date, _ := time.Parse("2006-01-02", d)
+		return date
+	}
+	plan := database.TreatmentPlan{ID: uuid.New(), StartDate: day("2026-03-02"), PlannedCycles: 4, Status: database.PlanStatusEnumActive, Baseline: []byte("{}")}
+	templates := []dosing.CycleTemplate{{Label: "Cycle 1", Duration: "21 days"}, {Label: "Cycles 2-4", Duration: "4 weeks"}}
+	cycle := func(n int32, status database.PlanCycleStatusEnum, on string, delay int32, dose float64) database.GetTreatmentPlanCyclesRow {
+		return database.GetTreatmentPlanCyclesRow{ID: uuid.New(), CycleNumber: n, Status: status, RecordedOn: day(on), DelayDays: delay, DosePercent: dose}
+	}
+
+	p := planProgress(plan, nil, templates)
+	if p.NextCycle != 1 || p.NextDueDate != "2026-03-02" || p.CyclesRemaining != 4 || p.CurrentDosePercent != 100 {
+		t.Errorf("new plan progress = %+v", p)
+	}
+
+	history := []database.GetTreatmentPlanCyclesRow{
+		cycle(1, database.PlanCycleStatusEnumGiven, "2026-03-02", 0, 100),
+		cycle(2, database.PlanCycleStatusEnumDelayed, "2026-03-23", 7, 100),
+		cycle(2, database.PlanCycleStatusEnumDoseReduced, "2026-03-30", 0, 80),
+		cycle(3, database.PlanCycleStatusEnumDelayed, "2026-04-27", 3, 80),
+	}
+	p = planProgress(plan, history, templates)
+	if p.CyclesCompleted != 2 || p.CyclesRemaining != 2 || p.PercentComplete != 50 {
+		t.Errorf("completed %d, remaining %d, %.1f%%", p.CyclesCompleted, p.CyclesRemaining, p.PercentComplete)
+	}
+	if p.Delays != 2 || p.TotalDelayDays != 10 || p.DoseReductions != 1 || p.CurrentDosePercent != 80 {
+		t.Errorf("delays %d (%d days), reductions %d, dose %.0f%%", p.Delays, p.TotalDelayDays, p.DoseReductions, p.CurrentDosePercent)
+	}
+	// cycle 2 lasts four weeks from the day it was given, then cycle 3 is
+	// held for three days
+	if p.NextCycle != 3 || p.NextDueDate != "2026-04-30" || len(p.History) != 4 {
+		t.Errorf("next cycle %d due %s, %d history rows", p.NextCycle, p.NextDueDate, len(p.History))
+	}
+
+	history = append(history,
+		cycle(3, database.PlanCycleStatusEnumGiven, "2026-04-30", 0, 80),
+		cycle(4, database.PlanCycleStatusEnumGiven, "2026-05-28", 0, 80),
+	)
+	p = planProgress(plan, history, templates)
+	if p.CyclesCompleted != 4 || p.PercentComplete != 100 || p.NextCycle != 0 || p.NextDueDate != "" {
+		t.Errorf("finished plan progress = %+v", p)
+	}
+
+	plan.Status = database.PlanStatusEnumDiscontinued
+	if p := planProgress(plan, history[:1], templates); p.NextCycle != 0 || p.CyclesRemaining != 3 {
+		t.Errorf("discontinued plan progress = %+v", p)
+	}
+}
+
+// planDB answers CreateTreatmentPlan with the error or empty result a test
+// sets, standing in for Postgres.
+type planDB struct{ err error }
+
+func (db *planDB) Open(name string) (driver.Conn, error) { return db, nil }
+func (db *planDB) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+func (db *planDB) Close() error { return nil }
+func (db *planDB) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+func (db *planDB) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if db.err != nil {
+		return nil, db.err
+	}
+	return noRows{}, nil
+}
+
+type noRows struct{}
+
+func (noRows) Columns() []string              { return make([]string, 15) }
+func (noRows) Close() error                   { return nil }
+func (noRows) Next(dest []driver.Value) error { return io.EOF }
+
+func TestCreateTreatmentPlanStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		body string
+		want int
+	}{
+		{"unknown protocol", nil, "", http.StatusNotFound},
+		{"protocol deleted meanwhile", &pq.Error{Code: "23503"}, "", http.StatusNotFound},
+		{"database down", errors.New("connection refused"), "", http.StatusInternalServerError},
+		{"bad baseline", nil, `,"baseline":[1]`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		db := &planDB{err: tt.err}
+		name := fmt.Sprintf("plans-%p", db)
+		sql.Register(name, db)
+		conn, err := sql.Open(name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := &config.Config{Database: conn, Db: database.New(conn), Validate: validator.New()}
+
+		body := fmt.Sprintf(`{"patient_ref":"p-1","protocol_id":%q,"start_date":"2026-03-02","planned_cycles":4%s}`, uuid.NewString(), tt.body)
+		w := httptest.NewRecorder()
+		HandleCreateTreatmentPlan(c, w, httptest.NewRequest(http.MethodPost, "/api/v1/treatment_plans", strings.NewReader(body)))
+		if w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, w.Code, tt.want, w.Body.String())
+		}
+		conn.Close()
+	}
+}
@@ -2,7 +2,12 @@ package protocols
 
 import (
 	"bcca_crawler/api"
+	"bcca_crawler/calendar"
+	"bcca_crawler/eligibility"
 	"bcca_crawler/internal/database"
+	"fmt"
+
+	"github.com/google/uuid"
 )
 
 //Cautions
@@ -70,6 +75,38 @@ func MapEligibilityWithProtocols[T any](r T) (EligibilityCriterionResp, error) {
 	return return_item, nil
 }
 
+func MapEligibilityRule(src database.EligibilityRule) EligibilityRuleResp {
+	return EligibilityRuleResp{
+		CriteriaID: src.CriteriaID,
+		CreatedAt:  src.CreatedAt,
+		UpdatedAt:  src.UpdatedAt,
+		Expression: src.Expression,
+		Author:     string(src.Author),
+		Fields:     ruleFields(src.Expression),
+	}
+}
+
+func MapEligibilityRuleWithCriterion(src database.GetEligibilityRulesRow) EligibilityRuleResp {
+	return EligibilityRuleResp{
+		CriteriaID:  src.CriteriaID,
+		CreatedAt:   src.CreatedAt,
+		UpdatedAt:   src.UpdatedAt,
+		Expression:  src.Expression,
+		Author:      string(src.Author),
+		Type:        string(src.Type),
+		Description: src.Description,
+		Fields:      ruleFields(src.Expression),
+	}
+}
+
+func ruleFields(expression string) []string {
+	rule, err := eligibility.Parse(expression)
+	if err != nil {
+		return []string{}
+	}
+	return rule.Fields()
+}
+
 //Precaution
 
 func mapToPrecautionResponse[T any](row T) PrecautionLike {
@@ -106,13 +143,14 @@ func MapPrecautionWithProtocols[T any](r T) (PrecautionResp, error) {
 
 func MapMedication(src database.Medication) MedicationResp {
 	return MedicationResp{
-		ID:             src.ID,
-		Name:           src.Name,
-		CreatedAt:      src.CreatedAt,
-		UpdatedAt:      src.UpdatedAt,
-		Description:    src.Description,
-		Category:       src.Category,
-		AlternateNames: src.AlternateNames,
+		ID:              src.ID,
+		Name:            src.Name,
+		CreatedAt:       src.CreatedAt,
+		UpdatedAt:       src.UpdatedAt,
+		Description:     src.Description,
+		Category:        src.Category,
+		AlternateNames:  src.AlternateNames,
+		EmetogenicLevel: string(src.EmetogenicLevel),
 	}
 }
 
@@ -191,3 +229,173 @@ func MapTestGroup(src database.ProtocolTest) ProtocolTestGroup {
 		Position:   src.Position,
 	}
 }
+
+//Dose limits
+
+func mapToDoseLimitLike[T any](row T) DoseLimitLike {
+	switch r := any(row).(type) {
+	case database.GetMedicationDoseLimitsRow:
+		return DoseLimitLike(r)
+	case database.GetMedicationDoseLimitByIDRow:
+		return DoseLimitLike(r)
+	default:
+		panic("unsupported row type")
+	}
+}
+
+func MapDoseLimit[T any](r T) DoseLimitResp {
+	src := mapToDoseLimitLike(r)
+	var classID *uuid.UUID
+	if src.EquivalenceClassID.Valid {
+		classID = &src.EquivalenceClassID.UUID
+	}
+	return DoseLimitResp{
+		ID:                 src.ID,
+		CreatedAt:          src.CreatedAt,
+		UpdatedAt:          src.UpdatedAt,
+		MedicationID:       src.MedicationID,
+		MedicationName:     src.MedicationName,
+		MaxCumulativeDose:  src.MaxCumulativeDose,
+		DoseBasis:          string(src.DoseBasis),
+		WarningThreshold:   src.WarningThreshold,
+		EquivalenceClassID: classID,
+		EquivalenceFactor:  src.EquivalenceFactor,
+		Notes:              src.Notes,
+	}
+}
+
+func MapDoseClass(src database.DoseEquivalenceClass) DoseClassResp {
+	return DoseClassResp{
+		ID:                src.ID,
+		CreatedAt:         src.CreatedAt,
+		UpdatedAt:         src.UpdatedAt,
+		Name:              src.Name,
+		ReferenceDrug:     src.ReferenceDrug,
+		MaxCumulativeDose: src.MaxCumulativeDose,
+		DoseBasis:         string(src.DoseBasis),
+		WarningThreshold:  src.WarningThreshold,
+		Notes:             src.Notes,
+	}
+}
+
+//Treatment plans
+
+func MapTreatmentPlan(src database.TreatmentPlan) TreatmentPlanResp {
+	return TreatmentPlanResp{
+		ID:                src.ID,
+		CreatedAt:         src.CreatedAt,
+		UpdatedAt:         src.UpdatedAt,
+		PatientRef:        src.PatientRef,
+		ProtocolID:        src.ProtocolID,
+		ProtocolCode:      src.ProtocolCode,
+		ProtocolRevisedOn: src.ProtocolRevisedOn,
+		StartDate:         src.StartDate.Format(calendar.DateLayout),
+		PlannedCycles:     src.PlannedCycles,
+		Status:            string(src.Status),
+		HeightCm:          src.HeightCm,
+		WeightKg:          src.WeightKg,
+		BSA:               src.Bsa,
+		Baseline:          src.Baseline,
+		Notes:             src.Notes,
+	}
+}
+
+func MapPlanCycle(src database.GetTreatmentPlanCyclesRow) PlanCycleResp {
+	return PlanCycleResp{
+		ID:              src.ID,
+		CreatedAt:       src.CreatedAt,
+		UpdatedAt:       src.UpdatedAt,
+		CycleNumber:     src.CycleNumber,
+		Status:          string(src.Status),
+		RecordedOn:      src.RecordedOn.Format(calendar.DateLayout),
+		DelayDays:       src.DelayDays,
+		DosePercent:     src.DosePercent,
+		Reason:          src.Reason,
+		ToxicityGradeID: nullableID(src.ToxicityGradeID),
+		ToxicityTitle:   src.ToxicityTitle,
+		ToxicityGrade:   src.ToxicityGrade,
+		TestID:          nullableID(src.TestID),
+		TestName:        src.TestName,
+		LabResult:       src.LabResult,
+		Notes:           src.Notes,
+	}
+}
+
+//Interactions
+
+func MapInteraction(src database.Interaction) InteractionResp {
+	return InteractionResp{
+		ID:          src.ID,
+		CreatedAt:   src.CreatedAt,
+		UpdatedAt:   src.UpdatedAt,
+		DrugA:       src.DrugA,
+		DrugB:       src.DrugB,
+		Severity:    string(src.Severity),
+		Description: src.Description,
+		Management:  src.Management,
+		Source:      src.Source,
+	}
+}
+
+//Documents
+
+func MapProtocolDocument(src database.ProtocolDocument) ProtocolDocumentResp {
+	resp := ProtocolDocumentResp{
+		ID:          src.ID,
+		CreatedAt:   src.CreatedAt,
+		UpdatedAt:   src.UpdatedAt,
+		ProtocolID:  src.ProtocolID,
+		Kind:        string(src.Kind),
+		Title:       src.Title,
+		Url:         src.Url,
+		ContentType: src.ContentType,
+		SizeBytes:   src.SizeBytes,
+	}
+	if src.DocumentHash.Valid {
+		resp.DocumentHash = src.DocumentHash.String
+		resp.ArchiveUrl = documentURL(src.DocumentHash.String)
+	} else if src.ArchivePath != "" {
+		resp.ArchiveUrl = fmt.Sprintf("/api/v1/protocols/%s/documents/%s", src.ProtocolID, src.ID)
+	}
+	if src.FetchedAt.Valid {
+		resp.FetchedAt = &src.FetchedAt.Time
+	}
+	return resp
+}
+
+func MapProtocolSource(src database.GetProtocolSourcesRow) ProtocolSourceResp {
+	return ProtocolSourceResp{
+		ID:           src.ID,
+		CreatedAt:    src.CreatedAt,
+		ProtocolID:   src.ProtocolID,
+		RevisedOn:    src.RevisedOn,
+		DocumentHash: src.DocumentHash,
+		DocumentUrl:  documentURL(src.DocumentHash),
+		SourceUrl:    src.Url,
+		ContentType:  src.ContentType,
+		SizeBytes:    src.SizeBytes,
+		FetchedAt:    src.FetchedAt,
+	}
+}
+
+func documentURL(hash string) string {
+	return fmt.Sprintf("/api/v1/documents/%s", hash)
+}
+
+//Inventory
+
+func MapProtocolListing(src database.ProtocolListing) ProtocolListingResp {
+	resp := ProtocolListingResp{
+		Code:        src.Code,
+		ProtocolID:  nullableID(src.ProtocolID),
+		TumorGroup:  src.TumorGroup,
+		Description: src.Description,
+		PageUrl:     src.PageUrl,
+		FirstSeenAt: src.FirstSeenAt,
+		LastSeenAt:  src.LastSeenAt,
+	}
+	if src.RetiredAt.Valid {
+		resp.RetiredAt = &src.RetiredAt.Time
+	}
+	return resp
+}
@@ -2,6 +2,9 @@ package protocols
 
 import (
 	"bcca_crawler/api"
+	"bcca_crawler/eligibility"
+	"bcca_crawler/emetogenic"
+	"bcca_crawler/interactions"
 	"bcca_crawler/internal/database"
 	"encoding/json"
 	"strings"
@@ -73,6 +76,42 @@ func (e *EligibilityCriterionReq) ToTypeEnum() database.EligibilityEnum {
 	return database.EligibilityEnum(strings.ToLower(e.Type))
 }
 
+type EligibilityRuleReq struct {
+	Expression string `json:"expression" validate:"required,max=2000"`
+	Author     string `json:"author" validate:"omitempty,oneof=ai editor"`
+}
+
+type EligibilityRuleResp struct {
+	CriteriaID  uuid.UUID `json:"criteria_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Expression  string    `json:"expression"`
+	Author      string    `json:"author"`
+	Type        string    `json:"type,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Fields      []string  `json:"fields"`
+}
+
+// EligibilityScreenReq is the patient profile screened against every
+// protocol's criteria. Omitted values are treated as unknown.
+type EligibilityScreenReq struct {
+	Age            *float64           `json:"age" validate:"omitempty,min=0,max=120"`
+	ECOG           *float64           `json:"ecog" validate:"omitempty,min=0,max=5"`
+	Sex            string             `json:"sex" validate:"omitempty,max=50"`
+	TumorGroup     string             `json:"tumor_group" validate:"omitempty,max=100"`
+	Diagnosis      string             `json:"diagnosis" validate:"omitempty,max=250"`
+	Labs           map[string]float64 `json:"labs"`
+	PriorTherapies []string           `json:"prior_therapies"`
+	Flags          map[string]bool    `json:"flags"`
+	// only screen protocols of the patient's tumor group
+	RestrictToTumorGroup bool `json:"restrict_to_tumor_group"`
+}
+
+type EligibilityScreenResp struct {
+	Counts    map[string]int               `json:"counts"`
+	Protocols []eligibility.ProtocolResult `json:"protocols"`
+}
+
 //Precautions
 
 type PrecautionReq struct {
@@ -103,21 +142,23 @@ type PrecautionLike struct {
 //Medications
 
 type MedicationResp struct {
-	ID             uuid.UUID `json:"id"`
-	Name           string    `json:"name"`
-	Description    string    `json:"description"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	Category       string    `json:"category"`
-	AlternateNames []string  `json:"alternate_names"`
+	ID              uuid.UUID `json:"id"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Category        string    `json:"category"`
+	AlternateNames  []string  `json:"alternate_names"`
+	EmetogenicLevel string    `json:"emetogenic_level"`
 }
 
 type MedReq struct {
-	ID             string   `json:"id" validate:"omitempty,uuid"`
-	Name           string   `json:"name" validate:"required,min=1,max=250"`
-	Description    string   `json:"description" validate:"omitempty,min=1,max=500"`
-	Category       string   `json:"category" validate:"omitempty,min=1,max=50"`
-	AlternateNames []string `json:"alternate_names" validate:"omitempty,min=1,max=500"`
+	ID              string   `json:"id" validate:"omitempty,uuid"`
+	Name            string   `json:"name" validate:"required,min=1,max=250"`
+	Description     string   `json:"description" validate:"omitempty,min=1,max=500"`
+	Category        string   `json:"category" validate:"omitempty,min=1,max=50"`
+	AlternateNames  []string `json:"alternate_names" validate:"omitempty,min=1,max=500"`
+	EmetogenicLevel string   `json:"emetogenic_level" validate:"omitempty,emetogenic_level"`
 }
 
 type PrescriptionReq struct {
@@ -307,3 +348,269 @@ type MedicationWithModifications struct {
 	MedicationName string     `json:"medication_name"`
 	Categories     []Category `json:"categories"`
 }
+
+// Dose limits
+
+type DoseLimitReq struct {
+	MedicationID       string  `json:"medication_id" validate:"required,uuid"`
+	MaxCumulativeDose  float64 `json:"max_cumulative_dose" validate:"min=0"`
+	DoseBasis          string  `json:"dose_basis" validate:"required,dose_basis"`
+	WarningThreshold   float64 `json:"warning_threshold" validate:"omitempty,gt=0,lte=1"`
+	EquivalenceClassID string  `json:"equivalence_class_id" validate:"omitempty,uuid"`
+	EquivalenceFactor  float64 `json:"equivalence_factor" validate:"omitempty,gt=0"`
+	Notes              string  `json:"notes" validate:"omitempty,max=1000"`
+}
+
+type DoseLimitResp struct {
+	ID                 uuid.UUID  `json:"id"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	MedicationID       uuid.UUID  `json:"medication_id"`
+	MedicationName     string     `json:"medication_name"`
+	MaxCumulativeDose  float64    `json:"max_cumulative_dose"`
+	DoseBasis          string     `json:"dose_basis"`
+	WarningThreshold   float64    `json:"warning_threshold"`
+	EquivalenceClassID *uuid.UUID `json:"equivalence_class_id"`
+	EquivalenceFactor  float64    `json:"equivalence_factor"`
+	Notes              string     `json:"notes"`
+}
+
+type DoseLimitLike struct {
+	ID                       uuid.UUID
+	CreatedAt                time.Time
+	UpdatedAt                time.Time
+	MedicationID             uuid.UUID
+	MedicationName           string
+	MedicationAlternateNames []string
+	MaxCumulativeDose        float64
+	DoseBasis                database.DoseBasisEnum
+	WarningThreshold         float64
+	EquivalenceClassID       uuid.NullUUID
+	EquivalenceFactor        float64
+	Notes                    string
+}
+
+type DoseClassReq struct {
+	ID                string  `json:"id" validate:"omitempty,uuid"`
+	Name              string  `json:"name" validate:"required,min=1,max=100"`
+	ReferenceDrug     string  `json:"reference_drug" validate:"omitempty,max=250"`
+	MaxCumulativeDose float64 `json:"max_cumulative_dose" validate:"min=0"`
+	DoseBasis         string  `json:"dose_basis" validate:"required,dose_basis"`
+	WarningThreshold  float64 `json:"warning_threshold" validate:"omitempty,gt=0,lte=1"`
+	Notes             string  `json:"notes" validate:"omitempty,max=1000"`
+}
+
+type DoseClassResp struct {
+	ID                uuid.UUID `json:"id"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	Name              string    `json:"name"`
+	ReferenceDrug     string    `json:"reference_drug"`
+	MaxCumulativeDose float64   `json:"max_cumulative_dose"`
+	DoseBasis         string    `json:"dose_basis"`
+	WarningThreshold  float64   `json:"warning_threshold"`
+	Notes             string    `json:"notes"`
+}
+
+type PriorExposureReq struct {
+	MedicationID   string  `json:"medication_id" validate:"omitempty,uuid"`
+	MedicationName string  `json:"medication_name" validate:"required_without=MedicationID"`
+	Amount         float64 `json:"amount" validate:"gt=0"`
+	DoseBasis      string  `json:"dose_basis" validate:"required,dose_basis"`
+}
+
+type CumulativeDoseReq struct {
+	PlannedCycles int                `json:"planned_cycles" validate:"required,min=1,max=100"`
+	BSA           float64            `json:"bsa" validate:"omitempty,gt=0,lt=5"`
+	WeightKg      float64            `json:"weight_kg" validate:"omitempty,gt=0,lt=500"`
+	DosePercent   float64            `json:"dose_percent" validate:"omitempty,gt=0,lte=200"`
+	PriorExposure []PriorExposureReq `json:"prior_exposure" validate:"omitempty,dive"`
+}
+
+// Treatment plans
+
+type TreatmentPlanReq struct {
+	PatientRef    string          `json:"patient_ref" validate:"required,min=1,max=128"`
+	ProtocolID    string          `json:"protocol_id" validate:"required,uuid"`
+	StartDate     string          `json:"start_date" validate:"required,datetime=2006-01-02"`
+	PlannedCycles int             `json:"planned_cycles" validate:"required,min=1,max=100"`
+	HeightCm      float64         `json:"height_cm" validate:"omitempty,gt=0,lt=300"`
+	WeightKg      float64         `json:"weight_kg" validate:"omitempty,gt=0,lt=500"`
+	BSA           float64         `json:"bsa" validate:"omitempty,gt=0,lt=5"`
+	Baseline      json.RawMessage `json:"baseline"`
+	Notes         string          `json:"notes" validate:"omitempty,max=2000"`
+}
+
+type TreatmentPlanUpdateReq struct {
+	Status        string          `json:"status" validate:"omitempty,plan_status"`
+	PlannedCycles int             `json:"planned_cycles" validate:"omitempty,min=1,max=100"`
+	HeightCm      float64         `json:"height_cm" validate:"omitempty,gt=0,lt=300"`
+	WeightKg      float64         `json:"weight_kg" validate:"omitempty,gt=0,lt=500"`
+	BSA           float64         `json:"bsa" validate:"omitempty,gt=0,lt=5"`
+	Baseline      json.RawMessage `json:"baseline"`
+	Notes         *string         `json:"notes" validate:"omitempty,max=2000"`
+}
+
+type TreatmentPlanResp struct {
+	ID                uuid.UUID       `json:"id"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+	PatientRef        string          `json:"patient_ref"`
+	ProtocolID        uuid.UUID       `json:"protocol_id"`
+	ProtocolCode      string          `json:"protocol_code"`
+	ProtocolRevisedOn string          `json:"protocol_revised_on"`
+	StartDate         string          `json:"start_date"`
+	PlannedCycles     int32           `json:"planned_cycles"`
+	Status            string          `json:"status"`
+	HeightCm          float64         `json:"height_cm"`
+	WeightKg          float64         `json:"weight_kg"`
+	BSA               float64         `json:"bsa"`
+	Baseline          json.RawMessage `json:"baseline"`
+	Notes             string          `json:"notes"`
+}
+
+type PlanCycleReq struct {
+	CycleNumber     int     `json:"cycle_number" validate:"required,min=1,max=100"`
+	Status          string  `json:"status" validate:"required,plan_cycle_status"`
+	RecordedOn      string  `json:"recorded_on" validate:"required,datetime=2006-01-02"`
+	DelayDays       int     `json:"delay_days" validate:"omitempty,min=0,max=365"`
+	DosePercent     float64 `json:"dose_percent" validate:"omitempty,gt=0,lte=100"`
+	Reason          string  `json:"reason" validate:"omitempty,max=1000"`
+	ToxicityGradeID string  `json:"toxicity_grade_id" validate:"omitempty,uuid"`
+	TestID          string  `json:"test_id" validate:"omitempty,uuid"`
+	LabResult       string  `json:"lab_result" validate:"omitempty,max=250"`
+	Notes           string  `json:"notes" validate:"omitempty,max=2000"`
+}
+
+type PlanCycleResp struct {
+	ID              uuid.UUID  `json:"id"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	CycleNumber     int32      `json:"cycle_number"`
+	Status          string     `json:"status"`
+	RecordedOn      string     `json:"recorded_on"`
+	DelayDays       int32      `json:"delay_days"`
+	DosePercent     float64    `json:"dose_percent"`
+	Reason          string     `json:"reason"`
+	ToxicityGradeID *uuid.UUID `json:"toxicity_grade_id"`
+	ToxicityTitle   string     `json:"toxicity_title"`
+	ToxicityGrade   string     `json:"toxicity_grade"`
+	TestID          *uuid.UUID `json:"test_id"`
+	TestName        string     `json:"test_name"`
+	LabResult       string     `json:"lab_result"`
+	Notes           string     `json:"notes"`
+}
+
+type TreatmentPlanProgress struct {
+	Plan               TreatmentPlanResp `json:"plan"`
+	CyclesCompleted    int               `json:"cycles_completed"`
+	CyclesRemaining    int               `json:"cycles_remaining"`
+	PercentComplete    float64           `json:"percent_complete"`
+	Delays             int               `json:"delays"`
+	TotalDelayDays     int               `json:"total_delay_days"`
+	DoseReductions     int               `json:"dose_reductions"`
+	CurrentDosePercent float64           `json:"current_dose_percent"`
+	NextCycle          int               `json:"next_cycle"`
+	NextDueDate        string            `json:"next_due_date"`
+	History            []PlanCycleResp   `json:"history"`
+}
+
+// Interactions
+
+type InteractionReq struct {
+	DrugA       string `json:"drug_a" validate:"required,min=1,max=250"`
+	DrugB       string `json:"drug_b" validate:"required,min=1,max=250,nefield=DrugA"`
+	Severity    string `json:"severity" validate:"required,interaction_severity"`
+	Description string `json:"description" validate:"omitempty,max=2000"`
+	Management  string `json:"management" validate:"omitempty,max=2000"`
+	Source      string `json:"source" validate:"omitempty,max=500"`
+}
+
+type InteractionResp struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	DrugA       string    `json:"drug_a"`
+	DrugB       string    `json:"drug_b"`
+	Severity    string    `json:"severity"`
+	Description string    `json:"description"`
+	Management  string    `json:"management"`
+	Source      string    `json:"source"`
+}
+
+type ProtocolInteractionsResp struct {
+	ProtocolID      uuid.UUID           `json:"protocol_id"`
+	Medications     []interactions.Drug `json:"medications"`
+	HomeMedications []interactions.Drug `json:"home_medications"`
+	Interactions    []interactions.Hit  `json:"interactions"`
+	Counts          map[string]int      `json:"counts"`
+}
+
+// Emetogenic risk
+
+type EmetogenicRiskResp struct {
+	ProtocolID   uuid.UUID             `json:"protocol_id"`
+	ProtocolCode string                `json:"protocol_code"`
+	Assessment   emetogenic.Assessment `json:"assessment"`
+	Premeds      []emetogenic.Agent    `json:"premeds"`
+	Audit        emetogenic.Audit      `json:"audit"`
+}
+
+type EmetogenicAuditResp struct {
+	Total     int                  `json:"total"`
+	Counts    map[string]int       `json:"counts"`
+	Protocols []EmetogenicRiskResp `json:"protocols"`
+}
+
+// Documents
+
+type ProtocolDocumentResp struct {
+	ID           uuid.UUID  `json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	ProtocolID   uuid.UUID  `json:"protocol_id"`
+	Kind         string     `json:"kind"`
+	Title        string     `json:"title"`
+	Url          string     `json:"url"`
+	DocumentHash string     `json:"document_hash"`
+	ArchiveUrl   string     `json:"archive_url"`
+	ContentType  string     `json:"content_type"`
+	SizeBytes    int64      `json:"size_bytes"`
+	FetchedAt    *time.Time `json:"fetched_at"`
+}
+
+type ProtocolSourceResp struct {
+	ID           uuid.UUID `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	ProtocolID   uuid.UUID `json:"protocol_id"`
+	RevisedOn    string    `json:"revised_on"`
+	DocumentHash string    `json:"document_hash"`
+	DocumentUrl  string    `json:"document_url"`
+	SourceUrl    string    `json:"source_url"`
+	ContentType  string    `json:"content_type"`
+	SizeBytes    int64     `json:"size_bytes"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// Inventory
+
+type ProtocolListingResp struct {
+	Code        string     `json:"code"`
+	ProtocolID  *uuid.UUID `json:"protocol_id"`
+	TumorGroup  string     `json:"tumor_group"`
+	Description string     `json:"description"`
+	PageUrl     string     `json:"page_url"`
+	FirstSeenAt time.Time  `json:"first_seen_at"`
+	LastSeenAt  time.Time  `json:"last_seen_at"`
+	RetiredAt   *time.Time `json:"retired_at"`
+}
+
+type InventoryResult struct {
+	Pages      int                   `json:"pages"`
+	Listed     int                   `json:"listed"`
+	Incomplete bool                  `json:"incomplete"`
+	Errors     []string              `json:"errors"`
+	Warnings   []string              `json:"warnings"`
+	Missing    []ProtocolListingResp `json:"missing"`
+	Retired    []ProtocolListingResp `json:"retired"`
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Source is where in the protocol PDF the model read an extracted item.
+type Source struct {
+	Page  int32  `json:"page" desc:"Page of the PDF file the item was read on, counting the first page of the file as 1, not the number printed on the page." schema:"required"`
+	Quote string `json:"quote" desc:"A short quote of the text the item was read from, copied verbatim from that page, at most 200 characters." schema:"required"`
+}
+
+// ProvenanceResp is the source of one item of a protocol summary. EntityID
+// is the id of the treatment, prescription, criterion or test; for a
+// toxicity adjustment it is the id of the toxicity grade.
+type ProvenanceResp struct {
+	EntityType   database.ProvenanceEntityEnum `json:"entity_type"`
+	EntityID     uuid.UUID                     `json:"entity_id"`
+	Page         *int32                        `json:"page"`
+	Quote        string                        `json:"quote"`
+	ExtractionID *uuid.UUID                    `json:"extraction_id"`
+	UpdatedAt    time.Time                     `json:"updated_at"`
+}
+
+func MapProvenance(src database.Provenance) ProvenanceResp {
+	resp := ProvenanceResp{
+		EntityType: src.EntityType,
+		EntityID:   src.EntityID,
+		Quote:      src.Quote,
+		UpdatedAt:  src.UpdatedAt,
+	}
+	if src.Page.Valid {
+		resp.Page = &src.Page.Int32
+	}
+	if src.ExtractionID.Valid {
+		resp.ExtractionID = &src.ExtractionID.UUID
+	}
+	return resp
+}
+
+func GetProtocolProvenance(c *config.Config, ctx context.Context, protocolID uuid.UUID) ([]ProvenanceResp, error) {
+	items, err := c.Db.GetProtocolProvenance(ctx, protocolID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting provenance: %s, with error: %v", protocolID.String(), err)
+	}
+	return MapAll(items, MapProvenance), nil
+}
+
+// includes reports whether the comma separated include query parameter
+// names an option.
+func includes(r *http.Request, option string) bool {
+	for _, name := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if strings.EqualFold(strings.TrimSpace(name), option) {
+			return true
+		}
+	}
+	return false
+}
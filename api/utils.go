@@ -1,11 +1,13 @@
 package api
 
 import (
+	"bcca_crawler/fetch"
 	"bcca_crawler/internal/config"
 	"bcca_crawler/internal/json_utils"
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -44,6 +46,17 @@ func WrapError(code int, msg string, err error) *HandlerError {
 	return &HandlerError{StatusCode: code, Message: msg, Err: err}
 }
 
+// RespondWithHandlerError answers with the status and message of a
+// HandlerError, and with a 500 for any other error.
+func RespondWithHandlerError(w http.ResponseWriter, err error) {
+	var handlerErr *HandlerError
+	if errors.As(err, &handlerErr) {
+		json_utils.RespondWithError(w, handlerErr.StatusCode, handlerErr.Message)
+		return
+	}
+	json_utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+}
+
 type IPApiResponse struct {
 	Query    string  `json:"query"`
 	Country  string  `json:"country"`
@@ -71,6 +84,7 @@ type MapperFunc[T any] func(dbResult any) (T, error)
 type UpsertFunc[Req any] func(cfg *config.Config, ctx context.Context, req Req, ids IDs) error
 type GetFunc[Res any] func(cfg *config.Config, ctx context.Context, ids IDs) (Res, error)
 type GetFuncWithQueries[Res any] func(cfg *config.Config, ctx context.Context, ids IDs, query url.Values) (Res, error)
+type PostFunc[Req any, Res any] func(cfg *config.Config, ctx context.Context, req Req, ids IDs) (Res, error)
 type ModifierFunc func(cfg *config.Config, ctx context.Context, ids IDs) (string, error)
 
 func ParseAndValidateID(r *http.Request) (IDs, error) {
@@ -156,7 +170,7 @@ func HandleUpsert[Req any](
 
 	err = upsertFn(c, r.Context(), req, ids)
 	if err != nil {
-		json_utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		RespondWithHandlerError(w, err)
 		return
 	}
 
@@ -177,7 +191,7 @@ func HandleGet[Res any](
 
 	res, err := getFn(c, r.Context(), ids)
 	if err != nil {
-		json_utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		RespondWithHandlerError(w, err)
 		return
 	}
 
@@ -200,7 +214,34 @@ func HandleGetWithQ[Res any](
 
 	res, err := getFn(c, r.Context(), ids,query)
 	if err != nil {
-		json_utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		RespondWithHandlerError(w, err)
+		return
+	}
+
+	json_utils.RespondWithJSON(w, http.StatusOK, res)
+}
+
+func HandlePost[Req any, Res any](
+	c *config.Config,
+	w http.ResponseWriter,
+	r *http.Request,
+	postFn PostFunc[Req, Res],
+) {
+	var req Req
+	ids, err := ParseAndValidateID(r)
+	if err != nil {
+		json_utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := UnmarshalAndValidatePayload(c, r, &req); err != nil {
+		json_utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := postFn(c, r.Context(), req, ids)
+	if err != nil {
+		RespondWithHandlerError(w, err)
 		return
 	}
 
@@ -222,7 +263,7 @@ func HandleModify(
 	msg, err := modifierFn(c, r.Context(), ids)
 	if err != nil {
 		fmt.Printf("Handle Modify Error with Request: %v\n", r.URL)
-		json_utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		RespondWithHandlerError(w, err)
 		return
 	}
 
@@ -297,22 +338,12 @@ func GetIPGeoLocation(ip string) (IPApiResponse, error) {
 	// Make a request to ip-api.com
 	rawURL := fmt.Sprintf("http://ip-api.com/json/%s", ip)
 
-	resp, err := http.Get(rawURL)
-
+	resp, err := fetch.Services.Get(context.Background(), rawURL)
 	if err != nil {
 		return ipApiResponse, err
 	}
-	if resp.StatusCode != http.StatusOK {
-		return ipApiResponse, err
-	}
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return ipApiResponse, fmt.Errorf("no body in request")
-	}
-
-	defer resp.Body.Close()
 
-	err = json.Unmarshal(body, &ipApiResponse)
+	err = json.Unmarshal(resp.Body, &ipApiResponse)
 	if err != nil {
 		return ipApiResponse, fmt.Errorf("invalid request body")
 	}
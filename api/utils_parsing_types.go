@@ -35,18 +35,19 @@ type ProtocolSumPayload struct {
 	TreatmentModifications      []MedicationWithModifications      `json:"treatment_modifications"`
 	Physicians                  []Physician                        `json:"physicians"`
 	ArticleReferences           []ArticleReference                 `json:"article_references"`
+	Provenance                  []ProvenanceResp                   `json:"provenance,omitempty"`
 }
 
 type ArticleReference struct {
-	ID        uuid.UUID `json:"id"`
-	Title     string    `json:"title"`
-	Authors   string    `json:"authors"`
-	Journal   string    `json:"journal"`
-	Year      string    `json:"year"`
-	Pmid      string    `json:"pmid"`
-	Doi       string    `json:"doi"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uuid.UUID `json:"id" schema:"-"`
+	Title     string    `json:"title" desc:"The title of the scientific article." schema:"required"`
+	Authors   string    `json:"authors" desc:"The authors of the article (e.g., 'Doe J, Smith A')." schema:"required"`
+	Journal   string    `json:"journal" desc:"The journal where the article was published." schema:"required"`
+	Year      string    `json:"year" desc:"The publication year (e.g., '2023')." schema:"required"`
+	Pmid      string    `json:"pmid" desc:"PubMed ID, if available."`
+	Doi       string    `json:"doi" desc:"Digital Object Identifier, if available."`
+	CreatedAt time.Time `json:"created_at" schema:"-"`
+	UpdatedAt time.Time `json:"updated_at" schema:"-"`
 }
 
 type ProtocolMedications struct {
@@ -79,49 +80,51 @@ type LinkedProtocols struct {
 }
 
 type Physician struct {
-	ID        uuid.UUID `json:"id"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uuid.UUID `json:"id" schema:"-"`
+	FirstName string    `json:"first_name" desc:"The physician's first name." schema:"required"`
+	LastName  string    `json:"last_name" desc:"The physician's last name." schema:"required"`
+	CreatedAt time.Time `json:"created_at" schema:"-"`
+	UpdatedAt time.Time `json:"updated_at" schema:"-"`
 }
 
 type SummaryProtocol struct {
-	ID          uuid.UUID `json:"id"`
-	TumorGroup  string    `json:"tumor_group"`
-	Code        string    `json:"code"`
-	Name        string    `json:"name"`
-	Tags        []string  `json:"tags"`
-	Notes       string    `json:"notes"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	RevisedOn   string    `json:"revised_on"`
-	ActivatedOn string    `json:"activated_on"`
-	ProtocolUrl string    `json:"protocol_url"`
-	HandOutUrl  string    `json:"handout_url"`
+	ID          uuid.UUID `json:"id" schema:"-"`
+	TumorGroup  string    `json:"tumor_group" desc:"The tumor group associated with the protocol." schema:"required,enum=tumor_group"`
+	Code        string    `json:"code" desc:"The protocol's unique code." schema:"required"`
+	Name        string    `json:"name" desc:"The full name of the protocol." schema:"required"`
+	Tags        []string  `json:"tags" desc:"Tags associated with the protocol... e.g., 'B-Cell Lymphoma', 'Follicular Lymphoma', 'DLBCL', 'R-CHOP', 'Rituximab', 'Cyclophosphamide', 'Doxorubicin', 'Vincristine', 'Prednisone',etc." schema:"required"`
+	Notes       string    `json:"notes" desc:"Any additional notes or comments about the protocol."`
+	CreatedAt   time.Time `json:"created_at" schema:"-"`
+	UpdatedAt   time.Time `json:"updated_at" schema:"-"`
+	RevisedOn   string    `json:"revised_on" desc:"Date when the protocol was last revised, format: YYYY-MMM-DD (e.g., '2024-Feb-29')." schema:"required"`
+	ActivatedOn string    `json:"activated_on" desc:"Date when the protocol was activated, format: YYYY-MMM-DD (e.g., '2023-Jan-15')." schema:"required"`
+	ProtocolUrl string    `json:"protocol_url" desc:"URL to the protocol document or resource."`
+	HandOutUrl  string    `json:"handout_url" desc:"URL to the patient handout document or resource."`
 }
 
 type ProtocolEligibilityCriterion struct {
-	ID          uuid.UUID                `json:"id"`
-	Type        database.EligibilityEnum `json:"type"`
-	Description string                   `json:"description"`
-	CreatedAt   time.Time                `json:"created_at"`
-	UpdatedAt   time.Time                `json:"updated_at"`
+	ID          uuid.UUID                `json:"id" schema:"-"`
+	Type        database.EligibilityEnum `json:"type" desc:"Type of criterion: 'inclusion', 'exclusion', or 'unknown'. Each bullet point should be a separate object." schema:"required,enum=inclusion|exclusion|unknown"`
+	Description string                   `json:"description" desc:"The detailed description of the eligibility criterion." schema:"required"`
+	Rule        string                   `json:"rule,omitempty" desc:"Optional machine-evaluable form of the criterion, only when it can be expressed exactly. Fields: age, ecog, sex, tumor_group, diagnosis (text), lab.<name> (number, e.g. lab.anc, lab.platelets, lab.creatinine_clearance), prior.<therapy> and flag.<name> (booleans). Combine comparisons (==, !=, <, <=, >, >=, in [\"a\", \"b\"]) with and, or, not and parentheses. For an exclusion criterion, write the excluding condition. Examples: 'age >= 18', 'ecog <= 2 and lab.anc >= 1.5', 'prior.anthracycline'."`
+	CreatedAt   time.Time                `json:"created_at" schema:"-"`
+	UpdatedAt   time.Time                `json:"updated_at" schema:"-"`
+	Source      *Source                  `json:"source,omitempty" desc:"Where in the PDF this criterion was read." schema:"required"`
 }
 
 type ProtocolPrecaution struct {
-	ID          uuid.UUID `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uuid.UUID `json:"id" schema:"-"`
+	Title       string    `json:"title" desc:"A concise title for the precaution (e.g., 'Myelosuppression')." schema:"required"`
+	Description string    `json:"description" desc:"Detailed description of the precaution and management." schema:"required"`
+	CreatedAt   time.Time `json:"created_at" schema:"-"`
+	UpdatedAt   time.Time `json:"updated_at" schema:"-"`
 }
 
 type ProtocolCaution struct {
-	ID          uuid.UUID `json:"id"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uuid.UUID `json:"id" schema:"-"`
+	Description string    `json:"description" desc:"Detailed description of the caution." schema:"required"`
+	CreatedAt   time.Time `json:"created_at" schema:"-"`
+	UpdatedAt   time.Time `json:"updated_at" schema:"-"`
 }
 
 type LabsByProtocol struct {
@@ -130,9 +133,10 @@ type LabsByProtocol struct {
 }
 
 type LabSummary struct {
-	ID          uuid.UUID `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
+	ID          uuid.UUID `json:"id" schema:"-"`
+	Name        string    `json:"name" desc:"Name of the test (e.g., 'CBC', 'Creatinine','Electrolytes','Calcium','ALT','AST')." schema:"required"`
+	Description string    `json:"description" desc:"Brief description or purpose of the test." schema:"required"`
+	Source      *Source   `json:"source,omitempty" desc:"Where in the PDF this test was read." schema:"required"`
 }
 
 type MedicationModification struct {
@@ -157,23 +161,24 @@ type Modifications struct {
 }
 
 type ToxicityModification struct {
-	ID               uuid.UUID `json:"id"`
-	GradeID          uuid.UUID `json:"grade_id"`
-	Grade            string    `json:"grade"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
-	GradeDescription string    `json:"grade_description"`
-	Adjustment       string    `json:"adjustment"`
+	ID               uuid.UUID `json:"id" schema:"-"`
+	GradeID          uuid.UUID `json:"grade_id" schema:"-"`
+	Grade            string    `json:"grade" desc:"The CTCAE grade number (1, 2, 3, or 4)." schema:"required,enum=1|2|3|4"`
+	CreatedAt        time.Time `json:"created_at" schema:"-"`
+	UpdatedAt        time.Time `json:"updated_at" schema:"-"`
+	GradeDescription string    `json:"grade_description" desc:"Description of the grade using CTCAE v5 terminology." schema:"required"`
+	Adjustment       string    `json:"adjustment" desc:"Recommended adjustment (e.g., 'Dose reduction', 'Delay', 'Discontinuation'). Leave blank if no information."`
+	Source           *Source   `json:"source,omitempty" desc:"Where in the PDF the adjustment for this grade was read." schema:"required"`
 }
 
 type Toxicity struct {
-	ID            uuid.UUID              `json:"id"`
-	Title         string                 `json:"title"`
-	CreatedAt     time.Time              `json:"created_at"`
-	UpdatedAt     time.Time              `json:"updated_at"`
-	Description   string                 `json:"description"`
-	Category      string                 `json:"category"`
-	Modifications []ToxicityModification `json:"modifications"`
+	ID            uuid.UUID              `json:"id" schema:"-"`
+	Title         string                 `json:"title" desc:"Title of the toxicity (e.g., 'Neuropathy', 'Thrombopenia', 'Diarrhea')." schema:"required"`
+	CreatedAt     time.Time              `json:"created_at" schema:"-"`
+	UpdatedAt     time.Time              `json:"updated_at" schema:"-"`
+	Description   string                 `json:"description" desc:"Detailed description of the toxicity." schema:"required"`
+	Category      string                 `json:"category" desc:"Category of the toxicity (e.g., 'Hematologic', 'Neurologic', 'Gastrointestinal')." schema:"required,enum=toxicity_category"`
+	Modifications []ToxicityModification `json:"modifications" desc:"Array of modifications, one object for each grade (1, 2, 3 or 4) the protocol gives guidance for. Leave out the grades it does not mention." schema:"required,minItems=1,maxItems=4"`
 }
 
 type ToxicityGrade struct {
@@ -231,64 +236,66 @@ type MedicationWithModifications struct {
 }
 
 type Treatment struct {
-	MedicationID          uuid.UUID                      `json:"medication_id"`
-	MedicationName        string                         `json:"medication_name"`
-	MedicationDescription string                         `json:"medication_description"`
-	MedicationCategory    string                         `json:"medication_category"`
-	MedicationAlternates  []string                       `json:"medication_alternate_names"`
-	ID                    uuid.UUID                      `json:"id"`
-	Dose                  string                         `json:"dose"`
-	CreatedAt             time.Time                      `json:"created_at"`
-	UpdatedAt             time.Time                      `json:"updated_at"`
-	Route                 database.PrescriptionRouteEnum `json:"route"`
-	Frequency             string                         `json:"frequency"`
-	Duration              string                         `json:"duration"`
-	AdministrationGuide   string                         `json:"administration_guide"`
+	MedicationID          uuid.UUID                      `json:"medication_id" schema:"-"`
+	MedicationName        string                         `json:"medication_name" desc:"Name of the medication used in this treatment." schema:"required"`
+	MedicationDescription string                         `json:"medication_description" desc:"Description of the medication." schema:"required"`
+	MedicationCategory    string                         `json:"medication_category" desc:"Category of the medication (e.g., 'Antineoplastic')." schema:"required,enum=medication_category"`
+	MedicationAlternates  []string                       `json:"medication_alternate_names" desc:"Alternate names for the medication, if available."`
+	ID                    uuid.UUID                      `json:"id" schema:"-"`
+	Dose                  string                         `json:"dose" desc:"Dosage of the medication (e.g., '100 mg/m2')." schema:"required"`
+	CreatedAt             time.Time                      `json:"created_at" schema:"-"`
+	UpdatedAt             time.Time                      `json:"updated_at" schema:"-"`
+	Route                 database.PrescriptionRouteEnum `json:"route" desc:"Route of administration (e.g., 'iv', 'oral', 'sc', 'im', 'topical', 'inhalation', 'unknown')." schema:"required,enum=prescription_route"`
+	Frequency             string                         `json:"frequency" desc:"Frequency of administration (e.g., 'Day 1-2 ', 'Day 1, 8, 15 and 22', 'Day 1 to 14')." schema:"required"`
+	Duration              string                         `json:"duration" desc:"Duration of administration (e.g., 'every 28 days')." schema:"required"`
+	AdministrationGuide   string                         `json:"administration_guide" desc:"Specific administration guidelines." schema:"required"`
+	Source                *Source                        `json:"source,omitempty" desc:"Where in the PDF this treatment was read." schema:"required"`
 }
 
 type Prescription struct {
-	MedicationID          uuid.UUID                      `json:"medication_id"`
-	MedicationName        string                         `json:"medication_name"`
-	MedicationDescription string                         `json:"medication_description"`
-	MedicationCategory    string                         `json:"medication_category"`
-	MedicationAlternates  []string                       `json:"medication_alternate_names"`
-	ID                    uuid.UUID                      `json:"id"`
-	Dose                  string                         `json:"dose"`
-	CreatedAt             time.Time                      `json:"created_at"`
-	UpdatedAt             time.Time                      `json:"updated_at"`
-	Route                 database.PrescriptionRouteEnum `json:"route"`
-	Frequency             string                         `json:"frequency"`
-	Duration              string                         `json:"duration"`
-	Instructions          string                         `json:"instructions"`
-	Renewals              int32                          `json:"renewals"`
+	MedicationID          uuid.UUID                      `json:"medication_id" schema:"-"`
+	MedicationName        string                         `json:"medication_name" desc:"Name of the medication used in this prescription." schema:"required"`
+	MedicationDescription string                         `json:"medication_description" desc:"Description of the medication." schema:"required"`
+	MedicationCategory    string                         `json:"medication_category" desc:"Category of the medication (e.g., 'Antineoplastic')." schema:"required,enum=medication_category"`
+	MedicationAlternates  []string                       `json:"medication_alternate_names" desc:"Alternate names for the medication, commercial or otherwise."`
+	ID                    uuid.UUID                      `json:"id" schema:"-"`
+	Dose                  string                         `json:"dose" desc:"Dosage of the medication (e.g., '500mg')." schema:"required"`
+	CreatedAt             time.Time                      `json:"created_at" schema:"-"`
+	UpdatedAt             time.Time                      `json:"updated_at" schema:"-"`
+	Route                 database.PrescriptionRouteEnum `json:"route" desc:"Route of administration (e.g., 'iv', 'oral', 'sc', 'im', 'topical', 'inhalation', 'unknown')." schema:"required,enum=prescription_route"`
+	Frequency             string                         `json:"frequency" desc:"Frequency of administration (e.g., 'Start 3 days before chemotherapy and continue for 15 days', 'Start day 7 post-chemotherapy and continue daily for 7 days','30 minutes pre-chemotherapy',etc.)" schema:"required"`
+	Duration              string                         `json:"duration" desc:"Duration of administration (e.g., '7 doses every 21 days, 30 tabs, 120 tabs,etc.')." schema:"required"`
+	Instructions          string                         `json:"instructions" desc:"Specific instruction regarding medication use (e.g. 'use as necessary if bone pain associated with filgrastim', 'use 2 tabs after loose stools, and 1 tab after each loose stool afterward',etc.)." schema:"required"`
+	Renewals              int32                          `json:"renewals" desc:"Number of renewals, if unknown give an estimate based on number of cycles and known information." schema:"required"`
+	Source                *Source                        `json:"source,omitempty" desc:"Where in the PDF this prescription was read." schema:"required"`
 }
 
 type ProtocolCycle struct {
-	ID            uuid.UUID   `json:"id"`
-	CreatedAt     time.Time   `json:"created_at"`
-	UpdatedAt     time.Time   `json:"updated_at"`
-	Cycle         string      `json:"cycle"`
-	CycleDuration string      `json:"cycle_duration"`
-	Treatments    []Treatment `json:"treatments"`
+	ID            uuid.UUID   `json:"id" schema:"-"`
+	CreatedAt     time.Time   `json:"created_at" schema:"-"`
+	UpdatedAt     time.Time   `json:"updated_at" schema:"-"`
+	Cycle         string      `json:"cycle" desc:"The cycle number (e.g., 'Cycle 1'). If not specified in source, default to 'Cycle 1+'." schema:"required"`
+	CycleDuration string      `json:"cycle_duration" desc:"Duration of the cycle (e.g., '28 days'). If blank in source, default to '28 days'." schema:"required"`
+	Treatments    []Treatment `json:"treatments" desc:"List of treatments administered during this cycle." schema:"required"`
 }
 
 type TestGroup struct {
-	ID        uuid.UUID    `json:"id"`
-	CreatedAt time.Time    `json:"created_at"`
-	UpdatedAt time.Time    `json:"updated_at"`
-	Category  string       `json:"category"`
-	Comments  string       `json:"comments"`
-	Position  int32        `json:"position"`
-	Tests     []LabSummary `json:"tests"`
+	ID        uuid.UUID    `json:"id" schema:"-"`
+	CreatedAt time.Time    `json:"created_at" schema:"-"`
+	UpdatedAt time.Time    `json:"updated_at" schema:"-"`
+	Category  string       `json:"category" desc:"The category defining this grouping of tests in the structure of the protocol (e.g. Required Pre-treatment Tests, Day 1-4 Tests, Subsequent Pre-treatment Tests, If Clinicalled Indicated Tests, etc.)." schema:"required"`
+	Comments  string       `json:"comments" desc:"A brief description of the category if necessary to better understand.." schema:"required"`
+	Position  int32        `json:"position" desc:"The order that the test groups are displayed/organized on the front end. Should follow chronological order : '1, 2, 3, etc.'"`
+	Tests     []LabSummary `json:"tests" desc:"List of tests that are part of this category." schema:"required"`
 }
 
 type PrescriptionGroup struct {
-	ID            uuid.UUID      `json:"id"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	Category      string         `json:"category"`
-	Comments      string         `json:"comments"`
-	Prescriptions []Prescription `json:"prescriptions"`
+	ID            uuid.UUID      `json:"id" schema:"-"`
+	CreatedAt     time.Time      `json:"created_at" schema:"-"`
+	UpdatedAt     time.Time      `json:"updated_at" schema:"-"`
+	Category      string         `json:"category" desc:"The category defining this grouping of prescribed medications (NOT protocol treatments. This section is reserved for pre-medication, supportive medication etc.)" schema:"required"`
+	Comments      string         `json:"comments" desc:"A brief description of the category if necessary to better understand." schema:"required"`
+	Prescriptions []Prescription `json:"prescriptions" desc:"List of prescriptions that are part of this category." schema:"required"`
 }
 
 func ParsePostGRESData[T any](data any) ([]T, error) {
@@ -342,14 +349,30 @@ type ToxicityWithGradesLike struct {
 	Grades      json.RawMessage
 }
 
-func mapToTreatmentLike[T any](row T) Treatment {
+type TreatmentLike struct {
+	MedicationID          uuid.UUID
+	MedicationName        string
+	MedicationDescription string
+	MedicationCategory    string
+	MedicationAlternates  []string
+	ID                    uuid.UUID
+	Dose                  string
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	Route                 database.PrescriptionRouteEnum
+	Frequency             string
+	Duration              string
+	AdministrationGuide   string
+}
+
+func mapToTreatmentLike[T any](row T) TreatmentLike {
 	switch r := any(row).(type) {
 	case database.GetTreatmentsRow:
-		return Treatment(r)
+		return TreatmentLike(r)
 	case database.GetTreatmentsByCycleRow:
-		return Treatment(r)
+		return TreatmentLike(r)
 	case database.GetProtocolTreatmentByIDRow:
-		return Treatment(r)
+		return TreatmentLike(r)
 	default:
 		panic("unsupported row type")
 	}
@@ -8,25 +8,7 @@ import (
 )
 
 // Predefined list of valid tumor group codes
-var validTumorGroups = map[string]bool{
-	"lymphoma":         true,
-	"myeloma":          true,
-	"bmt":              true,
-	"leukemia":         true,
-	"breast":           true,
-	"gastrointestinal": true,
-	"genitourinary":    true,
-	"gynecology":       true,
-	"head_and_neck":    true,
-	"lung":             true,
-	"skin":             true,
-	"neuro-oncology":   true,
-	"sarcoma":          true,
-	"ocular":           true,
-	"unknown":          true,
-	"unknown_primary":  true,
-	// Add more as needed
-}
+var validTumorGroups = valueSet(TumorGroups)
 
 var validEligiblityCriteria = map[string]bool{
 	"inclusion": true,
@@ -54,14 +36,7 @@ var validProtocolPrescriptionCategory = map[string]bool{
 	"unknown": true,
 }
 
-var validPrescriptionRoutes = map[string]bool{
-	"oral":    true,
-	"iv":      true,
-	"im":      true,
-	"sc":      true,
-	"topical": true,
-	"unknown": true,
-}
+var validPrescriptionRoutes = valueSet(PrescriptionRoutes)
 
 var validGrades = map[string]bool{
 	"1":       true,
@@ -71,6 +46,44 @@ var validGrades = map[string]bool{
 	"unknown": true,
 }
 
+var validDoseBases = map[string]bool{
+	"mg_m2":    true,
+	"mg_kg":    true,
+	"mg":       true,
+	"units_m2": true,
+	"units":    true,
+	"unknown":  true,
+}
+
+var validPlanStatuses = map[string]bool{
+	"active":       true,
+	"on_hold":      true,
+	"completed":    true,
+	"discontinued": true,
+}
+
+var validPlanCycleStatuses = map[string]bool{
+	"given":        true,
+	"delayed":      true,
+	"dose_reduced": true,
+}
+
+var validInteractionSeverities = map[string]bool{
+	"contraindicated": true,
+	"major":           true,
+	"moderate":        true,
+	"minor":           true,
+	"unknown":         true,
+}
+
+var validEmetogenicLevels = map[string]bool{
+	"high":     true,
+	"moderate": true,
+	"low":      true,
+	"minimal":  true,
+	"unknown":  true,
+}
+
 var validPhysicianSites = map[string]bool{
 	"vancouver":     true,
 	"victoria":      true,
@@ -123,6 +136,31 @@ func PhysicianSiteValidator(fl validator.FieldLevel) bool {
 	return validPhysicianSites[physicianSite]
 }
 
+func DoseBasisValidator(fl validator.FieldLevel) bool {
+	doseBasis := strings.ToLower(fl.Field().String()) // Ensure case-insensitivity
+	return validDoseBases[doseBasis]
+}
+
+func PlanStatusValidator(fl validator.FieldLevel) bool {
+	planStatus := strings.ToLower(fl.Field().String()) // Ensure case-insensitivity
+	return validPlanStatuses[planStatus]
+}
+
+func PlanCycleStatusValidator(fl validator.FieldLevel) bool {
+	cycleStatus := strings.ToLower(fl.Field().String()) // Ensure case-insensitivity
+	return validPlanCycleStatuses[cycleStatus]
+}
+
+func InteractionSeverityValidator(fl validator.FieldLevel) bool {
+	severity := strings.ToLower(fl.Field().String()) // Ensure case-insensitivity
+	return validInteractionSeverities[severity]
+}
+
+func EmetogenicLevelValidator(fl validator.FieldLevel) bool {
+	level := strings.ToLower(fl.Field().String()) // Ensure case-insensitivity
+	return validEmetogenicLevels[level]
+}
+
 // PasswordStrengthValidator checks for strong passwords using bitwise operations.
 func PasswordStrengthValidator(fl validator.FieldLevel) bool {
 	password := fl.Field().String()
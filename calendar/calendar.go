@@ -0,0 +1,362 @@
+package calendar
+
+import (
+	"bcca_crawler/dosing"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+// ShiftRule decides what happens to an event that falls on a closed day.
+type ShiftRule string
+
+const (
+	ShiftNone     ShiftRule = "none"
+	ShiftForward  ShiftRule = "forward"
+	ShiftBackward ShiftRule = "backward"
+	ShiftNearest  ShiftRule = "nearest"
+)
+
+type EventKind string
+
+const (
+	EventTreatment EventKind = "treatment"
+	EventLab       EventKind = "lab"
+)
+
+// LabGroup is a protocol test group reduced to what the calendar needs.
+type LabGroup struct {
+	Category string
+	Tests    []string
+	Comments string
+}
+
+type Options struct {
+	Start time.Time
+	// Cycles is the number of cycles to lay out.
+	Cycles int
+	Shift  ShiftRule
+	// OpenWeekends keeps Saturday and Sunday bookable.
+	OpenWeekends bool
+	Holidays     []time.Time
+	// BaselineLeadDays is how many days before cycle 1 the baseline labs are
+	// drawn; 0 draws them on the day of treatment.
+	BaselineLeadDays int
+	// FollowupLeadDays is how many days before each later cycle the follow-up labs are drawn.
+	FollowupLeadDays int
+}
+
+type Medication struct {
+	MedicationID string `json:"medication_id"`
+	Name         string `json:"name"`
+	Dose         string `json:"dose"`
+	Route        string `json:"route,omitempty"`
+	Frequency    string `json:"frequency"`
+}
+
+type Event struct {
+	Kind          EventKind    `json:"kind"`
+	Title         string       `json:"title"`
+	Cycle         int          `json:"cycle"`
+	CycleDay      int          `json:"cycle_day"`
+	Date          string       `json:"date"`
+	ScheduledDate string       `json:"scheduled_date"`
+	Shifted       bool         `json:"shifted"`
+	ClinicVisit   bool         `json:"clinic_visit"`
+	Medications   []Medication `json:"medications,omitempty"`
+	Tests         []string     `json:"tests,omitempty"`
+	Notes         string       `json:"notes,omitempty"`
+}
+
+type Day struct {
+	Date    string  `json:"date"`
+	Weekday string  `json:"weekday"`
+	Events  []Event `json:"events"`
+}
+
+type Cycle struct {
+	Cycle     int    `json:"cycle"`
+	Label     string `json:"label"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Length    int    `json:"length_days"`
+}
+
+type Calendar struct {
+	ProtocolCode string   `json:"protocol_code"`
+	ProtocolName string   `json:"protocol_name"`
+	StartDate    string   `json:"start_date"`
+	EndDate      string   `json:"end_date"`
+	ShiftRule    string   `json:"shift_rule"`
+	Cycles       []Cycle  `json:"cycles"`
+	Days         []Day    `json:"days"`
+	Warnings     []string `json:"warnings"`
+}
+
+// Protocol is the input used to generate a calendar.
+type Protocol struct {
+	Code      string
+	Name      string
+	Templates []dosing.CycleTemplate
+	Labs      []LabGroup
+}
+
+// DefaultCycleLength is used when a cycle duration cannot be parsed.
+const DefaultCycleLength = 21
+
+// Lead days used when the caller does not ask for others.
+const (
+	DefaultBaselineLeadDays = 7
+	DefaultFollowupLeadDays = 1
+)
+
+// Generate lays out the requested number of cycles starting on opts.Start.
+// Cycle start dates follow the nominal schedule; only the individual
+// appointments are moved by the shift rule, so a holiday does not push the
+// rest of the treatment back.
+func Generate(p Protocol, opts Options) (Calendar, error) {
+	if opts.Cycles <= 0 {
+		return Calendar{}, fmt.Errorf("at least one cycle is required")
+	}
+	if opts.Start.IsZero() {
+		return Calendar{}, fmt.Errorf("a start date is required")
+	}
+	if opts.Shift == "" {
+		opts.Shift = ShiftForward
+	}
+	if opts.BaselineLeadDays < 0 || opts.FollowupLeadDays < 0 {
+		return Calendar{}, fmt.Errorf("lead days cannot be negative")
+	}
+
+	closed := newClosedDays(opts)
+	start := truncate(opts.Start)
+	cal := Calendar{
+		ProtocolCode: p.Code,
+		ProtocolName: p.Name,
+		StartDate:    start.Format(DateLayout),
+		ShiftRule:    string(opts.Shift),
+		Cycles:       []Cycle{},
+		Days:         []Day{},
+		Warnings:     []string{},
+	}
+	warn := func(format string, args ...any) {
+		msg := fmt.Sprintf(format, args...)
+		for _, w := range cal.Warnings {
+			if w == msg {
+				return
+			}
+		}
+		cal.Warnings = append(cal.Warnings, msg)
+	}
+
+	events := []Event{}
+	add := func(e Event, nominal time.Time) {
+		date := nominal
+		// only appointments move, oral doses taken at home keep their day
+		if e.ClinicVisit {
+			shifted, err := closed.shift(nominal, opts.Shift)
+			if err != nil {
+				warn("%s on %s: %s", e.Title, nominal.Format(DateLayout), err.Error())
+			} else {
+				date = shifted
+			}
+		}
+		e.ScheduledDate = nominal.Format(DateLayout)
+		e.Date = date.Format(DateLayout)
+		e.Shifted = !date.Equal(nominal)
+		events = append(events, e)
+	}
+
+	cycleStart := start
+	for n := 1; n <= opts.Cycles; n++ {
+		template, ok := dosing.SelectCycle(p.Templates, n)
+		if !ok {
+			warn("cycle %d: no matching protocol cycle", n)
+			break
+		}
+		length, ok := dosing.ParseDurationDays(template.Duration)
+		if !ok {
+			warn("cycle %d: could not parse duration '%s', using %d days", n, template.Duration, DefaultCycleLength)
+			length = DefaultCycleLength
+		}
+
+		cal.Cycles = append(cal.Cycles, Cycle{
+			Cycle:     n,
+			Label:     template.Label,
+			StartDate: cycleStart.Format(DateLayout),
+			EndDate:   cycleStart.AddDate(0, 0, length-1).Format(DateLayout),
+			Length:    length,
+		})
+
+		byDay := map[int][]Medication{}
+		for _, tx := range template.Treatments {
+			days, ok := dosing.ParseDays(tx.Frequency)
+			if !ok {
+				warn("%s: could not read days from '%s', assuming day 1", tx.MedicationName, tx.Frequency)
+			}
+			for _, d := range days {
+				if d > length {
+					warn("%s: day %d is past the %d day cycle", tx.MedicationName, d, length)
+					continue
+				}
+				byDay[d] = append(byDay[d], Medication{
+					MedicationID: tx.MedicationID,
+					Name:         tx.MedicationName,
+					Dose:         tx.Dose,
+					Route:        tx.Route,
+					Frequency:    tx.Frequency,
+				})
+			}
+		}
+
+		days := make([]int, 0, len(byDay))
+		for d := range byDay {
+			days = append(days, d)
+		}
+		sort.Ints(days)
+		for _, d := range days {
+			add(Event{
+				Kind:        EventTreatment,
+				Title:       fmt.Sprintf("%s cycle %d day %d", p.Code, n, d),
+				Cycle:       n,
+				CycleDay:    d,
+				Medications: byDay[d],
+				ClinicVisit: needsClinic(byDay[d]),
+			}, cycleStart.AddDate(0, 0, d-1))
+		}
+
+		for _, lab := range p.Labs {
+			category := strings.ToLower(lab.Category)
+			switch {
+			case category == "baseline" && n == 1:
+				add(labEvent(p.Code, "Baseline labs", n, -opts.BaselineLeadDays+1, lab), cycleStart.AddDate(0, 0, -opts.BaselineLeadDays))
+			case category == "followup" && n > 1:
+				add(labEvent(p.Code, "Pre-treatment labs", n, -opts.FollowupLeadDays+1, lab), cycleStart.AddDate(0, 0, -opts.FollowupLeadDays))
+			}
+		}
+
+		cycleStart = cycleStart.AddDate(0, 0, length)
+	}
+
+	cal.EndDate = cycleStart.AddDate(0, 0, -1).Format(DateLayout)
+	cal.Days = groupByDay(events)
+	return cal, nil
+}
+
+func labEvent(code, title string, cycle, day int, lab LabGroup) Event {
+	return Event{
+		Kind:        EventLab,
+		Title:       fmt.Sprintf("%s %s (cycle %d)", code, title, cycle),
+		Cycle:       cycle,
+		CycleDay:    day,
+		Tests:       lab.Tests,
+		Notes:       lab.Comments,
+		ClinicVisit: true,
+	}
+}
+
+// needsClinic reports whether any of the day's medications is given outside
+// the home, i.e. is not taken orally.
+func needsClinic(meds []Medication) bool {
+	for _, m := range meds {
+		if !strings.EqualFold(m.Route, "oral") {
+			return true
+		}
+	}
+	return false
+}
+
+func groupByDay(events []Event) []Day {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Date != events[j].Date {
+			return events[i].Date < events[j].Date
+		}
+		// labs are drawn before the chair appointment
+		return events[i].Kind == EventLab && events[j].Kind != EventLab
+	})
+
+	days := []Day{}
+	for _, e := range events {
+		if len(days) == 0 || days[len(days)-1].Date != e.Date {
+			date, _ := time.Parse(DateLayout, e.Date)
+			days = append(days, Day{Date: e.Date, Weekday: date.Weekday().String(), Events: []Event{}})
+		}
+		days[len(days)-1].Events = append(days[len(days)-1].Events, e)
+	}
+	return days
+}
+
+type closedDays struct {
+	weekends bool
+	holidays map[string]bool
+}
+
+func newClosedDays(opts Options) closedDays {
+	c := closedDays{weekends: !opts.OpenWeekends, holidays: map[string]bool{}}
+	for _, h := range opts.Holidays {
+		c.holidays[h.Format(DateLayout)] = true
+	}
+	return c
+}
+
+func (c closedDays) isClosed(d time.Time) bool {
+	if c.weekends && (d.Weekday() == time.Saturday || d.Weekday() == time.Sunday) {
+		return true
+	}
+	return c.holidays[d.Format(DateLayout)]
+}
+
+// shift moves d to the closest open day allowed by the rule.
+func (c closedDays) shift(d time.Time, rule ShiftRule) (time.Time, error) {
+	if rule == ShiftNone || !c.isClosed(d) {
+		return d, nil
+	}
+	for i := 1; i <= 14; i++ {
+		forward := d.AddDate(0, 0, i)
+		backward := d.AddDate(0, 0, -i)
+		switch rule {
+		case ShiftForward:
+			if !c.isClosed(forward) {
+				return forward, nil
+			}
+		case ShiftBackward:
+			if !c.isClosed(backward) {
+				return backward, nil
+			}
+		case ShiftNearest:
+			if !c.isClosed(forward) {
+				return forward, nil
+			}
+			if !c.isClosed(backward) {
+				return backward, nil
+			}
+		default:
+			return d, fmt.Errorf("unknown shift rule: %s", rule)
+		}
+	}
+	return d, fmt.Errorf("no open day within two weeks")
+}
+
+// ParseShiftRule accepts the rule names used in the API ("next" and
+// "previous" are accepted as aliases).
+func ParseShiftRule(s string) (ShiftRule, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "forward", "next":
+		return ShiftForward, nil
+	case "backward", "previous", "prev":
+		return ShiftBackward, nil
+	case "nearest":
+		return ShiftNearest, nil
+	case "none":
+		return ShiftNone, nil
+	default:
+		return "", fmt.Errorf("unknown shift rule: %s", s)
+	}
+}
+
+func truncate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
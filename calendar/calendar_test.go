@@ -0,0 +1,198 @@
+package calendar
+
+import (
+	"bcca_crawler/dosing"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShift(t *testing.T) {
+	// 2026-03-07 is a Saturday, 2026-04-03 Good Friday
+	closed := newClosedDays(Options{Holidays: dates(t, "2026-04-03")})
+	tests := []struct {
+		date string
+		rule ShiftRule
+		want string
+	}{
+		{"2026-03-06", ShiftForward, "2026-03-06"},
+		{"2026-03-07", ShiftForward, "2026-03-09"},
+		{"2026-03-07", ShiftBackward, "2026-03-06"},
+		{"2026-03-07", ShiftNearest, "2026-03-06"},
+		{"2026-03-08", ShiftNearest, "2026-03-09"},
+		{"2026-03-07", ShiftNone, "2026-03-07"},
+		{"2026-04-03", ShiftForward, "2026-04-06"},
+		{"2026-04-03", ShiftBackward, "2026-04-02"},
+		{"2026-04-03", ShiftNearest, "2026-04-02"},
+	}
+	for _, tt := range tests {
+		got, err := closed.shift(dates(t, tt.date)[0], tt.rule)
+		if err != nil || got.Format(DateLayout) != tt.want {
+			t.Errorf("shift %s %s = %s, %v, want %s", tt.rule, tt.date, got.Format(DateLayout), err, tt.want)
+		}
+	}
+
+	open := newClosedDays(Options{OpenWeekends: true, Holidays: dates(t, "2026-03-07")})
+	if got, _ := open.shift(dates(t, "2026-03-07")[0], ShiftForward); got.Format(DateLayout) != "2026-03-08" {
+		t.Errorf("open weekends shift to %s, want the Sunday", got.Format(DateLayout))
+	}
+
+	if _, err := closed.shift(dates(t, "2026-03-07")[0], ShiftRule("sideways")); err == nil {
+		t.Error("unknown rule accepted")
+	}
+	fortnight := newClosedDays(Options{})
+	for d := dates(t, "2026-03-01")[0]; d.Before(dates(t, "2026-04-01")[0]); d = d.AddDate(0, 0, 1) {
+		fortnight.holidays[d.Format(DateLayout)] = true
+	}
+	if _, err := fortnight.shift(dates(t, "2026-03-15")[0], ShiftForward); err == nil {
+		t.Error("shifted past two weeks of closed days")
+	}
+}
+
+func TestParseShiftRule(t *testing.T) {
+	for in, want := range map[string]ShiftRule{"": ShiftForward, "next": ShiftForward, " Previous ": ShiftBackward, "nearest": ShiftNearest, "none": ShiftNone} {
+		if got, err := ParseShiftRule(in); err != nil || got != want {
+			t.Errorf("ParseShiftRule(%q) = %s, %v, want %s", in, got, err, want)
+		}
+	}
+	if _, err := ParseShiftRule("later"); err == nil {
+		t.Error("unknown rule accepted")
+	}
+}
+
+var protocol = Protocol{
+	Code: "BRAJACT",
+	Templates: []dosing.CycleTemplate{{
+		Label:    "Cycle 1+",
+		Duration: "21 days",
+		Treatments: []dosing.TemplateTreatment{
+			{MedicationName: "DOCEtaxel", Dose: "75 mg/m2", Route: "IV", Frequency: "Day 1 and 8"},
+			{MedicationName: "capecitabine", Dose: "1000 mg/m2", Route: "oral", Frequency: "Days 1 to 14"},
+		},
+	}},
+	Labs: []LabGroup{
+		{Category: "Baseline", Tests: []string{"CBC"}},
+		{Category: "Followup", Tests: []string{"CBC", "creatinine"}},
+	},
+}
+
+func find(cal Calendar, title string) (Event, bool) {
+	for _, d := range cal.Days {
+		for _, e := range d.Events {
+			if e.Title == title {
+				return e, true
+			}
+		}
+	}
+	return Event{}, false
+}
+
+func TestGenerate(t *testing.T) {
+	opts := Options{
+		Start:            dates(t, "2026-03-02")[0],
+		Cycles:           2,
+		Holidays:         dates(t, "2026-03-09"),
+		BaselineLeadDays: DefaultBaselineLeadDays,
+		FollowupLeadDays: DefaultFollowupLeadDays,
+	}
+	cal, err := Generate(protocol, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Cycle{
+		{Cycle: 1, Label: "Cycle 1+", StartDate: "2026-03-02", EndDate: "2026-03-22", Length: 21},
+		{Cycle: 2, Label: "Cycle 1+", StartDate: "2026-03-23", EndDate: "2026-04-12", Length: 21},
+	}
+	if len(cal.Cycles) != 2 || cal.Cycles[0] != want[0] || cal.Cycles[1] != want[1] {
+		t.Errorf("cycles = %+v, want %+v", cal.Cycles, want)
+	}
+	if cal.StartDate != "2026-03-02" || cal.EndDate != "2026-04-12" || cal.ShiftRule != "forward" {
+		t.Errorf("calendar runs %s to %s, shift %s", cal.StartDate, cal.EndDate, cal.ShiftRule)
+	}
+
+	tests := []struct {
+		title, date, scheduled string
+		clinic                 bool
+	}{
+		{"BRAJACT cycle 1 day 1", "2026-03-02", "2026-03-02", true},
+		// day 8 is a closed Monday: the chair appointment moves to Tuesday
+		{"BRAJACT cycle 1 day 8", "2026-03-10", "2026-03-09", true},
+		// capecitabine alone is taken at home, even on a Saturday
+		{"BRAJACT cycle 1 day 6", "2026-03-07", "2026-03-07", false},
+		{"BRAJACT Baseline labs (cycle 1)", "2026-02-23", "2026-02-23", true},
+		// the day before cycle 2 is a Sunday
+		{"BRAJACT Pre-treatment labs (cycle 2)", "2026-03-23", "2026-03-22", true},
+		{"BRAJACT cycle 2 day 14", "2026-04-05", "2026-04-05", false},
+	}
+	for _, tt := range tests {
+		e, ok := find(cal, tt.title)
+		if !ok {
+			t.Errorf("no event %q", tt.title)
+			continue
+		}
+		if e.Date != tt.date || e.ScheduledDate != tt.scheduled || e.Shifted != (tt.date != tt.scheduled) || e.ClinicVisit != tt.clinic {
+			t.Errorf("%s = %+v", tt.title, e)
+		}
+	}
+	if _, ok := find(cal, "BRAJACT cycle 1 day 15"); ok {
+		t.Error("event on a day without treatment")
+	}
+
+	// the labs are listed before the treatment they share a day with
+	for _, d := range cal.Days {
+		if d.Date == "2026-03-23" && (len(d.Events) != 2 || d.Events[0].Kind != EventLab || d.Weekday != "Monday") {
+			t.Errorf("2026-03-23 = %+v", d)
+		}
+	}
+}
+
+func TestGenerateLeadDays(t *testing.T) {
+	opts := Options{Start: dates(t, "2026-03-02")[0], Cycles: 2, Shift: ShiftNone}
+	cal, err := Generate(protocol, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := find(cal, "BRAJACT Baseline labs (cycle 1)"); e.Date != "2026-03-02" || e.CycleDay != 1 {
+		t.Errorf("baseline labs with no lead = %+v", e)
+	}
+	if e, _ := find(cal, "BRAJACT Pre-treatment labs (cycle 2)"); e.Date != "2026-03-23" {
+		t.Errorf("pre-treatment labs with no lead = %+v", e)
+	}
+
+	for _, bad := range []Options{
+		{Start: opts.Start},
+		{Cycles: 1},
+		{Start: opts.Start, Cycles: 1, BaselineLeadDays: -1},
+		{Start: opts.Start, Cycles: 1, FollowupLeadDays: -1},
+	} {
+		if _, err := Generate(protocol, bad); err == nil {
+			t.Errorf("Generate(%+v) succeeded", bad)
+		}
+	}
+}
+
+func TestGenerateWarnings(t *testing.T) {
+	p := Protocol{Code: "X", Templates: []dosing.CycleTemplate{{
+		Label:    "Cycles 1-2",
+		Duration: "every cycle",
+		Treatments: []dosing.TemplateTreatment{
+			{MedicationName: "A", Route: "IV", Frequency: "Day 1 and 30"},
+			{MedicationName: "B", Route: "IV", Frequency: "as needed"},
+		},
+	}}}
+	cal, err := Generate(p, Options{Start: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Cycles: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"cycle 1: could not parse duration 'every cycle', using 21 days",
+		"A: day 30 is past the 21 day cycle",
+		"B: could not read days from 'as needed', assuming day 1",
+		"cycle 2: could not parse duration 'every cycle', using 21 days",
+		"cycle 3: no matching protocol cycle",
+	}
+	if strings.Join(cal.Warnings, "\n") != strings.Join(want, "\n") || len(cal.Cycles) != 2 {
+		t.Errorf("warnings = %q, %d cycles", cal.Warnings, len(cal.Cycles))
+	}
+}
@@ -0,0 +1,88 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BCHolidays returns the British Columbia statutory holidays for a year, plus
+// Boxing Day, when the cancer centres' treatment rooms are closed.
+func BCHolidays(year int) []time.Time {
+	date := func(m time.Month, d int) time.Time {
+		return time.Date(year, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	easter := easterSunday(year)
+
+	// Victoria Day is the last Monday before May 25
+	victoria := date(time.May, 24)
+	for victoria.Weekday() != time.Monday {
+		victoria = victoria.AddDate(0, 0, -1)
+	}
+
+	return []time.Time{
+		date(time.January, 1),
+		nthWeekday(year, time.February, time.Monday, 3), // Family Day
+		easter.AddDate(0, 0, -2),                        // Good Friday
+		victoria,
+		date(time.July, 1),
+		nthWeekday(year, time.August, time.Monday, 1),    // BC Day
+		nthWeekday(year, time.September, time.Monday, 1), // Labour Day
+		date(time.September, 30),                         // National Day for Truth and Reconciliation
+		nthWeekday(year, time.October, time.Monday, 2),   // Thanksgiving
+		date(time.November, 11),
+		date(time.December, 25),
+		date(time.December, 26),
+	}
+}
+
+// ParseHolidays reads a comma separated list of YYYY-MM-DD dates. The keyword
+// "bc" expands to the BC holidays of every year between from and to.
+func ParseHolidays(list string, from, to time.Time) ([]time.Time, error) {
+	holidays := []time.Time{}
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if strings.EqualFold(part, "bc") {
+			for y := from.Year(); y <= to.Year(); y++ {
+				holidays = append(holidays, BCHolidays(y)...)
+			}
+			continue
+		}
+		d, err := time.Parse(DateLayout, part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid holiday date: %s", part)
+		}
+		holidays = append(holidays, d)
+	}
+	return holidays, nil
+}
+
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	d := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	for d.Weekday() != weekday {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d.AddDate(0, 0, 7*(n-1))
+}
+
+// easterSunday uses the anonymous Gregorian algorithm.
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,80 @@
+package calendar
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func dates(t *testing.T, list ...string) []time.Time {
+	t.Helper()
+	out := []time.Time{}
+	for _, s := range list {
+		d, err := time.Parse(DateLayout, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func TestBCHolidays(t *testing.T) {
+	want := dates(t,
+		"2026-01-01",
+		"2026-02-16", // Family Day, third Monday of February
+		"2026-04-03", // Good Friday
+		"2026-05-18", // Victoria Day, May 24 is a Sunday
+		"2026-07-01",
+		"2026-08-03", // BC Day, first Monday of August
+		"2026-09-07", // Labour Day
+		"2026-09-30",
+		"2026-10-12", // Thanksgiving, second Monday of October
+		"2026-11-11",
+		"2026-12-25",
+		"2026-12-26",
+	)
+	if got := BCHolidays(2026); !reflect.DeepEqual(got, want) {
+		t.Errorf("BCHolidays(2026) = %v, want %v", got, want)
+	}
+
+	// Victoria Day falls on May 24 itself when that is a Monday
+	if got := BCHolidays(2027)[3]; got.Format(DateLayout) != "2027-05-24" {
+		t.Errorf("Victoria Day 2027 = %s", got.Format(DateLayout))
+	}
+	if got := BCHolidays(2025)[3]; got.Format(DateLayout) != "2025-05-19" {
+		t.Errorf("Victoria Day 2025 = %s", got.Format(DateLayout))
+	}
+}
+
+func TestEasterSunday(t *testing.T) {
+	for year, want := range map[int]string{
+		2008: "2008-03-23",
+		2019: "2019-04-21",
+		2024: "2024-03-31",
+		2025: "2025-04-20",
+		2026: "2026-04-05",
+		2038: "2038-04-25",
+	} {
+		if got := easterSunday(year).Format(DateLayout); got != want {
+			t.Errorf("easterSunday(%d) = %s, want %s", year, got, want)
+		}
+	}
+}
+
+func TestParseHolidays(t *testing.T) {
+	from, to := dates(t, "2025-11-01")[0], dates(t, "2026-02-01")[0]
+	got, err := ParseHolidays("2026-01-02, bc", from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1+2*12 || got[0].Format(DateLayout) != "2026-01-02" || got[1].Format(DateLayout) != "2025-01-01" {
+		t.Errorf("ParseHolidays = %v", got)
+	}
+	if _, err := ParseHolidays("2026-13-01", from, to); err == nil {
+		t.Error("invalid date accepted")
+	}
+	if got, err := ParseHolidays("", from, to); err != nil || len(got) != 0 {
+		t.Errorf("empty list = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,97 @@
+package calendar
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WriteICS exports the calendar as an iCalendar (RFC 5545) file with one
+// all-day event per appointment.
+func WriteICS(w io.Writer, cal Calendar, stamp time.Time) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//bcca_crawler//treatment calendar//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escapeText(fmt.Sprintf("%s treatment calendar", cal.ProtocolCode)),
+	}
+
+	dtstamp := stamp.UTC().Format("20060102T150405Z")
+	for _, day := range cal.Days {
+		date, err := time.Parse(DateLayout, day.Date)
+		if err != nil {
+			return fmt.Errorf("invalid calendar date: %s", day.Date)
+		}
+		for i, e := range day.Events {
+			lines = append(lines,
+				"BEGIN:VEVENT",
+				fmt.Sprintf("UID:%s-c%d-%s-%s-%d@bcca_crawler", strings.ToLower(cal.ProtocolCode), e.Cycle, e.Kind, date.Format("20060102"), i),
+				"DTSTAMP:"+dtstamp,
+				"DTSTART;VALUE=DATE:"+date.Format("20060102"),
+				"DTEND;VALUE=DATE:"+date.AddDate(0, 0, 1).Format("20060102"),
+				"SUMMARY:"+escapeText(e.Title),
+				"DESCRIPTION:"+escapeText(describe(e)),
+				"CATEGORIES:"+strings.ToUpper(string(e.Kind)),
+				"TRANSP:TRANSPARENT",
+				"END:VEVENT",
+			)
+		}
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, fold(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func describe(e Event) string {
+	parts := []string{}
+	for _, m := range e.Medications {
+		line := fmt.Sprintf("%s %s", m.Name, m.Dose)
+		if m.Route != "" && m.Route != "unknown" {
+			line += " " + strings.ToUpper(m.Route)
+		}
+		parts = append(parts, line)
+	}
+	if len(e.Tests) > 0 {
+		parts = append(parts, "Tests: "+strings.Join(e.Tests, ", "))
+	}
+	if e.Notes != "" {
+		parts = append(parts, e.Notes)
+	}
+	if e.Shifted {
+		parts = append(parts, fmt.Sprintf("Moved from %s", e.ScheduledDate))
+	}
+	return strings.Join(parts, "\n")
+}
+
+func escapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// fold splits content lines longer than 75 octets without breaking UTF-8
+// sequences.
+func fold(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
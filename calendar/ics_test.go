@@ -0,0 +1,95 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain", "plain"},
+		{"CBC, creatinine; LFTs", `CBC\, creatinine\; LFTs`},
+		{`C:\path`, `C:\\path`},
+		{"one\ntwo\r\nthree", `one\ntwo\nthree`},
+	}
+	for _, tt := range tests {
+		if got := escapeText(tt.in); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	short := strings.Repeat("a", 75)
+	if fold(short) != short {
+		t.Error("75 octet line folded")
+	}
+	for _, line := range []string{
+		"DESCRIPTION:" + strings.Repeat("x", 200),
+		"SUMMARY:" + strings.Repeat("é", 80),
+		"SUMMARY:" + strings.Repeat("ab€", 40),
+	} {
+		folded := fold(line)
+		for i, part := range strings.Split(folded, "\r\n") {
+			if len(part) > 75 {
+				t.Errorf("line %d is %d octets", i, len(part))
+			}
+			if i > 0 && !strings.HasPrefix(part, " ") {
+				t.Errorf("continuation %d does not start with a space", i)
+			}
+			if !utf8.ValidString(part) {
+				t.Errorf("line %d splits a UTF-8 sequence", i)
+			}
+		}
+		if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != line {
+			t.Errorf("unfolding gives %q", unfolded)
+		}
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	cal := Calendar{ProtocolCode: "BRAJACT", Days: []Day{{Date: "2026-03-10", Events: []Event{{
+		Kind:          EventTreatment,
+		Title:         "BRAJACT cycle 1 day 8",
+		Cycle:         1,
+		Date:          "2026-03-10",
+		ScheduledDate: "2026-03-09",
+		Shifted:       true,
+		Medications:   []Medication{{Name: "DOCEtaxel", Dose: "75 mg/m2", Route: "iv"}},
+		Tests:         []string{"CBC", "creatinine"},
+		Notes:         "Hold if ANC < 1.5; see protocol " + strings.Repeat("section ", 10),
+	}}}}}
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, cal, time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for i, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line %d is %d octets", i, len(line))
+		}
+	}
+
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:BRAJACT treatment calendar\r\n",
+		"UID:brajact-c1-treatment-20260310-0@bcca_crawler\r\n",
+		"DTSTAMP:20260301T083000Z\r\n",
+		"DTSTART;VALUE=DATE:20260310\r\nDTEND;VALUE=DATE:20260311\r\n",
+		`DESCRIPTION:DOCEtaxel 75 mg/m2 IV\nTests: CBC\, creatinine\nHold if ANC < 1.5\; see protocol section`,
+		`\nMoved from 2026-03-09` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("calendar has no %q:\n%s", want, unfolded)
+		}
+	}
+
+	if err := WriteICS(&buf, Calendar{Days: []Day{{Date: "10/03/2026"}}}, time.Now()); err == nil {
+		t.Error("invalid date accepted")
+	}
+}
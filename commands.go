@@ -1,21 +1,39 @@
 package main
 
 import (
-	"bcca_crawler/ai_helper"
 	"github.com/gorilla/mux"
 	"bcca_crawler/api"
+	"bcca_crawler/api/protocols"
+	"bcca_crawler/ai_helper"
 	"bcca_crawler/crawler"
+	"bcca_crawler/ctcae"
+	"bcca_crawler/eval"
+	"bcca_crawler/fetch"
+	"bcca_crawler/interactions"
 	"bcca_crawler/internal/config"	
 	"bcca_crawler/internal/auth"
+	"bcca_crawler/internal/database"
+	"bcca_crawler/jobs"
+	"bcca_crawler/medications"
+	"bcca_crawler/prompts"
 	"bcca_crawler/routes"
+	"bcca_crawler/scheduler"
 	"time"
 	"context"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
 	"strings"
-	"io"
+	"sync"
+	"syscall"
+	"database/sql"
+	neturl "net/url"
+
+	"github.com/google/uuid"
 )
 
 type command struct {
@@ -73,6 +91,7 @@ func handlerGeoLocation(s *config.Config, cmd command) error {
 
 func handlerAnalyzePDF(s *config.Config, cmd command) error {
 	// Analyze a PDF
+	cmd.Args = noCacheFlag(s, cmd.Args)
 	if len(cmd.Args) < 1 {
 		return errors.New("missing PDF URLs argument")
 	}
@@ -82,31 +101,23 @@ func handlerAnalyzePDF(s *config.Config, cmd command) error {
             return fmt.Errorf("invalid URL: %s. URL must start with http:// or https://", url)
         }
     }
-	// Call the AI helper to analyze the PDF
-	// This function will handle the PDF analysis and return any errors encountered
-
-	ai_helper.RunAllLinks(s,cmd.Args)	
-	
-	return nil
+	// Queue the PDFs for extraction and work the queue until they are done
+	return enqueueAndExtract(s, cmd.Args)
 }
 
 func handlerSearchPubmed(s *config.Config, cmd command) error {
 
-	url := "https://eutils.ncbi.nlm.nih.gov/entrez/eutils/esearch.fcgi?db=pubmed&term="+cmd.Args[0]+"&retmode=json"
-    resp, err := http.Get(url)
+	if len(cmd.Args) < 1 {
+		return errors.New("missing search term argument")
+	}
+	url := "https://eutils.ncbi.nlm.nih.gov/entrez/eutils/esearch.fcgi?db=pubmed&term="+neturl.QueryEscape(cmd.Args[0])+"&retmode=json"
+    resp, err := fetch.Services.Get(context.Background(), url)
     if err != nil {
         fmt.Println("Error:", err)
         return err
     }
-    defer resp.Body.Close()
 
-    body, err := io.ReadAll(resp.Body)
-    if err != nil {
-        fmt.Println("Error reading response:", err)
-        return err
-    }
-
-    fmt.Println(string(body))
+    fmt.Println(string(resp.Body))
 	return nil
 }
 
@@ -143,6 +154,63 @@ func handlerResetDatabase(s *config.Config, cmd command) error {
 	return nil
 }
 
+func handlerImportInteractions(s *config.Config, cmd command) error {
+	// Import a local drug interaction dataset (.csv or .json)
+	if len(cmd.Args) < 1 {
+		return errors.New("missing interaction file argument")
+	}
+	ctx := context.Background()
+	total := 0
+	for _, path := range cmd.Args {
+		records, err := interactions.LoadFile(path)
+		if err != nil {
+			fmt.Println("Error reading interactions: ", err)
+			return err
+		}
+		saved, err := protocols.ImportInteractions(s, ctx, records)
+		total += saved
+		if err != nil {
+			fmt.Println("Error importing interactions: ", err)
+			return err
+		}
+		fmt.Printf("Imported %d interactions from %s\n", saved, path)
+	}
+	fmt.Printf("Imported %d interactions\n", total)
+	return nil
+}
+
+func handlerClassifyEmetogenic(s *config.Config, cmd command) error {
+	// Set the emetogenic level of stored medications that have none
+	set, err := medications.ClassifyStored(s, context.Background())
+	if err != nil {
+		fmt.Println("Error classifying medications: ", err)
+		return err
+	}
+	fmt.Printf("Set the emetogenic level of %d medications\n", set)
+	return nil
+}
+
+func handlerImportCTCAE(s *config.Config, cmd command) error {
+	// Import the CTCAE v5 term list (.xlsx or .csv)
+	if len(cmd.Args) < 1 {
+		return errors.New("missing ctcae file argument")
+	}
+	ctx := context.Background()
+	for _, path := range cmd.Args {
+		terms, err := ctcae.LoadFile(path)
+		if err != nil {
+			fmt.Println("Error reading ctcae terms: ", err)
+			return err
+		}
+		result, err := ctcae.Import(s, ctx, terms)
+		if err != nil {
+			fmt.Println("Error importing ctcae terms: ", err)
+			return err
+		}
+		fmt.Printf("Imported %d ctcae terms from %s, linked %d toxicities, updated %d grades\n", result.Terms, path, result.Linked, result.Grades)
+	}
+	return nil
+}
 
 func handlerCreateUser(s *config.Config, cmd command) error {
 	// Create a new user
@@ -159,52 +227,345 @@ func handlerCreateUser(s *config.Config, cmd command) error {
 }
 
 func handlerCrawl(s *config.Config, cmd command) error {
+	cmd.Args = noCacheFlag(s, cmd.Args)
 	if len(cmd.Args) < 1 {
 		return errors.New("missing URL argument")
 	}
-	// Crawl a website
+	// Crawl a website: the listing page is parsed by a crawl job, which
+	// queues an extract job for every protocol it lists
 	url := cmd.Args[0]
-	
-	html,err := crawler.GetHTML(url)
+
+	job, err := jobs.EnqueueCrawl(s, context.Background(), url)
 	if err != nil {
-		fmt.Println("Error getting HTML: ", err)
+		return err
 	}
-	WebProtocol,err := crawler.GetURLsFromHTML(html)
-	if err != nil {
-		fmt.Println("Error getting URLs: ", err)
+	fmt.Printf("Queued crawl job %s for %s\n", job.ID, url)
+
+	return runJobs(s, true)
+}
+
+func handlerSingleCrawl(s *config.Config, cmd command) error {
+	cmd.Args = noCacheFlag(s, cmd.Args)
+	if len(cmd.Args) < 1 {
+		return errors.New("missing URL argument")
 	}
+	// Get a single protocol from a website
+	return enqueueAndExtract(s, cmd.Args)
+}
 
-	protocol_list := make([]string, 0)
+// noCacheFlag removes --no-cache from the arguments of an extracting
+// command and, when it is there, makes the extractions call the model
+// instead of reusing cached responses.
+func noCacheFlag(s *config.Config, args []string) []string {
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--no-cache" {
+			s.NoCache = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest
+}
 
-	for _, protocol := range WebProtocol {
-		fmt.Println("Protocol Name: ", protocol.Code)		
-		for _, link:= range protocol.Links {
-			textVal, ok := link["text"]
-			if ok && strings.Contains(textVal, "Protocol") {
-				href := link["href"]
-				fmt.Println("➡ Found Protocol link:", href)
-				protocol_list = append(protocol_list, href)
+// handlerReplay extracts protocols again from the cached model responses
+// of their latest extraction, with the same prompt versions and model, so
+// a change to the ingestion code can be applied without calling the model:
+//
+//	replay [code...]
+//
+// Without codes every protocol with a recorded extraction is replayed.
+func handlerReplay(s *config.Config, cmd command) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-			}
-		}		
-	}	
-	
-	ai_helper.RunAllLinks(s,protocol_list)
-	
+	latest, err := s.Db.GetLatestExtractions(ctx, cmd.Args)
+	if err != nil {
+		return fmt.Errorf("error getting extractions: %v", err)
+	}
+	if len(latest) == 0 {
+		return errors.New("no recorded extractions to replay")
+	}
+	failed := 0
+	for _, row := range latest {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := ai_helper.ReplayExtraction(ctx, s, row.Extraction); err != nil {
+			fmt.Printf("Error replaying %s: %v\n", row.Code, err)
+			failed++
+			continue
+		}
+		fmt.Printf("Replayed %s\n", row.Code)
+	}
+	fmt.Printf("%d replayed, %d failed\n", len(latest)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d replay(s) failed", failed)
+	}
 	return nil
 }
 
-func handlerSingleCrawl(s *config.Config, cmd command) error {
+// enqueueAndExtract queues protocol PDFs for extraction and works the queue
+// until they are done.
+func enqueueAndExtract(s *config.Config, links []string) error {
+	ctx := context.Background()
+	for _, link := range links {
+		job, err := jobs.EnqueueExtract(s, ctx, link, nil, "")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Queued extract job %s for %s\n", job.ID, link)
+	}
+	return runJobs(s, true)
+}
+
+// runJobs works the job queue until Ctrl-C, or until it is empty with
+// drain. Interrupted jobs go back in the queue for the next run.
+func runJobs(s *config.Config, drain bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return jobs.NewWorker(s).Run(ctx, drain)
+}
+
+// handlerJobs manages the job queue:
+//
+//	jobs list [status] [limit]
+//	jobs retry <id>
+//	jobs cancel <id>
+//	jobs work
+func handlerJobs(s *config.Config, cmd command) error {
 	if len(cmd.Args) < 1 {
-		return errors.New("missing URL argument")
+		return errors.New("usage: jobs list [status] [limit] | retry <id> | cancel <id> | work")
+	}
+	ctx := context.Background()
+
+	switch cmd.Args[0] {
+	case "list":
+		status := "all"
+		limit := 50
+		if len(cmd.Args) > 1 {
+			status = cmd.Args[1]
+		}
+		if len(cmd.Args) > 2 {
+			n, err := strconv.Atoi(cmd.Args[2])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid limit: %s", cmd.Args[2])
+			}
+			limit = n
+		}
+		items, err := s.Db.ListJobs(ctx, database.ListJobsParams{Status: status, RowLimit: int32(limit)})
+		if err != nil {
+			return err
+		}
+		for _, job := range items {
+			fmt.Printf("%s  %-8s %-10s %d/%d  %s\n", job.ID, job.Kind, job.Status, job.Attempts, job.MaxAttempts, job.Target)
+			if job.LastError != "" {
+				fmt.Printf("    last error: %s\n", job.LastError)
+			}
+		}
+		fmt.Printf("%d job(s)\n", len(items))
+		return nil
+	case "retry", "cancel":
+		if len(cmd.Args) < 2 {
+			return fmt.Errorf("missing job id")
+		}
+		id, err := uuid.Parse(cmd.Args[1])
+		if err != nil {
+			return fmt.Errorf("invalid job id: %s", cmd.Args[1])
+		}
+		var job database.Job
+		if cmd.Args[0] == "retry" {
+			job, err = s.Db.RetryJob(ctx, id)
+		} else {
+			job, err = s.Db.CancelJob(ctx, id)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("job %s not found, or its status does not allow %s", id, cmd.Args[0])
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Job %s is %s\n", job.ID, job.Status)
+		return nil
+	case "work":
+		return runJobs(s, false)
+	default:
+		return fmt.Errorf("unknown jobs command: %s", cmd.Args[0])
+	}
+}
+
+// handlerDiscover walks every tumor-group page under the protocol index,
+// records the inventory and retires protocols no longer listed. With
+// --extract the protocols that are not in the database yet are extracted.
+func handlerDiscover(s *config.Config, cmd command) error {
+	root := crawler.RootURL
+	extract := false
+	for _, arg := range noCacheFlag(s, cmd.Args) {
+		if arg == "--extract" {
+			extract = true
+		} else {
+			root = arg
+		}
+	}
+
+	inv, err := crawler.Discover(root)
+	if err != nil {
+		return err
+	}
+	for _, page := range inv.Pages {
+		fmt.Printf("Tumor group page: %s (%s)\n", page.URL, page.TumorGroup)
+	}
+	for _, e := range inv.Errors {
+		fmt.Println("Error reading page: ", e)
+	}
+	for _, warning := range inv.Warnings {
+		fmt.Println("Warning: ", warning)
+	}
+
+	ctx := context.Background()
+	result, err := protocols.SaveInventory(s, ctx, inv)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Found %d protocols on %d pages, %d not in the database\n", result.Listed, result.Pages, len(result.Missing))
+	if result.Incomplete {
+		fmt.Println("Inventory is incomplete, no protocols were retired")
+	}
+	for _, retired := range result.Retired {
+		fmt.Println("Retired: ", retired.Code)
+	}
+
+	if !extract || len(result.Missing) == 0 {
+		return nil
+	}
+	if _, err := jobs.QueueMissing(s, ctx, inv, result); err != nil {
+		return err
+	}
+	if err := runJobs(s, true); err != nil {
+		return err
+	}
+
+	// link the new protocols to their listings
+	if _, err := protocols.SaveInventory(s, ctx, inv); err != nil {
+		return err
 	}
-	// Get a single protocol from a website
-			
-	ai_helper.RunAllLinks(s,cmd.Args)
-	
 	return nil
 }
 
+// handlerEval scores the extractor against a gold set:
+//
+//	eval [dir] [--model name] [--mode single|sectioned] [--prompt version] [--threshold 0.8] [--json report.json] [--out dir] [--no-cache]
+//
+// --prompt tries a version of the extraction prompt other than the active
+// one; sectioned runs use the active overview and section prompts. --out
+// keeps every extraction next to the report for a closer look. A rerun with
+// the same prompt and model scores the cached responses unless --no-cache.
+func handlerEval(s *config.Config, cmd command) error {
+	dir := eval.DefaultDir
+	opts := eval.Options{Threshold: eval.DefaultThreshold}
+	jsonPath := ""
+	promptVersion := int32(0)
+	cmd.Args = noCacheFlag(s, cmd.Args)
+	for i := 0; i < len(cmd.Args); i++ {
+		arg := cmd.Args[i]
+		if !strings.HasPrefix(arg, "--") {
+			dir = arg
+			continue
+		}
+		if i+1 >= len(cmd.Args) {
+			return fmt.Errorf("missing value for %s", arg)
+		}
+		value := cmd.Args[i+1]
+		i++
+		switch arg {
+		case "--model":
+			s.GeminiModel = value
+		case "--mode":
+			if value != string(database.ExtractionModeEnumSingle) && value != string(database.ExtractionModeEnumSectioned) {
+				return fmt.Errorf("invalid mode: %s", value)
+			}
+			s.ExtractionMode = value
+		case "--prompt":
+			v, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || v <= 0 {
+				return fmt.Errorf("invalid prompt version: %s", value)
+			}
+			promptVersion = int32(v)
+		case "--threshold":
+			t, err := strconv.ParseFloat(value, 64)
+			if err != nil || t <= 0 || t > 1 {
+				return fmt.Errorf("invalid threshold: %s", value)
+			}
+			opts.Threshold = t
+		case "--json":
+			jsonPath = value
+		case "--out":
+			opts.OutDir = value
+		default:
+			return fmt.Errorf("unknown option: %s", arg)
+		}
+	}
+	opts.Model = ai_helper.Model(s)
+
+	cases, err := eval.LoadCases(dir)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if ai_helper.Mode(s) == database.ExtractionModeEnumSectioned {
+		if promptVersion > 0 {
+			return fmt.Errorf("--prompt only applies to single mode")
+		}
+		overview, err := prompts.Active(s, ctx, prompts.Overview)
+		if err != nil {
+			return err
+		}
+		section, err := prompts.Active(s, ctx, prompts.Section)
+		if err != nil {
+			return err
+		}
+		opts.Prompt = fmt.Sprintf("%s v%d + %s v%d", prompts.Overview, overview.Version, prompts.Section, section.Version)
+		report, err := eval.Run(ctx, cases, func(ctx context.Context, c eval.Case, pdf []byte) (ai_helper.ProtocolPayload, error) {
+			payload, _, err := ai_helper.ExtractSectioned(ctx, s, pdf, overview, section, c.Gold.ProtocolSummary.TumorGroup)
+			return payload, err
+		}, opts)
+		if err != nil {
+			return err
+		}
+		return printEvalReport(report, jsonPath)
+	}
+
+	var prompt database.PromptVersion
+	if promptVersion > 0 {
+		prompt, err = prompts.Version(s, ctx, prompts.Extraction, promptVersion)
+	} else {
+		prompt, err = prompts.Active(s, ctx, prompts.Extraction)
+	}
+	if err != nil {
+		return err
+	}
+	opts.Prompt = fmt.Sprintf("%s v%d", prompts.Extraction, prompt.Version)
+
+	report, err := eval.Run(ctx, cases, func(ctx context.Context, c eval.Case, pdf []byte) (ai_helper.ProtocolPayload, error) {
+		return ai_helper.Extract(ctx, s, pdf, prompt, prompts.Vars{TumorGroup: c.Gold.ProtocolSummary.TumorGroup})
+	}, opts)
+	if err != nil {
+		return err
+	}
+	return printEvalReport(report, jsonPath)
+}
+
+func printEvalReport(report eval.Report, jsonPath string) error {
+	if err := report.Print(os.Stdout); err != nil {
+		return err
+	}
+	if jsonPath != "" {
+		return report.WriteJSON(jsonPath)
+	}
+	return nil
+}
 
 func handlerStartServer(s *config.Config, cmd command) error {
 	// Start the server
@@ -226,11 +587,42 @@ func handlerStartServer(s *config.Config, cmd command) error {
 		WriteTimeout: 15 * time.Second,
 		ReadTimeout:  15 * time.Second,
 	}
+
+	// --scheduler runs the crawl schedules and a job worker in the server
+	background := false
+	for _, arg := range cmd.Args {
+		if arg == "--scheduler" {
+			background = true
+		}
+	}
+	var wg sync.WaitGroup
+	if background {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			scheduler.New(s).Run(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			if err := jobs.NewWorker(s).Run(ctx, false); err != nil {
+				log.Printf("Job worker stopped: %v", err)
+			}
+		}()
+		go func() {
+			<-ctx.Done()
+			srv.Shutdown(context.Background())
+		}()
+	}
+
 	log.Printf("Server listening on port %s", s.ServerPort)
 	err := srv.ListenAndServe()
-	if err != nil {
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		fmt.Println("Error starting server: ", err)
 		log.Fatal(err)
 	}
+	// let interrupted jobs go back in the queue
+	wg.Wait()
 	return nil
 }
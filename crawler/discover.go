@@ -0,0 +1,226 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// RootURL lists the tumor-group pages of the BC Cancer chemotherapy
+// protocols.
+const RootURL = "http://www.bccancer.bc.ca/health-professionals/clinical-resources/chemotherapy-protocols"
+
+type TumorGroupPage struct {
+	URL        string
+	Title      string
+	TumorGroup string
+}
+
+// InventoryItem is one protocol listed on a tumor-group page.
+type InventoryItem struct {
+	Code        string
+	Description string
+	TumorGroup  string
+	PageURL     string
+	Listing     WebProtocol
+}
+
+// Inventory is everything a discovery crawl found. Errors holds the pages
+// that could not be read or listed no protocols; an inventory with errors
+// is incomplete. Warnings are the parser's structural-drift reports.
+type Inventory struct {
+	Root      string
+	Pages     []TumorGroupPage
+	Protocols []InventoryItem
+	Errors    []string
+	Warnings  []string
+}
+
+// Discover reads the root page, then every tumor-group page it links to,
+// and lists their protocols. A protocol listed on several pages keeps the
+// first one.
+func Discover(root string) (Inventory, error) {
+	inv := Inventory{Root: root}
+	body, err := GetHTML(root)
+	if err != nil {
+		return inv, fmt.Errorf("error reading protocol index %s: %w", root, err)
+	}
+	inv.Pages, err = FindTumorGroupPages(body, root)
+	if err != nil {
+		return inv, err
+	}
+	if len(inv.Pages) == 0 {
+		return inv, fmt.Errorf("no tumor group pages found on %s", root)
+	}
+
+	seen := map[string]bool{}
+	for _, page := range inv.Pages {
+		body, err := GetHTML(page.URL)
+		if err != nil {
+			inv.Errors = append(inv.Errors, fmt.Sprintf("%s: %v", page.URL, err))
+			continue
+		}
+		listing, err := ParseListing(body, page.URL)
+		if err != nil {
+			inv.Errors = append(inv.Errors, fmt.Sprintf("%s: %v", page.URL, err))
+			continue
+		}
+		for _, w := range listing.Warnings {
+			inv.Warnings = append(inv.Warnings, fmt.Sprintf("%s: %s", page.URL, w))
+		}
+		if len(listing.Protocols) == 0 {
+			inv.Errors = append(inv.Errors, fmt.Sprintf("%s: no protocols found", page.URL))
+			continue
+		}
+		for _, p := range listing.Protocols {
+			code := strings.TrimSpace(p.Code)
+			if code == "" || seen[code] {
+				continue
+			}
+			seen[code] = true
+			inv.Protocols = append(inv.Protocols, InventoryItem{
+				Code:        code,
+				Description: p.Description,
+				TumorGroup:  ProtocolTumorGroup(code, page.TumorGroup),
+				PageURL:     page.URL,
+				Listing:     p,
+			})
+		}
+	}
+	return inv, nil
+}
+
+// FindTumorGroupPages returns the links of the index page that sit one
+// level below it, e.g. .../chemotherapy-protocols/breast.
+func FindTumorGroupPages(htmlBody, root string) ([]TumorGroupPage, error) {
+	base, err := url.Parse(root)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(strings.NewReader(htmlBody))
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.TrimSuffix(base.Path, "/") + "/"
+
+	pages := []TumorGroupPage{}
+	seen := map[string]bool{}
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			for _, attr := range n.Attr {
+				if attr.Key != "href" {
+					continue
+				}
+				u, err := base.Parse(strings.TrimSpace(attr.Val))
+				if err != nil || !strings.EqualFold(u.Host, base.Host) {
+					break
+				}
+				path := strings.TrimSuffix(u.Path, "/")
+				slug := strings.TrimPrefix(path, prefix)
+				if !strings.HasPrefix(path, prefix) || slug == "" || strings.Contains(slug, "/") || strings.Contains(slug, ".") {
+					break
+				}
+				u.Fragment, u.RawQuery = "", ""
+				u.Path = path
+				if seen[u.String()] {
+					break
+				}
+				seen[u.String()] = true
+				title := getTextContent(n)
+				pages = append(pages, TumorGroupPage{
+					URL:        u.String(),
+					Title:      title,
+					TumorGroup: TumorGroupOf(slug + " " + title),
+				})
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(doc)
+	return pages, nil
+}
+
+// tumorGroupKeywords map page names to tumor_group_enum values, most
+// specific first.
+var tumorGroupKeywords = []struct {
+	keyword string
+	group   string
+}{
+	{"unknown-primary", "unknown_primary"},
+	{"unknown primary", "unknown_primary"},
+	{"breast", "breast"},
+	{"gastro", "gastrointestinal"},
+	{"genito", "genitourinary"},
+	{"gyn", "gynecology"},
+	{"head", "head_and_neck"},
+	{"leuk", "leukemia"},
+	{"transplant", "bmt"},
+	{"bmt", "bmt"},
+	{"lung", "lung"},
+	{"lymphoma", "lymphoma"},
+	{"myeloma", "myeloma"},
+	{"neuro", "neuro-oncology"},
+	{"ocular", "ocular"},
+	{"eye", "ocular"},
+	{"sarcoma", "sarcoma"},
+	{"skin", "skin"},
+	{"melanoma", "skin"},
+}
+
+// TumorGroupOf infers the tumor group from a page slug or title.
+func TumorGroupOf(text string) string {
+	text = strings.ToLower(text)
+	for _, k := range tumorGroupKeywords {
+		if strings.Contains(text, k.keyword) {
+			return k.group
+		}
+	}
+	return "unknown"
+}
+
+// codePrefixes are the tumor-group prefixes of BC Cancer protocol codes
+// (LYCHOP, MYBORPRE, ...), used to split combined pages such as
+// lymphoma-myeloma.
+var codePrefixes = []struct {
+	prefix string
+	group  string
+}{
+	{"UBMT", "bmt"},
+	{"BMT", "bmt"},
+	{"BR", "breast"},
+	{"GI", "gastrointestinal"},
+	{"GU", "genitourinary"},
+	{"GO", "gynecology"},
+	{"HN", "head_and_neck"},
+	{"LK", "leukemia"},
+	{"LU", "lung"},
+	{"LY", "lymphoma"},
+	{"MY", "myeloma"},
+	{"CN", "neuro-oncology"},
+	{"SA", "sarcoma"},
+	{"SM", "skin"},
+	{"UP", "unknown_primary"},
+}
+
+// ProtocolTumorGroup prefers the group of the protocol code on the pages
+// that cover two groups (lymphoma-myeloma, leukemia-BMT) and on pages that
+// could not be classified.
+func ProtocolTumorGroup(code, pageGroup string) string {
+	code = strings.ToUpper(code)
+	// "U" marks protocols that need approval (e.g. UBRAJAC)
+	trimmed := strings.TrimPrefix(code, "U")
+	for _, p := range codePrefixes {
+		if strings.HasPrefix(code, p.prefix) || strings.HasPrefix(trimmed, p.prefix) {
+			if pageGroup == "unknown" || pageGroup == "lymphoma" || pageGroup == "myeloma" || pageGroup == "leukemia" || pageGroup == "bmt" {
+				return p.group
+			}
+			break
+		}
+	}
+	return pageGroup
+}
@@ -0,0 +1,156 @@
+package crawler
+
+import (
+	"bcca_crawler/fetch"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type LinkKind string
+
+const (
+	LinkProtocol       LinkKind = "protocol"
+	LinkPPO            LinkKind = "ppo"
+	LinkPatientHandout LinkKind = "patient_handout"
+	LinkOther          LinkKind = "other"
+)
+
+// Link is a classified link found under a protocol heading.
+type Link struct {
+	Text string
+	Href string
+	Kind LinkKind
+}
+
+// Document is a downloaded link.
+type Document struct {
+	URL         string
+	ContentType string
+	Header      http.Header
+	Body        []byte
+	FetchedAt   time.Time
+}
+
+var (
+	ppoPattern     = regexp.MustCompile(`(?i)\bppo\b|pre-?printed\s+order|doctor'?s\s+orders?`)
+	handoutPattern = regexp.MustCompile(`(?i)patient\s+(handout|info|information|education)|\bhandout\b`)
+	protocolFile   = regexp.MustCompile(`(?i)_?protocol\.pdf$`)
+	ppoFile        = regexp.MustCompile(`(?i)_?ppo\.pdf$`)
+	handoutFile    = regexp.MustCompile(`(?i)_?handout\.pdf$`)
+)
+
+// ClassifyLink decides what a protocol link points to from its text, falling
+// back on the BC Cancer file naming (e.g. LYCHOP_PPO.pdf) when the text is
+// not conclusive.
+func ClassifyLink(text, href string) LinkKind {
+	switch {
+	case ppoPattern.MatchString(text):
+		return LinkPPO
+	case handoutPattern.MatchString(text):
+		return LinkPatientHandout
+	case strings.Contains(strings.ToLower(text), "protocol"):
+		return LinkProtocol
+	}
+
+	name := href
+	if u, err := url.Parse(href); err == nil {
+		name = path.Base(u.Path)
+	}
+	switch {
+	case ppoFile.MatchString(name):
+		return LinkPPO
+	case handoutFile.MatchString(name):
+		return LinkPatientHandout
+	case protocolFile.MatchString(name):
+		return LinkProtocol
+	}
+	return LinkOther
+}
+
+// ClassifiedLinks returns the links of a protocol with their kind.
+func (p WebProtocol) ClassifiedLinks() []Link {
+	links := make([]Link, 0, len(p.Links))
+	for _, l := range p.Links {
+		links = append(links, Link{Text: l["text"], Href: l["href"], Kind: ClassifyLink(l["text"], l["href"])})
+	}
+	return links
+}
+
+// Download fetches a linked document with the shared polite fetcher.
+func Download(rawURL string) (Document, error) {
+	resp, err := fetch.Get(context.Background(), rawURL)
+	if err != nil {
+		return Document{}, err
+	}
+	return Document{
+		URL:         resp.URL,
+		ContentType: resp.Header.Get("Content-Type"),
+		Header:      resp.Header,
+		Body:        resp.Body,
+		FetchedAt:   resp.FetchedAt,
+	}, nil
+}
+
+var fileExtension = regexp.MustCompile(`^\.[A-Za-z0-9]{1,8}$`)
+
+// Archive writes a copy of the document to
+// dir/<code>/<kind>/<code>_<kind>_<hash>.<ext> and returns its path. The
+// hash is the start of the SHA-256 of the body, so two documents of a
+// protocol never replace each other, and the code has to be a protocol code,
+// so the path stays inside dir. Files are replaced atomically so a reader
+// never sees a partial copy.
+func Archive(dir, code string, kind LinkKind, doc Document) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !protocolCode.MatchString(code) {
+		return "", fmt.Errorf("cannot archive a document under %q, it is not a protocol code", code)
+	}
+	switch kind {
+	case LinkProtocol, LinkPPO, LinkPatientHandout, LinkOther:
+	default:
+		return "", fmt.Errorf("cannot archive a document of kind %q", kind)
+	}
+
+	ext := ""
+	if u, err := url.Parse(doc.URL); err == nil && fileExtension.MatchString(path.Ext(u.Path)) {
+		ext = strings.ToLower(path.Ext(u.Path))
+	} else if exts, _ := mime.ExtensionsByType(doc.ContentType); len(exts) > 0 {
+		ext = exts[0]
+	}
+	sum := sha256.Sum256(doc.Body)
+	name := fmt.Sprintf("%s_%s_%s%s", code, kind, hex.EncodeToString(sum[:8]), ext)
+
+	folder := filepath.Join(dir, code, string(kind))
+	if err := os.MkdirAll(folder, 0o755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(folder, ".download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(doc.Body); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	target := filepath.Join(folder, name)
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
@@ -0,0 +1,75 @@
+package crawler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArchive(t *testing.T) {
+	dir := t.TempDir()
+	first := Document{URL: RootURL + "/docs/BRAJACT/Protocol.pdf", ContentType: "application/pdf", Body: []byte("first")}
+	second := Document{URL: RootURL + "/other/BRAJACT/Protocol.pdf", ContentType: "application/pdf", Body: []byte("second")}
+
+	a, err := Archive(dir, " brajact ", LinkProtocol, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Archive(dir, "BRAJACT", LinkProtocol, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Fatalf("two documents with the same file name were archived to %s", a)
+	}
+	for path, body := range map[string]string{a: "first", b: "second"} {
+		if filepath.Dir(path) != filepath.Join(dir, "BRAJACT", "protocol") || !strings.HasPrefix(filepath.Base(path), "BRAJACT_protocol_") || filepath.Ext(path) != ".pdf" {
+			t.Errorf("archived to %s", path)
+		}
+		if data, err := os.ReadFile(path); err != nil || string(data) != body {
+			t.Errorf("%s holds %q, %v", path, data, err)
+		}
+	}
+
+	// the same document again replaces its copy
+	if again, err := Archive(dir, "BRAJACT", LinkProtocol, first); err != nil || again != a {
+		t.Errorf("archived again to %s, %v", again, err)
+	}
+
+	// no extension in the URL, it comes from the content type
+	c, err := Archive(dir, "LYCHOP-R", LinkPPO, Document{URL: RootURL + "/download?id=7", ContentType: "application/pdf", Body: []byte("ppo")})
+	if err != nil || filepath.Ext(c) != ".pdf" || filepath.Dir(c) != filepath.Join(dir, "LYCHOP-R", "ppo") {
+		t.Errorf("archived to %s, %v", c, err)
+	}
+
+	for _, code := range []string{"..", "../..", "BRAJACT/..", "", "BR.AJACT", "BR AJACT"} {
+		if path, err := Archive(dir, code, LinkProtocol, first); err == nil {
+			t.Errorf("code %q archived to %s", code, path)
+		}
+	}
+	if path, err := Archive(dir, "BRAJACT", LinkKind("../.."), first); err == nil {
+		t.Errorf("kind ../.. archived to %s", path)
+	}
+}
+
+func TestClassifyLink(t *testing.T) {
+	tests := []struct {
+		text, href string
+		want       LinkKind
+	}{
+		{"BRAJACT PPO", "/x.pdf", LinkPPO},
+		{"Pre-printed Order", "/x.pdf", LinkPPO},
+		{"Patient Handout", "/x.pdf", LinkPatientHandout},
+		{"Protocol", "/x.pdf", LinkProtocol},
+		{"Download", "/docs/LYCHOP_PPO.pdf", LinkPPO},
+		{"Download", "/docs/LYCHOP_Handout.pdf", LinkPatientHandout},
+		{"Download", "/docs/LYCHOP_Protocol.pdf", LinkProtocol},
+		{"Download", "/docs/LYCHOP_Summary.pdf", LinkOther},
+	}
+	for _, tt := range tests {
+		if got := ClassifyLink(tt.text, tt.href); got != tt.want {
+			t.Errorf("ClassifyLink(%q, %q) = %s, want %s", tt.text, tt.href, got, tt.want)
+		}
+	}
+}
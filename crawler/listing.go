@@ -0,0 +1,307 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Listing is the result of parsing a protocol listing page. Strategies
+// names the layouts that produced protocols; Warnings reports structure
+// that looked like a listing but gave no links, which usually means the
+// site layout changed.
+type Listing struct {
+	Protocols  []WebProtocol `json:"protocols"`
+	Strategies []string      `json:"strategies"`
+	Warnings   []string      `json:"warnings"`
+}
+
+// listingStrategy finds protocols in one page layout.
+type listingStrategy struct {
+	name  string
+	parse func(doc *html.Node, p *listingParser)
+}
+
+// listingStrategies are tried in order and merged by code, so a protocol
+// found by an earlier layout is kept. "headings" is the layout the site
+// has used so far: a code heading followed by a description and a list of
+// links.
+var listingStrategies = []listingStrategy{
+	{"headings", parseHeadingSections},
+	{"table", parseTableRows},
+	{"definition_list", parseDefinitionList},
+}
+
+// protocolCode matches BC Cancer protocol codes such as BRAJACT, UGIFOLFOX
+// or LYCHOP-R.
+var protocolCode = regexp.MustCompile(`^U?[A-Z]{2}[A-Z0-9]+(-[A-Z0-9]+)?$`)
+
+type listingParser struct {
+	base     *url.URL
+	found    []WebProtocol
+	warnings []string
+}
+
+// ParseListing reads the protocols of a listing page. Links are resolved
+// against pageURL (or the page's <base> element); an empty pageURL
+// resolves them against the BC Cancer site.
+func ParseListing(htmlBody, pageURL string) (Listing, error) {
+	if pageURL == "" {
+		pageURL = RootURL
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return Listing{}, fmt.Errorf("invalid page URL %s: %w", pageURL, err)
+	}
+	doc, err := html.Parse(strings.NewReader(htmlBody))
+	if err != nil {
+		return Listing{}, err
+	}
+	if href := baseHref(doc); href != "" {
+		if b, err := base.Parse(href); err == nil {
+			base = b
+		}
+	}
+
+	listing := Listing{Protocols: []WebProtocol{}, Strategies: []string{}, Warnings: []string{}}
+	seen := map[string]bool{}
+	for _, s := range listingStrategies {
+		p := &listingParser{base: base}
+		s.parse(doc, p)
+		listing.Warnings = append(listing.Warnings, p.warnings...)
+
+		added := 0
+		for _, wp := range p.found {
+			if seen[wp.Code] {
+				continue
+			}
+			seen[wp.Code] = true
+			listing.Protocols = append(listing.Protocols, wp)
+			added++
+		}
+		if added > 0 {
+			listing.Strategies = append(listing.Strategies, s.name)
+		}
+	}
+
+	switch {
+	case len(listing.Protocols) == 0:
+		listing.Warnings = append(listing.Warnings, "no protocol listings found; the page layout may have changed")
+	case listing.Strategies[0] != listingStrategies[0].name:
+		listing.Warnings = append(listing.Warnings, fmt.Sprintf("no protocol headings found, parsed the page as %s", strings.Join(listing.Strategies, ", ")))
+	}
+	for _, wp := range listing.Protocols {
+		if !hasProtocolLink(wp) {
+			listing.Warnings = append(listing.Warnings, fmt.Sprintf("%s has no protocol document link", wp.Code))
+		}
+	}
+	return listing, nil
+}
+
+// add records a protocol, warning instead when a code was found without
+// links.
+func (p *listingParser) add(code, description string, links []map[string]string) {
+	if len(links) == 0 {
+		p.warnings = append(p.warnings, fmt.Sprintf("%s is listed without links", code))
+		return
+	}
+	p.found = append(p.found, WebProtocol{Code: code, Description: description, Links: links})
+}
+
+// links returns the anchors under the given nodes with their href resolved
+// against the page. Page anchors, mailto: and javascript: links are
+// skipped.
+func (p *listingParser) links(nodes ...*html.Node) []map[string]string {
+	links := []map[string]string{}
+	for _, n := range nodes {
+		for _, a := range findAll(n, "a") {
+			href := strings.TrimSpace(attr(a, "href"))
+			if href == "" || strings.HasPrefix(href, "#") {
+				continue
+			}
+			u, err := p.base.Parse(href)
+			if err != nil {
+				p.warnings = append(p.warnings, fmt.Sprintf("skipped link %q: %v", href, err))
+				continue
+			}
+			if u.Scheme != "http" && u.Scheme != "https" {
+				continue
+			}
+			u.Fragment = ""
+			links = append(links, map[string]string{
+				"text": getTextContent(a),
+				"href": u.String(),
+			})
+		}
+	}
+	return links
+}
+
+// parseHeadingSections reads a heading holding a protocol code and the
+// content up to the next heading: the first paragraph without links is the
+// description and every link is a protocol link. Headings wrapped alone in
+// a container take the content following the container.
+func parseHeadingSections(doc *html.Node, p *listingParser) {
+	for _, h := range findAll(doc, "h2", "h3", "h4", "h5") {
+		code, rest := splitCode(getTextContent(h))
+		if code == "" {
+			continue
+		}
+		start := h
+		if nextElement(h) == nil && h.Parent != nil && h.Parent.Data != "body" {
+			start = h.Parent
+		}
+
+		section := []*html.Node{}
+		for n := nextElement(start); n != nil && !isHeading(n) && !containsHeading(n); n = nextElement(n) {
+			section = append(section, n)
+		}
+
+		description := rest
+	paragraphs:
+		for _, n := range section {
+			for _, para := range findAll(n, "p") {
+				if text := getTextContent(para); text != "" && len(findAll(para, "a")) == 0 {
+					description = text
+					break paragraphs
+				}
+			}
+		}
+		p.add(code, description, p.links(section...))
+	}
+}
+
+// parseTableRows reads rows whose first cell holds a protocol code; the
+// next cell without links is the description.
+func parseTableRows(doc *html.Node, p *listingParser) {
+	for _, tr := range findAll(doc, "tr") {
+		cells := []*html.Node{}
+		for c := tr.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && (c.Data == "td" || c.Data == "th") {
+				cells = append(cells, c)
+			}
+		}
+		if len(cells) < 2 {
+			continue
+		}
+		code, description := splitCode(getTextContent(cells[0]))
+		if code == "" {
+			continue
+		}
+		for _, c := range cells[1:] {
+			if len(findAll(c, "a")) == 0 {
+				if text := getTextContent(c); text != "" {
+					description = text
+					break
+				}
+			}
+		}
+		p.add(code, description, p.links(cells[1:]...))
+	}
+}
+
+// parseDefinitionList reads <dt>CODE</dt> followed by <dd> elements.
+func parseDefinitionList(doc *html.Node, p *listingParser) {
+	for _, dt := range findAll(doc, "dt") {
+		code, description := splitCode(getTextContent(dt))
+		if code == "" {
+			continue
+		}
+		section := []*html.Node{}
+		for n := nextElement(dt); n != nil && n.Data == "dd"; n = nextElement(n) {
+			section = append(section, n)
+			if len(findAll(n, "a")) == 0 && description == "" {
+				description = getTextContent(n)
+			}
+		}
+		p.add(code, description, p.links(section...))
+	}
+}
+
+// splitCode returns the protocol code at the start of a heading, and the
+// rest of the heading without its separator ("BRAJACT - Adjuvant ...").
+func splitCode(text string) (string, string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	code := fields[0]
+	if !protocolCode.MatchString(code) {
+		return "", ""
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), code))
+	rest = strings.TrimSpace(strings.TrimLeft(rest, "-–—:"))
+	return code, rest
+}
+
+func hasProtocolLink(wp WebProtocol) bool {
+	for _, link := range wp.ClassifiedLinks() {
+		if link.Kind == LinkProtocol {
+			return true
+		}
+	}
+	return false
+}
+
+func baseHref(doc *html.Node) string {
+	for _, b := range findAll(doc, "base") {
+		if href := attr(b, "href"); href != "" {
+			return href
+		}
+	}
+	return ""
+}
+
+func findAll(n *html.Node, tags ...string) []*html.Node {
+	found := []*html.Node{}
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for _, tag := range tags {
+				if n.Data == tag {
+					found = append(found, n)
+					break
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(n)
+	return found
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// nextElement skips the whitespace and comments between elements.
+func nextElement(n *html.Node) *html.Node {
+	for n = n.NextSibling; n != nil; n = n.NextSibling {
+		if n.Type == html.ElementNode {
+			return n
+		}
+	}
+	return nil
+}
+
+func isHeading(n *html.Node) bool {
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		return true
+	}
+	return false
+}
+
+func containsHeading(n *html.Node) bool {
+	return len(findAll(n, "h1", "h2", "h3", "h4", "h5", "h6")) > 0
+}
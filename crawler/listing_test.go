@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the listing tests")
+
+// listingPageURL is where the saved pages are parsed as if fetched from.
+const listingPageURL = RootURL + "/breast"
+
+// TestParseListingGolden parses the saved listing pages in
+// testdata/listings and compares the result with the .golden.json next to
+// each page. Run with -update after an intended parser change.
+func TestParseListingGolden(t *testing.T) {
+	pages, err := filepath.Glob("testdata/listings/*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) == 0 {
+		t.Fatal("no saved listing pages found")
+	}
+
+	for _, page := range pages {
+		name := strings.TrimSuffix(filepath.Base(page), ".html")
+		t.Run(name, func(t *testing.T) {
+			body, err := os.ReadFile(page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			listing, err := ParseListing(string(body), listingPageURL)
+			if err != nil {
+				t.Fatalf("parsing %s: %v", page, err)
+			}
+			got, err := json.MarshalIndent(listing, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := strings.TrimSuffix(page, ".html") + ".golden.json"
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("reading golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s does not match %s\ngot:\n%s", page, golden, got)
+			}
+		})
+	}
+}
+
+func TestSplitCode(t *testing.T) {
+	tests := []struct {
+		text, code, rest string
+	}{
+		{"BRAJACT", "BRAJACT", ""},
+		{"  UGIFOLFOX ", "UGIFOLFOX", ""},
+		{"LYCHOP-R", "LYCHOP-R", ""},
+		{"MYBORPRE - Myeloma Therapy", "MYBORPRE", "Myeloma Therapy"},
+		{"CAUTIONS:", "", ""},
+		{"Adjuvant Therapy", "", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		code, rest := splitCode(tt.text)
+		if code != tt.code || rest != tt.rest {
+			t.Errorf("splitCode(%q) = %q, %q; want %q, %q", tt.text, code, rest, tt.code, tt.rest)
+		}
+	}
+}
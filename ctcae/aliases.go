@@ -0,0 +1,52 @@
+package ctcae
+
+// aliases are names protocols and the model use for a CTCAE term: older
+// words, British spellings and the toxicity a lab value stands for. Only
+// names that mean one term are listed; "hepatotoxicity" could be several.
+var aliases = map[string]string{
+	"thrombocytopenia":                  "Platelet count decreased",
+	"thrombopenia":                      "Platelet count decreased",
+	"low platelets":                     "Platelet count decreased",
+	"neutropenia":                       "Neutrophil count decreased",
+	"granulocytopenia":                  "Neutrophil count decreased",
+	"leukopenia":                        "White blood cell decreased",
+	"leucopenia":                        "White blood cell decreased",
+	"lymphopenia":                       "Lymphocyte count decreased",
+	"anaemia":                           "Anemia",
+	"febrile neutropaenia":              "Febrile neutropenia",
+	"neuropathy":                        "Peripheral sensory neuropathy",
+	"peripheral neuropathy":             "Peripheral sensory neuropathy",
+	"sensory neuropathy":                "Peripheral sensory neuropathy",
+	"motor neuropathy":                  "Peripheral motor neuropathy",
+	"diarrhoea":                         "Diarrhea",
+	"mucositis":                         "Mucositis oral",
+	"stomatitis":                        "Mucositis oral",
+	"oral mucositis":                    "Mucositis oral",
+	"oesophagitis":                      "Esophagitis",
+	"hand-foot syndrome":                "Palmar-plantar erythrodysesthesia syndrome",
+	"hand foot syndrome":                "Palmar-plantar erythrodysesthesia syndrome",
+	"hand-foot skin reaction":           "Palmar-plantar erythrodysesthesia syndrome",
+	"palmar-plantar erythrodysesthesia": "Palmar-plantar erythrodysesthesia syndrome",
+	"rash":                              "Rash maculo-papular",
+	"infusion reaction":                 "Infusion related reaction",
+	"infusion-related reaction":         "Infusion related reaction",
+	"hypersensitivity":                  "Allergic reaction",
+	"hypersensitivity reaction":         "Allergic reaction",
+	"hyperbilirubinemia":                "Blood bilirubin increased",
+	"hyperbilirubinaemia":               "Blood bilirubin increased",
+	"elevated bilirubin":                "Blood bilirubin increased",
+	"bilirubin increased":               "Blood bilirubin increased",
+	"alt increased":                     "Alanine aminotransferase increased",
+	"elevated alt":                      "Alanine aminotransferase increased",
+	"ast increased":                     "Aspartate aminotransferase increased",
+	"elevated ast":                      "Aspartate aminotransferase increased",
+	"elevated creatinine":               "Creatinine increased",
+	"serum creatinine increased":        "Creatinine increased",
+	"ototoxicity":                       "Hearing impaired",
+	"hearing loss":                      "Hearing impaired",
+	"high blood pressure":               "Hypertension",
+	"hair loss":                         "Alopecia",
+	"tiredness":                         "Fatigue",
+	"loss of appetite":                  "Anorexia",
+	"extravasation":                     "Infusion site extravasation",
+}
@@ -0,0 +1,238 @@
+// Package ctcae maps extracted toxicities onto the terms of the Common
+// Terminology Criteria for Adverse Events v5. The term list is imported from
+// the NCI spreadsheet with its MedDRA codes, system organ classes and grade
+// definitions; Resolve titles a toxicity with the term it names and gives its
+// grades the dictionary wording instead of the model's.
+package ctcae
+
+import (
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Version is stored with a term whose file has no version column.
+const Version = "5.0"
+
+// Term is one row of the CTCAE spreadsheet. Grades holds grades 1 to 5, ""
+// for a grade the term does not have.
+type Term struct {
+	MeddraCode       string
+	SOC              string
+	Term             string
+	Definition       string
+	NavigationalNote string
+	Version          string
+	Grades           [5]string
+}
+
+// categories maps a MedDRA system organ class onto the toxicity categories.
+// "Investigations" and "General disorders" are left out: their terms belong
+// to several categories, and the model's category is kept.
+var categories = map[string]string{
+	"blood and lymphatic system disorders":            "Hematologic",
+	"nervous system disorders":                        "Neurologic",
+	"gastrointestinal disorders":                      "Gastrointestinal",
+	"skin and subcutaneous tissue disorders":          "Dermatologic",
+	"hepatobiliary disorders":                         "Hepatic",
+	"renal and urinary disorders":                     "Renal",
+	"respiratory, thoracic and mediastinal disorders": "Pulmonary",
+	"cardiac disorders":                               "Cardiovascular",
+	"vascular disorders":                              "Cardiovascular",
+	"endocrine disorders":                             "Endocrine",
+	"metabolism and nutrition disorders":              "Metabolic",
+	"immune system disorders":                         "Immune",
+}
+
+// Normalize lower-cases a name and collapses its whitespace.
+func Normalize(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// Names returns the lower-cased terms a toxicity title may stand for: the
+// title itself first, then the term it is an alias of.
+func Names(title string) []string {
+	n := Normalize(title)
+	names := []string{n}
+	if term, ok := aliases[n]; ok && Normalize(term) != n {
+		names = append(names, Normalize(term))
+	}
+	return names
+}
+
+// Category returns the toxicity category of a system organ class, or "" when
+// it has none.
+func Category(soc string) string {
+	return categories[Normalize(soc)]
+}
+
+// Lookup returns the term a toxicity title names.
+func Lookup(c *config.Config, ctx context.Context, title string) (database.CtcaeTerm, bool, error) {
+	names := Names(title)
+	found, err := c.Db.FindCTCAETermsByNames(ctx, names)
+	if err != nil {
+		return database.CtcaeTerm{}, false, fmt.Errorf("error finding ctcae term: %s, with error: %v", title, err)
+	}
+	for _, name := range names {
+		for _, term := range found {
+			if Normalize(term.Term) == name {
+				return term, true, nil
+			}
+		}
+	}
+	return database.CtcaeTerm{}, false, nil
+}
+
+// Incoming is a toxicity as an extraction names it.
+type Incoming struct {
+	Title       string
+	Description string
+	Category    string
+}
+
+// Resolved is the stored toxicity an extracted one maps to. Grades holds the
+// dictionary wording of grades 1 to 4, empty when the title names no term.
+type Resolved struct {
+	Toxicity database.Toxicity
+	Grades   map[database.GradeEnum]string
+}
+
+// Resolve returns the stored toxicity an extracted title refers to, creating
+// it when there is none. A title that names a CTCAE term is replaced by the
+// term, with its definition and category, and the grades of the toxicity are
+// filled in from the dictionary. Other titles are stored as the model wrote
+// them.
+func Resolve(c *config.Config, ctx context.Context, in Incoming) (Resolved, error) {
+	title := strings.TrimSpace(in.Title)
+	if title == "" {
+		return Resolved{}, fmt.Errorf("toxicity has no title")
+	}
+	term, known, err := Lookup(c, ctx, title)
+	if err != nil {
+		return Resolved{}, err
+	}
+
+	params := database.AddToxicityParams{
+		Title:       title,
+		Description: in.Description,
+		Category:    in.Category,
+	}
+	if known {
+		params.Title = term.Term
+		if term.Definition != "" {
+			params.Description = term.Definition
+		}
+		if category := Category(term.Soc); category != "" {
+			params.Category = category
+		}
+		params.CtcaeTermID = uuid.NullUUID{UUID: term.ID, Valid: true}
+	}
+	tox, err := c.Db.AddToxicity(ctx, params)
+	if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
+		// created by an earlier extraction
+		tox, err = c.Db.GetToxicityByName(ctx, params.Title)
+	}
+	if err != nil {
+		return Resolved{}, fmt.Errorf("error creating toxicity: %s, with error: %v", params.Title, err)
+	}
+	resolved := Resolved{Toxicity: tox, Grades: map[database.GradeEnum]string{}}
+	if !known {
+		return resolved, nil
+	}
+
+	if !tox.CtcaeTermID.Valid {
+		err := c.Db.LinkToxicityToCTCAETerm(ctx, database.LinkToxicityToCTCAETermParams{CtcaeTermID: params.CtcaeTermID, ID: tox.ID})
+		if err != nil {
+			return resolved, fmt.Errorf("error linking toxicity: %s, with error: %v", tox.Title, err)
+		}
+		resolved.Toxicity.CtcaeTermID = params.CtcaeTermID
+	}
+	grades, err := c.Db.GetCTCAEGrades(ctx, term.ID)
+	if err != nil {
+		return resolved, fmt.Errorf("error getting ctcae grades: %s, with error: %v", term.Term, err)
+	}
+	for _, g := range grades {
+		// toxicity grades stop at 4
+		if g.Grade < 1 || g.Grade > 4 {
+			continue
+		}
+		grade := database.GradeEnum(strconv.Itoa(int(g.Grade)))
+		_, err := c.Db.SetToxicityGradeDescription(ctx, database.SetToxicityGradeDescriptionParams{
+			Grade:       grade,
+			Description: g.Description,
+			ToxicityID:  tox.ID,
+		})
+		if err != nil {
+			return resolved, fmt.Errorf("error setting grade %s of: %s, with error: %v", grade, tox.Title, err)
+		}
+		resolved.Grades[grade] = g.Description
+	}
+	return resolved, nil
+}
+
+// ImportResult counts what an import changed.
+type ImportResult struct {
+	Terms  int
+	Linked int64
+	Grades int64
+}
+
+// Import saves the terms in one transaction, replacing the grades of a term
+// already imported. Toxicities titled with a term are then linked to it and
+// given its grade wording; a toxicity stored under an alias keeps its title
+// until its protocol is extracted again.
+func Import(c *config.Config, ctx context.Context, terms []Term) (ImportResult, error) {
+	result := ImportResult{}
+	tx, err := c.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+	q := c.Db.WithTx(tx)
+
+	for _, t := range terms {
+		saved, err := q.UpsertCTCAETerm(ctx, database.UpsertCTCAETermParams{
+			MeddraCode:       t.MeddraCode,
+			Term:             t.Term,
+			Soc:              t.SOC,
+			Definition:       t.Definition,
+			NavigationalNote: t.NavigationalNote,
+			Version:          t.Version,
+		})
+		if err != nil {
+			return result, fmt.Errorf("error saving ctcae term: %s, with error: %v", t.Term, err)
+		}
+		if err := q.DeleteCTCAEGrades(ctx, saved.ID); err != nil {
+			return result, fmt.Errorf("error replacing grades of: %s, with error: %v", t.Term, err)
+		}
+		grades, descriptions := []int16{}, []string{}
+		for i, d := range t.Grades {
+			if d != "" {
+				grades = append(grades, int16(i+1))
+				descriptions = append(descriptions, d)
+			}
+		}
+		err = q.AddCTCAEGrades(ctx, database.AddCTCAEGradesParams{TermID: saved.ID, Grades: grades, Descriptions: descriptions})
+		if err != nil {
+			return result, fmt.Errorf("error saving grades of: %s, with error: %v", t.Term, err)
+		}
+		result.Terms++
+	}
+
+	if result.Linked, err = q.LinkToxicitiesToCTCAETerms(ctx); err != nil {
+		return result, fmt.Errorf("error linking toxicities: %v", err)
+	}
+	if result.Grades, err = q.SyncToxicityGradeDescriptions(ctx); err != nil {
+		return result, fmt.Errorf("error updating toxicity grades: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("error committing import: %v", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,111 @@
+package ctcae
+
+import (
+	"archive/zip"
+	"bcca_crawler/api"
+	"bytes"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const sample = `MedDRA Code,MedDRA SOC,CTCAE Term,Grade 1,Grade 2,Grade 3,Grade 4,Grade 5,Definition,Navigational Note
+10002272,Blood and lymphatic system disorders,Anemia,Hemoglobin (Hgb) <LLN - 10.0 g/dL,Hgb <10.0 - 8.0 g/dL,Hgb <8.0 g/dL; transfusion indicated,Life-threatening consequences; urgent intervention indicated,Death,A disorder characterized by a reduction in the amount of hemoglobin.,
+10035528,Investigations,  Platelet   count decreased,<LLN - 75000/mm3,<75000 - 50000/mm3,<50000 - 25000/mm3,<25000/mm3,-,A finding based on laboratory test results.,
+,Investigations,No code,-,-,-,-,-,,
+`
+
+func TestParseCSV(t *testing.T) {
+	terms, err := ParseCSV(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(terms) != 2 {
+		t.Fatalf("got %d terms, want 2", len(terms))
+	}
+	anemia := terms[0]
+	if anemia.MeddraCode != "10002272" || anemia.SOC != "Blood and lymphatic system disorders" || anemia.Version != Version {
+		t.Errorf("anemia = %+v", anemia)
+	}
+	if anemia.Grades[4] != "Death" {
+		t.Errorf("grade 5 = %q", anemia.Grades[4])
+	}
+	platelets := terms[1]
+	if platelets.Term != "Platelet count decreased" {
+		t.Errorf("term = %q, want the spacing collapsed", platelets.Term)
+	}
+	if platelets.Grades[3] != "<25000/mm3" || platelets.Grades[4] != "" {
+		t.Errorf("grades = %q", platelets.Grades)
+	}
+}
+
+func TestParseCSVHeader(t *testing.T) {
+	if _, err := ParseCSV(strings.NewReader("SOC,Grade 1\nx,y\n")); err == nil {
+		t.Error("parsed a file without code and term columns")
+	}
+}
+
+func TestParseXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/sharedStrings.xml": `<sst><si><t>MedDRA Code</t></si><si><t>CTCAE Term</t></si><si><r><t>Grade </t></r><r><t>1</t></r></si><si><t>Nausea</t></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>
+			<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="s"><v>2</v></c></row>
+			<row r="2"><c r="A2"><v>10028813</v></c><c r="B2" t="s"><v>3</v></c><c r="D2" t="inlineStr"><is><t>Loss of appetite</t></is></c></row>
+		</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terms, err := ParseXLSX(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Term{MeddraCode: "10028813", Term: "Nausea", Version: Version}
+	want.Grades[0] = "Loss of appetite"
+	if len(terms) != 1 || !reflect.DeepEqual(terms[0], want) {
+		t.Errorf("terms = %+v, want %+v", terms, want)
+	}
+}
+
+func TestColumn(t *testing.T) {
+	for ref, want := range map[string]int{"A1": 0, "J20": 9, "AA3": 26, "": -1} {
+		if got := column(ref); got != want {
+			t.Errorf("column(%q) = %d, want %d", ref, got, want)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	if got, want := Names(" Thrombocytopenia "), []string{"thrombocytopenia", "platelet count decreased"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names = %v, want %v", got, want)
+	}
+	if got, want := Names("Nausea"), []string{"nausea"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names = %v, want %v", got, want)
+	}
+}
+
+func TestCategories(t *testing.T) {
+	for soc, category := range categories {
+		if !slices.Contains(api.ToxicityCategories, category) {
+			t.Errorf("%s maps to %s, not a toxicity category", soc, category)
+		}
+	}
+	if got := Category("Investigations"); got != "" {
+		t.Errorf("Category(Investigations) = %q, want the model's kept", got)
+	}
+}
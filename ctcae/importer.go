@@ -0,0 +1,247 @@
+package ctcae
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// column aliases accepted in the header row, after lower-casing and
+// replacing everything but letters and digits with "_"
+var columns = map[string][]string{
+	"meddra_code":       {"meddra_code", "meddra_code_v20_1", "meddra_llt_code", "code"},
+	"soc":               {"meddra_soc", "soc", "system_organ_class"},
+	"term":              {"ctcae_term", "ctcae_v5_0_term", "term"},
+	"grade_1":           {"grade_1"},
+	"grade_2":           {"grade_2"},
+	"grade_3":           {"grade_3"},
+	"grade_4":           {"grade_4"},
+	"grade_5":           {"grade_5"},
+	"definition":        {"definition"},
+	"navigational_note": {"navigational_note", "note"},
+	"version":           {"version", "ctcae_version"},
+}
+
+// LoadFile reads the CTCAE term list, choosing the format from the file
+// extension: the NCI spreadsheet (.xlsx) or a CSV export of it.
+func LoadFile(path string) ([]Term, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ParseCSV(f)
+	case ".xlsx":
+		r, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ParseXLSX(&r.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported ctcae file: %s, expected .xlsx or .csv", path)
+	}
+}
+
+// ParseCSV reads a CSV file with a header row.
+func ParseCSV(r io.Reader) ([]Term, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading csv: %w", err)
+	}
+	return parseRows(rows)
+}
+
+// ParseXLSX reads the first worksheet of a spreadsheet, whose first row is
+// the header.
+func ParseXLSX(r *zip.Reader) ([]Term, error) {
+	var shared struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if err := readXML(r, "xl/sharedStrings.xml", &shared); err != nil && err != errNoPart {
+		return nil, err
+	}
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := readXML(r, "xl/worksheets/sheet1.xml", &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, xr := range sheet.Rows {
+		var row []string
+		for i, c := range xr.Cells {
+			col := column(c.Ref)
+			if col < 0 {
+				col = i
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, fmt.Errorf("cell %s refers to a missing shared string: %s", c.Ref, c.Value)
+				}
+				row[col] = shared.Items[n].String()
+			case "inlineStr":
+				row[col] = c.Inline.String()
+			default:
+				row[col] = c.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return parseRows(rows)
+}
+
+// xlsxText is a string of a spreadsheet, plain or split in formatted runs.
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+var errNoPart = errors.New("missing spreadsheet part")
+
+func readXML(r *zip.Reader, name string, v any) error {
+	f, err := r.Open(name)
+	if err != nil {
+		return errNoPart
+	}
+	defer f.Close()
+	if err := xml.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("error reading %s: %w", name, err)
+	}
+	return nil
+}
+
+// column returns the zero based column of a cell reference such as "C12".
+func column(ref string) int {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return -1
+	}
+	return col - 1
+}
+
+func parseRows(rows [][]string) ([]Term, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("ctcae file is empty")
+	}
+	position := map[string]int{}
+	for i, h := range rows[0] {
+		if field := field(h); field != "" {
+			if _, ok := position[field]; !ok {
+				position[field] = i
+			}
+		}
+	}
+	for _, required := range []string{"meddra_code", "term"} {
+		if _, ok := position[required]; !ok {
+			return nil, fmt.Errorf("ctcae header has no %s column", required)
+		}
+	}
+
+	terms := []Term{}
+	for _, row := range rows[1:] {
+		get := func(name string) string {
+			i, ok := position[name]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+		t := Term{
+			MeddraCode:       get("meddra_code"),
+			SOC:              get("soc"),
+			Term:             strings.Join(strings.Fields(get("term")), " "),
+			Definition:       get("definition"),
+			NavigationalNote: get("navigational_note"),
+			Version:          get("version"),
+		}
+		if t.MeddraCode == "" || t.Term == "" {
+			continue
+		}
+		if t.Version == "" {
+			t.Version = Version
+		}
+		for g := range t.Grades {
+			t.Grades[g] = gradeText(get(fmt.Sprintf("grade_%d", g+1)))
+		}
+		terms = append(terms, t)
+	}
+	return terms, nil
+}
+
+// gradeText drops the dash the spreadsheet puts in a grade a term does not
+// have.
+func gradeText(s string) string {
+	switch s {
+	case "-", "–", "—":
+		return ""
+	}
+	return s
+}
+
+func field(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.Trim(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name), "_")
+	for strings.Contains(name, "__") {
+		name = strings.ReplaceAll(name, "__", "_")
+	}
+	for field, aliases := range columns {
+		for _, alias := range aliases {
+			if alias == name {
+				return field
+			}
+		}
+	}
+	return ""
+}
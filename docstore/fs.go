@@ -0,0 +1,100 @@
+package docstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FS stores documents on disk as dir/ab/abcd... with the metadata in a
+// .json file next to the content.
+type FS struct {
+	dir string
+}
+
+func NewFS(dir string) *FS {
+	return &FS{dir: dir}
+}
+
+func (s *FS) path(hash string) string {
+	return filepath.Join(s.dir, hash[:2], hash)
+}
+
+func (s *FS) Put(ctx context.Context, body []byte, meta Meta) (Meta, error) {
+	meta = fill(body, meta)
+	target := s.path(meta.Hash)
+
+	if existing, err := s.readMeta(meta.Hash); err == nil {
+		return existing, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return Meta{}, err
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return Meta{}, err
+	}
+	// content first, so a metadata file always points at a complete copy
+	if err := writeAtomic(target, body); err != nil {
+		return Meta{}, err
+	}
+	if err := writeAtomic(target+".json", data); err != nil {
+		return Meta{}, err
+	}
+	return meta, nil
+}
+
+func (s *FS) Open(ctx context.Context, hash string) (io.ReadCloser, Meta, error) {
+	if !ValidHash(hash) {
+		return nil, Meta{}, ErrNotFound
+	}
+	meta, err := s.readMeta(hash)
+	if err != nil {
+		return nil, Meta{}, err
+	}
+	f, err := os.Open(s.path(hash))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, Meta{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, Meta{}, err
+	}
+	return f, meta, nil
+}
+
+func (s *FS) readMeta(hash string) (Meta, error) {
+	data, err := os.ReadFile(s.path(hash) + ".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return Meta{}, ErrNotFound
+	}
+	if err != nil {
+		return Meta{}, err
+	}
+	var meta Meta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return Meta{}, fmt.Errorf("invalid metadata for document %s: %w", hash, err)
+	}
+	return meta, nil
+}
+
+func writeAtomic(target string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
@@ -0,0 +1,225 @@
+package docstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// S3Config points at an S3 compatible bucket (AWS, MinIO, R2...). Objects
+// are addressed path style: Endpoint/Bucket/Prefix/ab/abcd...
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3 stores documents in a bucket, signing requests with AWS Signature
+// Version 4.
+type S3 struct {
+	cfg    S3Config
+	client *http.Client
+}
+
+var bucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// Validate checks the settings a bucket can be reached with: an http(s)
+// endpoint without a path, a valid bucket name and both keys.
+func (cfg S3Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint is required")
+	}
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("endpoint %q is not an http or https URL", cfg.Endpoint)
+	}
+	if strings.Trim(u.Path, "/") != "" || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("endpoint %q must not have a path or query, the bucket is set on its own", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" {
+		return errors.New("bucket is required")
+	}
+	if !bucketName.MatchString(cfg.Bucket) || strings.Contains(cfg.Bucket, "..") {
+		return fmt.Errorf("bucket %q is not a valid bucket name", cfg.Bucket)
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return errors.New("access key and secret key are required")
+	}
+	return nil
+}
+
+func NewS3(cfg S3Config) *S3 {
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+	return &S3{cfg: cfg, client: &http.Client{Timeout: 60 * time.Second}}
+}
+
+func (s *S3) key(hash string) string {
+	key := hash[:2] + "/" + hash
+	if s.cfg.Prefix != "" {
+		key = s.cfg.Prefix + "/" + key
+	}
+	return key
+}
+
+func (s *S3) Put(ctx context.Context, body []byte, meta Meta) (Meta, error) {
+	meta = fill(body, meta)
+	key := s.key(meta.Hash)
+
+	if existing, err := s.readMeta(ctx, key); err == nil {
+		return existing, nil
+	} else if err != ErrNotFound {
+		return Meta{}, err
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return Meta{}, err
+	}
+	if err := s.put(ctx, key, body, meta.ContentType); err != nil {
+		return Meta{}, err
+	}
+	if err := s.put(ctx, key+".json", data, "application/json"); err != nil {
+		return Meta{}, err
+	}
+	return meta, nil
+}
+
+func (s *S3) Open(ctx context.Context, hash string) (io.ReadCloser, Meta, error) {
+	if !ValidHash(hash) {
+		return nil, Meta{}, ErrNotFound
+	}
+	key := s.key(hash)
+	meta, err := s.readMeta(ctx, key)
+	if err != nil {
+		return nil, Meta{}, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, Meta{}, err
+	}
+	return resp.Body, meta, nil
+}
+
+func (s *S3) readMeta(ctx context.Context, key string) (Meta, error) {
+	resp, err := s.do(ctx, http.MethodGet, key+".json", nil, "")
+	if err != nil {
+		return Meta{}, err
+	}
+	defer resp.Body.Close()
+	var meta Meta
+	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return Meta{}, fmt.Errorf("invalid metadata for document %s: %w", key, err)
+	}
+	return meta, nil
+}
+
+func (s *S3) put(ctx context.Context, key string, body []byte, contentType string) error {
+	resp, err := s.do(ctx, http.MethodPut, key, body, contentType)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends a signed request and turns 404 into ErrNotFound and any other
+// non 2xx status into an error.
+func (s *S3) do(ctx context.Context, method, key string, body []byte, contentType string) (*http.Response, error) {
+	path := "/" + uriEncode(s.cfg.Bucket) + "/" + uriEncode(key)
+	req, err := http.NewRequestWithContext(ctx, method, s.cfg.Endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, path, body, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("s3 %s %s: %s: %s", method, key, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+func (s *S3) sign(req *http.Request, path string, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := Hash(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		"",
+		canonicalHeaders,
+		strings.Join(signed, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + Hash([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), date)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, scope, strings.Join(signed, ";"), signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uriEncode escapes every character except the unreserved ones, keeping
+// the slashes between key segments, as the signature requires.
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package docstore
+
+import "testing"
+
+func TestS3ConfigValidate(t *testing.T) {
+	valid := S3Config{Endpoint: "https://s3.ca-central-1.amazonaws.com", Bucket: "bcca-documents", AccessKey: "key", SecretKey: "secret"}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+	tests := []struct {
+		name   string
+		change func(c *S3Config)
+	}{
+		{"no endpoint", func(c *S3Config) { c.Endpoint = "" }},
+		{"no scheme", func(c *S3Config) { c.Endpoint = "s3.amazonaws.com" }},
+		{"ftp endpoint", func(c *S3Config) { c.Endpoint = "ftp://s3.amazonaws.com" }},
+		{"no host", func(c *S3Config) { c.Endpoint = "https://" }},
+		{"bucket in the endpoint", func(c *S3Config) { c.Endpoint = "https://s3.amazonaws.com/bcca-documents" }},
+		{"no bucket", func(c *S3Config) { c.Bucket = "" }},
+		{"upper case bucket", func(c *S3Config) { c.Bucket = "BCCA" }},
+		{"short bucket", func(c *S3Config) { c.Bucket = "ab" }},
+		{"bucket with a slash", func(c *S3Config) { c.Bucket = "bcca/documents" }},
+		{"bucket with two dots", func(c *S3Config) { c.Bucket = "bcca..documents" }},
+		{"no access key", func(c *S3Config) { c.AccessKey = "" }},
+		{"no secret key", func(c *S3Config) { c.SecretKey = "" }},
+	}
+	for _, tt := range tests {
+		c := valid
+		tt.change(&c)
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+	local := valid
+	local.Endpoint = "http://localhost:9000/"
+	if err := local.Validate(); err != nil {
+		t.Errorf("MinIO endpoint: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+// Package docstore keeps every downloaded source document, addressed by the
+// SHA-256 of its content, so extracted data can be traced back to the exact
+// file it came from.
+package docstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+var ErrNotFound = errors.New("document not found")
+
+// Meta describes a stored document. Headers are the HTTP response headers
+// it was served with.
+type Meta struct {
+	Hash        string      `json:"hash"`
+	URL         string      `json:"url"`
+	ContentType string      `json:"content_type"`
+	Size        int64       `json:"size"`
+	FetchedAt   time.Time   `json:"fetched_at"`
+	Headers     http.Header `json:"headers"`
+}
+
+// Store saves documents under their hash. Putting the same content twice
+// keeps the first copy.
+type Store interface {
+	Put(ctx context.Context, body []byte, meta Meta) (Meta, error)
+	Open(ctx context.Context, hash string) (io.ReadCloser, Meta, error)
+}
+
+// Hash returns the hex SHA-256 of body.
+func Hash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidHash reports whether s is a lower case hex SHA-256.
+func ValidHash(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// fill completes the content derived fields of meta.
+func fill(body []byte, meta Meta) Meta {
+	meta.Hash = Hash(body)
+	meta.Size = int64(len(body))
+	if meta.ContentType == "" {
+		meta.ContentType = meta.Headers.Get("Content-Type")
+	}
+	if meta.ContentType == "" {
+		meta.ContentType = http.DetectContentType(body)
+	}
+	if meta.FetchedAt.IsZero() {
+		meta.FetchedAt = time.Now().UTC()
+	}
+	return meta
+}
@@ -0,0 +1,341 @@
+package dosing
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusWarning  Status = "warning"
+	StatusExceeded Status = "exceeded"
+)
+
+// MedicationLimit is a per-medication lifetime limit and its optional
+// membership in an equivalence class (e.g. doxorubicin-equivalents).
+type MedicationLimit struct {
+	MedicationID      string
+	Name              string
+	AlternateNames    []string
+	MaxDose           float64
+	Basis             Basis
+	WarningThreshold  float64
+	ClassID           string
+	EquivalenceFactor float64
+}
+
+// ClassLimit is a lifetime limit shared by every medication of a class.
+type ClassLimit struct {
+	ID               string
+	Name             string
+	ReferenceDrug    string
+	MaxDose          float64
+	Basis            Basis
+	WarningThreshold float64
+}
+
+// Exposure is a dose a patient has already received (or will receive).
+type Exposure struct {
+	MedicationID string  `json:"medication_id"`
+	Name         string  `json:"medication_name"`
+	Amount       float64 `json:"amount"`
+	Basis        Basis   `json:"dose_basis"`
+}
+
+// PlannedCycle is the list of doses administered during one cycle.
+type PlannedCycle struct {
+	Cycle int        `json:"cycle"`
+	Doses []Exposure `json:"doses"`
+}
+
+type TrackedDose struct {
+	Name           string  `json:"name"`
+	Kind           string  `json:"kind"`
+	DoseBasis      Basis   `json:"dose_basis"`
+	MaxDose        float64 `json:"max_cumulative_dose"`
+	CycleDose      float64 `json:"cycle_dose"`
+	Cumulative     float64 `json:"cumulative_dose"`
+	PercentOfLimit float64 `json:"percent_of_limit"`
+	Status         Status  `json:"status"`
+}
+
+type CycleProjection struct {
+	Cycle int           `json:"cycle"`
+	Doses []TrackedDose `json:"doses"`
+}
+
+type Warning struct {
+	Cycle   int    `json:"cycle"`
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+}
+
+type Projection struct {
+	Prior         []TrackedDose     `json:"prior_exposure"`
+	Cycles        []CycleProjection `json:"cycles"`
+	Warnings      []Warning         `json:"warnings"`
+	MaxSafeCycles int               `json:"max_safe_cycles"`
+	Unresolved    []string          `json:"unresolved"`
+}
+
+type tracker struct {
+	name      string
+	kind      string
+	basis     Basis
+	max       float64
+	threshold float64
+	cycle     float64
+	total     float64
+	status    Status
+}
+
+func (t *tracker) snapshot() TrackedDose {
+	percent := 0.0
+	if t.max > 0 {
+		percent = math.Round(t.total/t.max*1000) / 10
+	}
+	return TrackedDose{
+		Name:           t.name,
+		Kind:           t.kind,
+		DoseBasis:      t.basis,
+		MaxDose:        t.max,
+		CycleDose:      round(t.cycle),
+		Cumulative:     round(t.total),
+		PercentOfLimit: percent,
+		Status:         t.status,
+	}
+}
+
+func (t *tracker) evaluate() Status {
+	threshold := t.threshold
+	if threshold <= 0 || threshold > 1 {
+		threshold = 0.8
+	}
+	switch {
+	case t.total > t.max:
+		return StatusExceeded
+	case t.total >= t.max*threshold:
+		return StatusWarning
+	default:
+		return StatusOK
+	}
+}
+
+type projector struct {
+	patient    Patient
+	meds       []MedicationLimit
+	trackers   map[string]*tracker
+	order      []string
+	unresolved []string
+}
+
+// Project walks the planned cycles after adding the patient's prior exposure
+// and reports, per cycle, the cumulative dose against every applicable limit.
+func Project(plan []PlannedCycle, prior []Exposure, meds []MedicationLimit, classes []ClassLimit, p Patient) Projection {
+	pr := &projector{
+		patient:  p,
+		meds:     meds,
+		trackers: map[string]*tracker{},
+	}
+
+	for _, m := range meds {
+		if m.MaxDose > 0 {
+			pr.track("medication:"+m.MedicationID, m.Name, "medication", m.Basis, m.MaxDose, m.WarningThreshold)
+		}
+	}
+	for _, c := range classes {
+		if c.MaxDose > 0 {
+			name := c.Name
+			if c.ReferenceDrug != "" {
+				name = fmt.Sprintf("%s (%s-equivalent)", c.Name, c.ReferenceDrug)
+			}
+			pr.track("class:"+c.ID, name, "class", c.Basis, c.MaxDose, c.WarningThreshold)
+		}
+	}
+
+	projection := Projection{
+		Cycles:        []CycleProjection{},
+		Warnings:      []Warning{},
+		MaxSafeCycles: len(plan),
+		Unresolved:    []string{},
+	}
+
+	for _, e := range prior {
+		pr.add(e, "prior exposure")
+	}
+	projection.Prior = pr.close(0, &projection)
+
+	exceeded := false
+	for i, cycle := range plan {
+		for _, e := range cycle.Doses {
+			pr.add(e, fmt.Sprintf("cycle %d", cycle.Cycle))
+		}
+		doses := pr.close(cycle.Cycle, &projection)
+		projection.Cycles = append(projection.Cycles, CycleProjection{Cycle: cycle.Cycle, Doses: doses})
+
+		if !exceeded {
+			for _, d := range doses {
+				if d.Status == StatusExceeded {
+					exceeded = true
+					projection.MaxSafeCycles = i
+					break
+				}
+			}
+		}
+	}
+
+	projection.Unresolved = append(projection.Unresolved, pr.unresolved...)
+	return projection
+}
+
+func (pr *projector) track(key, name, kind string, basis Basis, max, threshold float64) {
+	if _, ok := pr.trackers[key]; ok {
+		return
+	}
+	pr.trackers[key] = &tracker{name: name, kind: kind, basis: basis, max: max, threshold: threshold, status: StatusOK}
+	pr.order = append(pr.order, key)
+}
+
+func (pr *projector) find(e Exposure) (MedicationLimit, bool) {
+	for _, m := range pr.meds {
+		if e.MedicationID != "" && e.MedicationID == m.MedicationID {
+			return m, true
+		}
+	}
+	name := strings.ToLower(strings.TrimSpace(e.Name))
+	if name == "" {
+		return MedicationLimit{}, false
+	}
+	for _, m := range pr.meds {
+		if strings.ToLower(m.Name) == name {
+			return m, true
+		}
+		for _, alt := range m.AlternateNames {
+			if strings.ToLower(alt) == name {
+				return m, true
+			}
+		}
+	}
+	return MedicationLimit{}, false
+}
+
+func (pr *projector) add(e Exposure, when string) {
+	m, ok := pr.find(e)
+	if !ok {
+		return
+	}
+	dose := Dose{Amount: e.Amount, Basis: e.Basis}
+
+	if t, ok := pr.trackers["medication:"+m.MedicationID]; ok {
+		amount, err := Convert(dose, t.basis, pr.patient)
+		if err != nil {
+			pr.unresolved = append(pr.unresolved, fmt.Sprintf("%s (%s): %s", m.Name, when, err.Error()))
+		} else {
+			t.cycle += amount
+		}
+	}
+
+	if m.ClassID == "" {
+		return
+	}
+	t, ok := pr.trackers["class:"+m.ClassID]
+	if !ok {
+		return
+	}
+	amount, err := Convert(dose, t.basis, pr.patient)
+	if err != nil {
+		pr.unresolved = append(pr.unresolved, fmt.Sprintf("%s (%s): %s", m.Name, when, err.Error()))
+		return
+	}
+	factor := m.EquivalenceFactor
+	if factor <= 0 {
+		factor = 1
+	}
+	t.cycle += amount * factor
+}
+
+// close folds the doses accumulated since the last call into the totals and
+// records a warning the first time a tracker changes status.
+func (pr *projector) close(cycle int, projection *Projection) []TrackedDose {
+	doses := []TrackedDose{}
+	for _, key := range pr.order {
+		t := pr.trackers[key]
+		if t.cycle == 0 && t.total == 0 {
+			continue
+		}
+		t.total += t.cycle
+		previous := t.status
+		t.status = t.evaluate()
+		if t.status != previous && t.status != StatusOK {
+			projection.Warnings = append(projection.Warnings, newWarning(cycle, t))
+		}
+		doses = append(doses, t.snapshot())
+		t.cycle = 0
+	}
+	return doses
+}
+
+func newWarning(cycle int, t *tracker) Warning {
+	when := fmt.Sprintf("at cycle %d", cycle)
+	if cycle == 0 {
+		when = "from prior exposure"
+	}
+	verb := "approaches"
+	if t.status == StatusExceeded {
+		verb = "exceeds"
+	}
+	return Warning{
+		Cycle:  cycle,
+		Name:   t.name,
+		Status: t.status,
+		Message: fmt.Sprintf("Cumulative %s dose reaches %.1f %s %s and %s the lifetime limit of %.1f %s",
+			t.name, round(t.total), t.basis.Label(), when, verb, t.max, t.basis.Label()),
+	}
+}
+
+// BuildPlan expands protocol cycle templates into the doses of each of the
+// first n cycles. Treatments whose dose cannot be parsed are reported back.
+func BuildPlan(templates []CycleTemplate, n int) ([]PlannedCycle, []string) {
+	plan := make([]PlannedCycle, 0, n)
+	unresolved := []string{}
+	seen := map[string]bool{}
+	report := func(msg string) {
+		if !seen[msg] {
+			seen[msg] = true
+			unresolved = append(unresolved, msg)
+		}
+	}
+	for i := 1; i <= n; i++ {
+		planned := PlannedCycle{Cycle: i, Doses: []Exposure{}}
+		template, ok := SelectCycle(templates, i)
+		if !ok {
+			report(fmt.Sprintf("cycle %d: no matching protocol cycle", i))
+			plan = append(plan, planned)
+			continue
+		}
+		for _, tx := range template.Treatments {
+			dose, ok := ParseDose(tx.Dose)
+			if !ok {
+				report(fmt.Sprintf("%s: could not parse dose '%s'", tx.MedicationName, tx.Dose))
+				continue
+			}
+			days, _ := ParseDays(tx.Frequency)
+			planned.Doses = append(planned.Doses, Exposure{
+				MedicationID: tx.MedicationID,
+				Name:         tx.MedicationName,
+				Amount:       dose.Amount * float64(len(days)),
+				Basis:        dose.Basis,
+			})
+		}
+		plan = append(plan, planned)
+	}
+	return plan, unresolved
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
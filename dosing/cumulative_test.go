@@ -0,0 +1,122 @@
+package dosing
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var (
+	doxorubicin    = MedicationLimit{MedicationID: "dox", Name: "DOXOrubicin", MaxDose: 450, Basis: BasisMgM2, ClassID: "anthracyclines", EquivalenceFactor: 1}
+	epirubicin     = MedicationLimit{MedicationID: "epi", Name: "EPIrubicin", AlternateNames: []string{"Ellence"}, MaxDose: 900, Basis: BasisMgM2, ClassID: "anthracyclines", EquivalenceFactor: 0.5}
+	anthracyclines = ClassLimit{ID: "anthracyclines", Name: "Anthracyclines", ReferenceDrug: "doxorubicin", MaxDose: 450, Basis: BasisMgM2}
+)
+
+func TestBuildPlan(t *testing.T) {
+	templates := []CycleTemplate{
+		{Label: "Cycle 1", Treatments: []TemplateTreatment{
+			{MedicationID: "dox", MedicationName: "DOXOrubicin", Dose: "60 mg/m2", Frequency: "Day 1"},
+			{MedicationID: "cyc", MedicationName: "Cyclophosphamide", Dose: "1,500 mg/m2", Frequency: "Day 1"},
+		}},
+		{Label: "Cycles 2-3", Treatments: []TemplateTreatment{
+			{MedicationID: "dox", MedicationName: "DOXOrubicin", Dose: "30 mg/m2", Frequency: "Days 1 and 8"},
+			{MedicationName: "Pembrolizumab", Dose: "per protocol", Frequency: "Day 1"},
+		}},
+	}
+	plan, unresolved := BuildPlan(templates, 4)
+	want := []PlannedCycle{
+		{Cycle: 1, Doses: []Exposure{
+			{MedicationID: "dox", Name: "DOXOrubicin", Amount: 60, Basis: BasisMgM2},
+			{MedicationID: "cyc", Name: "Cyclophosphamide", Amount: 1500, Basis: BasisMgM2},
+		}},
+		{Cycle: 2, Doses: []Exposure{{MedicationID: "dox", Name: "DOXOrubicin", Amount: 60, Basis: BasisMgM2}}},
+		{Cycle: 3, Doses: []Exposure{{MedicationID: "dox", Name: "DOXOrubicin", Amount: 60, Basis: BasisMgM2}}},
+		{Cycle: 4, Doses: []Exposure{}},
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("plan = %+v, want %+v", plan, want)
+	}
+	wantUnresolved := []string{"Pembrolizumab: could not parse dose 'per protocol'", "cycle 4: no matching protocol cycle"}
+	if !reflect.DeepEqual(unresolved, wantUnresolved) {
+		t.Errorf("unresolved = %q, want %q", unresolved, wantUnresolved)
+	}
+}
+
+func TestProject(t *testing.T) {
+	plan := []PlannedCycle{}
+	for i := 1; i <= 6; i++ {
+		plan = append(plan, PlannedCycle{Cycle: i, Doses: []Exposure{{MedicationID: "dox", Name: "DOXOrubicin", Amount: 60, Basis: BasisMgM2}}})
+	}
+	// 180 mg/m2 of epirubicin counts as 90 mg/m2 of doxorubicin
+	prior := []Exposure{{Name: "ellence", Amount: 180, Basis: BasisMgM2}}
+	p := Project(plan, prior, []MedicationLimit{doxorubicin, epirubicin}, []ClassLimit{anthracyclines}, Patient{BSA: 1.8})
+
+	class := func(doses []TrackedDose) TrackedDose {
+		for _, d := range doses {
+			if d.Kind == "class" {
+				return d
+			}
+		}
+		t.Fatalf("no class total in %+v", doses)
+		return TrackedDose{}
+	}
+	if c := class(p.Prior); c.Cumulative != 90 || c.Status != StatusOK {
+		t.Errorf("prior class total = %+v", c)
+	}
+	// 90 + 60n: 330 at cycle 4 warns (>= 80% of 450), 510 at cycle 6 exceeds
+	for i, want := range []struct {
+		total  float64
+		status Status
+	}{{150, StatusOK}, {210, StatusOK}, {270, StatusOK}, {330, StatusOK}, {390, StatusWarning}, {450, StatusWarning}} {
+		if c := class(p.Cycles[i].Doses); c.Cumulative != want.total || c.Status != want.status {
+			t.Errorf("cycle %d class total = %v %s, want %v %s", i+1, c.Cumulative, c.Status, want.total, want.status)
+		}
+	}
+	if p.MaxSafeCycles != 6 {
+		t.Errorf("max safe cycles = %d, want 6", p.MaxSafeCycles)
+	}
+	// the class warns at cycle 5, doxorubicin alone (360 of 450) at cycle 6
+	if len(p.Warnings) != 2 || p.Warnings[0].Cycle != 5 || p.Warnings[1].Cycle != 6 || p.Warnings[1].Name != "DOXOrubicin" {
+		t.Fatalf("warnings = %+v", p.Warnings)
+	}
+	if w := p.Warnings[0]; w.Status != StatusWarning || !strings.Contains(w.Message, "Anthracyclines (doxorubicin-equivalent) dose reaches 390.0 mg/m2 at cycle 5 and approaches the lifetime limit of 450.0 mg/m2") {
+		t.Errorf("class warning = %+v", w)
+	}
+}
+
+func TestProjectExceeded(t *testing.T) {
+	plan := []PlannedCycle{}
+	for i := 1; i <= 4; i++ {
+		plan = append(plan, PlannedCycle{Cycle: i, Doses: []Exposure{{MedicationID: "dox", Amount: 180, Basis: BasisMg}}})
+	}
+	prior := []Exposure{{MedicationID: "dox", Amount: 300, Basis: BasisMgM2}}
+	p := Project(plan, prior, []MedicationLimit{doxorubicin}, nil, Patient{BSA: 1.8})
+
+	// 300 prior + 100 per cycle: warning at cycle 1, exceeded at cycle 2
+	if p.MaxSafeCycles != 1 {
+		t.Errorf("max safe cycles = %d, want 1", p.MaxSafeCycles)
+	}
+	if len(p.Warnings) != 2 || p.Warnings[0].Cycle != 1 || p.Warnings[1].Cycle != 2 || p.Warnings[1].Status != StatusExceeded {
+		t.Fatalf("warnings = %+v", p.Warnings)
+	}
+	if !strings.Contains(p.Warnings[1].Message, "reaches 500.0 mg/m2 at cycle 2 and exceeds") {
+		t.Errorf("warnings = %+v", p.Warnings)
+	}
+
+	// without a BSA the mg doses cannot be counted against a mg/m2 limit
+	p = Project(plan[:1], nil, []MedicationLimit{doxorubicin}, nil, Patient{})
+	if len(p.Cycles) != 1 || len(p.Cycles[0].Doses) != 0 {
+		t.Errorf("cycles = %+v", p.Cycles)
+	}
+	if len(p.Unresolved) != 1 || !strings.Contains(p.Unresolved[0], "bsa is required") {
+		t.Errorf("unresolved = %q", p.Unresolved)
+	}
+}
+
+func TestProjectPriorWarning(t *testing.T) {
+	prior := []Exposure{{MedicationID: "dox", Amount: 400, Basis: BasisMgM2}}
+	p := Project(nil, prior, []MedicationLimit{doxorubicin}, nil, Patient{})
+	if len(p.Warnings) != 1 || p.Warnings[0].Cycle != 0 || !strings.Contains(p.Warnings[0].Message, "from prior exposure") {
+		t.Errorf("warnings = %+v", p.Warnings)
+	}
+}
@@ -0,0 +1,153 @@
+package dosing
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Basis mirrors the dose_basis_enum values stored in the database.
+type Basis string
+
+const (
+	BasisMgM2    Basis = "mg_m2"
+	BasisMgKg    Basis = "mg_kg"
+	BasisMg      Basis = "mg"
+	BasisUnitsM2 Basis = "units_m2"
+	BasisUnits   Basis = "units"
+	BasisUnknown Basis = "unknown"
+)
+
+// Dose is a single administration amount expressed in a given basis.
+type Dose struct {
+	Amount float64 `json:"amount"`
+	Basis  Basis   `json:"dose_basis"`
+}
+
+// Patient holds the body measurements needed to convert between dose bases.
+type Patient struct {
+	BSA      float64 `json:"bsa"`
+	WeightKg float64 `json:"weight_kg"`
+}
+
+// MostellerBSA estimates the body surface area in m2 from height and weight.
+func MostellerBSA(heightCm, weightKg float64) float64 {
+	if heightCm <= 0 || weightKg <= 0 {
+		return 0
+	}
+	return math.Round(math.Sqrt(heightCm*weightKg/3600)*100) / 100
+}
+
+// doseRegex only starts an amount after a character that cannot belong to a
+// number, so "1,500" is read whole and "0,5" is not read as 5.
+var doseRegex = regexp.MustCompile(`(?i)(?:^|[^\d.,])(\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?)\s*(mcg|microg|mg|g|units?|iu|u)\b\s*(?:/\s*(m2|m²|m\^2|kg))?`)
+
+// ParseDose extracts the first amount found in free-text dose strings such as
+// "50 mg/m2", "1.4 mg/m2 (max 2 mg)", "1,500 mg/m2" or "30 units".
+func ParseDose(text string) (Dose, bool) {
+	match := doseRegex.FindStringSubmatch(text)
+	if match == nil {
+		return Dose{Basis: BasisUnknown}, false
+	}
+
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64)
+	if err != nil || amount <= 0 {
+		return Dose{Basis: BasisUnknown}, false
+	}
+
+	unit := strings.ToLower(match[2])
+	per := strings.ToLower(match[3])
+
+	isUnits := false
+	switch unit {
+	case "mcg", "microg":
+		amount = amount / 1000
+	case "g":
+		amount = amount * 1000
+	case "unit", "units", "iu", "u":
+		isUnits = true
+	}
+
+	switch {
+	case isUnits && per == "":
+		return Dose{Amount: amount, Basis: BasisUnits}, true
+	case isUnits && per == "kg":
+		// units/kg are rare enough that we do not track them
+		return Dose{Amount: amount, Basis: BasisUnknown}, false
+	case isUnits:
+		return Dose{Amount: amount, Basis: BasisUnitsM2}, true
+	case per == "kg":
+		return Dose{Amount: amount, Basis: BasisMgKg}, true
+	case per != "":
+		return Dose{Amount: amount, Basis: BasisMgM2}, true
+	default:
+		return Dose{Amount: amount, Basis: BasisMg}, true
+	}
+}
+
+// Convert expresses a dose in the target basis, using the patient's BSA or
+// weight when the conversion requires it.
+func Convert(d Dose, target Basis, p Patient) (float64, error) {
+	if d.Basis == target {
+		return d.Amount, nil
+	}
+	if d.Basis == BasisUnknown || target == BasisUnknown {
+		return 0, fmt.Errorf("cannot convert an unknown dose basis")
+	}
+	if isUnitBasis(d.Basis) != isUnitBasis(target) {
+		return 0, fmt.Errorf("cannot convert %s to %s", d.Basis, target)
+	}
+
+	absolute := d.Amount
+	switch d.Basis {
+	case BasisMgM2, BasisUnitsM2:
+		if p.BSA <= 0 {
+			return 0, fmt.Errorf("bsa is required to convert %s to %s", d.Basis, target)
+		}
+		absolute = d.Amount * p.BSA
+	case BasisMgKg:
+		if p.WeightKg <= 0 {
+			return 0, fmt.Errorf("weight_kg is required to convert %s to %s", d.Basis, target)
+		}
+		absolute = d.Amount * p.WeightKg
+	}
+
+	switch target {
+	case BasisMgM2, BasisUnitsM2:
+		if p.BSA <= 0 {
+			return 0, fmt.Errorf("bsa is required to convert %s to %s", d.Basis, target)
+		}
+		return absolute / p.BSA, nil
+	case BasisMgKg:
+		if p.WeightKg <= 0 {
+			return 0, fmt.Errorf("weight_kg is required to convert %s to %s", d.Basis, target)
+		}
+		return absolute / p.WeightKg, nil
+	default:
+		return absolute, nil
+	}
+}
+
+// Label returns a human readable unit for a basis (e.g. "mg/m2").
+func (b Basis) Label() string {
+	switch b {
+	case BasisMgM2:
+		return "mg/m2"
+	case BasisMgKg:
+		return "mg/kg"
+	case BasisMg:
+		return "mg"
+	case BasisUnitsM2:
+		return "units/m2"
+	case BasisUnits:
+		return "units"
+	default:
+		return "unknown"
+	}
+}
+
+func isUnitBasis(b Basis) bool {
+	return b == BasisUnits || b == BasisUnitsM2
+}
@@ -0,0 +1,71 @@
+package dosing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseDose(t *testing.T) {
+	tests := []struct {
+		text   string
+		amount float64
+		basis  Basis
+		ok     bool
+	}{
+		{"50 mg/m2", 50, BasisMgM2, true},
+		{"1.4 mg/m2 (max 2 mg)", 1.4, BasisMgM2, true},
+		{"1,500 mg/m2", 1500, BasisMgM2, true},
+		{"3,000 mg/m²", 3000, BasisMgM2, true},
+		{"10,000 units/m2", 10000, BasisUnitsM2, true},
+		{"1,000,000 units", 1000000, BasisUnits, true},
+		{"1,250.5 mg", 1250.5, BasisMg, true},
+		{"AUC 5, 600 mg", 600, BasisMg, true},
+		{"15 mg/kg", 15, BasisMgKg, true},
+		{"300 mcg", 0.3, BasisMg, true},
+		{"2 g/m2", 2000, BasisMgM2, true},
+		{"30 units", 30, BasisUnits, true},
+		{"100 units/kg", 100, BasisUnknown, false},
+		{"0,5 mg", 0, BasisUnknown, false},
+		{"0 mg/m2", 0, BasisUnknown, false},
+		{"AUC 5", 0, BasisUnknown, false},
+		{"", 0, BasisUnknown, false},
+	}
+	for _, tt := range tests {
+		d, ok := ParseDose(tt.text)
+		if ok != tt.ok || d.Basis != tt.basis || (ok && math.Abs(d.Amount-tt.amount) > 1e-9) {
+			t.Errorf("ParseDose(%q) = %v %s, %v, want %v %s, %v", tt.text, d.Amount, d.Basis, ok, tt.amount, tt.basis, tt.ok)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	patient := Patient{BSA: 1.8, WeightKg: 70}
+	tests := []struct {
+		dose    Dose
+		target  Basis
+		patient Patient
+		want    float64
+		wantErr bool
+	}{
+		{Dose{50, BasisMgM2}, BasisMgM2, Patient{}, 50, false},
+		{Dose{50, BasisMgM2}, BasisMg, patient, 90, false},
+		{Dose{90, BasisMg}, BasisMgM2, patient, 50, false},
+		{Dose{2, BasisMgKg}, BasisMg, patient, 140, false},
+		{Dose{2, BasisMgKg}, BasisMgM2, patient, 140 / 1.8, false},
+		{Dose{10, BasisUnitsM2}, BasisUnits, patient, 18, false},
+		{Dose{50, BasisMgM2}, BasisMg, Patient{WeightKg: 70}, 0, true},
+		{Dose{2, BasisMgKg}, BasisMg, Patient{BSA: 1.8}, 0, true},
+		{Dose{10, BasisUnits}, BasisMg, patient, 0, true},
+		{Dose{10, BasisUnknown}, BasisMg, patient, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := Convert(tt.dose, tt.target, tt.patient)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Convert(%v, %s): error %v, want error %v", tt.dose, tt.target, err, tt.wantErr)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Convert(%v, %s) = %v, want %v", tt.dose, tt.target, got, tt.want)
+		}
+	}
+}
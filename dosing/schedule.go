@@ -0,0 +1,163 @@
+package dosing
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	dayListRegex  = regexp.MustCompile(`(?i)\bdays?\s+((?:\d+|\s|,|-|–|to|through|and|&)+)`)
+	rangeRegex    = regexp.MustCompile(`^(\d+)\s*(?:-|–|to|through)\s*(\d+)$`)
+	dailyForRegex = regexp.MustCompile(`(?i)daily\s*(?:x|for|times)\s*(\d+)\s*days?`)
+	cycleRegex    = regexp.MustCompile(`(?i)cycles?\s*(\d+)\s*(?:(?:-|–|to|through)\s*(\d+)|(\+|and\s+(?:on|beyond|after|subsequent)|onward|onwards))?`)
+	durationRegex = regexp.MustCompile(`(?i)(\d+)\s*(days?|weeks?|wks?)`)
+)
+
+// ParseDays returns the administration days of a cycle from frequency strings
+// such as "Day 1", "Day 1-2", "Day 1, 8, 15 and 22" or "Days 1 to 14".
+// When no day can be found it falls back to day 1 and returns false.
+func ParseDays(frequency string) ([]int, bool) {
+	seen := map[int]bool{}
+
+	for _, match := range dayListRegex.FindAllStringSubmatch(frequency, -1) {
+		list := strings.ToLower(match[1])
+		list = strings.ReplaceAll(list, "&", ",")
+		list = strings.ReplaceAll(list, " and ", ",")
+		for _, part := range strings.Split(list, ",") {
+			part = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(part), "and"))
+			if part == "" {
+				continue
+			}
+			if r := rangeRegex.FindStringSubmatch(part); r != nil {
+				from, _ := strconv.Atoi(r[1])
+				to, _ := strconv.Atoi(r[2])
+				for d := from; d <= to && to-from < 366; d++ {
+					seen[d] = true
+				}
+				continue
+			}
+			for _, field := range strings.Fields(part) {
+				if d, err := strconv.Atoi(field); err == nil {
+					seen[d] = true
+				}
+			}
+		}
+	}
+
+	if len(seen) == 0 {
+		if match := dailyForRegex.FindStringSubmatch(frequency); match != nil {
+			n, _ := strconv.Atoi(match[1])
+			for d := 1; d <= n; d++ {
+				seen[d] = true
+			}
+		}
+	}
+
+	if len(seen) == 0 {
+		return []int{1}, false
+	}
+
+	days := make([]int, 0, len(seen))
+	for d := range seen {
+		if d > 0 {
+			days = append(days, d)
+		}
+	}
+	sort.Ints(days)
+	return days, true
+}
+
+// CycleRange describes which cycle numbers a protocol_cycles label applies to.
+type CycleRange struct {
+	First      int  `json:"first"`
+	Last       int  `json:"last"`
+	OpenEnded  bool `json:"open_ended"`
+	Recognised bool `json:"recognised"`
+}
+
+// Contains reports whether cycle n falls within the range.
+func (r CycleRange) Contains(n int) bool {
+	if n < r.First {
+		return false
+	}
+	return r.OpenEnded || n <= r.Last
+}
+
+// ParseCycleRange understands labels such as "Cycle 1", "Cycle 1+",
+// "Cycles 2-6" or "Cycle 2 to 6". Unrecognised labels apply to every cycle.
+func ParseCycleRange(label string) CycleRange {
+	match := cycleRegex.FindStringSubmatch(label)
+	if match == nil {
+		return CycleRange{First: 1, OpenEnded: true}
+	}
+	first, _ := strconv.Atoi(match[1])
+	switch {
+	case match[2] != "":
+		last, _ := strconv.Atoi(match[2])
+		return CycleRange{First: first, Last: last, Recognised: true}
+	case match[3] != "":
+		return CycleRange{First: first, OpenEnded: true, Recognised: true}
+	default:
+		return CycleRange{First: first, Last: first, Recognised: true}
+	}
+}
+
+// ParseDurationDays converts "21 days", "every 28 days" or "3 weeks" to days.
+func ParseDurationDays(duration string) (int, bool) {
+	match := durationRegex.FindStringSubmatch(duration)
+	if match == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	if strings.HasPrefix(strings.ToLower(match[2]), "w") {
+		n = n * 7
+	}
+	return n, true
+}
+
+// CycleTemplate is the minimal view of a protocol cycle needed for planning.
+type CycleTemplate struct {
+	Label      string
+	Duration   string
+	Treatments []TemplateTreatment
+}
+
+// TemplateTreatment is the minimal view of a protocol treatment.
+type TemplateTreatment struct {
+	MedicationID   string
+	MedicationName string
+	AlternateNames []string
+	Dose           string
+	Route          string
+	Frequency      string
+}
+
+// SelectCycle picks the template that applies to cycle n, preferring the
+// narrowest matching range so "Cycle 1" wins over "Cycle 1+".
+func SelectCycle(templates []CycleTemplate, n int) (CycleTemplate, bool) {
+	best := -1
+	bestSpan := 0
+	for i, t := range templates {
+		r := ParseCycleRange(t.Label)
+		if !r.Contains(n) {
+			continue
+		}
+		span := r.Last - r.First
+		if r.OpenEnded {
+			span = 1 << 30
+		}
+		if best == -1 || span < bestSpan {
+			best = i
+			bestSpan = span
+		}
+	}
+	if best == -1 {
+		return CycleTemplate{}, false
+	}
+	return templates[best], true
+}
@@ -0,0 +1,55 @@
+package dosing
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDays(t *testing.T) {
+	tests := []struct {
+		frequency string
+		days      []int
+		ok        bool
+	}{
+		{"Day 1", []int{1}, true},
+		{"Day 1-2", []int{1, 2}, true},
+		{"Day 1, 8, 15 and 22", []int{1, 8, 15, 22}, true},
+		{"Days 1 to 14", []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14}, true},
+		{"days 1 & 8", []int{1, 8}, true},
+		{"Days 1–3", []int{1, 2, 3}, true},
+		{"daily x 5 days", []int{1, 2, 3, 4, 5}, true},
+		{"once", []int{1}, false},
+	}
+	for _, tt := range tests {
+		days, ok := ParseDays(tt.frequency)
+		if ok != tt.ok || !reflect.DeepEqual(days, tt.days) {
+			t.Errorf("ParseDays(%q) = %v, %v, want %v, %v", tt.frequency, days, ok, tt.days, tt.ok)
+		}
+	}
+}
+
+func TestParseCycleRange(t *testing.T) {
+	tests := []struct {
+		label string
+		want  CycleRange
+	}{
+		{"Cycle 1", CycleRange{First: 1, Last: 1, Recognised: true}},
+		{"Cycle 1+", CycleRange{First: 1, OpenEnded: true, Recognised: true}},
+		{"Cycles 2-6", CycleRange{First: 2, Last: 6, Recognised: true}},
+		{"Cycle 2 to 6", CycleRange{First: 2, Last: 6, Recognised: true}},
+		{"Cycle 2 and subsequent", CycleRange{First: 2, OpenEnded: true, Recognised: true}},
+		{"Maintenance", CycleRange{First: 1, OpenEnded: true}},
+	}
+	for _, tt := range tests {
+		if got := ParseCycleRange(tt.label); got != tt.want {
+			t.Errorf("ParseCycleRange(%q) = %+v, want %+v", tt.label, got, tt.want)
+		}
+	}
+
+	r := ParseCycleRange("Cycles 2-6")
+	for n, want := range map[int]bool{1: false, 2: true, 6: true, 7: false} {
+		if r.Contains(n) != want {
+			t.Errorf("Cycles 2-6 contains %d = %v", n, !want)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package eligibility
+
+import (
+	"strings"
+)
+
+// Verdict is the outcome of a rule for one patient. Missing profile fields
+// make a rule unknown unless the rest of the expression already decides it.
+type Verdict string
+
+const (
+	Met     Verdict = "met"
+	NotMet  Verdict = "not_met"
+	Unknown Verdict = "unknown"
+)
+
+// Profile is the patient information rules are evaluated against. Nil or
+// empty fields are unknown; a prior_therapies list, once given, is taken to
+// be complete.
+type Profile struct {
+	Age            *float64           `json:"age"`
+	ECOG           *float64           `json:"ecog"`
+	Sex            string             `json:"sex"`
+	TumorGroup     string             `json:"tumor_group"`
+	Diagnosis      string             `json:"diagnosis"`
+	Labs           map[string]float64 `json:"labs"`
+	PriorTherapies []string           `json:"prior_therapies"`
+	Flags          map[string]bool    `json:"flags"`
+}
+
+func key(s string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "_")
+}
+
+func (p Profile) lookup(name string) value {
+	switch name {
+	case "age":
+		return numberOrUnknown(p.Age)
+	case "ecog":
+		return numberOrUnknown(p.ECOG)
+	case "sex":
+		return textOrUnknown(p.Sex)
+	case "tumor_group":
+		return textOrUnknown(p.TumorGroup)
+	case "diagnosis":
+		return textOrUnknown(p.Diagnosis)
+	}
+
+	prefix, rest, _ := strings.Cut(name, ".")
+	switch prefix {
+	case "lab":
+		for k, v := range p.Labs {
+			if key(k) == rest {
+				return value{kind: typeNumber, num: v, known: true}
+			}
+		}
+		return value{kind: typeNumber}
+	case "prior":
+		if p.PriorTherapies == nil {
+			return value{kind: typeBool}
+		}
+		for _, t := range p.PriorTherapies {
+			if key(t) == rest {
+				return value{kind: typeBool, b: true, known: true}
+			}
+		}
+		return value{kind: typeBool, b: false, known: true}
+	case "flag":
+		for k, v := range p.Flags {
+			if key(k) == rest {
+				return value{kind: typeBool, b: v, known: true}
+			}
+		}
+		return value{kind: typeBool}
+	}
+	return value{}
+}
+
+func numberOrUnknown(n *float64) value {
+	if n == nil {
+		return value{kind: typeNumber}
+	}
+	return value{kind: typeNumber, num: *n, known: true}
+}
+
+func textOrUnknown(s string) value {
+	if strings.TrimSpace(s) == "" {
+		return value{kind: typeString}
+	}
+	return value{kind: typeString, str: s, known: true}
+}
+
+// Evaluate applies the rule to a profile with three-valued logic.
+func (r *Rule) Evaluate(p Profile) Verdict {
+	v := r.root.eval(p)
+	switch {
+	case !v.known:
+		return Unknown
+	case v.b:
+		return Met
+	default:
+		return NotMet
+	}
+}
+
+// Missing lists the fields the rule reads that the profile doesn't provide.
+func (r *Rule) Missing(p Profile) []string {
+	missing := []string{}
+	for _, f := range r.fields {
+		if !p.lookup(f).known {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// --- expression tree ---
+
+type value struct {
+	kind  valueType
+	known bool
+	num   float64
+	str   string
+	b     bool
+}
+
+var unknownBool = value{kind: typeBool}
+
+func boolean(b bool) value {
+	return value{kind: typeBool, b: b, known: true}
+}
+
+type node interface {
+	typeOf() valueType
+	eval(p Profile) value
+}
+
+type literal struct{ v value }
+
+func (n literal) typeOf() valueType    { return n.v.kind }
+func (n literal) eval(p Profile) value { return n.v }
+
+type field struct {
+	name string
+	kind valueType
+}
+
+func (n field) typeOf() valueType    { return n.kind }
+func (n field) eval(p Profile) value { return p.lookup(n.name) }
+
+type andNode struct{ left, right node }
+
+func (n andNode) typeOf() valueType { return typeBool }
+func (n andNode) eval(p Profile) value {
+	l, r := n.left.eval(p), n.right.eval(p)
+	if (l.known && !l.b) || (r.known && !r.b) {
+		return boolean(false)
+	}
+	if l.known && r.known {
+		return boolean(true)
+	}
+	return unknownBool
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) typeOf() valueType { return typeBool }
+func (n orNode) eval(p Profile) value {
+	l, r := n.left.eval(p), n.right.eval(p)
+	if (l.known && l.b) || (r.known && r.b) {
+		return boolean(true)
+	}
+	if l.known && r.known {
+		return boolean(false)
+	}
+	return unknownBool
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) typeOf() valueType { return typeBool }
+func (n notNode) eval(p Profile) value {
+	v := n.operand.eval(p)
+	if !v.known {
+		return unknownBool
+	}
+	return boolean(!v.b)
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) typeOf() valueType { return typeBool }
+func (n compareNode) eval(p Profile) value {
+	l, r := n.left.eval(p), n.right.eval(p)
+	if !l.known || !r.known {
+		return unknownBool
+	}
+	switch n.op {
+	case "==":
+		return boolean(equal(l, r))
+	case "!=":
+		return boolean(!equal(l, r))
+	case "<":
+		return boolean(l.num < r.num)
+	case "<=":
+		return boolean(l.num <= r.num)
+	case ">":
+		return boolean(l.num > r.num)
+	default:
+		return boolean(l.num >= r.num)
+	}
+}
+
+type inNode struct {
+	operand node
+	list    []node
+}
+
+func (n inNode) typeOf() valueType { return typeBool }
+func (n inNode) eval(p Profile) value {
+	v := n.operand.eval(p)
+	if !v.known {
+		return unknownBool
+	}
+	for _, item := range n.list {
+		if equal(v, item.eval(p)) {
+			return boolean(true)
+		}
+	}
+	return boolean(false)
+}
+
+func equal(a, b value) bool {
+	switch a.kind {
+	case typeNumber:
+		return a.num == b.num
+	case typeString:
+		return strings.EqualFold(strings.TrimSpace(a.str), strings.TrimSpace(b.str))
+	default:
+		return a.b == b.b
+	}
+}
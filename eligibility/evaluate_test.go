@@ -0,0 +1,69 @@
+package eligibility
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	patient := Profile{
+		Age:            number(64),
+		Sex:            "F",
+		Diagnosis:      "NSCLC",
+		Labs:           map[string]float64{"ANC": 1.8, "Creatinine Clearance": 45},
+		PriorTherapies: []string{"Anthracycline"},
+		Flags:          map[string]bool{"pregnant": false},
+	}
+	tests := []struct {
+		source  string
+		profile Profile
+		want    Verdict
+	}{
+		{"age >= 18 and lab.anc >= 1.5", patient, Met},
+		{"age >= 18 and lab.anc >= 2", patient, NotMet},
+		{`sex == "f" and diagnosis in ["nsclc", "sclc"]`, patient, Met},
+		{"lab.creatinine_clearance < 30", patient, NotMet},
+		{"prior.anthracycline and not flag.pregnant", patient, Met},
+		{"prior.bleomycin", patient, NotMet},
+
+		// a missing field is unknown
+		{"ecog <= 2", patient, Unknown},
+		{"tumor_group == 'lung'", patient, Unknown},
+		{"diagnosis in ['nsclc']", Profile{}, Unknown},
+		{"lab.platelets >= 100", patient, Unknown},
+		{"flag.smoker", patient, Unknown},
+		{"not flag.smoker", patient, Unknown},
+		{"prior.anthracycline", Profile{}, Unknown},
+		{"prior.anthracycline", Profile{PriorTherapies: []string{}}, NotMet},
+
+		// unless the rest of the expression decides it
+		{"ecog <= 2 and age < 18", patient, NotMet},
+		{"ecog <= 2 and age >= 18", patient, Unknown},
+		{"ecog <= 2 or age >= 18", patient, Met},
+		{"ecog <= 2 or age < 18", patient, Unknown},
+		{"age < 18 and ecog <= 2", patient, NotMet},
+		{"age >= 18 or ecog <= 2", patient, Met},
+		{"not (ecog <= 2 and age < 18)", patient, Met},
+		{"flag.smoker or flag.smoker", patient, Unknown},
+	}
+	for _, tt := range tests {
+		rule, err := Parse(tt.source)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.source, err)
+		}
+		if got := rule.Evaluate(tt.profile); got != tt.want {
+			t.Errorf("%q = %s, want %s", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestMissing(t *testing.T) {
+	rule, err := Parse("age >= 18 and ecog <= 2 and lab.anc >= 1.5 and prior.bleomycin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := Profile{Age: number(40), Labs: map[string]float64{"anc": 2}}
+	if got, want := rule.Missing(p), []string{"ecog", "prior.bleomycin"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Missing = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,381 @@
+package eligibility
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Rules are small boolean expressions over a patient profile, for example
+//
+//	age >= 18 and ecog <= 2 and lab.anc >= 1.5
+//	tumor_group == "lung" and diagnosis in ["nsclc", "mesothelioma"]
+//	prior.anthracycline or flag.pregnant
+//
+// Fields are age, ecog, sex, tumor_group, diagnosis, lab.<name> (numbers),
+// prior.<therapy> and flag.<name> (booleans). Expressions combine comparisons
+// (==, !=, <, <=, >, >=, in [...]) with and, or, not and parentheses.
+
+type valueType int
+
+const (
+	typeNumber valueType = iota
+	typeString
+	typeBool
+)
+
+func (t valueType) String() string {
+	switch t {
+	case typeNumber:
+		return "number"
+	case typeString:
+		return "text"
+	default:
+		return "boolean"
+	}
+}
+
+var fieldTypes = map[string]valueType{
+	"age":         typeNumber,
+	"ecog":        typeNumber,
+	"sex":         typeString,
+	"tumor_group": typeString,
+	"diagnosis":   typeString,
+}
+
+var prefixTypes = map[string]valueType{
+	"lab":   typeNumber,
+	"prior": typeBool,
+	"flag":  typeBool,
+}
+
+func fieldType(name string) (valueType, bool) {
+	if t, ok := fieldTypes[name]; ok {
+		return t, true
+	}
+	prefix, rest, found := strings.Cut(name, ".")
+	if !found || rest == "" {
+		return 0, false
+	}
+	t, ok := prefixTypes[prefix]
+	return t, ok
+}
+
+// Rule is a parsed eligibility expression.
+type Rule struct {
+	source string
+	root   node
+	fields []string
+}
+
+// Parse checks the expression's syntax and field types.
+func Parse(source string) (*Rule, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, seen: map[string]bool{}}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	if t := root.typeOf(); t != typeBool {
+		return nil, fmt.Errorf("rule must be a condition, not a %s", t)
+	}
+	return &Rule{source: strings.TrimSpace(source), root: root, fields: p.fields}, nil
+}
+
+func (r *Rule) String() string {
+	return r.source
+}
+
+// Fields lists the profile fields the rule reads, in order of appearance.
+func (r *Rule) Fields() []string {
+	return r.fields
+}
+
+// --- lexer ---
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lex(src string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case r == '[':
+			tokens = append(tokens, token{tokLBracket, "[", i})
+			i++
+		case r == ']':
+			tokens = append(tokens, token{tokRBracket, "]", i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokComma, ",", i})
+			i++
+		case strings.ContainsRune("=!<>", r):
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			if op == "=" || op == "!" {
+				return nil, fmt.Errorf("unknown operator %q at position %d, use == or !=", op, i)
+			}
+			tokens = append(tokens, token{tokOp, op, i})
+			i += len(op)
+		case r == '"' || r == '\'':
+			start := i
+			i++
+			var b strings.Builder
+			for i < len(runes) && runes[i] != r {
+				b.WriteRune(runes[i])
+				i++
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated text starting at position %d", start)
+			}
+			i++
+			tokens = append(tokens, token{tokString, b.String(), start})
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, string(runes[start:i]), start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, strings.ToLower(string(runes[start:i])), start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+	return append(tokens, token{tokEOF, "end of rule", len(runes)}), nil
+}
+
+// --- parser ---
+
+type parser struct {
+	tokens []token
+	pos    int
+	fields []string
+	seen   map[string]bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) keyword(word string) bool {
+	tok := p.peek()
+	if tok.kind == tokIdent && tok.text == word {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if err := requireBool(left, right); err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if err := requireBool(left, right); err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.keyword("not") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if err := requireBool(operand); err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	if p.peek().kind == tokLParen {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != tokRParen {
+			return nil, fmt.Errorf("expected ) at position %d", tok.pos)
+		}
+		return inner, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.keyword("in") {
+		list, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			if item.typeOf() != left.typeOf() {
+				return nil, fmt.Errorf("cannot look for a %s in a list of %s", left.typeOf(), item.typeOf())
+			}
+		}
+		return inNode{left, list}, nil
+	}
+
+	tok := p.peek()
+	if tok.kind != tokOp {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if left.typeOf() != right.typeOf() {
+		return nil, fmt.Errorf("cannot compare %s with %s at position %d", left.typeOf(), right.typeOf(), tok.pos)
+	}
+	if left.typeOf() != typeNumber && tok.text != "==" && tok.text != "!=" {
+		return nil, fmt.Errorf("%s only applies to numbers, at position %d", tok.text, tok.pos)
+	}
+	return compareNode{tok.text, left, right}, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return literal{value{kind: typeNumber, num: n, known: true}}, nil
+	case tokString:
+		return literal{value{kind: typeString, str: tok.text, known: true}}, nil
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			return literal{value{kind: typeBool, b: tok.text == "true", known: true}}, nil
+		case "and", "or", "not", "in":
+			return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+		}
+		t, ok := fieldType(tok.text)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q at position %d", tok.text, tok.pos)
+		}
+		if !p.seen[tok.text] {
+			p.seen[tok.text] = true
+			p.fields = append(p.fields, tok.text)
+		}
+		return field{tok.text, t}, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+}
+
+func (p *parser) parseList() ([]node, error) {
+	if tok := p.next(); tok.kind != tokLBracket {
+		return nil, fmt.Errorf("expected [ after in at position %d", tok.pos)
+	}
+	list := []node{}
+	for {
+		item, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := item.(literal); !ok {
+			return nil, fmt.Errorf("lists may only contain numbers or text")
+		}
+		list = append(list, item)
+		tok := p.next()
+		if tok.kind == tokRBracket {
+			return list, nil
+		}
+		if tok.kind != tokComma {
+			return nil, fmt.Errorf("expected , or ] at position %d", tok.pos)
+		}
+	}
+}
+
+func requireBool(nodes ...node) error {
+	for _, n := range nodes {
+		if n.typeOf() != typeBool {
+			return fmt.Errorf("and, or and not need conditions, got a %s", n.typeOf())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package eligibility
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		source string
+		fields []string
+	}{
+		{"age >= 18 and ecog <= 2 and lab.anc >= 1.5", []string{"age", "ecog", "lab.anc"}},
+		{`tumor_group == "lung" and diagnosis in ["nsclc", 'mesothelioma']`, []string{"tumor_group", "diagnosis"}},
+		{"prior.anthracycline or flag.pregnant", []string{"prior.anthracycline", "flag.pregnant"}},
+		{"not (AGE < 18 or age > 75)", []string{"age"}},
+		{"flag.pregnant == false", []string{"flag.pregnant"}},
+		{"lab.creatinine_clearance > .5", []string{"lab.creatinine_clearance"}},
+	}
+	for _, tt := range tests {
+		rule, err := Parse(tt.source)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.source, err)
+			continue
+		}
+		if got := rule.Fields(); !reflect.DeepEqual(got, tt.fields) {
+			t.Errorf("Parse(%q) fields = %v, want %v", tt.source, got, tt.fields)
+		}
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	for _, source := range []string{
+		"",
+		"age",
+		"age >",
+		"age = 18",
+		"age >= 18 and",
+		"and age >= 18",
+		"(age >= 18",
+		"age >= 18)",
+		"age # 18",
+		`diagnosis == "nsclc`,
+		"weight >= 50",
+		"lab. >= 1",
+		`sex < "f"`,
+		`age >= "18"`,
+		"age >= 18 or 5",
+		"not age",
+		`diagnosis in ["nsclc", 1]`,
+		"diagnosis in [sex]",
+		`diagnosis in "nsclc"`,
+		`diagnosis in ["nsclc" "sclc"]`,
+		"age >= 1.2.3",
+		"flag.pregnant flag.smoker",
+	} {
+		if rule, err := Parse(source); err == nil {
+			t.Errorf("Parse(%q) = %q, want an error", source, rule)
+		}
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	flags := func(a, b, c bool) Profile {
+		return Profile{Flags: map[string]bool{"a": a, "b": b, "c": c}}
+	}
+	tests := []struct {
+		source  string
+		profile Profile
+		want    Verdict
+	}{
+		// and binds tighter than or
+		{"flag.a or flag.b and flag.c", flags(true, false, false), Met},
+		{"flag.a and flag.b or flag.c", flags(false, false, true), Met},
+		{"flag.a and (flag.b or flag.c)", flags(false, false, true), NotMet},
+		// not binds tighter than and
+		{"not flag.a and flag.b", flags(true, false, false), NotMet},
+		{"not flag.a and flag.b", flags(false, true, false), Met},
+		{"not (flag.a and flag.b)", flags(true, false, false), Met},
+		{"not not flag.a", flags(true, false, false), Met},
+		// comparisons bind tighter than not
+		{"not age >= 18", Profile{Age: number(17)}, Met},
+	}
+	for _, tt := range tests {
+		rule, err := Parse(tt.source)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.source, err)
+		}
+		if got := rule.Evaluate(tt.profile); got != tt.want {
+			t.Errorf("%q = %s, want %s", tt.source, got, tt.want)
+		}
+	}
+}
+
+func number(n float64) *float64 {
+	return &n
+}
@@ -0,0 +1,113 @@
+package eligibility
+
+// Status summarises a protocol's criteria for one patient.
+type Status string
+
+const (
+	Eligible     Status = "eligible"
+	NotEligible  Status = "not_eligible"
+	Undetermined Status = "undetermined"
+)
+
+type Criterion struct {
+	ID          string
+	Type        string // inclusion, exclusion, notes or unknown
+	Description string
+	Expression  string
+}
+
+type Protocol struct {
+	ID         string
+	Code       string
+	Name       string
+	TumorGroup string
+	Criteria   []Criterion
+}
+
+type CriterionResult struct {
+	ID          string   `json:"id"`
+	Type        string   `json:"type"`
+	Description string   `json:"description"`
+	Rule        string   `json:"rule"`
+	Verdict     Verdict  `json:"verdict"`
+	Missing     []string `json:"missing_fields"`
+	Error       string   `json:"error,omitempty"`
+}
+
+type ProtocolResult struct {
+	ID         string            `json:"id"`
+	Code       string            `json:"code"`
+	Name       string            `json:"name"`
+	TumorGroup string            `json:"tumor_group"`
+	Status     Status            `json:"status"`
+	Criteria   []CriterionResult `json:"criteria"`
+}
+
+// Screen evaluates every criterion of every protocol. For an exclusion the
+// rule describes the excluding condition, so "met" means the patient is
+// excluded. Criteria without a rule are unknown, and a protocol without
+// inclusion or exclusion criteria is undetermined.
+func Screen(protocols []Protocol, p Profile) []ProtocolResult {
+	results := make([]ProtocolResult, 0, len(protocols))
+	for _, proto := range protocols {
+		result := ProtocolResult{
+			ID:         proto.ID,
+			Code:       proto.Code,
+			Name:       proto.Name,
+			TumorGroup: proto.TumorGroup,
+			Criteria:   []CriterionResult{},
+		}
+		excluded, undetermined, screened := false, false, 0
+
+		for _, c := range proto.Criteria {
+			cr := screenCriterion(c, p)
+			result.Criteria = append(result.Criteria, cr)
+
+			switch c.Type {
+			case "inclusion":
+				screened++
+				excluded = excluded || cr.Verdict == NotMet
+				undetermined = undetermined || cr.Verdict == Unknown
+			case "exclusion":
+				screened++
+				excluded = excluded || cr.Verdict == Met
+				undetermined = undetermined || cr.Verdict == Unknown
+			}
+		}
+
+		switch {
+		case excluded:
+			result.Status = NotEligible
+		case undetermined || screened == 0:
+			result.Status = Undetermined
+		default:
+			result.Status = Eligible
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func screenCriterion(c Criterion, p Profile) CriterionResult {
+	cr := CriterionResult{
+		ID:          c.ID,
+		Type:        c.Type,
+		Description: c.Description,
+		Rule:        c.Expression,
+		Verdict:     Unknown,
+		Missing:     []string{},
+	}
+	if c.Expression == "" {
+		return cr
+	}
+	rule, err := Parse(c.Expression)
+	if err != nil {
+		cr.Error = err.Error()
+		return cr
+	}
+	cr.Verdict = rule.Evaluate(p)
+	if cr.Verdict == Unknown {
+		cr.Missing = rule.Missing(p)
+	}
+	return cr
+}
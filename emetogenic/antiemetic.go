@@ -0,0 +1,212 @@
+package emetogenic
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Class groups antiemetics the way the guidelines refer to them.
+type Class string
+
+const (
+	ClassNK1                Class = "nk1_antagonist"
+	Class5HT3               Class = "5ht3_antagonist"
+	ClassDexamethasone      Class = "dexamethasone"
+	ClassOlanzapine         Class = "olanzapine"
+	ClassDopamineAntagonist Class = "dopamine_antagonist"
+)
+
+var classMembers = map[Class][]string{
+	ClassNK1:                {"aprepitant", "fosaprepitant", "netupitant", "rolapitant", "netupitant-palonosetron", "akynzeo", "emend"},
+	Class5HT3:               {"ondansetron", "granisetron", "palonosetron", "dolasetron", "tropisetron", "zofran"},
+	ClassDexamethasone:      {"dexamethasone", "decadron"},
+	ClassOlanzapine:         {"olanzapine", "zyprexa"},
+	ClassDopamineAntagonist: {"metoclopramide", "prochlorperazine", "haloperidol", "domperidone"},
+}
+
+// Classify returns the antiemetic classes a medication belongs to, using its
+// name and alternate names. A combination product can belong to several.
+func Classify(names ...string) []Class {
+	classes := []Class{}
+	for _, class := range []Class{ClassNK1, Class5HT3, ClassDexamethasone, ClassOlanzapine, ClassDopamineAntagonist} {
+		if matches(classMembers[class], names) {
+			classes = append(classes, class)
+		}
+	}
+	// netupitant-palonosetron is both an NK1 and a 5-HT3 antagonist
+	if containsClass(classes, ClassNK1) && matches([]string{"netupitant-palonosetron", "akynzeo"}, names) && !containsClass(classes, Class5HT3) {
+		classes = append(classes, Class5HT3)
+	}
+	return classes
+}
+
+func matches(members []string, names []string) bool {
+	for _, n := range names {
+		n = strings.ToLower(strings.TrimSpace(n))
+		for _, m := range members {
+			if n == m || strings.HasPrefix(n, m+" ") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Recommendation is the prophylaxis expected for an emetogenic level, based
+// on the MASCC/ESMO and ASCO antiemetic guidelines.
+type Recommendation struct {
+	Level    Level   `json:"emetogenic_level"`
+	Required []Class `json:"required"`
+	AnyOf    []Class `json:"any_of"`
+	Optional []Class `json:"optional"`
+	Summary  string  `json:"summary"`
+}
+
+func Recommend(level Level) Recommendation {
+	rec := recommendation(level)
+	for _, classes := range []*[]Class{&rec.Required, &rec.AnyOf, &rec.Optional} {
+		if *classes == nil {
+			*classes = []Class{}
+		}
+	}
+	return rec
+}
+
+func recommendation(level Level) Recommendation {
+	switch level {
+	case LevelHigh:
+		return Recommendation{
+			Level:    level,
+			Required: []Class{ClassNK1, Class5HT3, ClassDexamethasone},
+			Optional: []Class{ClassOlanzapine},
+			Summary:  "NK1 antagonist, 5-HT3 antagonist and dexamethasone, olanzapine may be added",
+		}
+	case LevelModerate:
+		return Recommendation{
+			Level:    level,
+			Required: []Class{Class5HT3, ClassDexamethasone},
+			Optional: []Class{ClassNK1, ClassOlanzapine},
+			Summary:  "5-HT3 antagonist and dexamethasone, an NK1 antagonist may be added (e.g. carboplatin)",
+		}
+	case LevelLow:
+		return Recommendation{
+			Level:   level,
+			AnyOf:   []Class{Class5HT3, ClassDexamethasone, ClassDopamineAntagonist},
+			Summary: "a single agent: 5-HT3 antagonist, dexamethasone or a dopamine antagonist",
+		}
+	case LevelMinimal:
+		return Recommendation{
+			Level:   level,
+			Summary: "no routine prophylaxis",
+		}
+	default:
+		return Recommendation{Level: LevelUnknown, Summary: "emetogenic risk unknown"}
+	}
+}
+
+type AuditStatus string
+
+const (
+	AuditMatch        AuditStatus = "match"
+	AuditUnderTreated AuditStatus = "under_treated"
+	AuditOverTreated  AuditStatus = "over_treated"
+	AuditUnknown      AuditStatus = "unknown"
+)
+
+type Audit struct {
+	Recommendation Recommendation `json:"recommendation"`
+	Status         AuditStatus    `json:"status"`
+	Mismatch       bool           `json:"mismatch"`
+	Present        []Class        `json:"present"`
+	Missing        []Class        `json:"missing"`
+	Unexpected     []Class        `json:"unexpected"`
+	Findings       []string       `json:"findings"`
+}
+
+// Compare checks the premedication classes against the recommendation for
+// the given level.
+func Compare(level Level, premeds []Agent) Audit {
+	rec := Recommend(level)
+	audit := Audit{
+		Recommendation: rec,
+		Status:         AuditMatch,
+		Present:        []Class{},
+		Missing:        []Class{},
+		Unexpected:     []Class{},
+		Findings:       []string{},
+	}
+
+	for _, p := range premeds {
+		for _, class := range Classify(append([]string{p.Name}, p.AlternateNames...)...) {
+			if !containsClass(audit.Present, class) {
+				audit.Present = append(audit.Present, class)
+			}
+		}
+	}
+
+	if level == LevelUnknown {
+		audit.Status = AuditUnknown
+		audit.Findings = append(audit.Findings, "the emetogenic level of the protocol's agents is not set")
+		return audit
+	}
+
+	for _, class := range rec.Required {
+		if !containsClass(audit.Present, class) {
+			audit.Missing = append(audit.Missing, class)
+		}
+	}
+	if len(rec.AnyOf) > 0 && !containsAny(audit.Present, rec.AnyOf) {
+		audit.Missing = append(audit.Missing, rec.AnyOf...)
+		audit.Findings = append(audit.Findings, fmt.Sprintf("%s risk protocols need one of: %s", level, joinClasses(rec.AnyOf)))
+	}
+
+	expected := append(append(append([]Class{}, rec.Required...), rec.AnyOf...), rec.Optional...)
+	for _, class := range audit.Present {
+		// dopamine antagonists are usually prescribed for breakthrough nausea
+		if class == ClassDopamineAntagonist {
+			continue
+		}
+		if !containsClass(expected, class) {
+			audit.Unexpected = append(audit.Unexpected, class)
+		}
+	}
+
+	switch {
+	case len(audit.Missing) > 0:
+		audit.Status = AuditUnderTreated
+		if len(rec.Required) > 0 && len(rec.AnyOf) == 0 {
+			audit.Findings = append(audit.Findings, fmt.Sprintf("%s risk protocols need %s, missing: %s", level, joinClasses(rec.Required), joinClasses(audit.Missing)))
+		}
+	case len(audit.Unexpected) > 0:
+		audit.Status = AuditOverTreated
+		audit.Findings = append(audit.Findings, fmt.Sprintf("%s is more than recommended for %s risk protocols", joinClasses(audit.Unexpected), level))
+	}
+	audit.Mismatch = audit.Status == AuditUnderTreated || audit.Status == AuditOverTreated
+	return audit
+}
+
+func containsClass(classes []Class, class Class) bool {
+	for _, c := range classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(classes []Class, candidates []Class) bool {
+	for _, c := range candidates {
+		if containsClass(classes, c) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinClasses(classes []Class) string {
+	parts := make([]string, 0, len(classes))
+	for _, c := range classes {
+		parts = append(parts, string(c))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,86 @@
+package emetogenic
+
+import "strings"
+
+// levels holds the MASCC/ESMO emetogenic level of the agents we know,
+// intravenous and oral (capecitabine, temozolomide) alike, by generic name.
+// Dose dependent agents (cyclophosphamide, cytarabine) are listed at their
+// usual dose and adjusted when a protocol is assessed. Migration 015 seeded
+// the medications stored at the time from the same list.
+var levels = map[string]Level{
+	"cisplatin":       LevelHigh,
+	"carmustine":      LevelHigh,
+	"dacarbazine":     LevelHigh,
+	"mechlorethamine": LevelHigh,
+	"streptozocin":    LevelHigh,
+
+	"carboplatin":      LevelModerate,
+	"oxaliplatin":      LevelModerate,
+	"irinotecan":       LevelModerate,
+	"cyclophosphamide": LevelModerate,
+	"doxorubicin":      LevelModerate,
+	"epirubicin":       LevelModerate,
+	"daunorubicin":     LevelModerate,
+	"idarubicin":       LevelModerate,
+	"ifosfamide":       LevelModerate,
+	"bendamustine":     LevelModerate,
+	"busulfan":         LevelModerate,
+	"clofarabine":      LevelModerate,
+	"melphalan":        LevelModerate,
+	"temozolomide":     LevelModerate,
+	"trabectedin":      LevelModerate,
+	"azacitidine":      LevelModerate,
+	"alemtuzumab":      LevelModerate,
+	"romidepsin":       LevelModerate,
+	"thiotepa":         LevelModerate,
+	"dactinomycin":     LevelModerate,
+
+	"docetaxel":             LevelLow,
+	"paclitaxel":            LevelLow,
+	"nab-paclitaxel":        LevelLow,
+	"cabazitaxel":           LevelLow,
+	"etoposide":             LevelLow,
+	"fluorouracil":          LevelLow,
+	"gemcitabine":           LevelLow,
+	"pemetrexed":            LevelLow,
+	"topotecan":             LevelLow,
+	"mitomycin":             LevelLow,
+	"mitoxantrone":          LevelLow,
+	"ixabepilone":           LevelLow,
+	"eribulin":              LevelLow,
+	"cetuximab":             LevelLow,
+	"panitumumab":           LevelLow,
+	"trastuzumab emtansine": LevelLow,
+	"carfilzomib":           LevelLow,
+	"cytarabine":            LevelLow,
+	"methotrexate":          LevelLow,
+	"capecitabine":          LevelLow,
+
+	"bevacizumab":   LevelMinimal,
+	"bleomycin":     LevelMinimal,
+	"cladribine":    LevelMinimal,
+	"fludarabine":   LevelMinimal,
+	"nivolumab":     LevelMinimal,
+	"pembrolizumab": LevelMinimal,
+	"ipilimumab":    LevelMinimal,
+	"atezolizumab":  LevelMinimal,
+	"durvalumab":    LevelMinimal,
+	"rituximab":     LevelMinimal,
+	"trastuzumab":   LevelMinimal,
+	"pertuzumab":    LevelMinimal,
+	"vinblastine":   LevelMinimal,
+	"vincristine":   LevelMinimal,
+	"vinorelbine":   LevelMinimal,
+	"bortezomib":    LevelMinimal,
+}
+
+// LevelOf returns the level of the first of the names that is on the list,
+// or LevelUnknown when none is.
+func LevelOf(names ...string) Level {
+	for _, name := range names {
+		if level, ok := levels[strings.Join(strings.Fields(strings.ToLower(name)), " ")]; ok {
+			return level
+		}
+	}
+	return LevelUnknown
+}
@@ -0,0 +1,34 @@
+package emetogenic
+
+import (
+	"os"
+	"regexp"
+	"testing"
+)
+
+// The list seeded the medications stored when migration 015 ran; the two
+// must not drift apart.
+func TestLevelsMatchMigration(t *testing.T) {
+	data, err := os.ReadFile("../sql/schema/015_emetogenicity.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeded := map[string]Level{}
+	update := regexp.MustCompile(`(?s)SET emetogenic_level = '(\w+)'\s+WHERE lower\(name\) IN \(([^)]*)\)`)
+	for _, m := range update.FindAllStringSubmatch(string(data), -1) {
+		for _, name := range regexp.MustCompile(`'([^']+)'`).FindAllStringSubmatch(m[2], -1) {
+			seeded[name[1]] = ParseLevel(m[1])
+		}
+	}
+	if len(seeded) != len(levels) {
+		t.Errorf("migration seeds %d agents, the list has %d", len(seeded), len(levels))
+	}
+	for name, level := range seeded {
+		if got := LevelOf(name); got != level {
+			t.Errorf("LevelOf(%s) = %s, migration seeds %s", name, got, level)
+		}
+	}
+	if got := LevelOf("  Trastuzumab   Emtansine "); got != LevelLow {
+		t.Errorf("LevelOf does not normalize names: %s", got)
+	}
+}
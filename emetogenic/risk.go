@@ -0,0 +1,167 @@
+package emetogenic
+
+import (
+	"bcca_crawler/dosing"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Level mirrors the emetogenic_level_enum values stored on medications.
+type Level string
+
+const (
+	LevelHigh     Level = "high"
+	LevelModerate Level = "moderate"
+	LevelLow      Level = "low"
+	LevelMinimal  Level = "minimal"
+	LevelUnknown  Level = "unknown"
+)
+
+// Rank orders levels so a higher rank is more emetogenic.
+func (l Level) Rank() int {
+	switch l {
+	case LevelHigh:
+		return 4
+	case LevelModerate:
+		return 3
+	case LevelLow:
+		return 2
+	case LevelMinimal:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func ParseLevel(s string) Level {
+	switch Level(strings.ToLower(strings.TrimSpace(s))) {
+	case LevelHigh:
+		return LevelHigh
+	case LevelModerate:
+		return LevelModerate
+	case LevelLow:
+		return LevelLow
+	case LevelMinimal:
+		return LevelMinimal
+	default:
+		return LevelUnknown
+	}
+}
+
+// Agent is one drug given on a treatment day.
+type Agent struct {
+	MedicationID   string   `json:"medication_id"`
+	Name           string   `json:"name"`
+	AlternateNames []string `json:"-"`
+	Dose           string   `json:"dose,omitempty"`
+	Level          Level    `json:"emetogenic_level"`
+}
+
+func (a Agent) is(names ...string) bool {
+	candidates := append([]string{a.Name}, a.AlternateNames...)
+	for _, c := range candidates {
+		c = strings.ToLower(strings.TrimSpace(c))
+		for _, n := range names {
+			if c == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Day is the set of agents given together on one day of a cycle.
+type Day struct {
+	Cycle  string  `json:"cycle"`
+	Day    int     `json:"day"`
+	Agents []Agent `json:"agents"`
+}
+
+type DayRisk struct {
+	Cycle string   `json:"cycle"`
+	Day   int      `json:"day"`
+	Level Level    `json:"emetogenic_level"`
+	Rules []string `json:"rules"`
+}
+
+type Assessment struct {
+	Level        Level     `json:"emetogenic_level"`
+	Driver       string    `json:"driver"`
+	Days         []DayRisk `json:"days"`
+	Unclassified []string  `json:"unclassified_agents"`
+}
+
+var anthracyclines = []string{"doxorubicin", "epirubicin", "daunorubicin", "idarubicin"}
+
+// Assess returns the protocol's overall risk, which is the risk of its most
+// emetogenic treatment day. A day is the level of its most emetogenic agent,
+// raised to high when an anthracycline is given with cyclophosphamide (AC),
+// which MASCC/ESMO and ASCO class as highly emetogenic whatever the levels of
+// the two drugs.
+func Assess(days []Day) Assessment {
+	assessment := Assessment{Level: LevelUnknown, Days: []DayRisk{}, Unclassified: []string{}}
+	unclassified := map[string]bool{}
+
+	for _, day := range days {
+		risk := DayRisk{Cycle: day.Cycle, Day: day.Day, Level: LevelUnknown, Rules: []string{}}
+		driver := ""
+		hasAnthracycline, hasCyclophosphamide := false, false
+
+		for _, agent := range day.Agents {
+			// the AC rule goes by name, an agent without a level counts too
+			if agent.is(anthracyclines...) {
+				hasAnthracycline = true
+			}
+			if agent.is("cyclophosphamide") {
+				hasCyclophosphamide = true
+			}
+			level, rule := adjustForDose(agent)
+			if rule != "" {
+				risk.Rules = append(risk.Rules, rule)
+			}
+			if level == LevelUnknown {
+				unclassified[agent.Name] = true
+				continue
+			}
+			if level.Rank() > risk.Level.Rank() {
+				risk.Level = level
+				driver = agent.Name
+			}
+		}
+
+		if risk.Level != LevelHigh && hasAnthracycline && hasCyclophosphamide {
+			risk.Level = LevelHigh
+			risk.Rules = append(risk.Rules, "anthracycline with cyclophosphamide is highly emetogenic")
+			driver = "anthracycline + cyclophosphamide"
+		}
+
+		if risk.Level.Rank() > assessment.Level.Rank() {
+			assessment.Level = risk.Level
+			assessment.Driver = driver
+		}
+		assessment.Days = append(assessment.Days, risk)
+	}
+
+	for name := range unclassified {
+		assessment.Unclassified = append(assessment.Unclassified, name)
+	}
+	sort.Strings(assessment.Unclassified)
+	return assessment
+}
+
+// adjustForDose applies the dose thresholds of agents whose emetogenicity
+// depends on the dose given.
+func adjustForDose(agent Agent) (Level, string) {
+	dose, ok := dosing.ParseDose(agent.Dose)
+	if !ok || dose.Basis != dosing.BasisMgM2 {
+		return agent.Level, ""
+	}
+	switch {
+	case agent.is("cyclophosphamide") && dose.Amount >= 1500:
+		return LevelHigh, fmt.Sprintf("cyclophosphamide %.0f mg/m2 is at least 1500 mg/m2", dose.Amount)
+	case agent.is("cytarabine") && dose.Amount > 1000:
+		return LevelModerate, fmt.Sprintf("cytarabine %.0f mg/m2 is above 1000 mg/m2", dose.Amount)
+	}
+	return agent.Level, ""
+}
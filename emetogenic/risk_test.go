@@ -0,0 +1,52 @@
+package emetogenic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAssess(t *testing.T) {
+	doxorubicin := Agent{Name: "DOXOrubicin", Dose: "60 mg/m2", Level: LevelModerate}
+	cyclophosphamide := Agent{Name: "Cyclophosphamide", Dose: "600 mg/m2", Level: LevelModerate}
+	tests := []struct {
+		name         string
+		agents       []Agent
+		level        Level
+		driver       string
+		unclassified []string
+	}{
+		{"AC", []Agent{doxorubicin, cyclophosphamide}, LevelHigh, "anthracycline + cyclophosphamide", []string{}},
+		{"AC with an unclassified anthracycline",
+			[]Agent{{Name: "Epirubicin", Dose: "100 mg/m2", Level: LevelUnknown}, cyclophosphamide, {Name: "trial drug", Level: LevelUnknown}},
+			LevelHigh, "anthracycline + cyclophosphamide", []string{"Epirubicin", "trial drug"}},
+		{"AC by alternate name", []Agent{{Name: "Adriamycin", AlternateNames: []string{"doxorubicin"}, Level: LevelModerate}, cyclophosphamide}, LevelHigh, "anthracycline + cyclophosphamide", []string{}},
+		{"single moderate agent", []Agent{cyclophosphamide}, LevelModerate, "Cyclophosphamide", []string{}},
+		{"two moderate agents stay moderate",
+			[]Agent{{Name: "Carboplatin", Level: LevelModerate}, {Name: "Oxaliplatin", Level: LevelModerate}},
+			LevelModerate, "Carboplatin", []string{}},
+		{"high dose cyclophosphamide", []Agent{{Name: "cyclophosphamide", Dose: "1500 mg/m2", Level: LevelModerate}}, LevelHigh, "cyclophosphamide", []string{}},
+		{"high dose cyclophosphamide with a thousands separator", []Agent{{Name: "cyclophosphamide", Dose: "1,500 mg/m2", Level: LevelModerate}}, LevelHigh, "cyclophosphamide", []string{}},
+		{"high dose cytarabine", []Agent{{Name: "cytarabine", Dose: "3,000 mg/m2", Level: LevelLow}}, LevelModerate, "cytarabine", []string{}},
+		{"only unclassified agents", []Agent{{Name: "trial drug", Level: LevelUnknown}}, LevelUnknown, "", []string{"trial drug"}},
+	}
+	for _, tt := range tests {
+		a := Assess([]Day{{Cycle: "1", Day: 1, Agents: tt.agents}})
+		if a.Level != tt.level || a.Driver != tt.driver {
+			t.Errorf("%s: level %s driven by %q, want %s by %q", tt.name, a.Level, a.Driver, tt.level, tt.driver)
+		}
+		if !reflect.DeepEqual(a.Unclassified, tt.unclassified) {
+			t.Errorf("%s: unclassified %v, want %v", tt.name, a.Unclassified, tt.unclassified)
+		}
+	}
+}
+
+func TestAssessWorstDay(t *testing.T) {
+	a := Assess([]Day{
+		{Cycle: "1", Day: 1, Agents: []Agent{{Name: "Vincristine", Level: LevelMinimal}}},
+		{Cycle: "1", Day: 8, Agents: []Agent{{Name: "Cisplatin", Level: LevelHigh}}},
+		{Cycle: "2", Day: 1, Agents: []Agent{{Name: "Docetaxel", Level: LevelLow}}},
+	})
+	if a.Level != LevelHigh || a.Driver != "Cisplatin" || len(a.Days) != 3 {
+		t.Errorf("assessment = %+v", a)
+	}
+}
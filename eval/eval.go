@@ -0,0 +1,205 @@
+// Package eval scores extractions against hand-curated gold protocols, so
+// prompts and models can be compared before they are switched. A gold set
+// is a directory of protocol PDFs, each next to a <name>.gold.json holding
+// the ai_helper.ProtocolPayload a perfect extraction returns.
+package eval
+
+import (
+	"bcca_crawler/ai_helper"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// DefaultDir is the gold set kept in the repository.
+const DefaultDir = "eval/gold"
+
+// Case is one protocol of a gold set.
+type Case struct {
+	Name string
+	PDF  string
+	Gold ai_helper.ProtocolPayload
+}
+
+// Extractor reads the PDF of a case, e.g. ai_helper.Extract with a prompt
+// rendered for the case's tumor group.
+type Extractor func(ctx context.Context, c Case, pdf []byte) (ai_helper.ProtocolPayload, error)
+
+type SectionScore struct {
+	Section string `json:"section"`
+	Score
+}
+
+type CaseReport struct {
+	Name     string         `json:"name"`
+	Error    string         `json:"error,omitempty"`
+	Sections []SectionScore `json:"sections,omitempty"`
+}
+
+type Report struct {
+	Model     string         `json:"model"`
+	Prompt    string         `json:"prompt,omitempty"`
+	Threshold float64        `json:"threshold"`
+	Cases     []CaseReport   `json:"cases"`
+	Totals    []SectionScore `json:"totals"`
+}
+
+// LoadCases reads the gold set in dir. A PDF without a gold file is an
+// error rather than being skipped, so a case cannot silently drop out.
+func LoadCases(dir string) ([]Case, error) {
+	pdfs, err := filepath.Glob(filepath.Join(dir, "*.pdf"))
+	if err != nil {
+		return nil, err
+	}
+	if len(pdfs) == 0 {
+		return nil, fmt.Errorf("no protocol PDFs found in %s", dir)
+	}
+	cases := make([]Case, 0, len(pdfs))
+	for _, pdf := range pdfs {
+		name := strings.TrimSuffix(filepath.Base(pdf), filepath.Ext(pdf))
+		goldPath := filepath.Join(dir, name+".gold.json")
+		data, err := os.ReadFile(goldPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading gold file for %s: %v", pdf, err)
+		}
+		c := Case{Name: name, PDF: pdf}
+		// a misspelled field would silently lower the scores
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&c.Gold); err != nil {
+			return nil, fmt.Errorf("error decoding gold file: %s, with error: %v", goldPath, err)
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// Options tune a run. Model and Prompt only label the report. OutDir, when
+// set, receives the extraction of every case as <name>.json for a closer
+// look.
+type Options struct {
+	Model     string
+	Prompt    string
+	Threshold float64
+	OutDir    string
+}
+
+// Run extracts every case and scores it. A case that fails to extract is
+// reported with its error and left out of the totals.
+func Run(ctx context.Context, cases []Case, extract Extractor, opts Options) (Report, error) {
+	if opts.Threshold <= 0 {
+		opts.Threshold = DefaultThreshold
+	}
+	report := Report{Model: opts.Model, Prompt: opts.Prompt, Threshold: opts.Threshold}
+	if opts.OutDir != "" {
+		if err := os.MkdirAll(opts.OutDir, 0o755); err != nil {
+			return report, err
+		}
+	}
+
+	totals := make([]Score, len(Sections))
+	for _, c := range cases {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		pdf, err := os.ReadFile(c.PDF)
+		if err != nil {
+			return report, err
+		}
+		predicted, err := extract(ctx, c, pdf)
+		if err != nil {
+			report.Cases = append(report.Cases, CaseReport{Name: c.Name, Error: err.Error()})
+			continue
+		}
+		if opts.OutDir != "" {
+			if err := writeJSON(filepath.Join(opts.OutDir, c.Name+".json"), predicted); err != nil {
+				return report, err
+			}
+		}
+
+		cr := ScoreCase(c.Name, c.Gold, predicted, opts.Threshold)
+		for i, s := range cr.Sections {
+			totals[i] = totals[i].Add(s.Score)
+		}
+		report.Cases = append(report.Cases, cr)
+	}
+
+	for i, section := range Sections {
+		report.Totals = append(report.Totals, SectionScore{Section: section.Name, Score: totals[i]})
+	}
+	return report, nil
+}
+
+// ScoreCase compares an extraction with its gold protocol, section by
+// section.
+func ScoreCase(name string, gold, predicted ai_helper.ProtocolPayload, threshold float64) CaseReport {
+	cr := CaseReport{Name: name}
+	for _, section := range Sections {
+		cr.Sections = append(cr.Sections, SectionScore{
+			Section: section.Name,
+			Score:   section.Compare(gold, predicted, threshold),
+		})
+	}
+	return cr
+}
+
+// Print writes the report as a table, followed by what each case missed
+// and added.
+func (r Report) Print(w io.Writer) error {
+	fmt.Fprintf(w, "model %s", r.Model)
+	if r.Prompt != "" {
+		fmt.Fprintf(w, ", prompt %s", r.Prompt)
+	}
+	fmt.Fprintf(w, ", similarity threshold %.2f\n\n", r.Threshold)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "case\tsection\tgold\tpredicted\tmatched\tprecision\trecall\tf1")
+	row := func(name string, s SectionScore) {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%.2f\t%.2f\t%.2f\n", name, s.Section, s.Gold, s.Predicted, s.Matched, s.Precision, s.Recall, s.F1)
+	}
+	for _, c := range r.Cases {
+		if c.Error != "" {
+			fmt.Fprintf(tw, "%s\terror: %s\n", c.Name, c.Error)
+			continue
+		}
+		for _, s := range c.Sections {
+			row(c.Name, s)
+		}
+	}
+	for _, s := range r.Totals {
+		row("TOTAL", s)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, c := range r.Cases {
+		for _, s := range c.Sections {
+			for _, item := range s.Missed {
+				fmt.Fprintf(w, "%s %s missed: %s\n", c.Name, s.Section, item)
+			}
+			for _, item := range s.Extra {
+				fmt.Fprintf(w, "%s %s extra: %s\n", c.Name, s.Section, item)
+			}
+		}
+	}
+	return nil
+}
+
+// WriteJSON saves the report, e.g. to compare two runs later.
+func (r Report) WriteJSON(path string) error {
+	return writeJSON(path, r)
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
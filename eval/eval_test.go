@@ -0,0 +1,121 @@
+package eval
+
+import (
+	"bcca_crawler/ai_helper"
+	"context"
+	"testing"
+)
+
+// TestGoldSet checks every gold file decodes and scores perfectly against
+// itself.
+func TestGoldSet(t *testing.T) {
+	cases, err := LoadCases("gold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		for _, s := range ScoreCase(c.Name, c.Gold, c.Gold, DefaultThreshold).Sections {
+			if s.Gold == 0 && s.Section != "doses" && s.Section != "medications" {
+				t.Errorf("%s: gold file has no %s", c.Name, s.Section)
+			}
+			if s.Precision != 1 || s.Recall != 1 {
+				t.Errorf("%s %s against itself: precision %.2f, recall %.2f", c.Name, s.Section, s.Precision, s.Recall)
+			}
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	gold := []string{"CBC & Diff", "Total bilirubin", "ALT", "Creatinine"}
+	predicted := []string{"cbc & diff", "Bilirubin, total", "Creatinine clearance", "ALT", "ECG"}
+	// word order does not matter, a longer name of another test does
+	s := Compare(gold, predicted, DefaultThreshold)
+	if s.Matched != 3 || len(s.Missed) != 1 || s.Missed[0] != "Creatinine" {
+		t.Errorf("matched %d, want 3 (missed %v, extra %v)", s.Matched, s.Missed, s.Extra)
+	}
+	if s.Precision != 3.0/5 || s.Recall != 3.0/4 {
+		t.Errorf("precision %.2f, recall %.2f; want 0.60, 0.75", s.Precision, s.Recall)
+	}
+
+	empty := Compare(nil, nil, DefaultThreshold)
+	if empty.Precision != 1 || empty.Recall != 1 {
+		t.Errorf("nothing expected and nothing found: precision %.2f, recall %.2f", empty.Precision, empty.Recall)
+	}
+	none := Compare(gold, nil, DefaultThreshold)
+	if none.Precision != 0 || none.Recall != 0 {
+		t.Errorf("nothing found: precision %.2f, recall %.2f", none.Precision, none.Recall)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		match bool
+	}{
+		{"acalabrutinib", "Acalabrutinib", true},
+		{"LY ACAL", "LYACAL", true},
+		{"acalabrutinib 100 mg", "acalabrutinib 100mg", true},
+		{"ibrutinib", "acalabrutinib", false},
+		{"Neutropenia", "Thrombocytopenia", false},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b) >= DefaultThreshold; got != tt.match {
+			t.Errorf("Similarity(%q, %q) = %.2f, match %v; want %v", tt.a, tt.b, Similarity(tt.a, tt.b), got, tt.match)
+		}
+	}
+}
+
+func TestDoseSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		match bool
+	}{
+		{"acalabrutinib 100 mg", "acalabrutinib 100mg", true},
+		{"acalabrutinib 100 mg", "Acalabrutinib 100 MG", true},
+		{"acalabrutinib 100 mg", "acalabrutinib 200 mg", false},
+		{"cyclophosphamide 1000 mg", "cyclophosphamide 100 mg", false},
+		{"cyclophosphamide 1,000 mg", "cyclophosphamide 1000 mg", true},
+		{"rituximab 375 mg/m2", "riTUXimab 375 mg/m²", true},
+		{"rituximab 375 mg/m2", "rituximab 375 mg", false},
+		{"filgrastim 300 mcg", "filgrastim 300 µg", true},
+		{"carboplatin AUC 5", "carboplatin AUC 6", false},
+		{"5-fluorouracil 400 mg/m2", "fluorouracil 400 mg/m2", true},
+		{"ondansetron as directed", "ondansetron 8 mg", false},
+		{"ibrutinib 420 mg", "acalabrutinib 420 mg", false},
+	}
+	for _, tt := range tests {
+		if got := DoseSimilarity(tt.a, tt.b) >= DefaultThreshold; got != tt.match {
+			t.Errorf("DoseSimilarity(%q, %q) = %.2f, match %v; want %v", tt.a, tt.b, DoseSimilarity(tt.a, tt.b), got, tt.match)
+		}
+	}
+}
+
+func TestRunTotals(t *testing.T) {
+	cases, err := LoadCases("gold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := cases[0]
+	// an extraction that misses the first toxicity
+	extract := func(ctx context.Context, c Case, pdf []byte) (ai_helper.ProtocolPayload, error) {
+		p := c.Gold
+		p.Toxicities = p.Toxicities[1:]
+		return p, nil
+	}
+	report, err := Run(context.Background(), []Case{c}, extract, Options{Model: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range report.Totals {
+		missed := report.Cases[0].Sections[i].Missed
+		if s.Section != "toxicities" {
+			if s.F1 != 1 {
+				t.Errorf("%s: f1 %.2f, want 1", s.Section, s.F1)
+			}
+			continue
+		}
+		if s.Precision != 1 || s.Recall >= 1 || len(missed) != 1 || missed[0] != c.Gold.Toxicities[0].Title {
+			t.Errorf("toxicities: precision %.2f, recall %.2f, missed %v", s.Precision, s.Recall, missed)
+		}
+	}
+}
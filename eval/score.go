@@ -0,0 +1,332 @@
+package eval
+
+import (
+	"bcca_crawler/ai_helper"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultThreshold is the similarity two items need to count as the same.
+const DefaultThreshold = 0.8
+
+// Section picks the items of one part of a protocol that are scored. Match
+// is the similarity of two items, Similarity when nil.
+type Section struct {
+	Name  string
+	Items func(p ai_helper.ProtocolPayload) []string
+	Match func(a, b string) float64
+}
+
+// Sections are the parts of a protocol the evaluation scores.
+var Sections = []Section{
+	{Name: "medications", Items: medications},
+	{Name: "doses", Items: doses, Match: DoseSimilarity},
+	{Name: "eligibility", Items: eligibility},
+	{Name: "tests", Items: tests},
+	{Name: "toxicities", Items: toxicities},
+}
+
+// Score counts the items of a section the extraction got right. Missed are
+// gold items it did not find, Extra the ones it found that are not in the
+// gold file.
+type Score struct {
+	Gold      int      `json:"gold"`
+	Predicted int      `json:"predicted"`
+	Matched   int      `json:"matched"`
+	Precision float64  `json:"precision"`
+	Recall    float64  `json:"recall"`
+	F1        float64  `json:"f1"`
+	Missed    []string `json:"missed,omitempty"`
+	Extra     []string `json:"extra,omitempty"`
+}
+
+// Compare scores the items of the section in gold and predicted.
+func (s Section) Compare(gold, predicted ai_helper.ProtocolPayload, threshold float64) Score {
+	match := s.Match
+	if match == nil {
+		match = Similarity
+	}
+	return compare(s.Items(gold), s.Items(predicted), threshold, match)
+}
+
+// Compare matches the predicted items against the gold ones, each item at
+// most once, best matches first.
+func Compare(gold, predicted []string, threshold float64) Score {
+	return compare(gold, predicted, threshold, Similarity)
+}
+
+func compare(gold, predicted []string, threshold float64, match func(a, b string) float64) Score {
+	type pair struct {
+		g, p int
+		sim  float64
+	}
+	var pairs []pair
+	for i, g := range gold {
+		for j, p := range predicted {
+			if sim := match(g, p); sim >= threshold {
+				pairs = append(pairs, pair{i, j, sim})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool { return pairs[a].sim > pairs[b].sim })
+
+	goldUsed := make([]bool, len(gold))
+	predUsed := make([]bool, len(predicted))
+	score := Score{Gold: len(gold), Predicted: len(predicted)}
+	for _, pr := range pairs {
+		if goldUsed[pr.g] || predUsed[pr.p] {
+			continue
+		}
+		goldUsed[pr.g], predUsed[pr.p] = true, true
+		score.Matched++
+	}
+	for i, used := range goldUsed {
+		if !used {
+			score.Missed = append(score.Missed, gold[i])
+		}
+	}
+	for j, used := range predUsed {
+		if !used {
+			score.Extra = append(score.Extra, predicted[j])
+		}
+	}
+	score.rates()
+	return score
+}
+
+// Add sums two scores, for the totals over all cases.
+func (s Score) Add(o Score) Score {
+	sum := Score{Gold: s.Gold + o.Gold, Predicted: s.Predicted + o.Predicted, Matched: s.Matched + o.Matched}
+	sum.rates()
+	return sum
+}
+
+// rates sets precision, recall and F1. An empty side counts as perfect
+// only when the other one is empty too.
+func (s *Score) rates() {
+	s.Precision = ratio(s.Matched, s.Predicted, s.Gold == 0)
+	s.Recall = ratio(s.Matched, s.Gold, s.Predicted == 0)
+	if s.Precision+s.Recall > 0 {
+		s.F1 = 2 * s.Precision * s.Recall / (s.Precision + s.Recall)
+	} else {
+		s.F1 = 0
+	}
+}
+
+func ratio(n, d int, emptyIsPerfect bool) float64 {
+	if d == 0 {
+		if emptyIsPerfect {
+			return 1
+		}
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// Similarity is the Dice coefficient of the letter pairs of two strings
+// once case, punctuation and spacing are ignored, from 0 to 1.
+func Similarity(a, b string) float64 {
+	a, b = normalize(a), normalize(b)
+	if a == b {
+		return 1
+	}
+	ba, bb := bigrams(a), bigrams(b)
+	if len(ba) == 0 || len(bb) == 0 {
+		return 0
+	}
+	counts := map[string]int{}
+	for _, g := range ba {
+		counts[g]++
+	}
+	shared := 0
+	for _, g := range bb {
+		if counts[g] > 0 {
+			counts[g]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(ba)+len(bb))
+}
+
+// DoseSimilarity is the similarity of the medication names of two dose
+// items, or 0 when their doses differ. Names may be spelled differently,
+// but the amounts and units of the doses have to be the same.
+func DoseSimilarity(a, b string) float64 {
+	nameA, doseA := splitDose(a)
+	nameB, doseB := splitDose(b)
+	if !sameAmounts(amounts(doseA), amounts(doseB)) {
+		return 0
+	}
+	return Similarity(nameA, nameB)
+}
+
+// splitDose splits a dose item at the first word after the medication name
+// that starts with a digit. An item without one has no dose.
+func splitDose(item string) (name, dose string) {
+	fields := strings.Fields(item)
+	for i := 1; i < len(fields); i++ {
+		if unicode.IsDigit([]rune(fields[i])[0]) {
+			return strings.Join(fields[:i], " "), strings.Join(fields[i:], " ")
+		}
+	}
+	return item, ""
+}
+
+// amount is a number of a dose with the unit written after it.
+type amount struct {
+	value float64
+	unit  string
+}
+
+var (
+	amountPattern    = regexp.MustCompile(`(\d*\.?\d+)\s*([a-zµμ]+(?:/[a-z]+[0-9²]?)?)?`)
+	thousandsPattern = regexp.MustCompile(`(\d),(\d{3})`)
+)
+
+// units maps spellings of a unit to one name.
+var units = map[string]string{
+	"µg":      "mcg",
+	"μg":      "mcg",
+	"ug":      "mcg",
+	"unit":    "units",
+	"u":       "units",
+	"iu":      "units",
+	"gm":      "g",
+	"mgs":     "mg",
+	"tab":     "tablets",
+	"tabs":    "tablets",
+	"tablet":  "tablets",
+	"cap":     "capsules",
+	"caps":    "capsules",
+	"capsule": "capsules",
+}
+
+// amounts reads the numbers of a dose and their units, in order.
+func amounts(dose string) []amount {
+	dose = thousandsPattern.ReplaceAllString(strings.ToLower(dose), "$1$2")
+	dose = strings.ReplaceAll(dose, "m²", "m2")
+	var out []amount
+	for _, m := range amountPattern.FindAllStringSubmatch(dose, -1) {
+		value, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			continue
+		}
+		unit, per, _ := strings.Cut(m[2], "/")
+		if u, ok := units[unit]; ok {
+			unit = u
+		}
+		if per != "" {
+			unit += "/" + per
+		}
+		out = append(out, amount{value, unit})
+	}
+	return out
+}
+
+func sameAmounts(a, b []amount) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func bigrams(s string) []string {
+	runes := []rune(s)
+	if len(runes) < 2 {
+		return nil
+	}
+	out := make([]string, 0, len(runes)-1)
+	for i := 0; i < len(runes)-1; i++ {
+		out = append(out, string(runes[i:i+2]))
+	}
+	return out
+}
+
+// distinct drops empty items and repeats of the same normalized item.
+func distinct(items []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, item := range items {
+		key := normalize(item)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, strings.TrimSpace(item))
+	}
+	return out
+}
+
+func medications(p ai_helper.ProtocolPayload) []string {
+	var names []string
+	for _, cycle := range p.ProtocolCycles {
+		for _, tx := range cycle.Treatments {
+			names = append(names, tx.MedicationName)
+		}
+	}
+	for _, group := range p.PrescriptionGroups {
+		for _, px := range group.Prescriptions {
+			names = append(names, px.MedicationName)
+		}
+	}
+	return distinct(names)
+}
+
+func doses(p ai_helper.ProtocolPayload) []string {
+	var items []string
+	for _, cycle := range p.ProtocolCycles {
+		for _, tx := range cycle.Treatments {
+			items = append(items, tx.MedicationName+" "+tx.Dose)
+		}
+	}
+	for _, group := range p.PrescriptionGroups {
+		for _, px := range group.Prescriptions {
+			items = append(items, px.MedicationName+" "+px.Dose)
+		}
+	}
+	return distinct(items)
+}
+
+func eligibility(p ai_helper.ProtocolPayload) []string {
+	var items []string
+	for _, c := range p.ProtocolEligibilityCriteria {
+		items = append(items, c.Description)
+	}
+	return distinct(items)
+}
+
+func tests(p ai_helper.ProtocolPayload) []string {
+	var names []string
+	for _, group := range p.TestGroups {
+		for _, test := range group.Tests {
+			names = append(names, test.Name)
+		}
+	}
+	return distinct(names)
+}
+
+func toxicities(p ai_helper.ProtocolPayload) []string {
+	var titles []string
+	for _, tox := range p.Toxicities {
+		titles = append(titles, tox.Title)
+	}
+	return distinct(titles)
+}
@@ -0,0 +1,60 @@
+package fetch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// StatusError is returned for responses outside 2xx (and 304 when the
+// request was conditional). RetryAfter is set when the server asked for it.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status fetching %s: %s", e.URL, e.Status)
+}
+
+// Temporary reports whether the request may succeed if retried later.
+func (e *StatusError) Temporary() bool {
+	return retryableStatus(e.StatusCode)
+}
+
+// DisallowedError is returned when robots.txt forbids fetching a URL.
+type DisallowedError struct {
+	URL string
+}
+
+func (e *DisallowedError) Error() string {
+	return fmt.Sprintf("fetching %s is disallowed by robots.txt", e.URL)
+}
+
+// TooLargeError is returned when a body is bigger than the size limit.
+type TooLargeError struct {
+	URL   string
+	Limit int64
+}
+
+func (e *TooLargeError) Error() string {
+	return fmt.Sprintf("response from %s is larger than %d bytes", e.URL, e.Limit)
+}
+
+// IsStatus reports whether err is a StatusError with the given code.
+func IsStatus(err error, code int) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.StatusCode == code
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package fetch
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+const robots = `# comments are ignored
+User-agent: *
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Crawl-delay: 2
+
+User-agent: otherbot
+Disallow: /
+
+User-agent: bcca_crawler
+User-agent: anotherbot
+Disallow: /drafts
+Allow: /drafts/
+Disallow: /drafts/
+Disallow:
+`
+
+func TestRobotsAllowed(t *testing.T) {
+	wildcard := ParseRobots(robots, "somebot/2.0")
+	ours := ParseRobots(robots, DefaultUserAgent)
+	cases := []struct {
+		name    string
+		robots  *Robots
+		path    string
+		allowed bool
+	}{
+		{"no rule matches", wildcard, "/protocols/LYCHOP.pdf.html", true},
+		{"empty path is the root", wildcard, "", true},
+		{"disallow prefix", wildcard, "/private/notes", false},
+		{"longer allow wins", wildcard, "/private/public/page", true},
+		{"wildcard and anchor", wildcard, "/docs/LYCHOP.pdf", false},
+		{"anchor needs the end", wildcard, "/docs/LYCHOP.pdf?v=2", true},
+		{"agent group replaces the wildcard group", ours, "/private/notes", true},
+		{"agent group rule", ours, "/drafts", false},
+		{"longer allow in agent group", ours, "/drafts/one", true},
+		{"tie goes to allow", ours, "/drafts/", true},
+		{"no robots allows all", ParseRobots("", "x"), "/private", true},
+	}
+	for _, c := range cases {
+		if got := c.robots.Allowed(c.path); got != c.allowed {
+			t.Errorf("%s: Allowed(%q) = %v, want %v", c.name, c.path, got, c.allowed)
+		}
+	}
+	if wildcard.CrawlDelay != 2*time.Second {
+		t.Errorf("crawl delay = %s, want 2s", wildcard.CrawlDelay)
+	}
+	if ours.CrawlDelay != 0 {
+		t.Errorf("crawl delay of the agent group = %s, want none", ours.CrawlDelay)
+	}
+	if ParseRobots(robots, "otherbot").Allowed("/") {
+		t.Error("otherbot may not fetch anything")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"0", 0},
+		{"-5", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{"Sun, 01 Mar 2026 12:00:30 GMT", 30 * time.Second},
+		{"Sunday, 01-Mar-26 12:01:00 GMT", time.Minute},
+		{now.Add(-time.Hour).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+	for _, c := range cases {
+		if got := parseRetryAfter(c.value, now); got != c.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", c.value, got, c.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	f := &Fetcher{opts: Options{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}}
+	cases := []struct {
+		attempt int
+		err     error
+		min     time.Duration
+		max     time.Duration
+	}{
+		{1, nil, 0, 100 * time.Millisecond},
+		{3, nil, 0, 400 * time.Millisecond},
+		{10, nil, 0, time.Second},
+		{64, nil, 0, time.Second},
+		{1, &StatusError{StatusCode: 503, RetryAfter: 5 * time.Second}, 5 * time.Second, 5 * time.Second},
+	}
+	for _, c := range cases {
+		for i := 0; i < 20; i++ {
+			if d := f.backoff(c.attempt, c.err); d < c.min || d > c.max {
+				t.Fatalf("backoff(%d, %v) = %s, want between %s and %s", c.attempt, c.err, d, c.min, c.max)
+			}
+		}
+	}
+}
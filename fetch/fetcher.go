@@ -0,0 +1,380 @@
+// Package fetch is the shared HTTP client for every outbound call: it sends
+// a user agent, honours robots.txt, spaces requests to the same host,
+// retries 429/5xx with jittered backoff, revalidates with conditional
+// requests and caps response sizes.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const DefaultUserAgent = "bcca_crawler/1.0 (BC Cancer protocol research crawler)"
+
+type Options struct {
+	UserAgent     string
+	Timeout       time.Duration // per attempt
+	MinInterval   time.Duration // between two requests to the same host
+	MaxRetries    int
+	BaseBackoff   time.Duration
+	MaxBackoff    time.Duration // longer Retry-After values are not waited for
+	MaxBytes      int64
+	RespectRobots bool
+	CacheBytes    int64 // bodies kept to answer 304 responses
+}
+
+// Response is a fully read response. NotModified is set when the body was
+// served from the cache after a 304.
+type Response struct {
+	URL         string
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+	FetchedAt   time.Time
+	NotModified bool
+}
+
+type Fetcher struct {
+	opts   Options
+	client *http.Client
+
+	mu       sync.Mutex
+	nextSlot map[string]time.Time
+	robots   map[string]robotsEntry
+	cache    map[string]cacheEntry
+	order    []string
+	cached   int64
+}
+
+type robotsEntry struct {
+	robots  *Robots
+	expires time.Time
+}
+
+type cacheEntry struct {
+	etag         string
+	lastModified string
+	resp         Response
+}
+
+// Default is used for pages and documents of crawled sites; Services for
+// APIs and local services, which do not publish robots.txt.
+var (
+	Default  = New(Options{RespectRobots: true})
+	Services = New(Options{MinInterval: 350 * time.Millisecond})
+)
+
+func Get(ctx context.Context, rawURL string) (*Response, error) {
+	return Default.Get(ctx, rawURL)
+}
+
+func New(opts Options) *Fetcher {
+	if opts.UserAgent == "" {
+		opts.UserAgent = DefaultUserAgent
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.MinInterval == 0 {
+		opts.MinInterval = time.Second
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.BaseBackoff == 0 {
+		opts.BaseBackoff = time.Second
+	}
+	if opts.MaxBackoff == 0 {
+		opts.MaxBackoff = 30 * time.Second
+	}
+	if opts.MaxBytes == 0 {
+		opts.MaxBytes = 50 << 20
+	}
+	if opts.CacheBytes == 0 {
+		opts.CacheBytes = 64 << 20
+	}
+	return &Fetcher{
+		opts:     opts,
+		client:   &http.Client{Timeout: opts.Timeout},
+		nextSlot: map[string]time.Time{},
+		robots:   map[string]robotsEntry{},
+		cache:    map[string]cacheEntry{},
+	}
+}
+
+// Get fetches a URL, revalidating a cached copy with If-None-Match and
+// If-Modified-Since when there is one.
+func (f *Fetcher) Get(ctx context.Context, rawURL string) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	entry, cached := f.cache[rawURL]
+	f.mu.Unlock()
+	if cached {
+		if entry.etag != "" {
+			req.Header.Set("If-None-Match", entry.etag)
+		}
+		if entry.lastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.lastModified)
+		}
+	}
+
+	resp, err := f.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.NotModified && cached {
+		hit := entry.resp
+		hit.NotModified = true
+		hit.FetchedAt = resp.FetchedAt
+		return &hit, nil
+	}
+	f.remember(rawURL, *resp)
+	return resp, nil
+}
+
+// Do sends a request with the fetcher's politeness rules. Bodies are
+// replayed on retries through req.GetBody, which http.NewRequest sets for
+// in-memory readers. A 304 is only accepted for conditional requests.
+func (f *Fetcher) Do(req *http.Request) (*Response, error) {
+	ctx := req.Context()
+	host := req.URL.Scheme + "://" + req.URL.Host
+
+	interval := f.opts.MinInterval
+	if f.opts.RespectRobots && req.Method == http.MethodGet && req.URL.Path != "/robots.txt" {
+		robots, err := f.robotsFor(ctx, req.URL)
+		if err != nil {
+			return nil, err
+		}
+		if !robots.Allowed(req.URL.RequestURI()) {
+			return nil, &DisallowedError{URL: req.URL.String()}
+		}
+		if robots.CrawlDelay > interval {
+			interval = robots.CrawlDelay
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= f.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, f.backoff(attempt, lastErr)); err != nil {
+				return nil, err
+			}
+		}
+		if err := f.wait(ctx, host, interval); err != nil {
+			return nil, err
+		}
+
+		resp, err := f.attempt(req, attempt)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if !retryable(ctx, err) {
+			return nil, err
+		}
+		var se *StatusError
+		if errors.As(err, &se) && se.RetryAfter > f.opts.MaxBackoff {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("after %d attempts: %w", f.opts.MaxRetries+1, lastErr)
+}
+
+func (f *Fetcher) attempt(req *http.Request, attempt int) (*Response, error) {
+	r := req.Clone(req.Context())
+	if attempt > 0 && req.Body != nil {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("cannot retry %s %s: request body cannot be replayed", req.Method, req.URL)
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	if r.Header.Get("User-Agent") == "" {
+		r.Header.Set("User-Agent", f.opts.UserAgent)
+	}
+
+	resp, err := f.client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	out := &Response{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		FetchedAt:  time.Now().UTC(),
+	}
+	conditional := r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != ""
+	if resp.StatusCode == http.StatusNotModified && conditional {
+		out.NotModified = true
+		return out, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return nil, &StatusError{
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	if resp.ContentLength > f.opts.MaxBytes {
+		return nil, &TooLargeError{URL: req.URL.String(), Limit: f.opts.MaxBytes}
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.opts.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > f.opts.MaxBytes {
+		return nil, &TooLargeError{URL: req.URL.String(), Limit: f.opts.MaxBytes}
+	}
+	out.Body = body
+	return out, nil
+}
+
+// wait reserves the next free slot for host and sleeps until it.
+func (f *Fetcher) wait(ctx context.Context, host string, interval time.Duration) error {
+	f.mu.Lock()
+	now := time.Now()
+	slot := f.nextSlot[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	f.nextSlot[host] = slot.Add(interval)
+	f.mu.Unlock()
+	return sleep(ctx, time.Until(slot))
+}
+
+// backoff is exponential with full jitter; a longer Retry-After wins.
+func (f *Fetcher) backoff(attempt int, err error) time.Duration {
+	ceiling := f.opts.BaseBackoff << (attempt - 1)
+	if ceiling > f.opts.MaxBackoff || ceiling <= 0 {
+		ceiling = f.opts.MaxBackoff
+	}
+	d := time.Duration(rand.Int63n(int64(ceiling) + 1))
+	var se *StatusError
+	if errors.As(err, &se) && se.RetryAfter > d {
+		d = se.RetryAfter
+	}
+	return d
+}
+
+// robotsFor returns the cached robots.txt of the URL's host. A missing
+// robots.txt (4xx) allows everything; a server error is reported so the
+// crawl does not go ahead blind.
+func (f *Fetcher) robotsFor(ctx context.Context, u *url.URL) (*Robots, error) {
+	host := u.Scheme + "://" + u.Host
+	f.mu.Lock()
+	entry, ok := f.robots[host]
+	f.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.robots, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, host+"/robots.txt", nil)
+	if err != nil {
+		return nil, err
+	}
+	robots := &Robots{}
+	resp, err := f.Do(req)
+	var se *StatusError
+	switch {
+	case err == nil:
+		robots = ParseRobots(string(resp.Body), f.opts.UserAgent)
+	case errors.As(err, &se) && se.StatusCode >= 400 && se.StatusCode < 500:
+	default:
+		return nil, fmt.Errorf("error reading robots.txt of %s: %w", u.Host, err)
+	}
+
+	f.mu.Lock()
+	f.robots[host] = robotsEntry{robots: robots, expires: time.Now().Add(24 * time.Hour)}
+	f.mu.Unlock()
+	return robots, nil
+}
+
+// remember keeps validated responses for conditional requests, dropping
+// the oldest ones past the cache budget.
+func (f *Fetcher) remember(rawURL string, resp Response) {
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	size := int64(len(resp.Body))
+	if etag == "" && lastModified == "" || size > f.opts.CacheBytes {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if old, ok := f.cache[rawURL]; ok {
+		f.cached -= int64(len(old.resp.Body))
+	} else {
+		f.order = append(f.order, rawURL)
+	}
+	f.cache[rawURL] = cacheEntry{etag: etag, lastModified: lastModified, resp: resp}
+	f.cached += size
+	for f.cached > f.opts.CacheBytes && len(f.order) > 0 {
+		oldest := f.order[0]
+		f.order = f.order[1:]
+		f.cached -= int64(len(f.cache[oldest].resp.Body))
+		delete(f.cache, oldest)
+	}
+}
+
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Temporary()
+	}
+	var ne net.Error
+	if errors.As(err, &ne) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// parseRetryAfter reads delay-seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
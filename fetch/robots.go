@@ -0,0 +1,140 @@
+package fetch
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Robots is a parsed robots.txt, reduced to the group that applies to one
+// user agent.
+type Robots struct {
+	rules      []robotsRule
+	CrawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+type robotsGroup struct {
+	agents []string
+	rules  []robotsRule
+	delay  time.Duration
+}
+
+// ParseRobots reads robots.txt and keeps the rules of the most specific
+// group matching agent, falling back on the "*" group.
+func ParseRobots(data string, agent string) *Robots {
+	groups := []*robotsGroup{}
+	var current *robotsGroup
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if current == nil || !lastWasAgent {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			lastWasAgent = true
+			continue
+		case "allow", "disallow":
+			if current != nil {
+				// an empty disallow allows everything and adds no rule
+				if value != "" {
+					current.rules = append(current.rules, robotsRule{allow: key == "allow", pattern: value})
+				}
+			}
+		case "crawl-delay":
+			if current != nil {
+				if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+					current.delay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		}
+		lastWasAgent = false
+	}
+
+	agent = strings.ToLower(agent)
+	if i := strings.IndexByte(agent, '/'); i >= 0 {
+		agent = agent[:i]
+	}
+	var best, wildcard *robotsGroup
+	bestLen := 0
+	for _, g := range groups {
+		for _, a := range g.agents {
+			if a == "*" {
+				if wildcard == nil {
+					wildcard = g
+				}
+			} else if strings.Contains(agent, a) && len(a) > bestLen {
+				best, bestLen = g, len(a)
+			}
+		}
+	}
+	if best == nil {
+		best = wildcard
+	}
+	if best == nil {
+		return &Robots{}
+	}
+	return &Robots{rules: best.rules, CrawlDelay: best.delay}
+}
+
+// Allowed applies the longest matching rule to a path (with its query);
+// on a tie allow wins. Paths no rule matches are allowed.
+func (r *Robots) Allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	allowed, matched := true, -1
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > matched || (n == matched && rule.allow) {
+			allowed, matched = rule.allow, n
+		}
+	}
+	return allowed
+}
+
+// robotsMatch matches a robots.txt pattern where "*" is any sequence and a
+// trailing "$" anchors the end of the path.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i, part := range parts[1:] {
+		if i == len(parts)-2 && anchored {
+			return strings.HasSuffix(rest, part)
+		}
+		j := strings.Index(rest, part)
+		if j < 0 {
+			return false
+		}
+		rest = rest[j+len(part):]
+	}
+	return !anchored || rest == ""
+}
@@ -0,0 +1,402 @@
+package fhir
+
+import (
+	"bcca_crawler/dosing"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// MedicationSystem identifies medications by their id in this database.
+	MedicationSystem = "urn:bcca_crawler:medication"
+	// ProtocolSystem identifies protocols by their BC Cancer code.
+	ProtocolSystem = "http://www.bccancer.bc.ca/protocol-code"
+
+	ucum   = "http://unitsofmeasure.org"
+	snomed = "http://snomed.info/sct"
+)
+
+// Protocol is the view of a protocol exported to FHIR.
+type Protocol struct {
+	ID                string
+	Code              string
+	Name              string
+	TumorGroup        string
+	RevisedOn         string
+	ProtocolURL       string
+	PatientHandoutURL string
+	Cycles            []Cycle
+	MedGroups         []MedGroup
+	LabGroups         []LabGroup
+}
+
+type Cycle struct {
+	ID          string
+	Label       string
+	Duration    string
+	Medications []Medication
+}
+
+// MedGroup is a prescription group such as premed or support.
+type MedGroup struct {
+	ID          string
+	Category    string
+	Comments    string
+	Medications []Medication
+}
+
+// Medication is one treatment or prescription line. ID is the treatment or
+// prescription id, MedicationID the medication it prescribes.
+type Medication struct {
+	ID             string
+	MedicationID   string
+	Name           string
+	Category       string
+	AlternateNames []string
+	Dose           string
+	Route          string
+	Frequency      string
+	Duration       string
+	Instructions   string
+}
+
+type LabGroup struct {
+	ID       string
+	Category string
+	Comments string
+	Tests    []Lab
+}
+
+type Lab struct {
+	ID          string
+	Name        string
+	Description string
+	Unit        string
+	LowerLimit  float64
+	UpperLimit  float64
+}
+
+var routes = map[string]Coding{
+	"iv":         {System: snomed, Code: "47625008", Display: "Intravenous route"},
+	"oral":       {System: snomed, Code: "26643006", Display: "Oral route"},
+	"sc":         {System: snomed, Code: "34206005", Display: "Subcutaneous route"},
+	"im":         {System: snomed, Code: "78421000", Display: "Intramuscular route"},
+	"topical":    {System: snomed, Code: "6064005", Display: "Topical route"},
+	"inhalation": {System: snomed, Code: "447694001", Display: "Respiratory tract route"},
+}
+
+var doseUnits = map[dosing.Basis]string{
+	dosing.BasisMgM2:    "mg/m2",
+	dosing.BasisMgKg:    "mg/kg",
+	dosing.BasisMg:      "mg",
+	dosing.BasisUnitsM2: "[U]/m2",
+	dosing.BasisUnits:   "[U]",
+}
+
+// Build lays the protocol out as a collection Bundle: one PlanDefinition
+// order set whose actions point at an ActivityDefinition per medication
+// order or lab group, plus a MedicationKnowledge per drug and an
+// ObservationDefinition per lab test.
+func Build(p Protocol, stamp time.Time) Bundle {
+	b := &builder{
+		bundle: Bundle{
+			ResourceType: "Bundle",
+			ID:           p.ID,
+			Type:         "collection",
+			Timestamp:    stamp.UTC().Format(time.RFC3339),
+			Entry:        []BundleEntry{},
+		},
+		activities:   map[string]bool{},
+		medications:  map[string]bool{},
+		observations: map[string]bool{},
+	}
+
+	plan := &PlanDefinition{
+		ResourceType: "PlanDefinition",
+		ID:           p.ID,
+		URL:          "urn:uuid:" + p.ID,
+		Identifier:   []Identifier{{System: ProtocolSystem, Value: p.Code}},
+		Version:      p.RevisedOn,
+		Name:         computableName(p.Code),
+		Title:        p.Name,
+		Type: &CodeableConcept{
+			Coding: []Coding{{System: "http://terminology.hl7.org/CodeSystem/plan-definition-type", Code: "order-set", Display: "Order Set"}},
+		},
+		Status:    "active",
+		Date:      fhirDate(p.RevisedOn),
+		Publisher: "BC Cancer",
+		Action:    []PlanDefinitionAction{},
+	}
+	if p.TumorGroup != "" {
+		plan.Topic = []CodeableConcept{{Text: p.TumorGroup}}
+	}
+	if p.ProtocolURL != "" {
+		plan.RelatedArtifact = append(plan.RelatedArtifact, RelatedArtifact{Type: "documentation", Display: "Protocol", URL: p.ProtocolURL})
+	}
+	if p.PatientHandoutURL != "" {
+		plan.RelatedArtifact = append(plan.RelatedArtifact, RelatedArtifact{Type: "documentation", Display: "Patient handout", URL: p.PatientHandoutURL})
+	}
+	b.add(plan)
+
+	for _, g := range p.LabGroups {
+		plan.Action = append(plan.Action, b.labAction(g))
+	}
+	// premedications come before the treatment, other groups after
+	for _, g := range p.MedGroups {
+		if strings.EqualFold(g.Category, "premed") {
+			plan.Action = append(plan.Action, b.groupAction(g))
+		}
+	}
+	for _, c := range p.Cycles {
+		plan.Action = append(plan.Action, b.cycleAction(c))
+	}
+	for _, g := range p.MedGroups {
+		if !strings.EqualFold(g.Category, "premed") {
+			plan.Action = append(plan.Action, b.groupAction(g))
+		}
+	}
+
+	return b.bundle
+}
+
+type builder struct {
+	bundle       Bundle
+	activities   map[string]bool
+	medications  map[string]bool
+	observations map[string]bool
+}
+
+func (b *builder) add(r Resource) {
+	b.bundle.Entry = append(b.bundle.Entry, BundleEntry{FullURL: "urn:uuid:" + r.ResourceID(), Resource: r})
+}
+
+func (b *builder) cycleAction(c Cycle) PlanDefinitionAction {
+	action := PlanDefinitionAction{
+		ID:                c.ID,
+		Title:             c.Label,
+		Description:       c.Duration,
+		GroupingBehavior:  "logical-group",
+		SelectionBehavior: "all",
+		Action:            []PlanDefinitionAction{},
+	}
+	if days, ok := dosing.ParseDurationDays(c.Duration); ok {
+		action.TimingDuration = &Quantity{Value: decimal(float64(days)), Unit: "days", System: ucum, Code: "d"}
+	}
+	for _, m := range c.Medications {
+		action.Action = append(action.Action, b.medicationAction(m, "Treatment"))
+	}
+	return action
+}
+
+func (b *builder) groupAction(g MedGroup) PlanDefinitionAction {
+	action := PlanDefinitionAction{
+		ID:                g.ID,
+		Title:             groupTitle(g.Category),
+		Code:              []CodeableConcept{{Text: g.Category}},
+		TextEquivalent:    g.Comments,
+		GroupingBehavior:  "logical-group",
+		SelectionBehavior: "all",
+		Action:            []PlanDefinitionAction{},
+	}
+	// supportive medications are offered, not all given
+	if !strings.EqualFold(g.Category, "premed") {
+		action.SelectionBehavior = "any"
+		action.RequiredBehavior = "could"
+	}
+	for _, m := range g.Medications {
+		action.Action = append(action.Action, b.medicationAction(m, groupTitle(g.Category)))
+	}
+	return action
+}
+
+// medicationAction adds the order once; a treatment given in several cycles
+// is referenced by each cycle's action.
+func (b *builder) medicationAction(m Medication, context string) PlanDefinitionAction {
+	action := PlanDefinitionAction{
+		Title:               fmt.Sprintf("%s %s", m.Name, m.Dose),
+		DefinitionCanonical: "urn:uuid:" + m.ID,
+	}
+	if b.activities[m.ID] {
+		return action
+	}
+	b.activities[m.ID] = true
+	b.medicationKnowledge(m)
+
+	dosage := Dosage{
+		Sequence:           1,
+		Text:               strings.Join(nonEmpty(m.Dose, strings.ToUpper(m.Route), m.Frequency, m.Duration), " "),
+		PatientInstruction: m.Instructions,
+	}
+	if m.Frequency != "" {
+		dosage.Timing = &Timing{Code: &CodeableConcept{Text: m.Frequency}}
+	}
+	if route, ok := routes[strings.ToLower(m.Route)]; ok {
+		dosage.Route = &CodeableConcept{Coding: []Coding{route}, Text: m.Route}
+	}
+	if dose, ok := dosing.ParseDose(m.Dose); ok {
+		if unit, ok := doseUnits[dose.Basis]; ok {
+			dosage.DoseAndRate = []DoseAndRate{{DoseQuantity: &Quantity{Value: decimal(dose.Amount), Unit: unit, System: ucum, Code: unit}}}
+		}
+	}
+
+	activity := &ActivityDefinition{
+		ResourceType:           "ActivityDefinition",
+		ID:                     m.ID,
+		URL:                    action.DefinitionCanonical,
+		Name:                   computableName(m.Name),
+		Title:                  action.Title,
+		Status:                 "active",
+		Description:            context,
+		Kind:                   "MedicationRequest",
+		Intent:                 "order",
+		ProductCodeableConcept: medicationCode(m),
+		Dosage:                 []Dosage{dosage},
+	}
+	b.add(activity)
+	return action
+}
+
+func (b *builder) medicationKnowledge(m Medication) {
+	if m.MedicationID == "" || b.medications[m.MedicationID] {
+		return
+	}
+	b.medications[m.MedicationID] = true
+
+	knowledge := &MedicationKnowledge{
+		ResourceType: "MedicationKnowledge",
+		ID:           m.MedicationID,
+		Code:         medicationCode(m),
+		Status:       "active",
+		Synonym:      m.AlternateNames,
+	}
+	if route, ok := routes[strings.ToLower(m.Route)]; ok {
+		knowledge.IntendedRoute = []CodeableConcept{{Coding: []Coding{route}, Text: m.Route}}
+	}
+	if m.Category != "" {
+		knowledge.MedicineClassification = []MedicineClassification{{
+			Type:           CodeableConcept{Text: "therapeutic class"},
+			Classification: []CodeableConcept{{Text: m.Category}},
+		}}
+	}
+	b.add(knowledge)
+}
+
+func (b *builder) labAction(g LabGroup) PlanDefinitionAction {
+	activity := &ActivityDefinition{
+		ResourceType:                 "ActivityDefinition",
+		ID:                           g.ID,
+		URL:                          "urn:uuid:" + g.ID,
+		Name:                         computableName(g.Category + " tests"),
+		Title:                        groupTitle(g.Category) + " tests",
+		Status:                       "active",
+		Description:                  g.Comments,
+		Kind:                         "ServiceRequest",
+		Intent:                       "order",
+		Code:                         &CodeableConcept{Text: g.Category},
+		ObservationResultRequirement: []Reference{},
+	}
+
+	for _, t := range g.Tests {
+		activity.ObservationResultRequirement = append(activity.ObservationResultRequirement, Reference{Reference: "urn:uuid:" + t.ID, Display: t.Name})
+		if b.observations[t.ID] {
+			continue
+		}
+		b.observations[t.ID] = true
+
+		observation := &ObservationDefinition{
+			ResourceType:        "ObservationDefinition",
+			ID:                  t.ID,
+			Category:            []CodeableConcept{{Coding: []Coding{{System: "http://terminology.hl7.org/CodeSystem/observation-category", Code: "laboratory", Display: "Laboratory"}}}},
+			Code:                CodeableConcept{Text: t.Name},
+			PreferredReportName: t.Description,
+		}
+		if t.Unit != "" {
+			observation.PermittedDataType = []string{"Quantity"}
+			observation.QuantitativeDetails = &QuantitativeDetails{Unit: &CodeableConcept{Text: t.Unit}}
+		}
+		if t.LowerLimit != 0 || t.UpperLimit != 0 {
+			r := &Range{}
+			if t.LowerLimit != 0 {
+				r.Low = &Quantity{Value: decimal(t.LowerLimit), Unit: t.Unit}
+			}
+			if t.UpperLimit != 0 {
+				r.High = &Quantity{Value: decimal(t.UpperLimit), Unit: t.Unit}
+			}
+			observation.QualifiedInterval = []QualifiedInterval{{Category: "reference", Range: r}}
+		}
+		b.add(observation)
+	}
+	b.add(activity)
+
+	return PlanDefinitionAction{
+		ID:                  g.ID,
+		Title:               activity.Title,
+		TextEquivalent:      g.Comments,
+		DefinitionCanonical: activity.URL,
+	}
+}
+
+func medicationCode(m Medication) *CodeableConcept {
+	code := &CodeableConcept{Text: m.Name}
+	if m.MedicationID != "" {
+		code.Coding = []Coding{{System: MedicationSystem, Code: m.MedicationID, Display: m.Name}}
+	}
+	return code
+}
+
+var nonName = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// computableName turns labels into the machine friendly form FHIR expects
+// for the name element, e.g. "LUAVPC" or "Baseline_tests".
+func computableName(label string) string {
+	name := strings.Trim(nonName.ReplaceAllString(label, "_"), "_")
+	if name == "" {
+		return ""
+	}
+	if c := name[0]; c < 'A' || c > 'Z' {
+		name = strings.ToUpper(name[:1]) + name[1:]
+		if c := name[0]; c < 'A' || c > 'Z' {
+			name = "P" + name
+		}
+	}
+	return name
+}
+
+func groupTitle(category string) string {
+	switch strings.ToLower(category) {
+	case "premed":
+		return "Premedications"
+	case "support":
+		return "Supportive medications"
+	case "":
+		return "Other"
+	}
+	return strings.ToUpper(category[:1]) + category[1:]
+}
+
+// fhirDate keeps revised_on values that are already ISO dates and drops
+// the free text ones.
+func fhirDate(s string) string {
+	for _, layout := range []string{"2006-01-02", "2 Jan 2006", "January 2, 2006", "1 January 2006"} {
+		if d, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return d.Format("2006-01-02")
+		}
+	}
+	return ""
+}
+
+func decimal(f float64) *float64 {
+	return &f
+}
+
+func nonEmpty(parts ...string) []string {
+	out := []string{}
+	for _, p := range parts {
+		if strings.TrimSpace(p) != "" {
+			out = append(out, strings.TrimSpace(p))
+		}
+	}
+	return out
+}
@@ -0,0 +1,177 @@
+package fhir
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func sampleProtocol() Protocol {
+	doxorubicin := Medication{
+		ID:             "0b6a1d0e-6d1f-4a55-9a51-3f8cfb2e0a01",
+		MedicationID:   "5d0c6a58-22a3-4a5e-8a0d-6c7f0f1e2b01",
+		Name:           "DOXOrubicin",
+		Category:       "Antineoplastic",
+		AlternateNames: []string{"Adriamycin"},
+		Dose:           "60 mg/m2",
+		Route:          "iv",
+		Frequency:      "Day 1",
+	}
+	cyclophosphamide := Medication{
+		ID:           "0b6a1d0e-6d1f-4a55-9a51-3f8cfb2e0a02",
+		MedicationID: "5d0c6a58-22a3-4a5e-8a0d-6c7f0f1e2b02",
+		Name:         "cyclophosphamide",
+		Category:     "Antineoplastic",
+		Dose:         "600 mg/m2",
+		Route:        "iv",
+		Frequency:    "Day 1",
+	}
+
+	return Protocol{
+		ID:                "9f1c1b7a-1e0e-4c3b-8d8e-2d8f6c1b0a00",
+		Code:              "BRAJAC",
+		Name:              "Adjuvant therapy for breast cancer using DOXOrubicin and cyclophosphamide",
+		TumorGroup:        "Breast",
+		RevisedOn:         "2024-03-01",
+		ProtocolURL:       "http://www.bccancer.bc.ca/chemotherapy-protocols-site/Documents/Breast/BRAJAC_Protocol.pdf",
+		PatientHandoutURL: "http://www.bccancer.bc.ca/chemotherapy-protocols-site/Documents/Breast/BRAJAC_Handout.pdf",
+		Cycles: []Cycle{
+			{ID: "c1000000-0000-4000-8000-000000000001", Label: "Cycle 1", Duration: "21 days", Medications: []Medication{doxorubicin, cyclophosphamide}},
+			// the same treatments repeat in later cycles
+			{ID: "c1000000-0000-4000-8000-000000000002", Label: "Cycle 2-4", Duration: "21 days", Medications: []Medication{doxorubicin, cyclophosphamide}},
+		},
+		MedGroups: []MedGroup{
+			{
+				ID:       "a2000000-0000-4000-8000-000000000001",
+				Category: "support",
+				Medications: []Medication{{
+					ID: "b3000000-0000-4000-8000-000000000002", MedicationID: "5d0c6a58-22a3-4a5e-8a0d-6c7f0f1e2b04",
+					Name: "prochlorperazine", Dose: "10 mg", Route: "oral", Frequency: "q6h prn", Instructions: "for nausea",
+				}},
+			},
+			{
+				ID:       "a2000000-0000-4000-8000-000000000002",
+				Category: "premed",
+				Comments: "antiemetic protocol for highly emetogenic chemotherapy",
+				Medications: []Medication{{
+					ID: "b3000000-0000-4000-8000-000000000001", MedicationID: "5d0c6a58-22a3-4a5e-8a0d-6c7f0f1e2b03",
+					Name: "ondansetron", Dose: "16 mg", Route: "oral", Frequency: "30 minutes prior to treatment",
+				}},
+			},
+		},
+		LabGroups: []LabGroup{{
+			ID:       "d4000000-0000-4000-8000-000000000001",
+			Category: "baseline",
+			Tests: []Lab{
+				{ID: "e5000000-0000-4000-8000-000000000001", Name: "CBC & diff", Unit: "10^9/L"},
+				{ID: "e5000000-0000-4000-8000-000000000002", Name: "Neutrophils", Unit: "10^9/L", LowerLimit: 1.5},
+			},
+		}},
+	}
+}
+
+func buildSample(t *testing.T) (Bundle, []byte) {
+	t.Helper()
+	bundle := Build(sampleProtocol(), time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return bundle, data
+}
+
+func TestBundleValidatesAgainstSchema(t *testing.T) {
+	_, data := buildSample(t)
+	if err := validate(compileSchema(t), data); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	bundle, data := buildSample(t)
+
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !reflect.DeepEqual(bundle, parsed) {
+		t.Fatalf("bundle changed after a round trip")
+	}
+
+	again, err := json.MarshalIndent(parsed, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Errorf("serialization is not stable:\n%s\n---\n%s", data, again)
+	}
+	if err := validate(compileSchema(t), again); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBundleContents(t *testing.T) {
+	bundle, _ := buildSample(t)
+
+	counts := map[string]int{}
+	urls := map[string]bool{}
+	for _, e := range bundle.Entry {
+		counts[e.Resource.TypeName()]++
+		if urls[e.FullURL] {
+			t.Errorf("duplicate entry %s", e.FullURL)
+		}
+		urls[e.FullURL] = true
+	}
+	// two treatments repeated over both cycles are defined once, plus the
+	// premed, the supportive medication and the lab group
+	want := map[string]int{"PlanDefinition": 1, "ActivityDefinition": 5, "MedicationKnowledge": 4, "ObservationDefinition": 2}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("resource counts = %v, want %v", counts, want)
+	}
+
+	plan := bundle.Entry[0].Resource.(*PlanDefinition)
+	titles := []string{}
+	var check func(actions []PlanDefinitionAction)
+	check = func(actions []PlanDefinitionAction) {
+		for _, a := range actions {
+			if a.DefinitionCanonical != "" && !urls[a.DefinitionCanonical] {
+				t.Errorf("action %q points outside the bundle: %s", a.Title, a.DefinitionCanonical)
+			}
+			check(a.Action)
+		}
+	}
+	check(plan.Action)
+	for _, a := range plan.Action {
+		titles = append(titles, a.Title)
+	}
+	wantTitles := []string{"Baseline tests", "Premedications", "Cycle 1", "Cycle 2-4", "Supportive medications"}
+	if !reflect.DeepEqual(titles, wantTitles) {
+		t.Errorf("action order = %v, want %v", titles, wantTitles)
+	}
+	if plan.Action[2].TimingDuration == nil || *plan.Action[2].TimingDuration.Value != 21 {
+		t.Errorf("cycle duration not exported: %+v", plan.Action[2].TimingDuration)
+	}
+
+	for _, e := range bundle.Entry {
+		activity, ok := e.Resource.(*ActivityDefinition)
+		if !ok || activity.ID != "0b6a1d0e-6d1f-4a55-9a51-3f8cfb2e0a01" {
+			continue
+		}
+		dose := activity.Dosage[0].DoseAndRate[0].DoseQuantity
+		if *dose.Value != 60 || dose.Code != "mg/m2" {
+			t.Errorf("dose = %v %s, want 60 mg/m2", *dose.Value, dose.Code)
+		}
+		if activity.Dosage[0].Route.Coding[0].Code != "47625008" {
+			t.Errorf("route not coded: %+v", activity.Dosage[0].Route)
+		}
+	}
+}
+
+func TestParseRejectsUnknownResources(t *testing.T) {
+	_, err := Parse([]byte(`{"resourceType":"Bundle","type":"collection","entry":[{"resource":{"resourceType":"Patient"}}]}`))
+	if err == nil {
+		t.Error("expected an error for an unsupported resource")
+	}
+}
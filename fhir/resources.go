@@ -0,0 +1,238 @@
+package fhir
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The types below cover the subset of FHIR R4 (4.0.1) needed to publish a
+// protocol as an order set. Field names and cardinalities follow the R4
+// specification so the output validates against the official JSON schema.
+
+type Coding struct {
+	System  string `json:"system,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+type CodeableConcept struct {
+	Coding []Coding `json:"coding,omitempty"`
+	Text   string   `json:"text,omitempty"`
+}
+
+type Identifier struct {
+	System string `json:"system,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+type Reference struct {
+	Reference string `json:"reference,omitempty"`
+	Display   string `json:"display,omitempty"`
+}
+
+type Quantity struct {
+	Value  *float64 `json:"value,omitempty"`
+	Unit   string   `json:"unit,omitempty"`
+	System string   `json:"system,omitempty"`
+	Code   string   `json:"code,omitempty"`
+}
+
+type Range struct {
+	Low  *Quantity `json:"low,omitempty"`
+	High *Quantity `json:"high,omitempty"`
+}
+
+type RelatedArtifact struct {
+	Type    string `json:"type"`
+	Display string `json:"display,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+type TimingRepeat struct {
+	Count      int      `json:"count,omitempty"`
+	Period     *float64 `json:"period,omitempty"`
+	PeriodUnit string   `json:"periodUnit,omitempty"`
+}
+
+type Timing struct {
+	Repeat *TimingRepeat    `json:"repeat,omitempty"`
+	Code   *CodeableConcept `json:"code,omitempty"`
+}
+
+type DoseAndRate struct {
+	DoseQuantity *Quantity `json:"doseQuantity,omitempty"`
+}
+
+type Dosage struct {
+	Sequence           int              `json:"sequence,omitempty"`
+	Text               string           `json:"text,omitempty"`
+	PatientInstruction string           `json:"patientInstruction,omitempty"`
+	Timing             *Timing          `json:"timing,omitempty"`
+	AsNeededBoolean    bool             `json:"asNeededBoolean,omitempty"`
+	Route              *CodeableConcept `json:"route,omitempty"`
+	DoseAndRate        []DoseAndRate    `json:"doseAndRate,omitempty"`
+}
+
+// Resource is implemented by every resource a Bundle can carry.
+type Resource interface {
+	TypeName() string
+	ResourceID() string
+}
+
+type PlanDefinitionAction struct {
+	ID                  string                 `json:"id,omitempty"`
+	Prefix              string                 `json:"prefix,omitempty"`
+	Title               string                 `json:"title,omitempty"`
+	Description         string                 `json:"description,omitempty"`
+	TextEquivalent      string                 `json:"textEquivalent,omitempty"`
+	Code                []CodeableConcept      `json:"code,omitempty"`
+	TimingDuration      *Quantity              `json:"timingDuration,omitempty"`
+	GroupingBehavior    string                 `json:"groupingBehavior,omitempty"`
+	SelectionBehavior   string                 `json:"selectionBehavior,omitempty"`
+	RequiredBehavior    string                 `json:"requiredBehavior,omitempty"`
+	DefinitionCanonical string                 `json:"definitionCanonical,omitempty"`
+	Action              []PlanDefinitionAction `json:"action,omitempty"`
+}
+
+type PlanDefinition struct {
+	ResourceType    string                 `json:"resourceType"`
+	ID              string                 `json:"id,omitempty"`
+	URL             string                 `json:"url,omitempty"`
+	Identifier      []Identifier           `json:"identifier,omitempty"`
+	Version         string                 `json:"version,omitempty"`
+	Name            string                 `json:"name,omitempty"`
+	Title           string                 `json:"title,omitempty"`
+	Type            *CodeableConcept       `json:"type,omitempty"`
+	Status          string                 `json:"status"`
+	Date            string                 `json:"date,omitempty"`
+	Publisher       string                 `json:"publisher,omitempty"`
+	Description     string                 `json:"description,omitempty"`
+	Topic           []CodeableConcept      `json:"topic,omitempty"`
+	RelatedArtifact []RelatedArtifact      `json:"relatedArtifact,omitempty"`
+	Action          []PlanDefinitionAction `json:"action,omitempty"`
+}
+
+func (r *PlanDefinition) TypeName() string   { return "PlanDefinition" }
+func (r *PlanDefinition) ResourceID() string { return r.ID }
+
+type ActivityDefinition struct {
+	ResourceType                 string           `json:"resourceType"`
+	ID                           string           `json:"id,omitempty"`
+	URL                          string           `json:"url,omitempty"`
+	Name                         string           `json:"name,omitempty"`
+	Title                        string           `json:"title,omitempty"`
+	Status                       string           `json:"status"`
+	Description                  string           `json:"description,omitempty"`
+	Kind                         string           `json:"kind,omitempty"`
+	Intent                       string           `json:"intent,omitempty"`
+	Code                         *CodeableConcept `json:"code,omitempty"`
+	ProductCodeableConcept       *CodeableConcept `json:"productCodeableConcept,omitempty"`
+	Dosage                       []Dosage         `json:"dosage,omitempty"`
+	ObservationResultRequirement []Reference      `json:"observationResultRequirement,omitempty"`
+}
+
+func (r *ActivityDefinition) TypeName() string   { return "ActivityDefinition" }
+func (r *ActivityDefinition) ResourceID() string { return r.ID }
+
+type MedicineClassification struct {
+	Type           CodeableConcept   `json:"type"`
+	Classification []CodeableConcept `json:"classification,omitempty"`
+}
+
+type MedicationKnowledge struct {
+	ResourceType           string                   `json:"resourceType"`
+	ID                     string                   `json:"id,omitempty"`
+	Code                   *CodeableConcept         `json:"code,omitempty"`
+	Status                 string                   `json:"status,omitempty"`
+	Synonym                []string                 `json:"synonym,omitempty"`
+	IntendedRoute          []CodeableConcept        `json:"intendedRoute,omitempty"`
+	MedicineClassification []MedicineClassification `json:"medicineClassification,omitempty"`
+}
+
+func (r *MedicationKnowledge) TypeName() string   { return "MedicationKnowledge" }
+func (r *MedicationKnowledge) ResourceID() string { return r.ID }
+
+type QuantitativeDetails struct {
+	Unit *CodeableConcept `json:"unit,omitempty"`
+}
+
+type QualifiedInterval struct {
+	Category string `json:"category,omitempty"`
+	Range    *Range `json:"range,omitempty"`
+}
+
+type ObservationDefinition struct {
+	ResourceType        string               `json:"resourceType"`
+	ID                  string               `json:"id,omitempty"`
+	Category            []CodeableConcept    `json:"category,omitempty"`
+	Code                CodeableConcept      `json:"code"`
+	PermittedDataType   []string             `json:"permittedDataType,omitempty"`
+	PreferredReportName string               `json:"preferredReportName,omitempty"`
+	QuantitativeDetails *QuantitativeDetails `json:"quantitativeDetails,omitempty"`
+	QualifiedInterval   []QualifiedInterval  `json:"qualifiedInterval,omitempty"`
+}
+
+func (r *ObservationDefinition) TypeName() string   { return "ObservationDefinition" }
+func (r *ObservationDefinition) ResourceID() string { return r.ID }
+
+type BundleEntry struct {
+	FullURL  string   `json:"fullUrl,omitempty"`
+	Resource Resource `json:"resource"`
+}
+
+// UnmarshalJSON picks the resource type from the resourceType member.
+func (e *BundleEntry) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		FullURL  string          `json:"fullUrl"`
+		Resource json.RawMessage `json:"resource"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var header struct {
+		ResourceType string `json:"resourceType"`
+	}
+	if err := json.Unmarshal(raw.Resource, &header); err != nil {
+		return err
+	}
+
+	var resource Resource
+	switch header.ResourceType {
+	case "PlanDefinition":
+		resource = &PlanDefinition{}
+	case "ActivityDefinition":
+		resource = &ActivityDefinition{}
+	case "MedicationKnowledge":
+		resource = &MedicationKnowledge{}
+	case "ObservationDefinition":
+		resource = &ObservationDefinition{}
+	default:
+		return fmt.Errorf("unsupported resource type: %q", header.ResourceType)
+	}
+	if err := json.Unmarshal(raw.Resource, resource); err != nil {
+		return fmt.Errorf("error reading %s: %w", header.ResourceType, err)
+	}
+	e.FullURL = raw.FullURL
+	e.Resource = resource
+	return nil
+}
+
+type Bundle struct {
+	ResourceType string        `json:"resourceType"`
+	ID           string        `json:"id,omitempty"`
+	Type         string        `json:"type"`
+	Timestamp    string        `json:"timestamp,omitempty"`
+	Entry        []BundleEntry `json:"entry,omitempty"`
+}
+
+// Parse reads a Bundle produced by Build.
+func Parse(data []byte) (Bundle, error) {
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return Bundle{}, err
+	}
+	if b.ResourceType != "Bundle" {
+		return Bundle{}, fmt.Errorf("expected a Bundle, got %q", b.ResourceType)
+	}
+	return b, nil
+}
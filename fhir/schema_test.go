@@ -0,0 +1,52 @@
+package fhir
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// compileSchema loads the FHIR R4 JSON schema the bundle is checked
+// against: testdata/fhir.schema.json, or the file FHIR_SCHEMA names, such as
+// the official fhir.schema.json from https://hl7.org/fhir/R4/downloads.html.
+func compileSchema(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+	path := "testdata/fhir.schema.json"
+	if p := os.Getenv("FHIR_SCHEMA"); p != "" {
+		path = p
+	}
+	schema, err := jsonschema.NewCompiler().Compile(path)
+	if err != nil {
+		t.Fatalf("compiling %s: %v", path, err)
+	}
+	return schema
+}
+
+func validate(schema *jsonschema.Schema, document []byte) error {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(document))
+	if err != nil {
+		return err
+	}
+	return schema.Validate(doc)
+}
+
+func TestSchemaRejectsInvalidResources(t *testing.T) {
+	schema := compileSchema(t)
+
+	cases := map[string]string{
+		"unknown property": `{"resourceType":"Bundle","type":"collection","entry":[{"resource":{"resourceType":"PlanDefinition","status":"active","titel":"x"}}]}`,
+		"bad enum":         `{"resourceType":"Bundle","type":"list"}`,
+		"bad id":           `{"resourceType":"Bundle","id":"has spaces","type":"collection"}`,
+		"entry not a list": `{"resourceType":"Bundle","type":"collection","entry":{"resource":{"resourceType":"PlanDefinition","status":"active"}}}`,
+		"bad status":       `{"resourceType":"Bundle","type":"collection","entry":[{"resource":{"resourceType":"ActivityDefinition","status":"published"}}]}`,
+	}
+	for name, doc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := validate(schema, []byte(doc)); err == nil {
+				t.Errorf("expected schema errors for %s", doc)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package interactions
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// column aliases accepted in CSV headers and JSON keys
+var columns = map[string][]string{
+	"drug_a":      {"drug_a", "drug1", "drug_1", "object", "medication_a"},
+	"drug_b":      {"drug_b", "drug2", "drug_2", "precipitant", "medication_b"},
+	"severity":    {"severity", "level", "risk"},
+	"description": {"description", "effect", "summary", "interaction"},
+	"management":  {"management", "recommendation", "action"},
+	"source":      {"source", "reference"},
+}
+
+// LoadFile reads an interaction dataset, choosing the format from the file
+// extension (.csv or .json).
+func LoadFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ParseCSV(f)
+	case ".json":
+		return ParseJSON(f)
+	default:
+		return nil, fmt.Errorf("unsupported interaction file: %s, expected .csv or .json", path)
+	}
+}
+
+// ParseCSV reads a CSV file with a header row. Only the two drug columns are
+// required.
+func ParseCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading csv header: %w", err)
+	}
+	position := map[string]int{}
+	for i, h := range header {
+		if field := field(h); field != "" {
+			if _, ok := position[field]; !ok {
+				position[field] = i
+			}
+		}
+	}
+	if _, ok := position["drug_a"]; !ok {
+		return nil, fmt.Errorf("csv header has no drug_a column")
+	}
+	if _, ok := position["drug_b"]; !ok {
+		return nil, fmt.Errorf("csv header has no drug_b column")
+	}
+
+	records := []Record{}
+	line := 1
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("error reading csv line %d: %w", line, err)
+		}
+		get := func(name string) string {
+			i, ok := position[name]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+		records = append(records, newRecord(get))
+	}
+	return clean(records), nil
+}
+
+// ParseJSON reads either an array of interaction objects or an object with an
+// "interactions" array.
+func ParseJSON(r io.Reader) ([]Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []map[string]any
+	if err := json.Unmarshal(data, &items); err != nil {
+		var wrapped struct {
+			Interactions []map[string]any `json:"interactions"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("error parsing interaction json: %w", err)
+		}
+		items = wrapped.Interactions
+	}
+
+	records := make([]Record, 0, len(items))
+	for _, item := range items {
+		values := map[string]string{}
+		for k, v := range item {
+			if f := field(k); f != "" {
+				values[f] = strings.TrimSpace(fmt.Sprint(v))
+			}
+		}
+		records = append(records, newRecord(func(name string) string { return values[name] }))
+	}
+	return clean(records), nil
+}
+
+func field(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.ReplaceAll(name, " ", "_")
+	for field, aliases := range columns {
+		for _, alias := range aliases {
+			if alias == name {
+				return field
+			}
+		}
+	}
+	return ""
+}
+
+func newRecord(get func(string) string) Record {
+	a, b := Pair(get("drug_a"), get("drug_b"))
+	return Record{
+		DrugA:       a,
+		DrugB:       b,
+		Severity:    ParseSeverity(get("severity")),
+		Description: get("description"),
+		Management:  get("management"),
+		Source:      get("source"),
+	}
+}
+
+// clean drops rows without two distinct drugs, and rows saying they do not
+// interact, then keeps the most serious row of a pair listed more than once,
+// which is the one Check would report.
+func clean(records []Record) []Record {
+	kept := records[:0]
+	for _, r := range records {
+		if r.DrugA != "" && r.DrugB != "" && r.DrugA != r.DrugB && r.Severity != SeverityNone {
+			kept = append(kept, r)
+		}
+	}
+	return Collapse(kept)
+}
@@ -0,0 +1,87 @@
+package interactions
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSeverity(t *testing.T) {
+	for in, want := range map[string]Severity{
+		"X":                    SeverityContraindicated,
+		"D":                    SeverityMajor,
+		" Moderate ":           SeverityModerate,
+		"B":                    SeverityMinor,
+		"A":                    SeverityNone,
+		"No known interaction": SeverityNone,
+		"":                     SeverityUnknown,
+		"?":                    SeverityUnknown,
+	} {
+		if got := ParseSeverity(in); got != want {
+			t.Errorf("ParseSeverity(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	const data = `Object,Precipitant,Risk,Summary
+Warfarin,Capecitabine,D,INR rises
+ondansetron,Dexamethasone,A,no known interaction
+Cisplatin,cisplatin,X,same drug
+,Filgrastim,B,no first drug
+Éribulin,zoledronic acid,C,ordered by bytes
+`
+	records, err := ParseCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2: %+v", len(records), records)
+	}
+	if r := records[0]; r.DrugA != "capecitabine" || r.DrugB != "warfarin" || r.Severity != SeverityMajor || r.Description != "INR rises" {
+		t.Errorf("first record = %+v", r)
+	}
+	// byte order puts the accented name last, as COLLATE "C" does
+	if r := records[1]; r.DrugA != "zoledronic acid" || r.DrugB != "éribulin" {
+		t.Errorf("second record = %+v", r)
+	}
+}
+
+func TestParseCSVDuplicatePairs(t *testing.T) {
+	const data = `drug_a,drug_b,severity,description
+Warfarin,Capecitabine,moderate,monitor INR
+Cisplatin,Ondansetron,minor,QT
+capecitabine,WARFARIN,major,INR rises
+Warfarin,Capecitabine,minor,later row
+Cisplatin,Ondansetron,minor,second QT row
+`
+	records, err := ParseCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want one per pair: %+v", len(records), records)
+	}
+	// the stored row has to be the one Check reports, not the last one read
+	if r := records[0]; r.DrugA != "capecitabine" || r.Severity != SeverityMajor || r.Description != "INR rises" {
+		t.Errorf("capecitabine/warfarin kept %+v", r)
+	}
+	if r := records[1]; r.DrugA != "cisplatin" || r.Description != "QT" {
+		t.Errorf("cisplatin/ondansetron kept %+v", r)
+	}
+}
+
+func TestCollapseMatchesCheck(t *testing.T) {
+	records := []Record{
+		{DrugA: "warfarin", DrugB: "capecitabine", Severity: SeverityModerate, Description: "monitor INR"},
+		{DrugA: "Capecitabine", DrugB: "Warfarin", Severity: SeverityContraindicated, Description: "avoid"},
+		{DrugA: "warfarin", DrugB: "capecitabine", Severity: SeverityUnknown, Description: "unrated"},
+	}
+	collapsed := Collapse(records)
+	if len(collapsed) != 1 || collapsed[0].Description != "avoid" {
+		t.Fatalf("Collapse = %+v", collapsed)
+	}
+	hits := Check([]Drug{{Name: "Capecitabine"}}, []Drug{{Name: "Warfarin"}}, records)
+	if len(hits) != 1 || hits[0].Description != collapsed[0].Description {
+		t.Errorf("Check reports %+v, import keeps %+v", hits, collapsed)
+	}
+}
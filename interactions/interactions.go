@@ -0,0 +1,229 @@
+package interactions
+
+import (
+	"sort"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityContraindicated Severity = "contraindicated"
+	SeverityMajor           Severity = "major"
+	SeverityModerate        Severity = "moderate"
+	SeverityMinor           Severity = "minor"
+	SeverityUnknown         Severity = "unknown"
+	// SeverityNone is a dataset row saying two drugs do not interact, e.g.
+	// Lexicomp risk rating A. It is dropped on import, never stored.
+	SeverityNone Severity = "none"
+)
+
+// Rank orders severities from the most to the least serious.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityContraindicated:
+		return 0
+	case SeverityMajor:
+		return 1
+	case SeverityModerate:
+		return 2
+	case SeverityMinor:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// ParseSeverity maps the wording used by common interaction datasets onto
+// the severities stored in the database.
+func ParseSeverity(s string) Severity {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "contraindicated", "contraindication", "avoid", "x":
+		return SeverityContraindicated
+	case "major", "severe", "high", "serious", "d":
+		return SeverityMajor
+	case "moderate", "medium", "c":
+		return SeverityModerate
+	case "minor", "low", "mild", "b":
+		return SeverityMinor
+	case "none", "no interaction", "no known interaction", "a":
+		return SeverityNone
+	default:
+		return SeverityUnknown
+	}
+}
+
+// Record is one interaction between two drugs, identified by name.
+type Record struct {
+	DrugA       string   `json:"drug_a"`
+	DrugB       string   `json:"drug_b"`
+	Severity    Severity `json:"severity"`
+	Description string   `json:"description"`
+	Management  string   `json:"management"`
+	Source      string   `json:"source"`
+}
+
+// Normalize lower-cases a drug name and collapses its whitespace so that the
+// dataset, medication names and alternate names compare equal.
+func Normalize(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// Pair returns the two names normalized and in the order they are stored.
+func Pair(a, b string) (string, string) {
+	a, b = Normalize(a), Normalize(b)
+	if b < a {
+		return b, a
+	}
+	return a, b
+}
+
+// Drug is a medication taking part in the check, known by all its names.
+type Drug struct {
+	ID             string   `json:"medication_id,omitempty"`
+	Name           string   `json:"name"`
+	AlternateNames []string `json:"alternate_names,omitempty"`
+	Source         string   `json:"source"`
+}
+
+func (d Drug) names() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, n := range append([]string{d.Name}, d.AlternateNames...) {
+		n = Normalize(n)
+		if n != "" && !seen[n] {
+			seen[n] = true
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+func (d Drug) key() string {
+	if d.ID != "" {
+		return d.ID
+	}
+	return Normalize(d.Name)
+}
+
+// Names lists every normalized name of the drugs, for narrowing the lookup.
+func Names(drugs ...[]Drug) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, list := range drugs {
+		for _, d := range list {
+			for _, n := range d.names() {
+				if !seen[n] {
+					seen[n] = true
+					names = append(names, n)
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Collapse keeps one record per pair of drugs, the most serious one, or the
+// first of equally serious ones. Records keep the order their pairs first
+// appear in.
+func Collapse(records []Record) []Record {
+	position := map[[2]string]int{}
+	kept := []Record{}
+	for _, r := range records {
+		a, b := Pair(r.DrugA, r.DrugB)
+		i, ok := position[[2]string{a, b}]
+		if !ok {
+			position[[2]string{a, b}] = len(kept)
+			kept = append(kept, r)
+			continue
+		}
+		if r.Severity.Rank() < kept[i].Severity.Rank() {
+			kept[i] = r
+		}
+	}
+	return kept
+}
+
+type Hit struct {
+	Scope       string   `json:"scope"`
+	DrugA       Drug     `json:"drug_a"`
+	DrugB       Drug     `json:"drug_b"`
+	MatchedA    string   `json:"matched_a"`
+	MatchedB    string   `json:"matched_b"`
+	Severity    Severity `json:"severity"`
+	Description string   `json:"description"`
+	Management  string   `json:"management"`
+	Source      string   `json:"source"`
+}
+
+const (
+	ScopeProtocol = "protocol"
+	ScopeHome     = "home_medication"
+)
+
+// Check reports the interactions between every pair of protocol drugs and
+// between each protocol drug and each home medication, most serious first.
+func Check(protocol []Drug, home []Drug, records []Record) []Hit {
+	index := map[[2]string]Record{}
+	for _, r := range Collapse(records) {
+		a, b := Pair(r.DrugA, r.DrugB)
+		index[[2]string{a, b}] = r
+	}
+
+	hits := []Hit{}
+	seen := map[[2]string]bool{}
+	match := func(scope string, x, y Drug) {
+		if x.key() == y.key() {
+			return
+		}
+		pairKey := [2]string{x.key(), y.key()}
+		if pairKey[1] < pairKey[0] {
+			pairKey = [2]string{pairKey[1], pairKey[0]}
+		}
+		if seen[pairKey] {
+			return
+		}
+		var best *Hit
+		for _, nx := range x.names() {
+			for _, ny := range y.names() {
+				a, b := Pair(nx, ny)
+				r, ok := index[[2]string{a, b}]
+				if !ok || (best != nil && best.Severity.Rank() <= r.Severity.Rank()) {
+					continue
+				}
+				best = &Hit{
+					Scope:       scope,
+					DrugA:       x,
+					DrugB:       y,
+					MatchedA:    nx,
+					MatchedB:    ny,
+					Severity:    r.Severity,
+					Description: r.Description,
+					Management:  r.Management,
+					Source:      r.Source,
+				}
+			}
+		}
+		if best != nil {
+			seen[pairKey] = true
+			hits = append(hits, *best)
+		}
+	}
+
+	for i := range protocol {
+		for j := i + 1; j < len(protocol); j++ {
+			match(ScopeProtocol, protocol[i], protocol[j])
+		}
+	}
+	for _, p := range protocol {
+		for _, h := range home {
+			match(ScopeHome, p, h)
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Severity.Rank() < hits[j].Severity.Rank()
+	})
+	return hits
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bcca_crawler/docstore"
 	"bcca_crawler/internal/database"
 	"github.com/go-playground/validator/v10"
 	"database/sql"
@@ -14,7 +15,28 @@ type Config struct {
 	DatabaseUrl    string
 	Secret         string
 	GeminiApiKey   string
+	GeminiModel    string
+	ExtractionMode string
+	NoCache        bool // call the model even when its response is cached
+	CacheOnly      bool // never call the model, for replays
+	LLMPrices      map[string]LLMPrice
+	LLMBudget      LLMBudget
 	MailGunApiKey  string
+	Documents      docstore.Store
+	ArchiveDir     string // local copies of crawled documents the store could not take
 	Validate	   *validator.Validate
 	
-}
\ No newline at end of file
+}
+
+// LLMPrice is what a model costs in USD per million tokens.
+type LLMPrice struct {
+	Input  float64
+	Output float64
+}
+
+// LLMBudget caps the USD spent on the model; extract jobs wait for the next
+// day or month rather than go over. Zero is no cap.
+type LLMBudget struct {
+	Daily   float64
+	Monthly float64
+}
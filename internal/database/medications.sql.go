@@ -17,7 +17,7 @@ import (
 const addMedication = `-- name: AddMedication :one
 INSERT INTO medications (name, description, category,alternate_names)
 VALUES ($1, $2, $3, $4)
-RETURNING id, created_at, updated_at, name, description, alternate_names, category
+RETURNING id, created_at, updated_at, name, description, alternate_names, category, emetogenic_level
 `
 
 type AddMedicationParams struct {
@@ -43,6 +43,7 @@ func (q *Queries) AddMedication(ctx context.Context, arg AddMedicationParams) (M
 		&i.Description,
 		pq.Array(&i.AlternateNames),
 		&i.Category,
+		&i.EmetogenicLevel,
 	)
 	return i, err
 }
@@ -190,7 +191,7 @@ func (q *Queries) GetMedCategoryByID(ctx context.Context, id uuid.UUID) (json.Ra
 }
 
 const getMedicationByID = `-- name: GetMedicationByID :one
-SELECT id, created_at, updated_at, name, description, alternate_names, category FROM medications
+SELECT id, created_at, updated_at, name, description, alternate_names, category, emetogenic_level FROM medications
 WHERE id = $1
 `
 
@@ -205,12 +206,13 @@ func (q *Queries) GetMedicationByID(ctx context.Context, id uuid.UUID) (Medicati
 		&i.Description,
 		pq.Array(&i.AlternateNames),
 		&i.Category,
+		&i.EmetogenicLevel,
 	)
 	return i, err
 }
 
 const getMedicationByName = `-- name: GetMedicationByName :one
-SELECT id, created_at, updated_at, name, description, alternate_names, category FROM medications
+SELECT id, created_at, updated_at, name, description, alternate_names, category, emetogenic_level FROM medications
 WHERE name = $1
 `
 
@@ -225,6 +227,7 @@ func (q *Queries) GetMedicationByName(ctx context.Context, name string) (Medicat
 		&i.Description,
 		pq.Array(&i.AlternateNames),
 		&i.Category,
+		&i.EmetogenicLevel,
 	)
 	return i, err
 }
@@ -349,7 +352,7 @@ func (q *Queries) GetMedicationModificationsByProtocol(ctx context.Context, prot
 }
 
 const getMedications = `-- name: GetMedications :many
-SELECT id, created_at, updated_at, name, description, alternate_names, category FROM medications
+SELECT id, created_at, updated_at, name, description, alternate_names, category, emetogenic_level FROM medications
 ORDER BY name ASC
 `
 
@@ -370,6 +373,7 @@ func (q *Queries) GetMedications(ctx context.Context) ([]Medication, error) {
 			&i.Description,
 			pq.Array(&i.AlternateNames),
 			&i.Category,
+			&i.EmetogenicLevel,
 		); err != nil {
 			return nil, err
 		}
@@ -385,7 +389,7 @@ func (q *Queries) GetMedications(ctx context.Context) ([]Medication, error) {
 }
 
 const getMedicationsByCategory = `-- name: GetMedicationsByCategory :many
-SELECT id, created_at, updated_at, name, description, alternate_names, category FROM medications
+SELECT id, created_at, updated_at, name, description, alternate_names, category, emetogenic_level FROM medications
 WHERE category = $1
 ORDER BY name ASC
 `
@@ -407,6 +411,7 @@ func (q *Queries) GetMedicationsByCategory(ctx context.Context, category string)
 			&i.Description,
 			pq.Array(&i.AlternateNames),
 			&i.Category,
+			&i.EmetogenicLevel,
 		); err != nil {
 			return nil, err
 		}
@@ -945,7 +950,7 @@ SET name = EXCLUDED.name,
     category = EXCLUDED.category,
     alternate_names = EXCLUDED.alternate_names,
     updated_at = NOW()
-RETURNING id, created_at, updated_at, name, description, alternate_names, category
+RETURNING id, created_at, updated_at, name, description, alternate_names, category, emetogenic_level
 `
 
 type UpsertMedicationParams struct {
@@ -973,6 +978,7 @@ func (q *Queries) UpsertMedication(ctx context.Context, arg UpsertMedicationPara
 		&i.Description,
 		pq.Array(&i.AlternateNames),
 		&i.Category,
+		&i.EmetogenicLevel,
 	)
 	return i, err
 }
@@ -7,12 +7,103 @@ package database
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type DocumentKindEnum string
+
+const (
+	DocumentKindEnumProtocol       DocumentKindEnum = "protocol"
+	DocumentKindEnumPpo            DocumentKindEnum = "ppo"
+	DocumentKindEnumPatientHandout DocumentKindEnum = "patient_handout"
+	DocumentKindEnumOther          DocumentKindEnum = "other"
+)
+
+func (e *DocumentKindEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DocumentKindEnum(s)
+	case string:
+		*e = DocumentKindEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DocumentKindEnum: %T", src)
+	}
+	return nil
+}
+
+type NullDocumentKindEnum struct {
+	DocumentKindEnum DocumentKindEnum `json:"document_kind_enum"`
+	Valid            bool             `json:"valid"` // Valid is true if DocumentKindEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDocumentKindEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.DocumentKindEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DocumentKindEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDocumentKindEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DocumentKindEnum), nil
+}
+
+type DoseBasisEnum string
+
+const (
+	DoseBasisEnumMgM2    DoseBasisEnum = "mg_m2"
+	DoseBasisEnumMgKg    DoseBasisEnum = "mg_kg"
+	DoseBasisEnumMg      DoseBasisEnum = "mg"
+	DoseBasisEnumUnitsM2 DoseBasisEnum = "units_m2"
+	DoseBasisEnumUnits   DoseBasisEnum = "units"
+	DoseBasisEnumUnknown DoseBasisEnum = "unknown"
+)
+
+func (e *DoseBasisEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DoseBasisEnum(s)
+	case string:
+		*e = DoseBasisEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DoseBasisEnum: %T", src)
+	}
+	return nil
+}
+
+type NullDoseBasisEnum struct {
+	DoseBasisEnum DoseBasisEnum `json:"dose_basis_enum"`
+	Valid         bool          `json:"valid"` // Valid is true if DoseBasisEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDoseBasisEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.DoseBasisEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DoseBasisEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDoseBasisEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DoseBasisEnum), nil
+}
+
 type EligibilityEnum string
 
 const (
@@ -57,6 +148,93 @@ func (ns NullEligibilityEnum) Value() (driver.Value, error) {
 	return string(ns.EligibilityEnum), nil
 }
 
+type EmetogenicLevelEnum string
+
+const (
+	EmetogenicLevelEnumHigh     EmetogenicLevelEnum = "high"
+	EmetogenicLevelEnumModerate EmetogenicLevelEnum = "moderate"
+	EmetogenicLevelEnumLow      EmetogenicLevelEnum = "low"
+	EmetogenicLevelEnumMinimal  EmetogenicLevelEnum = "minimal"
+	EmetogenicLevelEnumUnknown  EmetogenicLevelEnum = "unknown"
+)
+
+func (e *EmetogenicLevelEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EmetogenicLevelEnum(s)
+	case string:
+		*e = EmetogenicLevelEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EmetogenicLevelEnum: %T", src)
+	}
+	return nil
+}
+
+type NullEmetogenicLevelEnum struct {
+	EmetogenicLevelEnum EmetogenicLevelEnum `json:"emetogenic_level_enum"`
+	Valid               bool                `json:"valid"` // Valid is true if EmetogenicLevelEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEmetogenicLevelEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.EmetogenicLevelEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EmetogenicLevelEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEmetogenicLevelEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EmetogenicLevelEnum), nil
+}
+
+type ExtractionModeEnum string
+
+const (
+	ExtractionModeEnumSingle    ExtractionModeEnum = "single"
+	ExtractionModeEnumSectioned ExtractionModeEnum = "sectioned"
+)
+
+func (e *ExtractionModeEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ExtractionModeEnum(s)
+	case string:
+		*e = ExtractionModeEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ExtractionModeEnum: %T", src)
+	}
+	return nil
+}
+
+type NullExtractionModeEnum struct {
+	ExtractionModeEnum ExtractionModeEnum `json:"extraction_mode_enum"`
+	Valid              bool               `json:"valid"` // Valid is true if ExtractionModeEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullExtractionModeEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.ExtractionModeEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ExtractionModeEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullExtractionModeEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ExtractionModeEnum), nil
+}
+
 type GradeEnum string
 
 const (
@@ -102,6 +280,182 @@ func (ns NullGradeEnum) Value() (driver.Value, error) {
 	return string(ns.GradeEnum), nil
 }
 
+type InteractionSeverityEnum string
+
+const (
+	InteractionSeverityEnumContraindicated InteractionSeverityEnum = "contraindicated"
+	InteractionSeverityEnumMajor           InteractionSeverityEnum = "major"
+	InteractionSeverityEnumModerate        InteractionSeverityEnum = "moderate"
+	InteractionSeverityEnumMinor           InteractionSeverityEnum = "minor"
+	InteractionSeverityEnumUnknown         InteractionSeverityEnum = "unknown"
+)
+
+func (e *InteractionSeverityEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = InteractionSeverityEnum(s)
+	case string:
+		*e = InteractionSeverityEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for InteractionSeverityEnum: %T", src)
+	}
+	return nil
+}
+
+type NullInteractionSeverityEnum struct {
+	InteractionSeverityEnum InteractionSeverityEnum `json:"interaction_severity_enum"`
+	Valid                   bool                    `json:"valid"` // Valid is true if InteractionSeverityEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullInteractionSeverityEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.InteractionSeverityEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.InteractionSeverityEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullInteractionSeverityEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.InteractionSeverityEnum), nil
+}
+
+type JobKindEnum string
+
+const (
+	JobKindEnumCrawl    JobKindEnum = "crawl"
+	JobKindEnumExtract  JobKindEnum = "extract"
+	JobKindEnumDiscover JobKindEnum = "discover"
+)
+
+func (e *JobKindEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = JobKindEnum(s)
+	case string:
+		*e = JobKindEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for JobKindEnum: %T", src)
+	}
+	return nil
+}
+
+type NullJobKindEnum struct {
+	JobKindEnum JobKindEnum `json:"job_kind_enum"`
+	Valid       bool        `json:"valid"` // Valid is true if JobKindEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullJobKindEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.JobKindEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.JobKindEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullJobKindEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.JobKindEnum), nil
+}
+
+type JobStatusEnum string
+
+const (
+	JobStatusEnumPending   JobStatusEnum = "pending"
+	JobStatusEnumRunning   JobStatusEnum = "running"
+	JobStatusEnumSucceeded JobStatusEnum = "succeeded"
+	JobStatusEnumDead      JobStatusEnum = "dead"
+	JobStatusEnumCancelled JobStatusEnum = "cancelled"
+)
+
+func (e *JobStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = JobStatusEnum(s)
+	case string:
+		*e = JobStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for JobStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullJobStatusEnum struct {
+	JobStatusEnum JobStatusEnum `json:"job_status_enum"`
+	Valid         bool          `json:"valid"` // Valid is true if JobStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullJobStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.JobStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.JobStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullJobStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.JobStatusEnum), nil
+}
+
+type LlmCallOutcomeEnum string
+
+const (
+	LlmCallOutcomeEnumSuccess         LlmCallOutcomeEnum = "success"
+	LlmCallOutcomeEnumApiError        LlmCallOutcomeEnum = "api_error"
+	LlmCallOutcomeEnumInvalidResponse LlmCallOutcomeEnum = "invalid_response"
+)
+
+func (e *LlmCallOutcomeEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = LlmCallOutcomeEnum(s)
+	case string:
+		*e = LlmCallOutcomeEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for LlmCallOutcomeEnum: %T", src)
+	}
+	return nil
+}
+
+type NullLlmCallOutcomeEnum struct {
+	LlmCallOutcomeEnum LlmCallOutcomeEnum `json:"llm_call_outcome_enum"`
+	Valid              bool               `json:"valid"` // Valid is true if LlmCallOutcomeEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullLlmCallOutcomeEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.LlmCallOutcomeEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.LlmCallOutcomeEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullLlmCallOutcomeEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.LlmCallOutcomeEnum), nil
+}
+
 type MedAdjCategoryEnum string
 
 const (
@@ -193,6 +547,135 @@ func (ns NullPhysicianSiteEnum) Value() (driver.Value, error) {
 	return string(ns.PhysicianSiteEnum), nil
 }
 
+type PlanCycleStatusEnum string
+
+const (
+	PlanCycleStatusEnumGiven       PlanCycleStatusEnum = "given"
+	PlanCycleStatusEnumDelayed     PlanCycleStatusEnum = "delayed"
+	PlanCycleStatusEnumDoseReduced PlanCycleStatusEnum = "dose_reduced"
+)
+
+func (e *PlanCycleStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PlanCycleStatusEnum(s)
+	case string:
+		*e = PlanCycleStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PlanCycleStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullPlanCycleStatusEnum struct {
+	PlanCycleStatusEnum PlanCycleStatusEnum `json:"plan_cycle_status_enum"`
+	Valid               bool                `json:"valid"` // Valid is true if PlanCycleStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPlanCycleStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.PlanCycleStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PlanCycleStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPlanCycleStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PlanCycleStatusEnum), nil
+}
+
+type PlanStatusEnum string
+
+const (
+	PlanStatusEnumActive       PlanStatusEnum = "active"
+	PlanStatusEnumOnHold       PlanStatusEnum = "on_hold"
+	PlanStatusEnumCompleted    PlanStatusEnum = "completed"
+	PlanStatusEnumDiscontinued PlanStatusEnum = "discontinued"
+)
+
+func (e *PlanStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PlanStatusEnum(s)
+	case string:
+		*e = PlanStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PlanStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullPlanStatusEnum struct {
+	PlanStatusEnum PlanStatusEnum `json:"plan_status_enum"`
+	Valid          bool           `json:"valid"` // Valid is true if PlanStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPlanStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.PlanStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PlanStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPlanStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PlanStatusEnum), nil
+}
+
+type PpoFormatEnum string
+
+const (
+	PpoFormatEnumPdf  PpoFormatEnum = "pdf"
+	PpoFormatEnumHtml PpoFormatEnum = "html"
+)
+
+func (e *PpoFormatEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PpoFormatEnum(s)
+	case string:
+		*e = PpoFormatEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PpoFormatEnum: %T", src)
+	}
+	return nil
+}
+
+type NullPpoFormatEnum struct {
+	PpoFormatEnum PpoFormatEnum `json:"ppo_format_enum"`
+	Valid         bool          `json:"valid"` // Valid is true if PpoFormatEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPpoFormatEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.PpoFormatEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PpoFormatEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPpoFormatEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PpoFormatEnum), nil
+}
+
 type PrescriptionRouteEnum string
 
 const (
@@ -240,6 +723,135 @@ func (ns NullPrescriptionRouteEnum) Value() (driver.Value, error) {
 	return string(ns.PrescriptionRouteEnum), nil
 }
 
+type ProvenanceEntityEnum string
+
+const (
+	ProvenanceEntityEnumTreatment          ProvenanceEntityEnum = "treatment"
+	ProvenanceEntityEnumPrescription       ProvenanceEntityEnum = "prescription"
+	ProvenanceEntityEnumCriterion          ProvenanceEntityEnum = "criterion"
+	ProvenanceEntityEnumToxicityAdjustment ProvenanceEntityEnum = "toxicity_adjustment"
+	ProvenanceEntityEnumTest               ProvenanceEntityEnum = "test"
+)
+
+func (e *ProvenanceEntityEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProvenanceEntityEnum(s)
+	case string:
+		*e = ProvenanceEntityEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProvenanceEntityEnum: %T", src)
+	}
+	return nil
+}
+
+type NullProvenanceEntityEnum struct {
+	ProvenanceEntityEnum ProvenanceEntityEnum `json:"provenance_entity_enum"`
+	Valid                bool                 `json:"valid"` // Valid is true if ProvenanceEntityEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProvenanceEntityEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProvenanceEntityEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProvenanceEntityEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProvenanceEntityEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProvenanceEntityEnum), nil
+}
+
+type RuleAuthorEnum string
+
+const (
+	RuleAuthorEnumAi     RuleAuthorEnum = "ai"
+	RuleAuthorEnumEditor RuleAuthorEnum = "editor"
+)
+
+func (e *RuleAuthorEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RuleAuthorEnum(s)
+	case string:
+		*e = RuleAuthorEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RuleAuthorEnum: %T", src)
+	}
+	return nil
+}
+
+type NullRuleAuthorEnum struct {
+	RuleAuthorEnum RuleAuthorEnum `json:"rule_author_enum"`
+	Valid          bool           `json:"valid"` // Valid is true if RuleAuthorEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRuleAuthorEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.RuleAuthorEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.RuleAuthorEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRuleAuthorEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.RuleAuthorEnum), nil
+}
+
+type ScheduleActionEnum string
+
+const (
+	ScheduleActionEnumDiscover ScheduleActionEnum = "discover"
+	ScheduleActionEnumCrawl    ScheduleActionEnum = "crawl"
+)
+
+func (e *ScheduleActionEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ScheduleActionEnum(s)
+	case string:
+		*e = ScheduleActionEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ScheduleActionEnum: %T", src)
+	}
+	return nil
+}
+
+type NullScheduleActionEnum struct {
+	ScheduleActionEnum ScheduleActionEnum `json:"schedule_action_enum"`
+	Valid              bool               `json:"valid"` // Valid is true if ScheduleActionEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullScheduleActionEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.ScheduleActionEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ScheduleActionEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullScheduleActionEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ScheduleActionEnum), nil
+}
+
 type TumorGroupEnum string
 
 const (
@@ -324,6 +936,115 @@ type CancerProtocol struct {
 	ProtocolID uuid.UUID `json:"protocol_id"`
 }
 
+type CtcaeGrade struct {
+	TermID      uuid.UUID `json:"term_id"`
+	Grade       int16     `json:"grade"`
+	Description string    `json:"description"`
+}
+
+type CtcaeTerm struct {
+	ID               uuid.UUID `json:"id"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	MeddraCode       string    `json:"meddra_code"`
+	Term             string    `json:"term"`
+	Soc              string    `json:"soc"`
+	Definition       string    `json:"definition"`
+	NavigationalNote string    `json:"navigational_note"`
+	Version          string    `json:"version"`
+}
+
+type DoseEquivalenceClass struct {
+	ID                uuid.UUID     `json:"id"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+	Name              string        `json:"name"`
+	ReferenceDrug     string        `json:"reference_drug"`
+	MaxCumulativeDose float64       `json:"max_cumulative_dose"`
+	DoseBasis         DoseBasisEnum `json:"dose_basis"`
+	WarningThreshold  float64       `json:"warning_threshold"`
+	Notes             string        `json:"notes"`
+}
+
+type EligibilityRule struct {
+	CriteriaID uuid.UUID      `json:"criteria_id"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	Expression string         `json:"expression"`
+	Author     RuleAuthorEnum `json:"author"`
+}
+
+type Extraction struct {
+	ID                     uuid.UUID          `json:"id"`
+	CreatedAt              time.Time          `json:"created_at"`
+	ProtocolID             uuid.UUID          `json:"protocol_id"`
+	DocumentHash           string             `json:"document_hash"`
+	PromptVersionID        uuid.UUID          `json:"prompt_version_id"`
+	Model                  string             `json:"model"`
+	Mode                   ExtractionModeEnum `json:"mode"`
+	SectionPromptVersionID uuid.NullUUID      `json:"section_prompt_version_id"`
+	Conflicts              json.RawMessage    `json:"conflicts"`
+}
+
+type ExtractionCache struct {
+	ID              uuid.UUID       `json:"id"`
+	CreatedAt       time.Time       `json:"created_at"`
+	DocumentHash    string          `json:"document_hash"`
+	PromptVersionID uuid.UUID       `json:"prompt_version_id"`
+	Model           string          `json:"model"`
+	SchemaHash      string          `json:"schema_hash"`
+	Purpose         string          `json:"purpose"`
+	RawResponse     string          `json:"raw_response"`
+	Payload         json.RawMessage `json:"payload"`
+}
+
+type Interaction struct {
+	ID          uuid.UUID               `json:"id"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+	DrugA       string                  `json:"drug_a"`
+	DrugB       string                  `json:"drug_b"`
+	Severity    InteractionSeverityEnum `json:"severity"`
+	Description string                  `json:"description"`
+	Management  string                  `json:"management"`
+	Source      string                  `json:"source"`
+}
+
+type Job struct {
+	ID             uuid.UUID       `json:"id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Kind           JobKindEnum     `json:"kind"`
+	Target         string          `json:"target"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         JobStatusEnum   `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	MaxAttempts    int32           `json:"max_attempts"`
+	LastError      string          `json:"last_error"`
+	RunAt          time.Time       `json:"run_at"`
+	StartedAt      sql.NullTime    `json:"started_at"`
+	FinishedAt     sql.NullTime    `json:"finished_at"`
+	LockedBy       string          `json:"locked_by"`
+	LeaseExpiresAt sql.NullTime    `json:"lease_expires_at"`
+	AttemptErrors  json.RawMessage `json:"attempt_errors"`
+}
+
+type LlmCall struct {
+	ID             uuid.UUID          `json:"id"`
+	CreatedAt      time.Time          `json:"created_at"`
+	Model          string             `json:"model"`
+	Purpose        string             `json:"purpose"`
+	DocumentHash   string             `json:"document_hash"`
+	Attempt        int32              `json:"attempt"`
+	Outcome        LlmCallOutcomeEnum `json:"outcome"`
+	Error          string             `json:"error"`
+	PromptTokens   int32              `json:"prompt_tokens"`
+	ResponseTokens int32              `json:"response_tokens"`
+	TotalTokens    int32              `json:"total_tokens"`
+	LatencyMs      int32              `json:"latency_ms"`
+	CostUsd        float64            `json:"cost_usd"`
+}
+
 type Log struct {
 	ID        uuid.UUID `json:"id"`
 	CreatedAt time.Time `json:"created_at"`
@@ -335,13 +1056,27 @@ type Log struct {
 }
 
 type Medication struct {
-	ID             uuid.UUID `json:"id"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	Name           string    `json:"name"`
-	Description    string    `json:"description"`
-	AlternateNames []string  `json:"alternate_names"`
-	Category       string    `json:"category"`
+	ID              uuid.UUID           `json:"id"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+	Name            string              `json:"name"`
+	Description     string              `json:"description"`
+	AlternateNames  []string            `json:"alternate_names"`
+	Category        string              `json:"category"`
+	EmetogenicLevel EmetogenicLevelEnum `json:"emetogenic_level"`
+}
+
+type MedicationDoseLimit struct {
+	ID                 uuid.UUID     `json:"id"`
+	CreatedAt          time.Time     `json:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at"`
+	MedicationID       uuid.UUID     `json:"medication_id"`
+	MaxCumulativeDose  float64       `json:"max_cumulative_dose"`
+	DoseBasis          DoseBasisEnum `json:"dose_basis"`
+	WarningThreshold   float64       `json:"warning_threshold"`
+	EquivalenceClassID uuid.NullUUID `json:"equivalence_class_id"`
+	EquivalenceFactor  float64       `json:"equivalence_factor"`
+	Notes              string        `json:"notes"`
 }
 
 type MedicationModification struct {
@@ -377,6 +1112,24 @@ type Physician struct {
 	Site      PhysicianSiteEnum `json:"site"`
 }
 
+type Prompt struct {
+	ID            uuid.UUID     `json:"id"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	Name          string        `json:"name"`
+	Description   string        `json:"description"`
+	ActiveVersion sql.NullInt32 `json:"active_version"`
+}
+
+type PromptVersion struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	PromptID  uuid.UUID `json:"prompt_id"`
+	Version   int32     `json:"version"`
+	Template  string    `json:"template"`
+	Notes     string    `json:"notes"`
+}
+
 type Protocol struct {
 	ID                uuid.UUID `json:"id"`
 	CreatedAt         time.Time `json:"created_at"`
@@ -418,6 +1171,21 @@ type ProtocolCycle struct {
 	ProtocolID    uuid.UUID `json:"protocol_id"`
 }
 
+type ProtocolDocument struct {
+	ID           uuid.UUID        `json:"id"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+	ProtocolID   uuid.UUID        `json:"protocol_id"`
+	Kind         DocumentKindEnum `json:"kind"`
+	Title        string           `json:"title"`
+	Url          string           `json:"url"`
+	ArchivePath  string           `json:"archive_path"`
+	ContentType  string           `json:"content_type"`
+	SizeBytes    int64            `json:"size_bytes"`
+	FetchedAt    sql.NullTime     `json:"fetched_at"`
+	DocumentHash sql.NullString   `json:"document_hash"`
+}
+
 type ProtocolEligibilityCriteriaValue struct {
 	ProtocolID uuid.UUID `json:"protocol_id"`
 	CriteriaID uuid.UUID `json:"criteria_id"`
@@ -431,6 +1199,19 @@ type ProtocolEligibilityCriterium struct {
 	Description string          `json:"description"`
 }
 
+type ProtocolListing struct {
+	Code        string        `json:"code"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	ProtocolID  uuid.NullUUID `json:"protocol_id"`
+	TumorGroup  string        `json:"tumor_group"`
+	Description string        `json:"description"`
+	PageUrl     string        `json:"page_url"`
+	FirstSeenAt time.Time     `json:"first_seen_at"`
+	LastSeenAt  time.Time     `json:"last_seen_at"`
+	RetiredAt   sql.NullTime  `json:"retired_at"`
+}
+
 type ProtocolMed struct {
 	ID         uuid.UUID `json:"id"`
 	CreatedAt  time.Time `json:"created_at"`
@@ -446,12 +1227,17 @@ type ProtocolMedsValue struct {
 }
 
 type ProtocolPpo struct {
-	ID         uuid.UUID `json:"id"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
-	Title      string    `json:"title"`
-	Url        string    `json:"url"`
-	ProtocolID uuid.UUID `json:"protocol_id"`
+	ID                uuid.UUID     `json:"id"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+	Title             string        `json:"title"`
+	Url               string        `json:"url"`
+	ProtocolID        uuid.UUID     `json:"protocol_id"`
+	Format            PpoFormatEnum `json:"format"`
+	Rendered          bool          `json:"rendered"`
+	Content           []byte        `json:"content"`
+	ContentHash       string        `json:"content_hash"`
+	ProtocolRevisedOn string        `json:"protocol_revised_on"`
 }
 
 type ProtocolPrecaution struct {
@@ -472,6 +1258,14 @@ type ProtocolReferencesValue struct {
 	ReferenceID uuid.UUID `json:"reference_id"`
 }
 
+type ProtocolSource struct {
+	ID           uuid.UUID `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	ProtocolID   uuid.UUID `json:"protocol_id"`
+	DocumentHash string    `json:"document_hash"`
+	RevisedOn    string    `json:"revised_on"`
+}
+
 type ProtocolTest struct {
 	ID         uuid.UUID `json:"id"`
 	CreatedAt  time.Time `json:"created_at"`
@@ -508,6 +1302,18 @@ type ProtocolTreatment struct {
 	AdministrationGuide string                `json:"administration_guide"`
 }
 
+type Provenance struct {
+	ID           uuid.UUID            `json:"id"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+	ProtocolID   uuid.UUID            `json:"protocol_id"`
+	EntityType   ProvenanceEntityEnum `json:"entity_type"`
+	EntityID     uuid.UUID            `json:"entity_id"`
+	Page         sql.NullInt32        `json:"page"`
+	Quote        string               `json:"quote"`
+	ExtractionID uuid.NullUUID        `json:"extraction_id"`
+}
+
 type RefreshToken struct {
 	Token     string       `json:"token"`
 	CreatedAt time.Time    `json:"created_at"`
@@ -517,6 +1323,31 @@ type RefreshToken struct {
 	UserID    uuid.UUID    `json:"user_id"`
 }
 
+type Schedule struct {
+	ID        uuid.UUID          `json:"id"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	Name      string             `json:"name"`
+	Cron      string             `json:"cron"`
+	Action    ScheduleActionEnum `json:"action"`
+	Target    string             `json:"target"`
+	Paused    bool               `json:"paused"`
+	NextRunAt sql.NullTime       `json:"next_run_at"`
+	LastRunAt sql.NullTime       `json:"last_run_at"`
+	LastError string             `json:"last_error"`
+}
+
+type SourceDocument struct {
+	Hash        string          `json:"hash"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Url         string          `json:"url"`
+	ContentType string          `json:"content_type"`
+	SizeBytes   int64           `json:"size_bytes"`
+	FetchedAt   time.Time       `json:"fetched_at"`
+	Headers     json.RawMessage `json:"headers"`
+}
+
 type Test struct {
 	ID           uuid.UUID `json:"id"`
 	CreatedAt    time.Time `json:"created_at"`
@@ -531,12 +1362,13 @@ type Test struct {
 }
 
 type Toxicity struct {
-	ID          uuid.UUID `json:"id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Title       string    `json:"title"`
-	Category    string    `json:"category"`
-	Description string    `json:"description"`
+	ID          uuid.UUID     `json:"id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	Title       string        `json:"title"`
+	Category    string        `json:"category"`
+	Description string        `json:"description"`
+	CtcaeTermID uuid.NullUUID `json:"ctcae_term_id"`
 }
 
 type ToxicityGrade struct {
@@ -553,6 +1385,41 @@ type TreatmentCyclesValue struct {
 	ProtocolCyclesID    uuid.UUID `json:"protocol_cycles_id"`
 }
 
+type TreatmentPlan struct {
+	ID                uuid.UUID       `json:"id"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+	PatientRef        string          `json:"patient_ref"`
+	ProtocolID        uuid.UUID       `json:"protocol_id"`
+	ProtocolCode      string          `json:"protocol_code"`
+	ProtocolRevisedOn string          `json:"protocol_revised_on"`
+	StartDate         time.Time       `json:"start_date"`
+	PlannedCycles     int32           `json:"planned_cycles"`
+	Status            PlanStatusEnum  `json:"status"`
+	HeightCm          float64         `json:"height_cm"`
+	WeightKg          float64         `json:"weight_kg"`
+	Bsa               float64         `json:"bsa"`
+	Baseline          json.RawMessage `json:"baseline"`
+	Notes             string          `json:"notes"`
+}
+
+type TreatmentPlanCycle struct {
+	ID              uuid.UUID           `json:"id"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+	PlanID          uuid.UUID           `json:"plan_id"`
+	CycleNumber     int32               `json:"cycle_number"`
+	Status          PlanCycleStatusEnum `json:"status"`
+	RecordedOn      time.Time           `json:"recorded_on"`
+	DelayDays       int32               `json:"delay_days"`
+	DosePercent     float64             `json:"dose_percent"`
+	Reason          string              `json:"reason"`
+	ToxicityGradeID uuid.NullUUID       `json:"toxicity_grade_id"`
+	TestID          uuid.NullUUID       `json:"test_id"`
+	LabResult       string              `json:"lab_result"`
+	Notes           string              `json:"notes"`
+}
+
 type User struct {
 	ID         uuid.UUID     `json:"id"`
 	CreatedAt  time.Time     `json:"created_at"`
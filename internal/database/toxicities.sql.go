@@ -15,19 +15,25 @@ import (
 )
 
 const addToxicity = `-- name: AddToxicity :one
-INSERT INTO toxicities (title, category, description)
-VALUES ($1, $2, $3)
-RETURNING id, created_at, updated_at, title, category, description
+INSERT INTO toxicities (title, category, description, ctcae_term_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id, created_at, updated_at, title, category, description, ctcae_term_id
 `
 
 type AddToxicityParams struct {
-	Title       string `json:"title"`
-	Category    string `json:"category"`
-	Description string `json:"description"`
+	Title       string        `json:"title"`
+	Category    string        `json:"category"`
+	Description string        `json:"description"`
+	CtcaeTermID uuid.NullUUID `json:"ctcae_term_id"`
 }
 
 func (q *Queries) AddToxicity(ctx context.Context, arg AddToxicityParams) (Toxicity, error) {
-	row := q.db.QueryRowContext(ctx, addToxicity, arg.Title, arg.Category, arg.Description)
+	row := q.db.QueryRowContext(ctx, addToxicity,
+		arg.Title,
+		arg.Category,
+		arg.Description,
+		arg.CtcaeTermID,
+	)
 	var i Toxicity
 	err := row.Scan(
 		&i.ID,
@@ -36,6 +42,7 @@ func (q *Queries) AddToxicity(ctx context.Context, arg AddToxicityParams) (Toxic
 		&i.Title,
 		&i.Category,
 		&i.Description,
+		&i.CtcaeTermID,
 	)
 	return i, err
 }
@@ -382,7 +389,7 @@ func (q *Queries) GetToxicityByID(ctx context.Context, id uuid.UUID) (GetToxicit
 }
 
 const getToxicityByName = `-- name: GetToxicityByName :one
-SELECT id, created_at, updated_at, title, category, description FROM toxicities
+SELECT id, created_at, updated_at, title, category, description, ctcae_term_id FROM toxicities
 WHERE title = $1
 `
 
@@ -396,6 +403,7 @@ func (q *Queries) GetToxicityByName(ctx context.Context, title string) (Toxicity
 		&i.Title,
 		&i.Category,
 		&i.Description,
+		&i.CtcaeTermID,
 	)
 	return i, err
 }
@@ -516,7 +524,7 @@ SET
     category = $3,
     description = $4
 WHERE id = $1
-RETURNING id, created_at, updated_at, title, category, description
+RETURNING id, created_at, updated_at, title, category, description, ctcae_term_id
 `
 
 type UpdateToxicityParams struct {
@@ -541,6 +549,7 @@ func (q *Queries) UpdateToxicity(ctx context.Context, arg UpdateToxicityParams)
 		&i.Title,
 		&i.Category,
 		&i.Description,
+		&i.CtcaeTermID,
 	)
 	return i, err
 }
@@ -632,7 +641,7 @@ ON CONFLICT (id) DO UPDATE SET
     category = EXCLUDED.category,
     description = EXCLUDED.description,
     updated_at = NOW()
-RETURNING id, created_at, updated_at, title, category, description
+RETURNING id, created_at, updated_at, title, category, description, ctcae_term_id
 `
 
 type UpsertToxicityParams struct {
@@ -657,6 +666,7 @@ func (q *Queries) UpsertToxicity(ctx context.Context, arg UpsertToxicityParams)
 		&i.Title,
 		&i.Category,
 		&i.Description,
+		&i.CtcaeTermID,
 	)
 	return i, err
 }
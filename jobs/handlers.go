@@ -0,0 +1,140 @@
+package jobs
+
+import (
+	"bcca_crawler/ai_helper"
+	"bcca_crawler/api/protocols"
+	"bcca_crawler/crawler"
+	"bcca_crawler/docstore"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"bcca_crawler/usage"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// handleDiscover walks every tumor-group page, saves the inventory and
+// queues the protocols that are not in the database yet. An incomplete
+// crawl fails after saving what it found, so it is retried.
+func handleDiscover(ctx context.Context, c *config.Config, job database.Job) error {
+	inv, err := crawler.Discover(job.Target)
+	if err != nil {
+		return err
+	}
+	for _, warning := range inv.Warnings {
+		log.Printf("job %s: %s", job.ID, warning)
+	}
+	result, err := protocols.SaveInventory(c, ctx, inv)
+	if err != nil {
+		return err
+	}
+	queued, err := QueueMissing(c, ctx, inv, result)
+	if err != nil {
+		return err
+	}
+	log.Printf("job %s: %d protocols listed, %d retired, %d extractions queued", job.ID, result.Listed, len(result.Retired), queued)
+	if result.Incomplete {
+		return fmt.Errorf("inventory incomplete, %d page(s) failed: %s", len(inv.Errors), strings.Join(inv.Errors, "; "))
+	}
+	return nil
+}
+
+// handleCrawl parses a listing page and queues an extract job for every
+// protocol PDF on it that is new or changed since it was last extracted. A
+// PDF that cannot be downloaded to compare is queued, for the extract job
+// to retry.
+func handleCrawl(ctx context.Context, c *config.Config, job database.Job) error {
+	body, err := crawler.GetHTML(job.Target)
+	if err != nil {
+		return fmt.Errorf("error getting listing page: %w", err)
+	}
+	listing, err := crawler.ParseListing(body, job.Target)
+	if err != nil {
+		return fmt.Errorf("error parsing listing page: %w", err)
+	}
+	for _, warning := range listing.Warnings {
+		log.Printf("job %s: %s", job.ID, warning)
+	}
+	if len(listing.Protocols) == 0 {
+		return fmt.Errorf("no protocols found on %s", job.Target)
+	}
+
+	queued, unchanged := 0, 0
+	pageGroup := crawler.TumorGroupOf(job.Target)
+	for i := range listing.Protocols {
+		wp := listing.Protocols[i]
+		tumorGroup := crawler.ProtocolTumorGroup(wp.Code, pageGroup)
+		for _, link := range wp.ClassifiedLinks() {
+			if link.Kind != crawler.LinkProtocol {
+				continue
+			}
+			extracted, err := alreadyExtracted(c, ctx, link.Href)
+			if err != nil {
+				log.Printf("job %s: %v", job.ID, err)
+			}
+			if extracted {
+				unchanged++
+				continue
+			}
+			if _, err := EnqueueExtract(c, ctx, link.Href, &wp, tumorGroup); err != nil {
+				return err
+			}
+			queued++
+		}
+	}
+	log.Printf("job %s: queued %d extractions from %d protocols, %d unchanged", job.ID, queued, len(listing.Protocols), unchanged)
+	return nil
+}
+
+// alreadyExtracted downloads a protocol PDF and reports whether a protocol
+// was already extracted from the same content.
+func alreadyExtracted(c *config.Config, ctx context.Context, pdfURL string) (bool, error) {
+	doc, err := crawler.Download(pdfURL)
+	if err != nil {
+		return false, fmt.Errorf("error downloading %s to compare: %v", pdfURL, err)
+	}
+	extracted, err := c.Db.IsDocumentExtracted(ctx, docstore.Hash(doc.Body))
+	if err != nil {
+		return false, fmt.Errorf("error looking up document of %s: %v", pdfURL, err)
+	}
+	return extracted, nil
+}
+
+// handleExtract extracts a protocol PDF, then attaches the documents of
+// the listing it came from. Documents that fail to download are reported
+// but do not fail the extraction, which is the expensive part to redo. Over
+// the LLM budget it returns a *usage.BudgetError without extracting.
+func handleExtract(ctx context.Context, c *config.Config, job database.Job) error {
+	var payload ExtractPayload
+	if len(job.Payload) > 0 {
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("error decoding job payload: %v", err)
+		}
+	}
+
+	if payload.NoCache {
+		cfg := *c
+		cfg.NoCache = true
+		c = &cfg
+	}
+
+	// deferred by the worker rather than failed when over the budget
+	if err := usage.CheckBudget(c, ctx); err != nil {
+		return err
+	}
+	if err := ai_helper.ExtractProtocol(ctx, c, job.Target, payload.TumorGroup); err != nil {
+		return err
+	}
+
+	if payload.Listing == nil {
+		return nil
+	}
+	saved, err := protocols.AttachDocuments(c, ctx, *payload.Listing)
+	if err != nil {
+		log.Printf("job %s: error attaching documents: %v", job.ID, err)
+	}
+	log.Printf("job %s: stored %d documents for %s", job.ID, saved, payload.Listing.Code)
+	return nil
+}
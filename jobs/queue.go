@@ -0,0 +1,99 @@
+// Package jobs is the Postgres-backed queue of crawl and extraction work.
+// Jobs are claimed with FOR UPDATE SKIP LOCKED so several workers can share
+// the queue, survive restarts through leases, and are retried with backoff
+// until they reach max_attempts and move to the dead state.
+package jobs
+
+import (
+	"bcca_crawler/api/protocols"
+	"bcca_crawler/crawler"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	KindCrawl    = database.JobKindEnumCrawl
+	KindExtract  = database.JobKindEnumExtract
+	KindDiscover = database.JobKindEnumDiscover
+)
+
+// DefaultMaxAttempts is how many times a job runs before it is dead.
+const DefaultMaxAttempts = 5
+
+// ExtractPayload carries the listing a protocol PDF was found under, so its
+// other documents can be attached once the protocol exists, and the tumor
+// group of its page for the extraction prompt. NoCache asks for fresh model
+// responses, whichever worker runs the job.
+type ExtractPayload struct {
+	Listing    *crawler.WebProtocol `json:"listing,omitempty"`
+	TumorGroup string               `json:"tumor_group,omitempty"`
+	NoCache    bool                 `json:"no_cache,omitempty"`
+}
+
+// Enqueue adds a job. A job of the same kind and target that is still
+// pending or running is returned instead of a duplicate.
+func Enqueue(c *config.Config, ctx context.Context, kind database.JobKindEnum, target string, payload any) (database.Job, error) {
+	if payload == nil {
+		payload = struct{}{}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return database.Job{}, fmt.Errorf("error encoding job payload: %s, with error: %v", target, err)
+	}
+	job, err := c.Db.EnqueueJob(ctx, database.EnqueueJobParams{
+		Kind:        kind,
+		Target:      target,
+		Payload:     data,
+		MaxAttempts: DefaultMaxAttempts,
+	})
+	if err != nil {
+		return database.Job{}, fmt.Errorf("error enqueuing %s job: %s, with error: %v", kind, target, err)
+	}
+	return job, nil
+}
+
+// EnqueueDiscover queues a discovery crawl from the protocol index.
+func EnqueueDiscover(c *config.Config, ctx context.Context, rootURL string) (database.Job, error) {
+	return Enqueue(c, ctx, KindDiscover, rootURL, nil)
+}
+
+// EnqueueCrawl queues a listing page to be parsed into extract jobs.
+func EnqueueCrawl(c *config.Config, ctx context.Context, pageURL string) (database.Job, error) {
+	return Enqueue(c, ctx, KindCrawl, pageURL, nil)
+}
+
+// EnqueueExtract queues a protocol PDF for extraction. listing may be nil
+// and tumorGroup empty when the PDF was not found on a listing page. With
+// c.NoCache the extraction does not reuse cached model responses.
+func EnqueueExtract(c *config.Config, ctx context.Context, pdfURL string, listing *crawler.WebProtocol, tumorGroup string) (database.Job, error) {
+	return Enqueue(c, ctx, KindExtract, pdfURL, ExtractPayload{Listing: listing, TumorGroup: tumorGroup, NoCache: c.NoCache})
+}
+
+// QueueMissing queues an extract job for every protocol of a discovery
+// crawl that is not in the database yet.
+func QueueMissing(c *config.Config, ctx context.Context, inv crawler.Inventory, result protocols.InventoryResult) (int, error) {
+	missing := map[string]bool{}
+	for _, listing := range result.Missing {
+		missing[listing.Code] = true
+	}
+	queued := 0
+	for i := range inv.Protocols {
+		item := inv.Protocols[i]
+		if !missing[item.Code] {
+			continue
+		}
+		for _, link := range item.Listing.ClassifiedLinks() {
+			if link.Kind != crawler.LinkProtocol {
+				continue
+			}
+			if _, err := EnqueueExtract(c, ctx, link.Href, &item.Listing, item.TumorGroup); err != nil {
+				return queued, err
+			}
+			queued++
+		}
+	}
+	return queued, nil
+}
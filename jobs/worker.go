@@ -0,0 +1,307 @@
+package jobs
+
+import (
+	"bcca_crawler/ai_helper"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"bcca_crawler/usage"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Handler runs one job. Its context is cancelled when the worker shuts
+// down or the job is cancelled.
+type Handler func(ctx context.Context, c *config.Config, job database.Job) error
+
+// Worker claims jobs from the queue and runs them with the handler of their
+// kind.
+type Worker struct {
+	Name        string
+	Concurrency int
+	Lease       time.Duration // renewed while a job runs; an expired lease lets another worker resume the job
+	Poll        time.Duration // wait between claims when the queue is empty
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+
+	c        *config.Config
+	handlers map[database.JobKindEnum]Handler
+	busy     atomic.Int32
+	budget   atomic.Pointer[usage.BudgetError] // set once a job was deferred for the LLM budget
+}
+
+// NewWorker returns a worker with the discover, crawl and extract handlers.
+func NewWorker(c *config.Config) *Worker {
+	host, _ := os.Hostname()
+	return &Worker{
+		Name:        fmt.Sprintf("%s-%d", host, os.Getpid()),
+		Concurrency: 5,
+		Lease:       10 * time.Minute,
+		Poll:        2 * time.Second,
+		BaseBackoff: 30 * time.Second,
+		MaxBackoff:  30 * time.Minute,
+		c:           c,
+		handlers: map[database.JobKindEnum]Handler{
+			KindDiscover: handleDiscover,
+			KindCrawl:    handleCrawl,
+			KindExtract:  handleExtract,
+		},
+	}
+}
+
+// Run works the queue until ctx is done. With drain it also returns once no
+// job is pending or running, which is how the crawl commands wait for the
+// work they queued, or with a *usage.BudgetError once the LLM budget stops
+// the extractions. Jobs interrupted by ctx are put back as pending.
+func (w *Worker) Run(ctx context.Context, drain bool) error {
+	var wg sync.WaitGroup
+	errs := make(chan error, w.Concurrency)
+	for i := 0; i < w.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.loop(ctx, drain); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return err
+	}
+	if budget := w.budget.Load(); drain && budget != nil {
+		return budget
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil
+	}
+	return ctx.Err()
+}
+
+func (w *Worker) loop(ctx context.Context, drain bool) error {
+	for ctx.Err() == nil {
+		if drain && w.budget.Load() != nil {
+			// the rest would only be deferred too
+			return nil
+		}
+		job, err := w.c.Db.ClaimJob(ctx, database.ClaimJobParams{Worker: w.Name, LeaseSeconds: int32(w.Lease / time.Second)})
+		if errors.Is(err, sql.ErrNoRows) {
+			if drain && w.busy.Load() == 0 {
+				done, err := w.queueEmpty(ctx)
+				if err != nil || done {
+					return err
+				}
+			}
+			if err := sleep(ctx, w.Poll); err != nil {
+				return nil
+			}
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("error claiming job: %v", err)
+		}
+
+		w.busy.Add(1)
+		w.execute(ctx, job)
+		w.busy.Add(-1)
+	}
+	return nil
+}
+
+// queueEmpty reports whether there is no pending or running job left,
+// including retries waiting for their backoff.
+func (w *Worker) queueEmpty(ctx context.Context) (bool, error) {
+	counts, err := w.c.Db.CountJobsByStatus(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return true, nil
+		}
+		return false, fmt.Errorf("error counting jobs: %v", err)
+	}
+	for _, row := range counts {
+		if (row.Status == database.JobStatusEnumPending || row.Status == database.JobStatusEnumRunning) && row.Count > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (w *Worker) execute(ctx context.Context, job database.Job) {
+	log.Printf("job %s: %s %s (attempt %d/%d)", job.ID, job.Kind, job.Target, job.Attempts, job.MaxAttempts)
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var cancelled atomic.Bool
+	stop := make(chan struct{})
+	defer close(stop)
+	go w.heartbeat(jobCtx, job, stop, func() {
+		cancelled.Store(true)
+		cancel()
+	})
+
+	var err error
+	if job.Attempts > job.MaxAttempts {
+		// reclaimed after its worker died on every attempt
+		err = fmt.Errorf("lease expired on the last attempt: %s", job.LastError)
+	} else {
+		err = w.run(jobCtx, job)
+	}
+
+	// ctx may be done by now, the outcome is saved regardless
+	saveCtx := context.WithoutCancel(ctx)
+	var budget *usage.BudgetError
+	switch {
+	case cancelled.Load():
+		log.Printf("job %s: cancelled", job.ID)
+	case ctx.Err() != nil:
+		if err := w.c.Db.ReleaseJob(saveCtx, database.ReleaseJobParams{ID: job.ID, Worker: w.Name}); err != nil {
+			log.Printf("job %s: error releasing job: %v", job.ID, err)
+		}
+		log.Printf("job %s: interrupted, back in the queue", job.ID)
+	case errors.As(err, &budget):
+		// not a failure: the job waits for the budget to reset
+		w.budget.Store(budget)
+		derr := w.c.Db.DeferJob(saveCtx, database.DeferJobParams{
+			LastError: err.Error(),
+			RunAt:     budget.Until,
+			ID:        job.ID,
+			Worker:    w.Name,
+		})
+		if derr != nil {
+			log.Printf("job %s: error deferring job: %v", job.ID, derr)
+		}
+		log.Printf("job %s: deferred: %v", job.ID, err)
+	case err == nil:
+		if err := w.c.Db.CompleteJob(saveCtx, database.CompleteJobParams{ID: job.ID, Worker: w.Name}); err != nil {
+			log.Printf("job %s: error completing job: %v", job.ID, err)
+		}
+		log.Printf("job %s: done", job.ID)
+	default:
+		var p permanent
+		final := errors.As(err, &p) && p.Permanent()
+		failed, ferr := w.c.Db.FailJob(saveCtx, database.FailJobParams{
+			Permanent:      final,
+			LastError:      err.Error(),
+			AttemptError:   attemptRecord(job, err),
+			RetryInSeconds: int32(w.backoff(job.Attempts) / time.Second),
+			ID:             job.ID,
+			Worker:         w.Name,
+		})
+		if ferr != nil {
+			log.Printf("job %s: error recording failure %q: %v", job.ID, err, ferr)
+			return
+		}
+		if final {
+			log.Printf("job %s: dead, not retried: %v", job.ID, err)
+		} else if failed.Status == database.JobStatusEnumDead {
+			log.Printf("job %s: dead after %d attempts: %v", job.ID, failed.Attempts, err)
+		} else {
+			log.Printf("job %s: failed, retrying at %s: %v", job.ID, failed.RunAt.Format(time.RFC3339), err)
+		}
+	}
+}
+
+// permanent is implemented by errors another attempt cannot fix, e.g. a
+// document that is not a PDF; the job is dead at once.
+type permanent interface {
+	Permanent() bool
+}
+
+// AttemptError is an entry of a job's attempt_errors: the error of one
+// failed attempt and of the model calls it made.
+type AttemptError struct {
+	Attempt int32                  `json:"attempt"`
+	At      time.Time              `json:"at"`
+	Error   string                 `json:"error"`
+	Calls   []*ai_helper.CallError `json:"calls,omitempty"`
+}
+
+func attemptRecord(job database.Job, err error) json.RawMessage {
+	data, merr := json.Marshal(AttemptError{
+		Attempt: job.Attempts,
+		At:      time.Now(),
+		Error:   err.Error(),
+		Calls:   ai_helper.Calls(err),
+	})
+	if merr != nil {
+		// keep the attempt, if not its details
+		data, _ = json.Marshal(AttemptError{Attempt: job.Attempts, At: time.Now(), Error: err.Error()})
+	}
+	return data
+}
+
+func (w *Worker) run(ctx context.Context, job database.Job) (err error) {
+	handler, ok := w.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for %s jobs", job.Kind)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, w.c, job)
+}
+
+// heartbeat renews the lease until stop is closed and calls cancel when the
+// job is no longer ours, i.e. it was cancelled.
+func (w *Worker) heartbeat(ctx context.Context, job database.Job, stop chan struct{}, cancel func()) {
+	ticker := time.NewTicker(w.Lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := w.c.Db.ExtendJobLease(ctx, database.ExtendJobLeaseParams{
+				LeaseSeconds: int32(w.Lease / time.Second),
+				ID:           job.ID,
+				Worker:       w.Name,
+			})
+			if err != nil {
+				log.Printf("job %s: error renewing lease: %v", job.ID, err)
+				continue
+			}
+			if n == 0 {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// backoff doubles from BaseBackoff with every attempt.
+func (w *Worker) backoff(attempts int32) time.Duration {
+	d := w.BaseBackoff
+	for i := int32(1); i < attempts && d < w.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > w.MaxBackoff {
+		d = w.MaxBackoff
+	}
+	return d
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"bcca_crawler/internal/database"
 	"bcca_crawler/api"
 	"bcca_crawler/internal/config"
+	"bcca_crawler/docstore"
+	"bcca_crawler/usage"
+	"strconv"
 	_ "github.com/lib/pq"
 	"database/sql"
 	"github.com/go-playground/validator/v10"
@@ -28,6 +31,11 @@ func init() {
 	validate.RegisterValidation("prescription_route", api.PrescriptionRouteValidator)
 	validate.RegisterValidation("protocol_prescription_category", api.ProtocolPrescriptionCategoryValidator)
 	validate.RegisterValidation("grade", api.GradeValidator)
+	validate.RegisterValidation("dose_basis", api.DoseBasisValidator)
+	validate.RegisterValidation("plan_status", api.PlanStatusValidator)
+	validate.RegisterValidation("plan_cycle_status", api.PlanCycleStatusValidator)
+	validate.RegisterValidation("interaction_severity", api.InteractionSeverityValidator)
+	validate.RegisterValidation("emetogenic_level", api.EmetogenicLevelValidator)
 }
 
 func main() {
@@ -36,7 +44,48 @@ func main() {
 	cfg.Secret = os.Getenv("SECRET")
 	cfg.DatabaseUrl = os.Getenv("DB_URL")
 	cfg.GeminiApiKey = os.Getenv("GEMINI_API_KEY")
+	cfg.GeminiModel = os.Getenv("GEMINI_MODEL")
+	cfg.ExtractionMode = os.Getenv("EXTRACTION_MODE")
 	cfg.MailGunApiKey = os.Getenv("MAILGUN_API_KEY")
+	prices, err := usage.Prices(os.Getenv("LLM_PRICING"))
+	if err != nil {
+		fmt.Println("Error reading LLM_PRICING: ", err)
+		return
+	}
+	cfg.LLMPrices = prices
+	for env, budget := range map[string]*float64{"LLM_DAILY_BUDGET": &cfg.LLMBudget.Daily, "LLM_MONTHLY_BUDGET": &cfg.LLMBudget.Monthly} {
+		if v := os.Getenv(env); v != "" {
+			if *budget, err = strconv.ParseFloat(v, 64); err != nil || *budget < 0 {
+				fmt.Printf("Error reading %s: %s is not an amount in USD\n", env, v)
+				return
+			}
+		}
+	}
+	if os.Getenv("S3_BUCKET") != "" || os.Getenv("S3_ENDPOINT") != "" {
+		s3 := docstore.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Prefix:    os.Getenv("S3_PREFIX"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		}
+		if err := s3.Validate(); err != nil {
+			fmt.Println("Error reading S3 settings: ", err)
+			return
+		}
+		cfg.Documents = docstore.NewS3(s3)
+	} else {
+		dir := os.Getenv("DOCUMENT_DIR")
+		if dir == "" {
+			dir = "documents"
+		}
+		cfg.Documents = docstore.NewFS(dir)
+	}
+	cfg.ArchiveDir = os.Getenv("ARCHIVE_DIR")
+	if cfg.ArchiveDir == "" {
+		cfg.ArchiveDir = "archive"
+	}
 	db, err := sql.Open("postgres", os.Getenv("DB_URL"))
 	if err != nil {
 		fmt.Println("Error fetching database: ", err)
@@ -58,8 +107,13 @@ func main() {
 	commands.register("pubmed", handlerSearchPubmed)
 	commands.register("reset", handlerResetDatabase)
 	commands.register("scrawl",handlerSingleCrawl)
-
-	//http://www.bccancer.bc.ca/health-professionals/clinical-resources/chemotherapy-protocols/lymphoma-myeloma
+	commands.register("import_interactions", handlerImportInteractions)
+	commands.register("import_ctcae", handlerImportCTCAE)
+	commands.register("classify_emetogenic", handlerClassifyEmetogenic)
+	commands.register("discover", handlerDiscover)
+	commands.register("jobs", handlerJobs)
+	commands.register("eval", handlerEval)
+	commands.register("replay", handlerReplay)
 
 	args := os.Args[1:]
 
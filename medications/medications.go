@@ -0,0 +1,220 @@
+// Package medications keeps one medication row per drug. Extractions name
+// the same drug in different ways ("Doxorubicin", "DOXOrubicin",
+// "Adriamycin"); Resolve matches a name case-insensitively against the names
+// and alternate names of the medications and the synonym list before a new
+// one is created. Propose finds the duplicates already stored and Merge
+// folds them into one.
+package medications
+
+import (
+	"bcca_crawler/emetogenic"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Normalize lower-cases a name and collapses its whitespace, as the
+// FindMedicationsByNames query does.
+func Normalize(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// Keys returns the normalized names a medication may be stored under: the
+// name itself first, then its synonyms.
+func Keys(name string) []string {
+	n := Normalize(name)
+	keys := []string{n}
+	for _, s := range synonymGroup[n] {
+		if s = Normalize(s); s != n {
+			keys = append(keys, s)
+		}
+	}
+	return keys
+}
+
+// Generic returns the generic name of a synonym, or "" for a name that is
+// not on the list.
+func Generic(name string) string {
+	if group, ok := synonymGroup[Normalize(name)]; ok {
+		return group[0]
+	}
+	return ""
+}
+
+// Incoming is a medication as an extraction names it.
+type Incoming struct {
+	Name           string
+	Description    string
+	Category       string
+	AlternateNames []string
+}
+
+// Resolve returns the stored medication an extracted name refers to,
+// creating it when there is none. A match under another spelling learns the
+// new one as an alternate name. The alternate names the model suggests are
+// only stored with a new medication, never used to match: a model that
+// lists "doxorubicin" for liposomal doxorubicin would merge two drugs.
+func Resolve(c *config.Config, ctx context.Context, in Incoming) (database.Medication, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return database.Medication{}, fmt.Errorf("medication has no name")
+	}
+	keys := Keys(name)
+	found, err := c.Db.FindMedicationsByNames(ctx, keys)
+	if err != nil {
+		return database.Medication{}, fmt.Errorf("error finding medication: %s, with error: %v", name, err)
+	}
+	if med, ok := best(found, keys); ok {
+		if !hasName(med, name) {
+			err := c.Db.AddMedicationAlternateName(ctx, database.AddMedicationAlternateNameParams{Name: name, ID: med.ID})
+			if err != nil {
+				return med, fmt.Errorf("error adding alternate name: %s, with error: %v", name, err)
+			}
+		}
+		return classify(c, ctx, med, name)
+	}
+
+	alternates := in.AlternateNames
+	if alternates == nil {
+		alternates = []string{}
+	}
+	med, err := c.Db.AddMedication(ctx, database.AddMedicationParams{
+		Name:           name,
+		Description:    in.Description,
+		Category:       in.Category,
+		AlternateNames: alternates,
+	})
+	if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
+		// created by a concurrent extraction
+		med, err = c.Db.GetMedicationByName(ctx, name)
+	}
+	if err != nil {
+		return med, fmt.Errorf("error creating medication: %s, with error: %v", name, err)
+	}
+	return classify(c, ctx, med, name)
+}
+
+// Level returns the emetogenic level of a medication known by the names,
+// looking each one up under its generic name too.
+func Level(names ...string) emetogenic.Level {
+	for _, name := range names {
+		if level := emetogenic.LevelOf(Keys(name)...); level != emetogenic.LevelUnknown {
+			return level
+		}
+	}
+	return emetogenic.LevelUnknown
+}
+
+// classify stores the emetogenic level of a medication that has none yet.
+// A level set by hand is kept. Like matching, it goes by the names only and
+// never by the alternate names the model suggested.
+func classify(c *config.Config, ctx context.Context, med database.Medication, name string) (database.Medication, error) {
+	if med.EmetogenicLevel != database.EmetogenicLevelEnumUnknown {
+		return med, nil
+	}
+	level := Level(med.Name, name)
+	if level == emetogenic.LevelUnknown {
+		return med, nil
+	}
+	err := c.Db.UpdateMedicationEmetogenicLevel(ctx, database.UpdateMedicationEmetogenicLevelParams{
+		ID:              med.ID,
+		EmetogenicLevel: database.EmetogenicLevelEnum(level),
+	})
+	if err != nil {
+		return med, fmt.Errorf("error setting emetogenic level: %s, with error: %v", med.Name, err)
+	}
+	med.EmetogenicLevel = database.EmetogenicLevelEnum(level)
+	return med, nil
+}
+
+// ClassifyStored sets the emetogenic level of the stored medications that
+// have none and are on the list, and returns how many it set. It can be run
+// again whenever the list grows.
+func ClassifyStored(c *config.Config, ctx context.Context) (int, error) {
+	meds, err := c.Db.GetUnclassifiedMedications(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error getting unclassified medications, with error: %v", err)
+	}
+	set := 0
+	for _, med := range meds {
+		level := Level(med.Name)
+		if level == emetogenic.LevelUnknown {
+			continue
+		}
+		err := c.Db.UpdateMedicationEmetogenicLevel(ctx, database.UpdateMedicationEmetogenicLevelParams{
+			ID:              med.ID,
+			EmetogenicLevel: database.EmetogenicLevelEnum(level),
+		})
+		if err != nil {
+			return set, fmt.Errorf("error setting emetogenic level: %s, with error: %v", med.Name, err)
+		}
+		set++
+	}
+	return set, nil
+}
+
+// best picks the medication matching the earliest key, preferring a match
+// on its name to one on an alternate name, then the oldest.
+func best(found []database.Medication, keys []string) (database.Medication, bool) {
+	rank := func(med database.Medication) int {
+		for i, key := range keys {
+			if Normalize(med.Name) == key {
+				return 2 * i
+			}
+			for _, alt := range med.AlternateNames {
+				if Normalize(alt) == key {
+					return 2*i + 1
+				}
+			}
+		}
+		return -1
+	}
+	var pick database.Medication
+	pickRank := -1
+	for _, med := range found {
+		r := rank(med)
+		if r >= 0 && (pickRank < 0 || r < pickRank) {
+			pick, pickRank = med, r
+		}
+	}
+	return pick, pickRank >= 0
+}
+
+// hasName reports whether a medication is already known by a name.
+func hasName(med database.Medication, name string) bool {
+	n := Normalize(name)
+	if Normalize(med.Name) == n {
+		return true
+	}
+	for _, alt := range med.AlternateNames {
+		if Normalize(alt) == n {
+			return true
+		}
+	}
+	return false
+}
+
+// addNames appends the names not already in names or equal to primary.
+func addNames(names []string, primary string, more ...string) []string {
+	for _, name := range more {
+		name = strings.TrimSpace(name)
+		if name == "" || Normalize(name) == Normalize(primary) {
+			continue
+		}
+		known := false
+		for _, n := range names {
+			if Normalize(n) == Normalize(name) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			names = append(names, name)
+		}
+	}
+	return names
+}
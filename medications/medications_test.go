@@ -0,0 +1,148 @@
+package medications
+
+import (
+	"bcca_crawler/emetogenic"
+	"bcca_crawler/internal/database"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func med(name string, age int, alternates ...string) database.Medication {
+	return database.Medication{
+		ID:             uuid.New(),
+		Name:           name,
+		AlternateNames: alternates,
+		CreatedAt:      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Duration(age) * time.Hour),
+	}
+}
+
+func TestKeys(t *testing.T) {
+	if got, want := Keys("  DOXOrubicin "), []string{"doxorubicin", "adriamycin", "hydroxydaunorubicin"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys = %v, want %v", got, want)
+	}
+	if got, want := Keys("Adriamycin"), []string{"adriamycin", "doxorubicin", "hydroxydaunorubicin"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys = %v, want %v", got, want)
+	}
+	if got := Keys("Pegylated Liposomal Doxorubicin"); len(got) != 3 || got[1] != "caelyx" {
+		t.Errorf("liposomal doxorubicin has keys %v", got)
+	}
+}
+
+func TestBest(t *testing.T) {
+	byAlternate := med("Hydroxydaunorubicin", 3, "doxorubicin")
+	byName := med("Doxorubicin", 1)
+	bySynonym := med("Adriamycin", 5)
+
+	keys := Keys("doxorubicin")
+	if got, ok := best([]database.Medication{bySynonym, byAlternate, byName}, keys); !ok || got.ID != byName.ID {
+		t.Errorf("best = %s, want the name match", got.Name)
+	}
+	if got, ok := best([]database.Medication{bySynonym, byAlternate}, keys); !ok || got.ID != byAlternate.ID {
+		t.Errorf("best = %s, want the alternate name match", got.Name)
+	}
+	if got, ok := best([]database.Medication{bySynonym}, keys); !ok || got.ID != bySynonym.ID {
+		t.Errorf("best = %s, want the synonym", got.Name)
+	}
+	if _, ok := best([]database.Medication{med("Cisplatin", 1)}, keys); ok {
+		t.Error("matched an unrelated medication")
+	}
+}
+
+func TestAddNames(t *testing.T) {
+	got := addNames([]string{"Adriamycin"}, "Doxorubicin", "DOXOrubicin", "adriamycin", " ", "Hydroxydaunorubicin")
+	if want := []string{"Adriamycin", "Hydroxydaunorubicin"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("addNames = %v, want %v", got, want)
+	}
+}
+
+func TestPropose(t *testing.T) {
+	meds := []database.Medication{
+		med("DOXOrubicin", 10),
+		med("Doxorubicin", 1),
+		med("Adriamycin", 20),
+		med("Pegylated Liposomal Doxorubicin", 5),
+		med("Cyclophosphamid", 3),
+		med("Cyclophosphamide", 2),
+		med("Vincristine", 4),
+		med("Vinblastine", 4),
+		med("Velcade", 6, "bortezomib"),
+		med("Bortezomib", 1),
+		// alike, but two drugs of the synonym list
+		med("Pegfilgrastim", 7),
+		med("Filgrastim", 8),
+	}
+	if Similarity("pegfilgrastim", "filgrastim") < SpellingThreshold {
+		t.Fatal("pegfilgrastim and filgrastim no longer spelled alike enough to test")
+	}
+	proposals := Propose(meds)
+
+	type group struct {
+		survivor   string
+		duplicates []string
+		reasons    []string
+	}
+	var got []group
+	for _, p := range proposals {
+		g := group{survivor: p.Survivor.Name, reasons: p.Reasons}
+		for _, d := range p.Duplicates {
+			g.duplicates = append(g.duplicates, d.Name)
+		}
+		got = append(got, g)
+	}
+	want := []group{
+		{"Bortezomib", []string{"Velcade"}, []string{ReasonSameName}},
+		{"Cyclophosphamide", []string{"Cyclophosphamid"}, []string{ReasonSpelling}},
+		// the generic name survives, the oldest of two spellings of it
+		{"DOXOrubicin", []string{"Adriamycin", "Doxorubicin"}, []string{ReasonSameName, ReasonSynonym}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Propose =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  emetogenic.Level
+	}{
+		{[]string{"Cisplatin"}, emetogenic.LevelHigh},
+		{[]string{"ADRIAMYCIN"}, emetogenic.LevelModerate},
+		{[]string{"Temodal"}, emetogenic.LevelModerate},
+		{[]string{"xeloda"}, emetogenic.LevelLow},
+		{[]string{"trial drug", "Vincristine"}, emetogenic.LevelMinimal},
+		{[]string{"Pegylated Liposomal Doxorubicin"}, emetogenic.LevelUnknown},
+		{[]string{"trial drug"}, emetogenic.LevelUnknown},
+	}
+	for _, tt := range tests {
+		if got := Level(tt.names...); got != tt.want {
+			t.Errorf("Level(%v) = %s, want %s", tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestResolveClassifiesNewMedication(t *testing.T) {
+	db := &mergeDB{levels: map[string]string{}}
+	c := openMergeDB(t, db)
+
+	// an oral agent ingested after migration 015 ran
+	med, err := Resolve(c, context.Background(), Incoming{Name: "Temozolomide", AlternateNames: []string{"Temodal"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if med.EmetogenicLevel != database.EmetogenicLevelEnumModerate || db.levels[med.ID.String()] != "moderate" {
+		t.Errorf("level %s, stored %v", med.EmetogenicLevel, db.levels)
+	}
+
+	// the alternate names the model suggests do not classify a drug
+	med, err = Resolve(c, context.Background(), Incoming{Name: "Liposomal Doxorubicin", AlternateNames: []string{"doxorubicin"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if med.EmetogenicLevel != database.EmetogenicLevelEnumUnknown || len(db.levels) != 1 {
+		t.Errorf("level %s, stored %v", med.EmetogenicLevel, db.levels)
+	}
+}
@@ -0,0 +1,278 @@
+package medications
+
+import (
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Proposal is a set of medications that look like one drug. Survivor is
+// the one to keep: the generic name when one of them is, else the oldest.
+type Proposal struct {
+	Survivor   database.Medication
+	Duplicates []database.Medication
+	Reasons    []string
+}
+
+const (
+	ReasonSameName = "same name"        // equal once case and spacing are ignored, or one's alternate name
+	ReasonSynonym  = "synonym"          // names of one drug on the synonym list
+	ReasonSpelling = "similar spelling" // names at least SpellingThreshold alike
+)
+
+// SpellingThreshold is the similarity two names need to be proposed as a
+// misspelling of each other. Short names are left out: "Ara-C" and
+// "Ara-A" are different drugs. So are names of two drugs on the synonym
+// list, however alike: pegfilgrastim is not filgrastim.
+const SpellingThreshold = 0.85
+
+// Propose groups the medications that are likely duplicates. It only
+// proposes; nothing is merged until Merge is called.
+func Propose(meds []database.Medication) []Proposal {
+	parent := make([]int, len(meds))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	// why two medications were linked, the first reason found
+	reasons := map[[2]int]string{}
+	union := func(i, j int, reason string) {
+		if i == j {
+			return
+		}
+		ri, rj := find(i), find(j)
+		if ri != rj {
+			parent[rj] = ri
+		}
+		if _, ok := reasons[[2]int{i, j}]; !ok {
+			reasons[[2]int{i, j}] = reason
+		}
+	}
+
+	seen := map[string]int{}
+	link := func(i int, key, reason string) {
+		if j, ok := seen[key]; ok {
+			union(j, i, reason)
+			return
+		}
+		seen[key] = i
+	}
+	for i, med := range meds {
+		for _, name := range append([]string{med.Name}, med.AlternateNames...) {
+			n := Normalize(name)
+			if n == "" {
+				continue
+			}
+			link(i, "name:"+n, ReasonSameName)
+			if g := Generic(n); g != "" {
+				link(i, "synonym:"+g, ReasonSynonym)
+			}
+		}
+	}
+	for i := range meds {
+		for j := i + 1; j < len(meds); j++ {
+			a, b := Normalize(meds[i].Name), Normalize(meds[j].Name)
+			if ga, gb := Generic(a), Generic(b); ga != "" && gb != "" && ga != gb {
+				continue
+			}
+			if len(a) >= 6 && len(b) >= 6 && Similarity(a, b) >= SpellingThreshold {
+				union(i, j, ReasonSpelling)
+			}
+		}
+	}
+
+	groups := map[int][]int{}
+	for i := range meds {
+		root := find(i)
+		groups[root] = append(groups[root], i)
+	}
+	var proposals []Proposal
+	for _, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		survivor := members[0]
+		for _, i := range members {
+			if isGeneric(meds[i]) && !isGeneric(meds[survivor]) ||
+				isGeneric(meds[i]) == isGeneric(meds[survivor]) && meds[i].CreatedAt.Before(meds[survivor].CreatedAt) {
+				survivor = i
+			}
+		}
+		p := Proposal{Survivor: meds[survivor]}
+		why := map[string]bool{}
+		for _, i := range members {
+			if i != survivor {
+				p.Duplicates = append(p.Duplicates, meds[i])
+			}
+			for _, j := range members {
+				if r, ok := reasons[[2]int{i, j}]; ok && !why[r] {
+					why[r] = true
+					p.Reasons = append(p.Reasons, r)
+				}
+			}
+		}
+		sort.Slice(p.Duplicates, func(a, b int) bool { return p.Duplicates[a].Name < p.Duplicates[b].Name })
+		sort.Strings(p.Reasons)
+		proposals = append(proposals, p)
+	}
+	sort.Slice(proposals, func(a, b int) bool { return proposals[a].Survivor.Name < proposals[b].Survivor.Name })
+	return proposals
+}
+
+func isGeneric(med database.Medication) bool {
+	return Generic(med.Name) == Normalize(med.Name)
+}
+
+// Similarity is the Dice coefficient of the letter pairs of two names,
+// from 0 to 1.
+func Similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ba, bb := bigrams(a), bigrams(b)
+	if len(ba) == 0 || len(bb) == 0 {
+		return 0
+	}
+	counts := map[string]int{}
+	for _, g := range ba {
+		counts[g]++
+	}
+	shared := 0
+	for _, g := range bb {
+		if counts[g] > 0 {
+			counts[g]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(ba)+len(bb))
+}
+
+func bigrams(s string) []string {
+	runes := []rune(s)
+	if len(runes) < 2 {
+		return nil
+	}
+	out := make([]string, 0, len(runes)-1)
+	for i := 0; i < len(runes)-1; i++ {
+		out = append(out, string(runes[i:i+2]))
+	}
+	return out
+}
+
+// MergeResult counts the rows moved to the survivor, or replaced by an
+// identical row of the survivor's.
+type MergeResult struct {
+	Survivor      database.Medication
+	Merged        []uuid.UUID
+	Prescriptions int64
+	Treatments    int64
+	Modifications int64
+	DoseLimits    int64
+}
+
+// Merge folds duplicate medications into the survivor in one transaction:
+// their prescriptions, treatments, modifications and dose limit are
+// re-pointed to it, their names become its alternate names, and they are
+// deleted. A prescription or treatment the survivor already has is replaced
+// by the survivor's in the protocols that used it.
+func Merge(c *config.Config, ctx context.Context, survivorID uuid.UUID, duplicateIDs []uuid.UUID) (MergeResult, error) {
+	result := MergeResult{}
+	if len(duplicateIDs) == 0 {
+		return result, errors.New("no duplicates to merge")
+	}
+
+	tx, err := c.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+	q := c.Db.WithTx(tx)
+
+	survivor, err := getMedication(q, ctx, survivorID)
+	if err != nil {
+		return result, err
+	}
+	names := append([]string{}, survivor.AlternateNames...)
+	merged := map[uuid.UUID]bool{}
+	for _, id := range duplicateIDs {
+		if id == survivorID {
+			return result, fmt.Errorf("medication %s cannot be merged into itself", id)
+		}
+		if merged[id] {
+			continue
+		}
+		merged[id] = true
+		dup, err := getMedication(q, ctx, id)
+		if err != nil {
+			return result, err
+		}
+		px, err := q.MergeMedicationPrescriptions(ctx, database.MergeMedicationPrescriptionsParams{SurvivorID: survivorID, DuplicateID: id})
+		if err != nil {
+			return result, fmt.Errorf("error merging prescriptions of: %s, with error: %v", dup.Name, err)
+		}
+		treatments, err := q.MergeMedicationTreatments(ctx, database.MergeMedicationTreatmentsParams{SurvivorID: survivorID, DuplicateID: id})
+		if err != nil {
+			return result, fmt.Errorf("error merging treatments of: %s, with error: %v", dup.Name, err)
+		}
+		mods, err := q.MergeMedicationModifications(ctx, database.MergeMedicationModificationsParams{SurvivorID: survivorID, DuplicateID: id})
+		if err != nil {
+			return result, fmt.Errorf("error merging modifications of: %s, with error: %v", dup.Name, err)
+		}
+		limits, err := q.MergeMedicationDoseLimits(ctx, database.MergeMedicationDoseLimitsParams{SurvivorID: survivorID, DuplicateID: id})
+		if err != nil {
+			return result, fmt.Errorf("error merging dose limit of: %s, with error: %v", dup.Name, err)
+		}
+
+		names = addNames(names, survivor.Name, append([]string{dup.Name}, dup.AlternateNames...)...)
+		err = q.MergeMedicationDetails(ctx, database.MergeMedicationDetailsParams{
+			AlternateNames:  names,
+			Description:     dup.Description,
+			Category:        dup.Category,
+			EmetogenicLevel: dup.EmetogenicLevel,
+			ID:              survivorID,
+		})
+		if err != nil {
+			return result, fmt.Errorf("error updating medication: %s, with error: %v", survivor.Name, err)
+		}
+		if err := q.DeleteMedication(ctx, id); err != nil {
+			return result, fmt.Errorf("error deleting medication: %s, with error: %v", dup.Name, err)
+		}
+
+		result.Merged = append(result.Merged, id)
+		result.Prescriptions += px.Merged + px.Moved
+		result.Treatments += treatments.Merged + treatments.Moved
+		result.Modifications += mods.Merged + mods.Moved
+		result.DoseLimits += limits
+	}
+
+	if result.Survivor, err = q.GetMedicationByID(ctx, survivorID); err != nil {
+		return result, fmt.Errorf("error getting medication: %s, with error: %v", survivorID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("error committing merge: %v", err)
+	}
+	return result, nil
+}
+
+func getMedication(q *database.Queries, ctx context.Context, id uuid.UUID) (database.Medication, error) {
+	med, err := q.GetMedicationByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return med, fmt.Errorf("medication %s not found", id)
+	}
+	if err != nil {
+		return med, fmt.Errorf("error getting medication: %s, with error: %v", id, err)
+	}
+	return med, nil
+}
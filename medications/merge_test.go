@@ -0,0 +1,146 @@
+package medications
+
+import (
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// mergeDB stands in for Postgres: it answers the medication lookups of a
+// merge or a resolve with canned rows and records every statement run and
+// every emetogenic level set.
+type mergeDB struct {
+	meds       map[string]database.Medication
+	statements []string
+	levels     map[string]string
+	committed  bool
+}
+
+func (db *mergeDB) Open(name string) (driver.Conn, error) { return &mergeConn{db}, nil }
+
+type mergeConn struct{ db *mergeDB }
+
+func (c *mergeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepared statements are not supported")
+}
+func (c *mergeConn) Close() error              { return nil }
+func (c *mergeConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *mergeConn) Commit() error             { c.db.committed = true; return nil }
+func (c *mergeConn) Rollback() error           { return nil }
+
+func (c *mergeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.statements = append(c.db.statements, query)
+	if queryName(query) == "UpdateMedicationEmetogenicLevel" {
+		c.db.levels[args[1].Value.(string)] = args[0].Value.(string)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *mergeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.statements = append(c.db.statements, query)
+	switch queryName(query) {
+	case "GetMedicationByID":
+		m, ok := c.db.meds[args[0].Value.(string)]
+		if !ok {
+			return &mergeRows{columns: make([]string, 8)}, nil
+		}
+		return &mergeRows{columns: make([]string, 8), rows: [][]driver.Value{{
+			m.ID.String(), m.CreatedAt, m.UpdatedAt, m.Name, m.Description,
+			"{" + strings.Join(m.AlternateNames, ",") + "}", m.Category, string(m.EmetogenicLevel),
+		}}}, nil
+	case "FindMedicationsByNames":
+		return &mergeRows{columns: make([]string, 8)}, nil
+	case "AddMedication":
+		return &mergeRows{columns: make([]string, 8), rows: [][]driver.Value{{
+			uuid.NewString(), time.Now(), time.Now(), args[0].Value, args[1].Value, "{}", args[2].Value, "unknown",
+		}}}, nil
+	case "MergeMedicationPrescriptions", "MergeMedicationTreatments", "MergeMedicationModifications":
+		return &mergeRows{columns: make([]string, 2), rows: [][]driver.Value{{int64(1), int64(0)}}}, nil
+	}
+	return nil, fmt.Errorf("unexpected query %s", queryName(query))
+}
+
+type mergeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *mergeRows) Columns() []string { return r.columns }
+func (r *mergeRows) Close() error      { return nil }
+func (r *mergeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func queryName(query string) string {
+	return strings.Fields(strings.TrimPrefix(query, "-- name: "))[0]
+}
+
+func openMergeDB(t *testing.T, db *mergeDB) *config.Config {
+	name := fmt.Sprintf("merge-%p", db)
+	sql.Register(name, db)
+	conn, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &config.Config{Database: conn, Db: database.New(conn)}
+}
+
+func TestMergeReattributesProvenance(t *testing.T) {
+	survivor := med("Doxorubicin", 2)
+	duplicate := med("Adriamycin", 1)
+	survivor.EmetogenicLevel = database.EmetogenicLevelEnumModerate
+	duplicate.EmetogenicLevel = database.EmetogenicLevelEnumModerate
+
+	db := &mergeDB{meds: map[string]database.Medication{survivor.ID.String(): survivor, duplicate.ID.String(): duplicate}}
+	c := openMergeDB(t, db)
+
+	result, err := Merge(c, context.Background(), survivor.ID, []uuid.UUID{duplicate.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !db.committed || len(result.Merged) != 1 || result.Prescriptions != 1 || result.Treatments != 1 {
+		t.Fatalf("committed %v, result %+v", db.committed, result)
+	}
+
+	// identical prescriptions and treatments of the duplicate are deleted,
+	// so the statement deleting them has to carry their page and quote over
+	// to the survivor before the medication goes
+	ran := map[string]string{}
+	order := []string{}
+	for _, q := range db.statements {
+		ran[queryName(q)] = q
+		order = append(order, queryName(q))
+	}
+	for name, entity := range map[string]string{"MergeMedicationPrescriptions": "prescription", "MergeMedicationTreatments": "treatment"} {
+		q, ok := ran[name]
+		if !ok {
+			t.Errorf("%s not run", name)
+			continue
+		}
+		insert := strings.Index(q, "INSERT INTO provenance")
+		remove := strings.Index(q, "DELETE FROM provenance")
+		if insert < 0 || remove < 0 || !strings.Contains(q, "entity_type = '"+entity+"'") ||
+			!strings.Contains(q[insert:remove], "pairs.survivor_id") || !strings.Contains(q[insert:remove], "ON CONFLICT DO NOTHING") {
+			t.Errorf("%s does not move %s provenance to the survivor:\n%s", name, entity, q)
+		}
+	}
+	// the survivor is read back after the duplicate is deleted
+	if deleted := order[len(order)-2]; deleted != "DeleteMedication" {
+		t.Errorf("statements ran in order %v", order)
+	}
+}
@@ -0,0 +1,70 @@
+package medications
+
+// synonyms are names a protocol may use for the same drug, generic name
+// first: brand names, older names and abbreviations. A formulation that
+// doses differently, e.g. liposomal doxorubicin, is its own drug.
+var synonyms = [][]string{
+	{"doxorubicin", "adriamycin", "hydroxydaunorubicin"},
+	{"pegylated liposomal doxorubicin", "caelyx", "doxil"},
+	{"epirubicin", "ellence", "pharmorubicin"},
+	{"cyclophosphamide", "cytoxan", "procytox"},
+	{"ifosfamide", "ifex"},
+	{"mesna", "mesnex", "uromitexan"},
+	{"vincristine", "oncovin"},
+	{"vinblastine", "velban"},
+	{"paclitaxel", "taxol"},
+	{"nab-paclitaxel", "abraxane"},
+	{"docetaxel", "taxotere"},
+	{"carboplatin", "paraplatin"},
+	{"cisplatin", "platinol"},
+	{"oxaliplatin", "eloxatin"},
+	{"fluorouracil", "5-fu", "5-fluorouracil", "adrucil"},
+	{"capecitabine", "xeloda"},
+	{"gemcitabine", "gemzar"},
+	{"irinotecan", "camptosar"},
+	{"etoposide", "vp-16", "vepesid"},
+	{"methotrexate", "mtx"},
+	{"cytarabine", "ara-c", "cytosar"},
+	{"bleomycin", "blenoxane"},
+	{"dacarbazine", "dtic"},
+	{"bendamustine", "treanda"},
+	{"temozolomide", "temodal", "temodar"},
+	{"rituximab", "rituxan"},
+	{"trastuzumab", "herceptin"},
+	{"pertuzumab", "perjeta"},
+	{"bevacizumab", "avastin"},
+	{"cetuximab", "erbitux"},
+	{"pembrolizumab", "keytruda"},
+	{"nivolumab", "opdivo"},
+	{"bortezomib", "velcade"},
+	{"lenalidomide", "revlimid"},
+	{"imatinib", "gleevec", "glivec"},
+	{"tamoxifen", "nolvadex"},
+	{"letrozole", "femara"},
+	{"anastrozole", "arimidex"},
+	{"leucovorin", "folinic acid", "calcium folinate"},
+	{"filgrastim", "neupogen", "g-csf"},
+	{"pegfilgrastim", "neulasta"},
+	{"zoledronic acid", "zometa"},
+	{"dexamethasone", "decadron"},
+	{"ondansetron", "zofran"},
+	{"granisetron", "kytril"},
+	{"aprepitant", "emend"},
+	{"metoclopramide", "maxeran", "reglan"},
+	{"prochlorperazine", "stemetil", "compazine"},
+	{"olanzapine", "zyprexa"},
+	{"diphenhydramine", "benadryl"},
+	{"famotidine", "pepcid"},
+	{"acetaminophen", "paracetamol", "tylenol"},
+}
+
+// synonymGroup maps every normalized name of the list to its group.
+var synonymGroup = func() map[string][]string {
+	groups := map[string][]string{}
+	for _, group := range synonyms {
+		for _, name := range group {
+			groups[Normalize(name)] = group
+		}
+	}
+	return groups
+}()
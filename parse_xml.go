@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bcca_crawler/fetch"
 	"encoding/xml"
 	"strings"
 	"fmt"
@@ -59,24 +60,12 @@ func ExtractText(filePath string, tikaURL string) (string, error) {
 	}
 	req.Header.Set("Content-Type", "application/pdf")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := fetch.Services.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %v", err)
 	}
-	defer resp.Body.Close()	
-
-	// Check the response status
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected response status: %s", resp.Status)
-	}
-
-	// Read the response body
-	responseText, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
-	}
 
+	responseText := resp.Body
 	return string(responseText), nil
 }
 
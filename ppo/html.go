@@ -0,0 +1,51 @@
+package ppo
+
+import (
+	"html/template"
+	"io"
+)
+
+var htmlTemplate = template.Must(template.New("ppo").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+  @page { size: letter; margin: 1.5cm; }
+  body { font-family: Helvetica, Arial, sans-serif; font-size: 10pt; color: #000; }
+  h1 { font-size: 14pt; margin: 0; }
+  h2 { font-size: 10.5pt; margin: 14pt 0 4pt; padding: 2pt 4pt; background: #e6e6e6; }
+  .subtitle { font-size: 11pt; margin: 2pt 0; }
+  .revision, .note, footer { font-size: 8.5pt; color: #444; }
+  .line { margin: 5pt 0; line-height: 1.5; }
+  .line.bold { font-weight: bold; }
+  .box { display: inline-block; width: 9pt; height: 9pt; border: 1px solid #000; margin-right: 5pt; vertical-align: -1pt; }
+  .blank { display: inline-block; border-bottom: 1px solid #000; height: 1em; margin: 0 3pt; }
+  section { page-break-inside: avoid; }
+  footer { margin-top: 18pt; border-top: 1px solid #999; padding-top: 4pt; }
+</style>
+</head>
+<body>
+<header>
+  <h1>{{.Title}}</h1>
+  <div class="subtitle">{{.Subtitle}}</div>
+  {{if .Revision}}<div class="revision">{{.Revision}}</div>{{end}}
+</header>
+{{range .Sections}}
+<section>
+  <h2>{{.Title}}</h2>
+  {{if .Note}}<div class="note">{{.Note}}</div>{{end}}
+  {{range .Lines}}
+  <div class="line{{if .Bold}} bold{{end}}">{{if .Checkbox}}<span class="box"></span>{{end}}{{range .Segments}}{{if .Blank}}<span class="blank" style="width: {{.Blank}}ch"></span>{{else}}{{.Text}} {{end}}{{end}}</div>
+  {{end}}
+</section>
+{{end}}
+<footer>{{.Footer}}</footer>
+</body>
+</html>
+`))
+
+// RenderHTML writes the sheet as a self-contained printable page.
+func RenderHTML(w io.Writer, sheet Sheet) error {
+	return htmlTemplate.Execute(w, sheet)
+}
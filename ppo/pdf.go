@@ -0,0 +1,279 @@
+package ppo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The PDF writer below only needs the standard Helvetica fonts, lines and
+// rectangles, so it writes PDF 1.4 directly instead of pulling in a library.
+
+const (
+	pageWidth    = 612.0 // US letter in points
+	pageHeight   = 792.0
+	margin       = 42.0
+	bodySize     = 9.5
+	lineHeight   = 15.0
+	checkboxSize = 8.0
+)
+
+// helveticaWidths and helveticaBoldWidths are the AFM widths of the
+// printable ASCII characters (32 to 126), in thousandths of the font size.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// textWidth measures text already passed through toWinAnsi.
+func textWidth(s string, size float64, bold bool) float64 {
+	widths := &helveticaWidths
+	if bold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= 32 && c <= 126 {
+			total += widths[c-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// toWinAnsi maps text to the WinAnsi encoding of the standard fonts,
+// spelling out the few symbols protocols use that it lacks.
+func toWinAnsi(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 32 && r <= 126:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			b.WriteByte(byte(r))
+		case r == '≥':
+			b.WriteString(">=")
+		case r == '≤':
+			b.WriteString("<=")
+		case r == '–' || r == '—':
+			b.WriteByte('-')
+		case r == '’' || r == '‘':
+			b.WriteByte('\'')
+		case r == '“' || r == '”':
+			b.WriteByte('"')
+		case r == '\t' || r == '\n':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// escape quotes an encoded string for a PDF literal.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c >= 0x80:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+type pdfLayout struct {
+	pages []*pdfPage
+	page  *pdfPage
+	y     float64
+}
+
+func (l *pdfLayout) newPage() {
+	l.page = &pdfPage{}
+	l.pages = append(l.pages, l.page)
+	l.y = pageHeight - margin
+}
+
+// ensure starts a new page when less than height is left.
+func (l *pdfLayout) ensure(height float64) {
+	if l.page == nil || l.y-height < margin {
+		l.newPage()
+	}
+}
+
+func (l *pdfLayout) text(x, y float64, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&l.page.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escape(s))
+}
+
+func (l *pdfLayout) hline(x1, x2, y float64) {
+	fmt.Fprintf(&l.page.content, "%.2f %.2f m %.2f %.2f l S\n", x1, y, x2, y)
+}
+
+func (l *pdfLayout) rect(x, y, w, h float64, fill bool) {
+	op := "S"
+	if fill {
+		op = "f"
+	}
+	fmt.Fprintf(&l.page.content, "%.2f %.2f %.2f %.2f re %s\n", x, y, w, h, op)
+}
+
+// words splits a text segment so long lines can wrap between words.
+func words(s string) []string {
+	return strings.Fields(toWinAnsi(s))
+}
+
+func (l *pdfLayout) line(line Line) {
+	const indent = margin + checkboxSize + 6
+	l.ensure(lineHeight)
+	x := margin
+	if line.Checkbox {
+		l.rect(margin, l.y-1, checkboxSize, checkboxSize, false)
+		x = indent
+	}
+	space := textWidth(" ", bodySize, line.Bold)
+	maxX := pageWidth - margin
+
+	for _, seg := range line.Segments {
+		if seg.Blank > 0 {
+			w := float64(seg.Blank) * textWidth("0", bodySize, false)
+			if x+w > maxX {
+				l.y -= lineHeight
+				l.ensure(lineHeight)
+				x = indent
+			}
+			l.hline(x, x+w, l.y-2)
+			x += w + space
+			continue
+		}
+		for _, word := range words(seg.Text) {
+			w := textWidth(word, bodySize, line.Bold)
+			if x+w > maxX && x > indent {
+				l.y -= lineHeight
+				l.ensure(lineHeight)
+				x = indent
+			}
+			l.text(x, l.y, bodySize, line.Bold, word)
+			x += w + space
+		}
+	}
+	l.y -= lineHeight
+}
+
+func (l *pdfLayout) paragraph(s string, size float64, bold bool) {
+	if s == "" {
+		return
+	}
+	x := margin
+	l.ensure(size + 4)
+	for _, word := range words(s) {
+		w := textWidth(word, size, bold)
+		if x+w > pageWidth-margin && x > margin {
+			l.y -= size + 4
+			l.ensure(size + 4)
+			x = margin
+		}
+		l.text(x, l.y, size, bold, word)
+		x += w + textWidth(" ", size, bold)
+	}
+	l.y -= size + 4
+}
+
+// RenderPDF writes the sheet as a letter size PDF.
+func RenderPDF(w io.Writer, sheet Sheet) error {
+	l := &pdfLayout{}
+	l.newPage()
+
+	l.paragraph(sheet.Title, 14, true)
+	l.paragraph(sheet.Subtitle, 11, false)
+	l.paragraph(sheet.Revision, 8, false)
+
+	for _, s := range sheet.Sections {
+		// keep a section title with at least its first line
+		l.ensure(lineHeight*3 + 6)
+		l.y -= 6
+		fmt.Fprintf(&l.page.content, "0.9 g\n")
+		l.rect(margin, l.y-4, pageWidth-2*margin, 15, true)
+		fmt.Fprintf(&l.page.content, "0 g\n")
+		l.text(margin+4, l.y, 10, true, toWinAnsi(s.Title))
+		l.y -= lineHeight + 2
+		l.paragraph(s.Note, 8, false)
+		for _, line := range s.Lines {
+			l.line(line)
+		}
+	}
+
+	l.y -= 8
+	l.paragraph(sheet.Footer, 7.5, false)
+
+	for i, p := range l.pages {
+		l.page = p
+		l.text(pageWidth-margin-60, margin-20, 7.5, false, fmt.Sprintf("Page %d of %d", i+1, len(l.pages)))
+	}
+
+	return writePDF(w, sheet.Title, l.pages)
+}
+
+func writePDF(w io.Writer, title string, pages []*pdfPage) error {
+	var out bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// objects 1-4 are fixed, then a page and a content stream per page
+	kids := []string{}
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+2*i))
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, p := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()))
+	}
+	object(fmt.Sprintf("<< /Title (%s) /Producer (bcca_crawler) >>", escape(toWinAnsi(title))))
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, len(offsets), xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
@@ -0,0 +1,125 @@
+package ppo
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain", "plain"},
+		{"ANC (x 10^9/L)", `ANC \(x 10^9/L\)`},
+		{`C:\path`, `C:\\path`},
+		{`\)(`, `\\\)\(`},
+		{toWinAnsi("é ≥ 1"), `\351 >= 1`},
+	}
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func renderSheet(t *testing.T, sheet Sheet) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := RenderPDF(&buf, sheet); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRenderPDFStructure(t *testing.T) {
+	sheet := Sheet{Title: `LYCHOP (R) \ test`, Subtitle: "Lymphoma", Footer: "footer"}
+	// enough lines for a second page
+	lines := []Line{}
+	for i := 0; i < 60; i++ {
+		lines = append(lines, check(text(fmt.Sprintf("Line %d (CBC)", i)), blank(6)))
+	}
+	sheet.Sections = []Section{{Title: "Tests", Lines: lines}}
+	pdf := renderSheet(t, sheet)
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf("missing header or trailer")
+	}
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n0 ")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	var count int
+	fmt.Sscanf(string(pdf[xref:]), "xref\n0 %d\n", &count)
+	entries := strings.Split(string(pdf[xref:]), "\n")[2 : 2+count]
+	if entries[0] != "0000000000 65535 f " {
+		t.Errorf("first xref entry = %q", entries[0])
+	}
+	for i, entry := range entries[1:] {
+		if len(entry)+1 != 20 {
+			t.Errorf("xref entry %d is %d bytes, want 20", i+1, len(entry)+1)
+		}
+		offset, _ := strconv.Atoi(entry[:10])
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(pdf[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, pdf[offset:offset+10])
+		}
+	}
+	if !bytes.Contains(pdf, []byte(fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>", count, count-1))) {
+		t.Errorf("trailer does not match %d xref entries", count)
+	}
+
+	if !bytes.Contains(pdf, []byte("/Count 2 >>")) {
+		t.Error("want 2 pages")
+	}
+	if !bytes.Contains(pdf, []byte("Page 2 of 2")) {
+		t.Error("no page numbers")
+	}
+	if !bytes.Contains(pdf, []byte(`/Title (LYCHOP \(R\) \\ test)`)) {
+		t.Error("title not escaped")
+	}
+
+	streams := regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)endstream`).FindAllSubmatch(pdf, -1)
+	if len(streams) != 2 {
+		t.Fatalf("%d content streams, want 2", len(streams))
+	}
+	for _, s := range streams {
+		if n, _ := strconv.Atoi(string(s[1])); n != len(s[2]) {
+			t.Errorf("stream /Length %d, content is %d bytes", n, len(s[2]))
+		}
+	}
+}
+
+// shown matches a word drawn by pdfLayout.text.
+var shown = regexp.MustCompile(`BT /F(\d) ([\d.]+) Tf ([\d.]+) ([\d.]+) Td \(((?:\\.|[^\\)])*)\) Tj ET`)
+
+func TestRenderPDFWrapping(t *testing.T) {
+	long := strings.Repeat("Hold if ANC less than 1.0 (x 10^9/L) or platelets less than 75. ", 6)
+	sheet := Sheet{Title: "T", Sections: []Section{{Title: "Treatment", Lines: []Line{check(text(long))}}}}
+	pdf := renderSheet(t, sheet)
+
+	unescape := strings.NewReplacer(`\(`, "(", `\)`, ")", `\\`, `\`)
+	rows := map[string]bool{}
+	for _, m := range shown.FindAllSubmatch(pdf, -1) {
+		size, _ := strconv.ParseFloat(string(m[2]), 64)
+		x, _ := strconv.ParseFloat(string(m[3]), 64)
+		word := unescape.Replace(string(m[5]))
+		if size != bodySize || strings.HasPrefix(word, "Page ") {
+			continue
+		}
+		rows[string(m[4])] = true
+		if end := x + textWidth(word, size, string(m[1]) == "2"); end > pageWidth-margin+0.01 {
+			t.Errorf("%q ends at %.2f, past the margin", word, end)
+		}
+		if x < margin+checkboxSize+6-0.01 {
+			t.Errorf("%q starts at %.2f, left of the checkbox indent", word, x)
+		}
+	}
+	if len(rows) < 3 {
+		t.Errorf("long line drawn on %d rows, want it wrapped", len(rows))
+	}
+}
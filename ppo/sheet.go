@@ -0,0 +1,235 @@
+package ppo
+
+import (
+	"bcca_crawler/dosing"
+	"fmt"
+	"strings"
+)
+
+// Protocol is the view of a protocol needed to lay out its order sheet.
+type Protocol struct {
+	Code       string
+	Name       string
+	TumorGroup string
+	RevisedOn  string
+	Templates  []dosing.CycleTemplate
+	Premeds    []Medication
+	Support    []Medication
+	Labs       []LabGroup
+}
+
+type Medication struct {
+	Name         string
+	Dose         string
+	Route        string
+	Frequency    string
+	Duration     string
+	Instructions string
+}
+
+// LabGroup mirrors a protocol test group; categories are baseline,
+// followup or any other grouping the protocol uses.
+type LabGroup struct {
+	Category string
+	Comments string
+	Tests    []string
+}
+
+// Segment is either text or, when Blank > 0, a blank line about Blank
+// characters wide for the prescriber to fill in.
+type Segment struct {
+	Text  string
+	Blank int
+}
+
+type Line struct {
+	Checkbox bool
+	Bold     bool
+	Segments []Segment
+}
+
+type Section struct {
+	Title string
+	Note  string
+	Lines []Line
+}
+
+// Sheet is a renderer independent order sheet.
+type Sheet struct {
+	Title    string
+	Subtitle string
+	Revision string
+	Sections []Section
+	Footer   string
+}
+
+func text(s string) Segment { return Segment{Text: s} }
+func blank(n int) Segment   { return Segment{Blank: n} }
+func check(segments ...Segment) Line {
+	return Line{Checkbox: true, Segments: segments}
+}
+func plain(segments ...Segment) Line {
+	return Line{Segments: segments}
+}
+
+// Build lays out the pre-printed order: patient details, baseline tests,
+// premedications, one treatment block per cycle with blanks for the
+// calculated doses, supportive medications, follow-up tests, the return
+// appointment and signatures.
+func Build(p Protocol) Sheet {
+	sheet := Sheet{
+		Title:    "PROVINCIAL PRE-PRINTED ORDER " + p.Code,
+		Subtitle: p.Name,
+		Footer:   "Doses and tests are taken from the protocol summary. Verify against the current BC Cancer protocol before signing.",
+	}
+	if p.RevisedOn != "" {
+		sheet.Revision = "Protocol revised " + p.RevisedOn
+	}
+
+	sheet.Sections = append(sheet.Sections, Section{
+		Title: "PATIENT",
+		Lines: []Line{
+			plain(text("Name:"), blank(30), text("PHN:"), blank(14)),
+			plain(text("Height:"), blank(6), text("cm   Weight:"), blank(6), text("kg   BSA:"), blank(6), text("m2")),
+			plain(text("Date:"), blank(12), text("To be given:"), blank(12), text("Cycle #:"), blank(4)),
+			plain(text("Date of previous cycle:"), blank(12)),
+			check(text("Delay treatment"), blank(4), text("week(s)")),
+		},
+	})
+
+	if s, ok := labSection("BASELINE TESTS", p.Labs, "baseline"); ok {
+		sheet.Sections = append(sheet.Sections, s)
+	}
+
+	if len(p.Premeds) > 0 {
+		premeds := Section{Title: "PREMEDICATIONS", Note: "Patient to take own supply unless otherwise indicated."}
+		for _, m := range p.Premeds {
+			premeds.Lines = append(premeds.Lines, check(text(describe(m))))
+		}
+		sheet.Sections = append(sheet.Sections, premeds)
+	}
+
+	for _, t := range p.Templates {
+		title := "TREATMENT"
+		if t.Label != "" {
+			title += " - " + strings.ToUpper(t.Label)
+		}
+		treatment := Section{Title: title, Note: t.Duration}
+		for _, tx := range t.Treatments {
+			treatment.Lines = append(treatment.Lines, treatmentLine(tx))
+		}
+		treatment.Lines = append(treatment.Lines,
+			check(text("Dose modification for:"), blank(16), text("Dose:"), blank(10)),
+		)
+		sheet.Sections = append(sheet.Sections, treatment)
+	}
+
+	if len(p.Support) > 0 {
+		support := Section{Title: "SUPPORTIVE MEDICATIONS"}
+		for _, m := range p.Support {
+			support.Lines = append(support.Lines, check(text(describe(m))))
+		}
+		sheet.Sections = append(sheet.Sections, support)
+	}
+
+	if s, ok := labSection("FOLLOW-UP TESTS", p.Labs, "followup"); ok {
+		sheet.Sections = append(sheet.Sections, s)
+	}
+	for _, g := range p.Labs {
+		category := strings.ToLower(g.Category)
+		if category == "baseline" || category == "followup" || len(g.Tests) == 0 {
+			continue
+		}
+		if s, ok := labSection(strings.ToUpper(g.Category)+" TESTS", []LabGroup{g}, category); ok {
+			sheet.Sections = append(sheet.Sections, s)
+		}
+	}
+
+	sheet.Sections = append(sheet.Sections,
+		Section{
+			Title: "RETURN APPOINTMENT ORDERS",
+			Lines: []Line{
+				check(text("Return in"), blank(4), text("week(s) for cycle"), blank(4)),
+				check(text("Last cycle. Return in"), blank(4), text("week(s)")),
+			},
+		},
+		Section{
+			Title: "SIGNATURES",
+			Lines: []Line{
+				plain(text("Doctor's signature:"), blank(26), text("UC:"), blank(6)),
+				plain(text("Nurse / pharmacist verification:"), blank(20), text("Date:"), blank(10)),
+			},
+		},
+	)
+	return sheet
+}
+
+func labSection(title string, groups []LabGroup, category string) (Section, bool) {
+	section := Section{Title: title}
+	notes := []string{}
+	for _, g := range groups {
+		if strings.ToLower(g.Category) != category {
+			continue
+		}
+		for _, t := range g.Tests {
+			section.Lines = append(section.Lines, check(text(t)))
+		}
+		if g.Comments != "" {
+			notes = append(notes, g.Comments)
+		}
+	}
+	section.Note = strings.Join(notes, " ")
+	return section, len(section.Lines) > 0
+}
+
+// treatmentLine leaves a blank for the calculated dose of BSA or weight
+// based doses, e.g. "DOXOrubicin 60 mg/m2 x BSA = ____ mg IV".
+func treatmentLine(tx dosing.TemplateTreatment) Line {
+	line := check(text(fmt.Sprintf("%s %s", tx.MedicationName, tx.Dose)))
+	line.Bold = true
+
+	if dose, ok := dosing.ParseDose(tx.Dose); ok {
+		switch dose.Basis {
+		case dosing.BasisMgM2:
+			line.Segments = append(line.Segments, text("x BSA ="), blank(8), text("mg"))
+		case dosing.BasisMgKg:
+			line.Segments = append(line.Segments, text("x weight ="), blank(8), text("mg"))
+		case dosing.BasisUnitsM2:
+			line.Segments = append(line.Segments, text("x BSA ="), blank(8), text("units"))
+		}
+	}
+
+	rest := strings.TrimSpace(strings.Join([]string{routeLabel(tx.Route), tx.Frequency}, " "))
+	if rest != "" {
+		line.Segments = append(line.Segments, text(rest))
+	}
+	return line
+}
+
+func describe(m Medication) string {
+	parts := []string{m.Name, m.Dose, routeLabel(m.Route), m.Frequency, m.Duration}
+	out := []string{}
+	for _, p := range parts {
+		if strings.TrimSpace(p) != "" {
+			out = append(out, strings.TrimSpace(p))
+		}
+	}
+	s := strings.Join(out, " ")
+	if m.Instructions != "" {
+		s += " (" + m.Instructions + ")"
+	}
+	return s
+}
+
+var routeLabels = map[string]string{
+	"iv":         "IV",
+	"oral":       "PO",
+	"sc":         "subcut",
+	"im":         "IM",
+	"topical":    "topical",
+	"inhalation": "inhaled",
+}
+
+func routeLabel(route string) string {
+	return routeLabels[strings.ToLower(route)]
+}
@@ -0,0 +1,89 @@
+// Package prompts reads the LLM prompts from the prompt registry in the
+// database. A prompt has numbered versions that are never edited and an
+// active version; extractions record the version they used, so a result can
+// be traced back to the exact text that produced it.
+package prompts
+
+import (
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const (
+	// Extraction is the prompt of a whole-protocol extraction.
+	Extraction = "protocol_extraction"
+	// Overview is the first pass of a sectioned extraction: the summary
+	// and where each section is.
+	Overview = "protocol_overview"
+	// Section is the prompt of the other passes, once per section.
+	Section = "protocol_section"
+)
+
+// Vars are the values a template can use, as {{.TumorGroup}},
+// {{.Section}} and {{.Location}}, the pages and headings the overview
+// found a section under. Any may be empty.
+type Vars struct {
+	TumorGroup string
+	Section    string
+	Location   string
+}
+
+// Active returns the active version of a prompt.
+func Active(c *config.Config, ctx context.Context, name string) (database.PromptVersion, error) {
+	v, err := c.Db.GetActivePromptVersion(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return v, fmt.Errorf("prompt %s has no active version", name)
+	}
+	if err != nil {
+		return v, fmt.Errorf("error getting prompt: %s, with error: %v", name, err)
+	}
+	return v, nil
+}
+
+// Version returns one version of a prompt, active or not.
+func Version(c *config.Config, ctx context.Context, name string, version int32) (database.PromptVersion, error) {
+	v, err := c.Db.GetPromptVersion(ctx, database.GetPromptVersionParams{Name: name, Version: version})
+	if errors.Is(err, sql.ErrNoRows) {
+		return v, fmt.Errorf("prompt %s has no version %d", name, version)
+	}
+	if err != nil {
+		return v, fmt.Errorf("error getting prompt: %s, with error: %v", name, err)
+	}
+	return v, nil
+}
+
+// Render fills in a prompt version.
+func Render(v database.PromptVersion, vars Vars) (string, error) {
+	t, err := parse(v.Template)
+	if err != nil {
+		return "", fmt.Errorf("prompt version %d: %v", v.Version, err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("error rendering prompt version %d: %v", v.Version, err)
+	}
+	return b.String(), nil
+}
+
+// Check reports a template that does not parse or uses a variable other
+// than those of Vars, before it is saved.
+func Check(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return errors.New("template is empty")
+	}
+	t, err := parse(text)
+	if err != nil {
+		return err
+	}
+	return t.Execute(&strings.Builder{}, Vars{TumorGroup: "lymphoma", Section: "toxicities", Location: "on pages 3-4"})
+}
+
+func parse(text string) (*template.Template, error) {
+	return template.New("prompt").Option("missingkey=error").Parse(text)
+}
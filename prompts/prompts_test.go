@@ -0,0 +1,71 @@
+package prompts
+
+import (
+	"bcca_crawler/internal/database"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	v := database.PromptVersion{Version: 2, Template: "Extract the {{.Section}} of this {{.TumorGroup}} protocol."}
+	got, err := Render(v, Vars{TumorGroup: "lymphoma", Section: "toxicities"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Extract the toxicities of this lymphoma protocol."; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := Render(database.PromptVersion{Template: "{{.Cancer}}"}, Vars{}); err == nil {
+		t.Error("rendering an unknown variable did not fail")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		ok   bool
+	}{
+		{"Plain text.", true},
+		{"{{if .TumorGroup}}Listed under {{.TumorGroup}}.{{end}}", true},
+		{"", false},
+		{"  \n", false},
+		{"{{.TumorGroup", false},
+		{"{{.Drug}}", false},
+	} {
+		if err := Check(tc.text); (err == nil) != tc.ok {
+			t.Errorf("Check(%q) = %v, want ok %v", tc.text, err, tc.ok)
+		}
+	}
+}
+
+var seeded = regexp.MustCompile(`(?s)INSERT INTO prompt_versions .*?SELECT id, \d+, '(.*?)', '`)
+
+// TestSeededPrompts checks the versions the migrations insert would be
+// accepted by the API.
+func TestSeededPrompts(t *testing.T) {
+	files, err := filepath.Glob("../sql/schema/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range seeded.FindAllStringSubmatch(string(data), -1) {
+			found++
+			text := strings.ReplaceAll(m[1], "''", "'")
+			if err := Check(text); err != nil {
+				t.Errorf("%s: seeded prompt does not render: %v", filepath.Base(file), err)
+			}
+		}
+	}
+	if found == 0 {
+		t.Fatal("no seeded prompt versions found")
+	}
+}
@@ -96,6 +96,12 @@ func RegisterRoutes(router *mux.Router, s *config.Config) {
 	RegisterPhysicianRoutes(pre, router, s)
 	RegisterToxicitiesRoutes(pre, router, s)
 	RegisterTreatmentRoutes(pre, router, s)
+	RegisterTreatmentPlanRoutes(pre, router, s)
+	RegisterDocumentRoutes(pre, router, s)
+	RegisterJobRoutes(pre, router, s)
+	RegisterScheduleRoutes(pre, router, s)
+	RegisterPromptRoutes(pre, router, s)
+	RegisterUsageRoutes(pre, router, s)
 
 }
 
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/internal/config"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterDocumentRoutes(prefix string, router *mux.Router, s *config.Config) {
+	// Stored source documents by SHA-256
+	router.HandleFunc(prefix+"/documents/{hash:[a-fA-F0-9]{64}}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			api.HandleGetDocument(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+}
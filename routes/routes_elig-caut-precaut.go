@@ -32,6 +32,38 @@ func RegisterCriteriaRoutes(prefix string, mux *mux.Router, s *config.Config) {
 		}
 	})
 
+	mux.HandleFunc(prefix +"/eligibility_criteria/{id}/rule", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			protocols.HandleGetEligibilityRule(s, w, r)
+		case http.MethodPut:
+			protocols.HandleUpsertEligibilityRule(s, w, r)
+		case http.MethodDelete:
+			protocols.HandleDeleteEligibilityRule(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc(prefix +"/eligibility_rules", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			protocols.HandleGetEligibilityRules(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Screens a patient profile against the criteria of every protocol
+	mux.HandleFunc(prefix +"/eligibility/screen", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			protocols.HandleScreenEligibility(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	mux.HandleFunc(prefix +"/cautions", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
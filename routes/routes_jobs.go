@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/internal/config"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterJobRoutes(prefix string, router *mux.Router, s *config.Config) {
+	// Crawl and extraction job queue, query = status, limit
+	router.HandleFunc(prefix+"/admin/jobs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			api.HandleGetJobs(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+
+	router.HandleFunc(prefix+"/admin/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			api.HandleGetJob(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+
+	router.HandleFunc(prefix+"/admin/jobs/{id}/retry", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			api.HandleRetryJob(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("POST")
+
+	router.HandleFunc(prefix+"/admin/jobs/{id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			api.HandleCancelJob(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("POST")
+}
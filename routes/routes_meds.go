@@ -64,6 +64,93 @@ func RegisterMedRoutes(prefix string, mux *mux.Router, s *config.Config) {
 		}
 	})
 
+	mux.HandleFunc(prefix +"/medications/dose_limits", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			protocols.HandleGetDoseLimits(s, w, r)
+		case http.MethodPut:
+			protocols.HandleUpsertDoseLimit(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc(prefix +"/medications/dose_limits/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			protocols.HandleGetDoseLimitByID(s, w, r)
+		case http.MethodDelete:
+			protocols.HandleDeleteDoseLimit(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc(prefix +"/medications/dose_classes", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			protocols.HandleGetDoseClasses(s, w, r)
+		case http.MethodPut:
+			protocols.HandleUpsertDoseClass(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc(prefix +"/medications/dose_classes/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			protocols.HandleGetDoseClassByID(s, w, r)
+		case http.MethodDelete:
+			protocols.HandleDeleteDoseClass(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Proposed merges of medications that look like one drug
+	mux.HandleFunc(prefix +"/admin/medications/duplicates", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			protocols.HandleGetMedDuplicates(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Merge the medications of the body, duplicate_ids, into this one
+	mux.HandleFunc(prefix +"/admin/medications/{id:"+uuidPattern+"}/merge", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			protocols.HandleMergeMeds(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc(prefix +"/interactions", func(w http.ResponseWriter, r *http.Request) {
+		//query = drug
+		switch r.Method {
+		case http.MethodGet:
+			protocols.HandleGetInteractions(s, w, r)
+		case http.MethodPut:
+			protocols.HandleUpsertInteraction(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc(prefix +"/interactions/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			protocols.HandleGetInteractionByID(s, w, r)
+		case http.MethodDelete:
+			protocols.HandleDeleteInteraction(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	mux.HandleFunc(prefix +"/prescriptions", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/internal/config"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterPromptRoutes(prefix string, router *mux.Router, s *config.Config) {
+	// Versioned LLM prompts
+	router.HandleFunc(prefix+"/admin/prompts", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			api.HandleGetPrompts(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+
+	router.HandleFunc(prefix+"/admin/prompts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			api.HandleGetPrompt(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+
+	// body = template, notes, activate
+	router.HandleFunc(prefix+"/admin/prompts/{id}/versions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			api.HandleCreatePromptVersion(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("POST")
+
+	// body = version
+	router.HandleFunc(prefix+"/admin/prompts/{id}/activate", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			api.HandleActivatePrompt(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("PUT")
+
+	// Prompt version and model of each extraction of a protocol
+	router.HandleFunc(prefix+"/protocols/{protocol_id}/extractions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			api.HandleGetProtocolExtractions(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+}
@@ -50,6 +50,24 @@ func RegisterProtocolRoutes(prefix string, router *mux.Router, s *config.Config)
 		}
 	}).Methods("GET", "PUT", "DELETE")
 
+	// Emetogenic audit of all protocols, query = mismatched=true
+	router.HandleFunc(prefix+"/protocols/emetogenic_audit", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			protocols.HandleGetEmetogenicAudit(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+
+	// Protocols listed on the website, query = status=active|retired|all
+	router.HandleFunc(prefix+"/protocols/inventory", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			protocols.HandleGetProtocolInventory(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+
 	// Special route for summary by code (doesn't follow the UUID pattern)
 	router.HandleFunc(prefix+"/protocols/summarycode/{code}", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -254,4 +272,97 @@ func RegisterProtocolRoutes(prefix string, router *mux.Router, s *config.Config)
 			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
 		}
 	}).Methods("GET")
+
+	// Cumulative dose projection
+	protocolRouter.HandleFunc("/cumulative_dose", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			protocols.HandleProjectCumulativeDose(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("POST")
+
+	// Treatment calendar
+	protocolRouter.HandleFunc("/calendar", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			protocols.HandleGetProtocolCalendar(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+
+	// Drug interactions, query = home_meds (comma separated)
+	protocolRouter.HandleFunc("/interactions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			protocols.HandleGetProtocolInteractions(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+
+	// Emetogenic risk and antiemetic premedication check
+	protocolRouter.HandleFunc("/emetogenic_risk", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			protocols.HandleGetProtocolEmetogenicRisk(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+
+	// FHIR R4 export, query = download=true
+	protocolRouter.HandleFunc("/fhir", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			protocols.HandleGetProtocolFHIR(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+
+	// Pre-printed order sheet, query = format=pdf|html; GET serves the stored copy, POST renders it again
+	protocolRouter.HandleFunc("/ppo", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			protocols.HandleGetProtocolPPO(s, w, r)
+		case http.MethodPost:
+			protocols.HandleStorePPO(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET", "POST")
+
+	// A stored order sheet, by the content hash in its url
+	protocolRouter.HandleFunc("/ppo/{hash:[a-fA-F0-9]{64}}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			protocols.HandleGetStoredPPO(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+
+	// Documents linked from the protocol page, with their stored copies
+	protocolRouter.HandleFunc("/documents", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			protocols.HandleGetProtocolDocuments(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+
+	// Local archive copy of a document the document store could not take
+	protocolRouter.HandleFunc("/documents/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			protocols.HandleGetProtocolDocumentArchive(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+
+	// Source documents each revision of the protocol was extracted from
+	protocolRouter.HandleFunc("/sources", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			protocols.HandleGetProtocolSources(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
 }
@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/internal/config"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterScheduleRoutes(prefix string, router *mux.Router, s *config.Config) {
+	// Crawl schedules run by `serve --scheduler`
+	router.HandleFunc(prefix+"/admin/schedules", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			api.HandleGetSchedules(s, w, r)
+		case http.MethodPost:
+			api.HandleCreateSchedule(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET", "POST")
+
+	router.HandleFunc(prefix+"/admin/schedules/{id}/pause", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			api.HandlePauseSchedule(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("PUT")
+
+	router.HandleFunc(prefix+"/admin/schedules/{id}/resume", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			api.HandleResumeSchedule(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("PUT")
+
+	// Run a schedule now
+	router.HandleFunc(prefix+"/admin/schedules/{id}/run", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			api.HandleTriggerSchedule(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("POST")
+}
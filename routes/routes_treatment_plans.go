@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"bcca_crawler/api/protocols"
+	"bcca_crawler/internal/config"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterTreatmentPlanRoutes(prefix string, mux *mux.Router, s *config.Config) {
+	uuidPattern := "[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}"
+
+	mux.HandleFunc(prefix+"/treatment_plans", func(w http.ResponseWriter, r *http.Request) {
+		//query = patient_ref
+		switch r.Method {
+		case http.MethodGet:
+			protocols.HandleGetTreatmentPlans(s, w, r)
+		case http.MethodPost:
+			protocols.HandleCreateTreatmentPlan(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc(prefix+"/treatment_plans/{id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			protocols.HandleGetTreatmentPlanByID(s, w, r)
+		case http.MethodPut:
+			protocols.HandleUpdateTreatmentPlan(s, w, r)
+		case http.MethodDelete:
+			protocols.HandleDeleteTreatmentPlan(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc(prefix+"/treatment_plans/{id:"+uuidPattern+"}/progress", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			protocols.HandleGetTreatmentPlanProgress(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc(prefix+"/treatment_plans/{id:"+uuidPattern+"}/cycles", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			protocols.HandleGetTreatmentPlanCycles(s, w, r)
+		case http.MethodPost:
+			protocols.HandleRecordTreatmentPlanCycle(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc(prefix+"/treatment_plans/{id:"+uuidPattern+"}/cycles/{cycle_id:"+uuidPattern+"}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			protocols.HandleDeleteTreatmentPlanCycle(s, w, r)
+		default:
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"bcca_crawler/api"
+	"bcca_crawler/internal/config"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterUsageRoutes(prefix string, router *mux.Router, s *config.Config) {
+	// LLM calls, tokens and cost, query = period, last
+	router.HandleFunc(prefix+"/admin/llm-usage", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			api.HandleGetLLMUsage(s, w, r)
+		} else {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		}
+	}).Methods("GET")
+}
@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	// 2 March 2026 is a Monday
+	monday := at(time.March, 2, 9, 30)
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"0 10 * * 1", monday, at(time.March, 2, 10, 0)},
+		{"0 10 * * 1", at(time.March, 2, 10, 0), at(time.March, 9, 10, 0)},
+		{"0 10 * * 1", monday.Add(30 * time.Second), at(time.March, 2, 10, 0)},
+
+		// steps
+		{"*/15 * * * *", at(time.March, 2, 9, 31), at(time.March, 2, 9, 45)},
+		{"0-30/10 9 * * *", at(time.March, 2, 9, 21), at(time.March, 2, 9, 30)},
+		{"0-30/10 9 * * *", at(time.March, 2, 9, 30), at(time.March, 3, 9, 0)},
+		{"5/20 * * * *", monday, at(time.March, 2, 9, 45)},
+
+		// lists and ranges
+		{"0 9,17 * * *", at(time.March, 2, 9, 0), at(time.March, 2, 17, 0)},
+		{"0 8-10 * * *", at(time.March, 2, 10, 0), at(time.March, 3, 8, 0)},
+		{"0 0 * * mon-fri", at(time.March, 6, 12, 0), at(time.March, 9, 0, 0)},
+		{"0 0 1,15 jan,jul *", monday, at(time.July, 1, 0, 0)},
+		{"0 0 * * 7", monday, at(time.March, 8, 0, 0)},
+
+		// both day fields restricted: either one matches
+		{"0 0 10 * 5", at(time.March, 2, 0, 0), at(time.March, 6, 0, 0)},
+		{"0 0 10 * 5", at(time.March, 7, 0, 0), at(time.March, 10, 0, 0)},
+		// one of them a wildcard or step: both have to match
+		{"0 0 10 * *", at(time.March, 2, 0, 0), at(time.March, 10, 0, 0)},
+		{"0 0 * * 5", at(time.March, 7, 0, 0), at(time.March, 13, 0, 0)},
+		{"0 0 1 * */2", monday, time.Date(2026, time.August, 1, 0, 0, 0, 0, time.UTC)},
+
+		{"@daily", monday, at(time.March, 3, 0, 0)},
+		{"@monthly", monday, at(time.April, 1, 0, 0)},
+		{"0 0 30 2 *", monday, time.Time{}},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := c.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q after %s = %s, want %s", tt.expr, tt.from.Format(time.RFC3339), got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+		}
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@often",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"-5 * * * *",
+		"1- * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		",5 * * * *",
+		"* * * foo *",
+		"* * * * mon-funday",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want an error", expr)
+		}
+	}
+}
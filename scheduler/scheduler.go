@@ -0,0 +1,205 @@
+// Package scheduler runs the cron schedules stored in the schedules table
+// inside the server process. A due schedule only queues jobs; the work is
+// done by the job workers. Each run takes a Postgres advisory lock on the
+// schedule, so replicas sharing the database queue it once.
+package scheduler
+
+import (
+	"bcca_crawler/crawler"
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrLocked is returned when another process is running the schedule.
+var ErrLocked = errors.New("schedule is being run by another process")
+
+// maxAttempts matches the job queue's default.
+const maxAttempts = 5
+
+type Scheduler struct {
+	Tick time.Duration
+
+	c *config.Config
+}
+
+func New(c *config.Config) *Scheduler {
+	return &Scheduler{Tick: 30 * time.Second, c: c}
+}
+
+// Run checks for due schedules every Tick until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	log.Printf("scheduler started")
+	ticker := time.NewTicker(s.Tick)
+	defer ticker.Stop()
+	for {
+		s.runDue(ctx)
+		select {
+		case <-ctx.Done():
+			log.Printf("scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runDue(ctx context.Context) {
+	due, err := s.c.Db.GetDueSchedules(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("scheduler: error getting due schedules: %v", err)
+		}
+		return
+	}
+	for _, schedule := range due {
+		next, err := NextRun(schedule.Cron)
+		if err != nil {
+			// paused until the expression is fixed
+			s.recordError(ctx, schedule, err, time.Time{})
+			continue
+		}
+
+		// a new schedule starts at its next occurrence
+		if !schedule.NextRunAt.Valid {
+			if err := s.c.Db.SetScheduleNextRun(ctx, database.SetScheduleNextRunParams{NextRunAt: next, ID: schedule.ID}); err != nil {
+				log.Printf("scheduler: error setting next run of %s: %v", schedule.Name, err)
+			}
+			continue
+		}
+
+		queued, err := s.fire(ctx, schedule, next, false)
+		switch {
+		case errors.Is(err, ErrLocked):
+		case err != nil:
+			s.recordError(ctx, schedule, err, next)
+		case queued >= 0:
+			log.Printf("scheduler: %s queued %d job(s), next run at %s", schedule.Name, queued, next.Format(time.RFC3339))
+		}
+	}
+}
+
+// Trigger runs a schedule now, paused or not, without moving its next run.
+func Trigger(c *config.Config, ctx context.Context, id uuid.UUID) (int, error) {
+	schedule, err := c.Db.GetSchedule(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	s := &Scheduler{c: c}
+	return s.fire(ctx, schedule, time.Time{}, true)
+}
+
+// NextRun returns when a cron expression next fires after now.
+func NextRun(expr string) (time.Time, error) {
+	cron, err := ParseCron(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := cron.Next(time.Now().UTC())
+	if next.IsZero() {
+		return next, fmt.Errorf("cron expression %q never fires", expr)
+	}
+	return next, nil
+}
+
+// fire queues the jobs of a schedule in one transaction holding the
+// schedule's advisory lock. A scheduled run also checks the schedule is
+// still due, so a replica that read it before another one ran it queues
+// nothing and returns -1.
+func (s *Scheduler) fire(ctx context.Context, schedule database.Schedule, next time.Time, manual bool) (int, error) {
+	tx, err := s.c.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+	q := s.c.Db.WithTx(tx)
+
+	locked, err := q.TryAdvisoryXactLock(ctx, "schedule:"+schedule.ID.String())
+	if err != nil {
+		return 0, fmt.Errorf("error taking schedule lock: %v", err)
+	}
+	if !locked {
+		return 0, ErrLocked
+	}
+
+	if manual {
+		err = q.RecordScheduleRun(ctx, schedule.ID)
+	} else {
+		var n int64
+		n, err = q.ClaimScheduleRun(ctx, database.ClaimScheduleRunParams{NextRunAt: next, ID: schedule.ID})
+		if err == nil && n == 0 {
+			return -1, nil
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error recording schedule run: %v", err)
+	}
+
+	targets, kind, err := s.targets(ctx, q, schedule)
+	if err != nil {
+		return 0, err
+	}
+	for _, target := range targets {
+		_, err := q.EnqueueJob(ctx, database.EnqueueJobParams{
+			Kind:        kind,
+			Target:      target,
+			Payload:     []byte("{}"),
+			MaxAttempts: maxAttempts,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("error enqueuing %s job: %s, with error: %v", kind, target, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing schedule run: %v", err)
+	}
+	return len(targets), nil
+}
+
+// targets returns the jobs a schedule queues: one discovery from the
+// protocol index, or a crawl of its target page or of every active
+// tumor-group page.
+func (s *Scheduler) targets(ctx context.Context, q *database.Queries, schedule database.Schedule) ([]string, database.JobKindEnum, error) {
+	switch schedule.Action {
+	case database.ScheduleActionEnumDiscover:
+		root := schedule.Target
+		if root == "" {
+			root = crawler.RootURL
+		}
+		return []string{root}, database.JobKindEnumDiscover, nil
+	case database.ScheduleActionEnumCrawl:
+		if schedule.Target != "" {
+			return []string{schedule.Target}, database.JobKindEnumCrawl, nil
+		}
+		pages, err := q.GetActiveListingPages(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("error getting listing pages: %v", err)
+		}
+		if len(pages) == 0 {
+			return nil, "", fmt.Errorf("no tumor-group pages known yet, run a discovery first")
+		}
+		return pages, database.JobKindEnumCrawl, nil
+	}
+	return nil, "", fmt.Errorf("unknown schedule action: %s", schedule.Action)
+}
+
+// recordError keeps the error on the schedule and moves it to its next
+// run; a zero next pauses it until it is fixed and resumed.
+func (s *Scheduler) recordError(ctx context.Context, schedule database.Schedule, runErr error, next time.Time) {
+	log.Printf("scheduler: %s failed: %v", schedule.Name, runErr)
+	err := s.c.Db.SetScheduleError(ctx, database.SetScheduleErrorParams{
+		LastError: runErr.Error(),
+		NextRunAt: sql.NullTime{Time: next, Valid: !next.IsZero()},
+		ID:        schedule.ID,
+	})
+	if err != nil {
+		log.Printf("scheduler: error recording failure of %s: %v", schedule.Name, err)
+	}
+}
@@ -0,0 +1,320 @@
+// Package schema builds the response schemas of the LLM extractions from Go
+// types, so the schema the model is asked to fill is the one the response
+// is decoded into. A struct field is described by two tags:
+//
+//	desc:"The full name of the protocol."
+//	schema:"required,enum=tumor_group,minItems=1,maxItems=4,format=date"
+//
+// Property names come from the json tag. schema:"-" leaves a field out,
+// e.g. the ids and timestamps the database sets. enum=name takes the values
+// of a named enum of the Generator, enum=a|b|c lists them.
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// Draft is the JSON Schema version JSON declares.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+)
+
+// Schema is a generated schema, a subset of JSON Schema that Gemini also
+// accepts. Properties keeps the struct field order.
+type Schema struct {
+	Type        string
+	Description string
+	Format      string
+	Enum        []string
+	Properties  []Property
+	Required    []string
+	Items       *Schema
+	MinItems    *int64
+	MaxItems    *int64
+}
+
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Property returns the schema of a property, or nil.
+func (s *Schema) Property(name string) *Schema {
+	for _, p := range s.Properties {
+		if p.Name == name {
+			return p.Schema
+		}
+	}
+	return nil
+}
+
+// Pick returns a copy of an object schema holding only the named
+// properties, in the order given, for a response that fills part of a
+// larger type.
+func (s *Schema) Pick(names ...string) (*Schema, error) {
+	picked := *s
+	picked.Properties, picked.Required = nil, nil
+	for _, name := range names {
+		p := s.Property(name)
+		if p == nil {
+			return nil, fmt.Errorf("schema has no property %s", name)
+		}
+		picked.Properties = append(picked.Properties, Property{Name: name, Schema: p})
+	}
+	for _, name := range s.Required {
+		if picked.Property(name) != nil {
+			picked.Required = append(picked.Required, name)
+		}
+	}
+	return &picked, nil
+}
+
+// Generator turns Go types into schemas.
+type Generator struct {
+	// Enums are the value lists fields refer to with enum=name.
+	Enums map[string][]string
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generate returns the schema of v's type.
+func (g Generator) Generate(v any) (*Schema, error) {
+	return g.generate(reflect.TypeOf(v), map[reflect.Type]bool{})
+}
+
+func (g Generator) generate(t reflect.Type, seen map[reflect.Type]bool) (*Schema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: TypeString, Format: "date-time"}, nil
+	}
+	// uuids and the like
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: TypeString}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: TypeString}, nil
+	case reflect.Bool:
+		return &Schema{Type: TypeBoolean}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: TypeInteger}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: TypeNumber}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: TypeString}, nil
+		}
+		items, err := g.generate(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: TypeArray, Items: items}, nil
+	case reflect.Struct:
+		if seen[t] {
+			return nil, fmt.Errorf("%s refers to itself", t)
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		s := &Schema{Type: TypeObject}
+		if err := g.fields(s, t, seen); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// fields adds the properties of a struct to s. Embedded structs without a
+// json name are flattened, as encoding/json does.
+func (g Generator) fields(s *Schema, t reflect.Type, seen map[reflect.Type]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Tag.Get("schema") == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := g.fields(s, ft, seen); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop, err := g.generate(f.Type, seen)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+		prop.Description = f.Tag.Get("desc")
+		required, err := g.applyOptions(prop, f.Tag.Get("schema"))
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+		if s.Property(name) != nil {
+			return fmt.Errorf("%s.%s: duplicate property %q", t.Name(), f.Name, name)
+		}
+		s.Properties = append(s.Properties, Property{Name: name, Schema: prop})
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
+
+// applyOptions applies the options of a schema tag and reports whether the
+// field is required.
+func (g Generator) applyOptions(s *Schema, tag string) (bool, error) {
+	required := false
+	if tag == "" {
+		return required, nil
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch key {
+		case "required":
+			required = true
+		case "format":
+			s.Format = value
+		case "enum":
+			if s.Type != TypeString {
+				return false, fmt.Errorf("enum on a %s", s.Type)
+			}
+			if strings.Contains(value, "|") {
+				s.Enum = strings.Split(value, "|")
+				break
+			}
+			values, ok := g.Enums[value]
+			if !ok {
+				return false, fmt.Errorf("unknown enum %q", value)
+			}
+			s.Enum = append([]string(nil), values...)
+		case "minItems", "maxItems":
+			if s.Type != TypeArray {
+				return false, fmt.Errorf("%s on a %s", key, s.Type)
+			}
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || n < 0 {
+				return false, fmt.Errorf("invalid %s %q", key, value)
+			}
+			if key == "minItems" {
+				s.MinItems = &n
+			} else {
+				s.MaxItems = &n
+			}
+		default:
+			return false, fmt.Errorf("unknown schema option %q", opt)
+		}
+	}
+	return required, nil
+}
+
+// Genai returns the schema as a Gemini response schema.
+func (s *Schema) Genai() *genai.Schema {
+	out := &genai.Schema{
+		Type:        genaiTypes[s.Type],
+		Description: s.Description,
+		Format:      s.Format,
+		Enum:        s.Enum,
+		Required:    s.Required,
+		MinItems:    s.MinItems,
+		MaxItems:    s.MaxItems,
+	}
+	if len(s.Enum) > 0 {
+		out.Format = "enum"
+	}
+	if s.Items != nil {
+		out.Items = s.Items.Genai()
+	}
+	if s.Type == TypeObject {
+		out.Properties = map[string]*genai.Schema{}
+		for _, p := range s.Properties {
+			out.Properties[p.Name] = p.Schema.Genai()
+			out.PropertyOrdering = append(out.PropertyOrdering, p.Name)
+		}
+	}
+	return out
+}
+
+var genaiTypes = map[string]genai.Type{
+	TypeObject:  genai.TypeObject,
+	TypeArray:   genai.TypeArray,
+	TypeString:  genai.TypeString,
+	TypeInteger: genai.TypeInteger,
+	TypeNumber:  genai.TypeNumber,
+	TypeBoolean: genai.TypeBoolean,
+}
+
+// JSON returns the schema as a standard JSON Schema document. Objects do
+// not allow properties the type does not have.
+func (s *Schema) JSON() ([]byte, error) {
+	doc := s.jsonSchema()
+	doc["$schema"] = Draft
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func (s *Schema) jsonSchema() map[string]any {
+	out := map[string]any{"type": s.Type}
+	if s.Description != "" {
+		out["description"] = s.Description
+	}
+	if s.Format != "" {
+		out["format"] = s.Format
+	}
+	if len(s.Enum) > 0 {
+		out["enum"] = s.Enum
+	}
+	if s.Items != nil {
+		out["items"] = s.Items.jsonSchema()
+	}
+	if s.MinItems != nil {
+		out["minItems"] = *s.MinItems
+	}
+	if s.MaxItems != nil {
+		out["maxItems"] = *s.MaxItems
+	}
+	if s.Type == TypeObject {
+		props := map[string]any{}
+		for _, p := range s.Properties {
+			props[p.Name] = p.Schema.jsonSchema()
+		}
+		out["properties"] = props
+		out["additionalProperties"] = false
+		if len(s.Required) > 0 {
+			out["required"] = s.Required
+		}
+	}
+	return out
+}
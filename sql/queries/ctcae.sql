@@ -0,0 +1,64 @@
+-- name: UpsertCTCAETerm :one
+INSERT INTO ctcae_terms (meddra_code, term, soc, definition, navigational_note, version)
+VALUES (@meddra_code, @term, @soc, @definition, @navigational_note, @version)
+ON CONFLICT (meddra_code) DO UPDATE SET
+    term = EXCLUDED.term,
+    soc = EXCLUDED.soc,
+    definition = EXCLUDED.definition,
+    navigational_note = EXCLUDED.navigational_note,
+    version = EXCLUDED.version,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteCTCAEGrades :exec
+DELETE FROM ctcae_grades
+WHERE term_id = @term_id;
+
+-- name: AddCTCAEGrades :exec
+-- a grade the term does not have ("-" in the spreadsheet) is left out
+INSERT INTO ctcae_grades (term_id, grade, description)
+SELECT @term_id, unnest(@grades::smallint[]), unnest(@descriptions::text[]);
+
+-- name: FindCTCAETermsByNames :many
+SELECT * FROM ctcae_terms
+WHERE lower(term) = ANY(@names::text[])
+ORDER BY term;
+
+-- name: GetCTCAEGrades :many
+SELECT * FROM ctcae_grades
+WHERE term_id = @term_id
+ORDER BY grade;
+
+-- name: LinkToxicityToCTCAETerm :exec
+UPDATE toxicities
+SET ctcae_term_id = @ctcae_term_id,
+    updated_at = NOW()
+WHERE id = @id AND ctcae_term_id IS NULL;
+
+-- name: LinkToxicitiesToCTCAETerms :execrows
+-- links the toxicities already titled with a term, once the list is imported
+UPDATE toxicities t
+SET ctcae_term_id = ct.id,
+    updated_at = NOW()
+FROM ctcae_terms ct
+WHERE lower(t.title) = lower(ct.term) AND t.ctcae_term_id IS NULL;
+
+-- name: SetToxicityGradeDescription :one
+-- the dictionary wording of a grade, replacing what the model wrote
+INSERT INTO toxicity_grades (grade, description, toxicity_id)
+VALUES (@grade, @description, @toxicity_id)
+ON CONFLICT (grade, toxicity_id) DO UPDATE SET
+    description = EXCLUDED.description,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: SyncToxicityGradeDescriptions :execrows
+-- rewrites the grades of the linked toxicities with the dictionary wording
+UPDATE toxicity_grades tg
+SET description = cg.description,
+    updated_at = NOW()
+FROM toxicities t
+JOIN ctcae_grades cg ON cg.term_id = t.ctcae_term_id
+WHERE tg.toxicity_id = t.id
+  AND tg.grade::text = cg.grade::text
+  AND tg.description <> cg.description;
@@ -0,0 +1,70 @@
+-- name: UpsertDoseEquivalenceClass :one
+WITH input_values(id, name, reference_drug, max_cumulative_dose, dose_basis, warning_threshold, notes) AS (
+  VALUES (
+    CASE
+      WHEN @id::uuid = '00000000-0000-0000-0000-000000000000'
+      THEN gen_random_uuid()
+      ELSE @id::uuid
+    END,
+    @name::text,
+    @reference_drug::text,
+    @max_cumulative_dose::float,
+    @dose_basis::dose_basis_enum,
+    @warning_threshold::float,
+    @notes::text
+  )
+)
+INSERT INTO dose_equivalence_classes (id, name, reference_drug, max_cumulative_dose, dose_basis, warning_threshold, notes)
+SELECT id, name, reference_drug, max_cumulative_dose, dose_basis, warning_threshold, notes FROM input_values
+ON CONFLICT (id) DO UPDATE
+SET name = EXCLUDED.name,
+    reference_drug = EXCLUDED.reference_drug,
+    max_cumulative_dose = EXCLUDED.max_cumulative_dose,
+    dose_basis = EXCLUDED.dose_basis,
+    warning_threshold = EXCLUDED.warning_threshold,
+    notes = EXCLUDED.notes,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetDoseEquivalenceClasses :many
+SELECT * FROM dose_equivalence_classes
+ORDER BY name ASC;
+
+-- name: GetDoseEquivalenceClassByID :one
+SELECT * FROM dose_equivalence_classes
+WHERE id = $1;
+
+-- name: RemoveDoseEquivalenceClass :exec
+DELETE FROM dose_equivalence_classes
+WHERE id = $1;
+
+-- name: UpsertMedicationDoseLimit :one
+INSERT INTO medication_dose_limits (medication_id, max_cumulative_dose, dose_basis, warning_threshold, equivalence_class_id, equivalence_factor, notes)
+VALUES (@medication_id::uuid, @max_cumulative_dose::float, @dose_basis::dose_basis_enum, @warning_threshold::float, sqlc.narg('equivalence_class_id')::uuid, @equivalence_factor::float, @notes::text)
+ON CONFLICT (medication_id) DO UPDATE
+SET max_cumulative_dose = EXCLUDED.max_cumulative_dose,
+    dose_basis = EXCLUDED.dose_basis,
+    warning_threshold = EXCLUDED.warning_threshold,
+    equivalence_class_id = EXCLUDED.equivalence_class_id,
+    equivalence_factor = EXCLUDED.equivalence_factor,
+    notes = EXCLUDED.notes,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetMedicationDoseLimits :many
+SELECT l.id, l.created_at, l.updated_at, l.medication_id, m.name AS medication_name, m.alternate_names AS medication_alternate_names,
+  l.max_cumulative_dose, l.dose_basis, l.warning_threshold, l.equivalence_class_id, l.equivalence_factor, l.notes
+FROM medication_dose_limits l
+JOIN medications m ON m.id = l.medication_id
+ORDER BY m.name ASC;
+
+-- name: GetMedicationDoseLimitByID :one
+SELECT l.id, l.created_at, l.updated_at, l.medication_id, m.name AS medication_name, m.alternate_names AS medication_alternate_names,
+  l.max_cumulative_dose, l.dose_basis, l.warning_threshold, l.equivalence_class_id, l.equivalence_factor, l.notes
+FROM medication_dose_limits l
+JOIN medications m ON m.id = l.medication_id
+WHERE l.id = $1;
+
+-- name: RemoveMedicationDoseLimit :exec
+DELETE FROM medication_dose_limits
+WHERE id = $1;
@@ -0,0 +1,35 @@
+-- name: UpsertEligibilityRule :one
+INSERT INTO eligibility_rules (criteria_id, expression, author)
+VALUES (@criteria_id::uuid, @expression::text, @author::rule_author_enum)
+ON CONFLICT (criteria_id) DO UPDATE
+SET expression = EXCLUDED.expression,
+    author = EXCLUDED.author,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetEligibilityRule :one
+SELECT * FROM eligibility_rules
+WHERE criteria_id = $1;
+
+-- name: GetEligibilityRules :many
+SELECT r.*, c.type, c.description
+FROM eligibility_rules r
+JOIN protocol_eligibility_criteria c ON c.id = r.criteria_id
+ORDER BY c.type ASC, c.description ASC;
+
+-- name: RemoveEligibilityRule :exec
+DELETE FROM eligibility_rules
+WHERE criteria_id = $1;
+
+-- name: GetEligibilityScreeningCriteria :many
+SELECT p.id AS protocol_id, p.code, p.name, p.tumor_group,
+  pec.id AS criteria_id,
+  COALESCE(pec.type::text, '')::text AS type,
+  COALESCE(pec.description, '')::text AS description,
+  COALESCE(r.expression, '')::text AS expression
+FROM protocols p
+LEFT JOIN protocol_eligibility_criteria_values v ON v.protocol_id = p.id
+LEFT JOIN protocol_eligibility_criteria pec ON pec.id = v.criteria_id
+LEFT JOIN eligibility_rules r ON r.criteria_id = pec.id
+WHERE (@tumor_group::text = '' OR lower(p.tumor_group) = lower(@tumor_group::text))
+ORDER BY p.code ASC, pec.type ASC, pec.description ASC;
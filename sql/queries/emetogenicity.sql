@@ -0,0 +1,19 @@
+-- name: UpdateMedicationEmetogenicLevel :exec
+UPDATE medications
+SET emetogenic_level = @emetogenic_level::emetogenic_level_enum,
+    updated_at = NOW()
+WHERE id = @id::uuid;
+
+-- name: GetMedicationEmetogenicLevels :many
+SELECT id, name, alternate_names, emetogenic_level FROM medications
+WHERE id = ANY(@ids::uuid[])
+ORDER BY name ASC;
+
+-- name: GetUnclassifiedMedications :many
+SELECT id, name, alternate_names, emetogenic_level FROM medications
+WHERE emetogenic_level = 'unknown'
+ORDER BY name ASC;
+
+-- name: GetProtocolCodes :many
+SELECT id, code, name FROM protocols
+ORDER BY code ASC;
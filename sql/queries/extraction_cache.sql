@@ -0,0 +1,12 @@
+-- name: GetCachedResponse :one
+SELECT * FROM extraction_cache
+WHERE document_hash = @document_hash::text
+  AND prompt_version_id = @prompt_version_id::uuid
+  AND model = @model::text
+  AND schema_hash = @schema_hash::text;
+
+-- name: SaveCachedResponse :exec
+INSERT INTO extraction_cache (document_hash, prompt_version_id, model, schema_hash, purpose, raw_response, payload)
+VALUES (@document_hash::text, @prompt_version_id::uuid, @model::text, @schema_hash::text, @purpose::text, @raw_response::text, @payload::jsonb)
+ON CONFLICT (document_hash, prompt_version_id, model, schema_hash)
+DO UPDATE SET created_at = NOW(), purpose = EXCLUDED.purpose, raw_response = EXCLUDED.raw_response, payload = EXCLUDED.payload;
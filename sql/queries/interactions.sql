@@ -0,0 +1,43 @@
+-- name: UpsertInteraction :one
+INSERT INTO interactions (drug_a, drug_b, severity, description, management, source)
+VALUES (@drug_a::text, @drug_b::text, @severity::interaction_severity_enum, @description::text, @management::text, @source::text)
+ON CONFLICT (drug_a, drug_b) DO UPDATE
+SET severity = EXCLUDED.severity,
+    description = EXCLUDED.description,
+    management = EXCLUDED.management,
+    source = EXCLUDED.source,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetInteractions :many
+SELECT * FROM interactions
+WHERE (@drug::text = '' OR drug_a = @drug::text OR drug_b = @drug::text)
+ORDER BY drug_a ASC, drug_b ASC;
+
+-- name: GetInteractionByID :one
+SELECT * FROM interactions
+WHERE id = $1;
+
+-- name: GetInteractionsForNames :many
+SELECT * FROM interactions
+WHERE drug_a = ANY(@names::text[]) AND drug_b = ANY(@names::text[]);
+
+-- name: RemoveInteraction :exec
+DELETE FROM interactions
+WHERE id = $1;
+
+-- name: GetProtocolMedicationNames :many
+SELECT m.id, m.name, m.alternate_names, 'treatment'::text AS source
+FROM protocol_cycles pc
+JOIN treatment_cycles_values tcv ON tcv.protocol_cycles_id = pc.id
+JOIN protocol_treatment pt ON pt.id = tcv.protocol_treatment_id
+JOIN medications m ON m.id = pt.medication_id
+WHERE pc.protocol_id = $1
+UNION
+SELECT m.id, m.name, m.alternate_names, pm.category AS source
+FROM protocol_meds pm
+JOIN protocol_meds_values pmv ON pmv.protocol_meds_id = pm.id
+JOIN medication_prescription mp ON mp.id = pmv.medication_prescription_id
+JOIN medications m ON m.id = mp.medication_id
+WHERE pm.protocol_id = $1
+ORDER BY source DESC, name ASC;
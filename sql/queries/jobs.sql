@@ -0,0 +1,102 @@
+-- name: EnqueueJob :one
+INSERT INTO jobs (kind, target, payload, max_attempts)
+VALUES (@kind, @target::text, @payload::jsonb, @max_attempts::int)
+ON CONFLICT (kind, target) WHERE status IN ('pending', 'running')
+DO UPDATE SET updated_at = NOW()
+RETURNING *;
+
+-- name: ClaimJob :one
+UPDATE jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    started_at = NOW(),
+    locked_by = @worker::text,
+    lease_expires_at = NOW() + make_interval(secs => @lease_seconds::int),
+    updated_at = NOW()
+WHERE id = (
+  SELECT j.id FROM jobs j
+  WHERE (j.status = 'pending' AND j.run_at <= NOW())
+     OR (j.status = 'running' AND j.lease_expires_at < NOW())
+  ORDER BY j.run_at ASC, j.created_at ASC
+  FOR UPDATE SKIP LOCKED
+  LIMIT 1
+)
+RETURNING *;
+
+-- name: ExtendJobLease :execrows
+UPDATE jobs
+SET lease_expires_at = NOW() + make_interval(secs => @lease_seconds::int),
+    updated_at = NOW()
+WHERE id = @id AND status = 'running' AND locked_by = @worker::text;
+
+-- name: CompleteJob :exec
+UPDATE jobs
+SET status = 'succeeded',
+    last_error = '',
+    finished_at = NOW(),
+    lease_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = @id AND status = 'running' AND locked_by = @worker::text;
+
+-- name: FailJob :one
+UPDATE jobs
+SET status = CASE WHEN @permanent::boolean OR attempts >= max_attempts THEN 'dead'::job_status_enum ELSE 'pending'::job_status_enum END,
+    last_error = @last_error::text,
+    attempt_errors = attempt_errors || jsonb_build_array(@attempt_error::jsonb),
+    run_at = NOW() + make_interval(secs => @retry_in_seconds::int),
+    finished_at = CASE WHEN @permanent::boolean OR attempts >= max_attempts THEN NOW() ELSE NULL END,
+    lease_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = @id AND status = 'running' AND locked_by = @worker::text
+RETURNING *;
+
+-- name: ReleaseJob :exec
+UPDATE jobs
+SET status = 'pending',
+    attempts = GREATEST(attempts - 1, 0),
+    lease_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = @id AND status = 'running' AND locked_by = @worker::text;
+
+-- name: DeferJob :exec
+UPDATE jobs
+SET status = 'pending',
+    attempts = GREATEST(attempts - 1, 0),
+    last_error = @last_error::text,
+    run_at = @run_at::timestamptz,
+    lease_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = @id AND status = 'running' AND locked_by = @worker::text;
+
+-- name: GetJob :one
+SELECT * FROM jobs WHERE id = @id;
+
+-- name: ListJobs :many
+SELECT * FROM jobs
+WHERE @status::text = 'all' OR status::text = @status::text
+ORDER BY created_at DESC
+LIMIT @row_limit::int;
+
+-- name: RetryJob :one
+UPDATE jobs
+SET status = 'pending',
+    attempts = 0,
+    run_at = NOW(),
+    finished_at = NULL,
+    updated_at = NOW()
+WHERE id = @id AND status IN ('dead', 'cancelled')
+RETURNING *;
+
+-- name: CancelJob :one
+UPDATE jobs
+SET status = 'cancelled',
+    finished_at = NOW(),
+    lease_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = @id AND status IN ('pending', 'running')
+RETURNING *;
+
+-- name: CountJobsByStatus :many
+SELECT status, COUNT(*) AS count FROM jobs
+GROUP BY status
+ORDER BY status;
@@ -0,0 +1,25 @@
+-- name: CreateLLMCall :exec
+INSERT INTO llm_calls (model, purpose, document_hash, attempt, outcome, error, prompt_tokens, response_tokens, total_tokens, latency_ms, cost_usd)
+VALUES (@model::text, @purpose::text, @document_hash::text, @attempt::int, @outcome, @error::text, @prompt_tokens::int, @response_tokens::int, @total_tokens::int, @latency_ms::int, @cost_usd::float8);
+
+-- name: GetLLMUsage :many
+SELECT date_trunc(@period::text, created_at AT TIME ZONE 'UTC')::timestamp AS period,
+  model,
+  COUNT(*)::int AS calls,
+  COUNT(*) FILTER (WHERE outcome <> 'success')::int AS failed,
+  COUNT(DISTINCT NULLIF(document_hash, ''))::int AS documents,
+  COALESCE(SUM(prompt_tokens), 0)::bigint AS prompt_tokens,
+  COALESCE(SUM(response_tokens), 0)::bigint AS response_tokens,
+  COALESCE(SUM(total_tokens), 0)::bigint AS total_tokens,
+  COALESCE(SUM(cost_usd), 0)::float8 AS cost_usd,
+  COALESCE(AVG(latency_ms), 0)::float8 AS avg_latency_ms
+FROM llm_calls
+WHERE created_at >= @since::timestamptz
+GROUP BY 1, 2
+ORDER BY 1 DESC, 2;
+
+-- name: GetLLMSpend :one
+SELECT COALESCE(SUM(cost_usd), 0)::float8 AS spent,
+  COUNT(DISTINCT NULLIF(document_hash, ''))::int AS documents
+FROM llm_calls
+WHERE created_at >= @since::timestamptz;
@@ -0,0 +1,129 @@
+-- name: FindMedicationsByNames :many
+SELECT * FROM medications m
+WHERE lower(regexp_replace(btrim(m.name), '\s+', ' ', 'g')) = ANY(@names::text[])
+   OR EXISTS (
+     SELECT 1 FROM unnest(m.alternate_names) AS alt(name)
+     WHERE lower(regexp_replace(btrim(alt.name), '\s+', ' ', 'g')) = ANY(@names::text[])
+   )
+ORDER BY m.created_at;
+
+-- name: AddMedicationAlternateName :exec
+UPDATE medications
+SET alternate_names = array_append(alternate_names, @name::text),
+    updated_at = NOW()
+WHERE id = @id AND NOT (@name::text = ANY(alternate_names));
+
+-- name: MergeMedicationDetails :exec
+UPDATE medications
+SET alternate_names = @alternate_names::text[],
+    description = CASE WHEN description = '' THEN @description::text ELSE description END,
+    category = CASE WHEN category = '' THEN @category::text ELSE category END,
+    emetogenic_level = CASE WHEN emetogenic_level = 'unknown' THEN @emetogenic_level::emetogenic_level_enum ELSE emetogenic_level END,
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: MergeMedicationPrescriptions :one
+-- a prescription the survivor already has is replaced by the survivor's in
+-- every protocol it is in, along with the page and quote recorded for it;
+-- the others move to the survivor
+WITH pairs AS (
+  SELECT dup.id AS duplicate_id, keep.id AS survivor_id
+  FROM medication_prescription dup
+  JOIN medication_prescription keep
+    ON keep.medication_id = @survivor_id::uuid
+   AND keep.dose = dup.dose AND keep.route = dup.route AND keep.frequency = dup.frequency
+   AND keep.duration = dup.duration AND keep.instructions = dup.instructions
+  WHERE dup.medication_id = @duplicate_id::uuid
+), relinked AS (
+  INSERT INTO protocol_meds_values (protocol_meds_id, medication_prescription_id)
+  SELECT v.protocol_meds_id, pairs.survivor_id
+  FROM protocol_meds_values v
+  JOIN pairs ON pairs.duplicate_id = v.medication_prescription_id
+  ON CONFLICT DO NOTHING
+  RETURNING 1
+), reattributed AS (
+  INSERT INTO provenance (protocol_id, entity_type, entity_id, page, quote, extraction_id)
+  SELECT p.protocol_id, p.entity_type, pairs.survivor_id, p.page, p.quote, p.extraction_id
+  FROM provenance p
+  JOIN pairs ON pairs.duplicate_id = p.entity_id
+  WHERE p.entity_type = 'prescription'
+  ON CONFLICT DO NOTHING
+  RETURNING 1
+), unattributed AS (
+  DELETE FROM provenance
+  WHERE entity_type = 'prescription' AND entity_id IN (SELECT duplicate_id FROM pairs)
+  RETURNING 1
+), dropped AS (
+  DELETE FROM medication_prescription
+  WHERE id IN (SELECT duplicate_id FROM pairs)
+  RETURNING id
+), moved AS (
+  UPDATE medication_prescription
+  SET medication_id = @survivor_id::uuid, updated_at = NOW()
+  WHERE medication_id = @duplicate_id::uuid AND id NOT IN (SELECT duplicate_id FROM pairs)
+  RETURNING id
+)
+SELECT (SELECT COUNT(*) FROM dropped) AS merged, (SELECT COUNT(*) FROM moved) AS moved;
+
+-- name: MergeMedicationTreatments :one
+-- as MergeMedicationPrescriptions, for the treatments of protocol cycles
+WITH pairs AS (
+  SELECT dup.id AS duplicate_id, keep.id AS survivor_id
+  FROM protocol_treatment dup
+  JOIN protocol_treatment keep
+    ON keep.medication_id = @survivor_id::uuid
+   AND keep.dose = dup.dose AND keep.route = dup.route AND keep.frequency = dup.frequency
+   AND keep.duration = dup.duration
+  WHERE dup.medication_id = @duplicate_id::uuid
+), relinked AS (
+  INSERT INTO treatment_cycles_values (protocol_treatment_id, protocol_cycles_id)
+  SELECT pairs.survivor_id, v.protocol_cycles_id
+  FROM treatment_cycles_values v
+  JOIN pairs ON pairs.duplicate_id = v.protocol_treatment_id
+  ON CONFLICT DO NOTHING
+  RETURNING 1
+), reattributed AS (
+  INSERT INTO provenance (protocol_id, entity_type, entity_id, page, quote, extraction_id)
+  SELECT p.protocol_id, p.entity_type, pairs.survivor_id, p.page, p.quote, p.extraction_id
+  FROM provenance p
+  JOIN pairs ON pairs.duplicate_id = p.entity_id
+  WHERE p.entity_type = 'treatment'
+  ON CONFLICT DO NOTHING
+  RETURNING 1
+), unattributed AS (
+  DELETE FROM provenance
+  WHERE entity_type = 'treatment' AND entity_id IN (SELECT duplicate_id FROM pairs)
+  RETURNING 1
+), dropped AS (
+  DELETE FROM protocol_treatment
+  WHERE id IN (SELECT duplicate_id FROM pairs)
+  RETURNING id
+), moved AS (
+  UPDATE protocol_treatment
+  SET medication_id = @survivor_id::uuid, updated_at = NOW()
+  WHERE medication_id = @duplicate_id::uuid AND id NOT IN (SELECT duplicate_id FROM pairs)
+  RETURNING id
+)
+SELECT (SELECT COUNT(*) FROM dropped) AS merged, (SELECT COUNT(*) FROM moved) AS moved;
+
+-- name: MergeMedicationModifications :one
+WITH dropped AS (
+  DELETE FROM medication_modifications dup
+  USING medication_modifications keep
+  WHERE dup.medication_id = @duplicate_id::uuid AND keep.medication_id = @survivor_id::uuid
+    AND keep.category = dup.category AND keep.subcategory = dup.subcategory AND keep.adjustment = dup.adjustment
+  RETURNING dup.id
+), moved AS (
+  UPDATE medication_modifications
+  SET medication_id = @survivor_id::uuid, updated_at = NOW()
+  WHERE medication_id = @duplicate_id::uuid AND id NOT IN (SELECT id FROM dropped)
+  RETURNING id
+)
+SELECT (SELECT COUNT(*) FROM dropped) AS merged, (SELECT COUNT(*) FROM moved) AS moved;
+
+-- name: MergeMedicationDoseLimits :execrows
+-- the survivor keeps its own limit; the duplicate's goes with it
+UPDATE medication_dose_limits
+SET medication_id = @survivor_id::uuid, updated_at = NOW()
+WHERE medication_id = @duplicate_id::uuid
+  AND NOT EXISTS (SELECT 1 FROM medication_dose_limits WHERE medication_id = @survivor_id::uuid);
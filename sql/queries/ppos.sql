@@ -0,0 +1,27 @@
+-- name: UpsertRenderedPPO :exec
+INSERT INTO protocol_ppos (protocol_id, format, rendered, title, url, content, content_hash, protocol_revised_on)
+VALUES (@protocol_id::uuid, @format::ppo_format_enum, true, @title::text, @url::text, @content::bytea, @content_hash::text, @protocol_revised_on::text)
+ON CONFLICT (protocol_id, format) WHERE rendered DO UPDATE
+SET title = EXCLUDED.title,
+    url = EXCLUDED.url,
+    content = EXCLUDED.content,
+    content_hash = EXCLUDED.content_hash,
+    protocol_revised_on = EXCLUDED.protocol_revised_on,
+    updated_at = NOW()
+WHERE protocol_ppos.content_hash <> EXCLUDED.content_hash
+   OR protocol_ppos.protocol_revised_on <> EXCLUDED.protocol_revised_on;
+
+-- name: GetRenderedPPO :one
+SELECT * FROM protocol_ppos
+WHERE protocol_id = @protocol_id::uuid AND format = @format::ppo_format_enum AND rendered;
+
+-- name: GetRenderedPPOByHash :one
+SELECT * FROM protocol_ppos
+WHERE protocol_id = @protocol_id::uuid AND content_hash = @content_hash::text AND rendered;
+
+-- name: UpsertLinkedPPO :exec
+INSERT INTO protocol_ppos (protocol_id, rendered, title, url)
+VALUES (@protocol_id::uuid, false, @title::text, @url::text)
+ON CONFLICT (protocol_id, url) WHERE NOT rendered DO UPDATE
+SET title = EXCLUDED.title,
+    updated_at = NOW();
@@ -0,0 +1,66 @@
+-- name: GetPrompts :many
+SELECT * FROM prompts
+ORDER BY name;
+
+-- name: GetPrompt :one
+SELECT * FROM prompts
+WHERE id = $1;
+
+-- name: GetPromptByName :one
+SELECT * FROM prompts
+WHERE name = $1;
+
+-- name: GetPromptVersions :many
+SELECT * FROM prompt_versions
+WHERE prompt_id = $1
+ORDER BY version DESC;
+
+-- name: GetActivePromptVersion :one
+SELECT pv.* FROM prompt_versions pv
+JOIN prompts p ON p.id = pv.prompt_id AND p.active_version = pv.version
+WHERE p.name = $1;
+
+-- name: GetPromptVersionByID :one
+SELECT * FROM prompt_versions
+WHERE id = $1;
+
+-- name: GetPromptVersion :one
+SELECT pv.* FROM prompt_versions pv
+JOIN prompts p ON p.id = pv.prompt_id
+WHERE p.name = @name::text AND pv.version = @version::integer;
+
+-- name: CreatePromptVersion :one
+INSERT INTO prompt_versions (prompt_id, version, template, notes)
+SELECT @prompt_id::uuid, COALESCE(MAX(version), 0) + 1, @template::text, @notes::text
+FROM prompt_versions
+WHERE prompt_id = @prompt_id::uuid
+RETURNING *;
+
+-- name: SetActivePromptVersion :execrows
+UPDATE prompts
+SET active_version = @version::integer, updated_at = NOW()
+WHERE id = @id::uuid
+  AND EXISTS (SELECT 1 FROM prompt_versions WHERE prompt_id = @id::uuid AND version = @version::integer);
+
+-- name: CreateExtraction :one
+INSERT INTO extractions (protocol_id, document_hash, prompt_version_id, model, mode, section_prompt_version_id, conflicts)
+VALUES (@protocol_id::uuid, @document_hash::text, @prompt_version_id::uuid, @model::text, @mode, sqlc.narg('section_prompt_version_id')::uuid, @conflicts::jsonb)
+RETURNING *;
+
+-- name: GetProtocolExtractions :many
+SELECT e.id, e.created_at, e.protocol_id, e.document_hash, e.model, e.mode, e.conflicts, e.prompt_version_id, p.name AS prompt_name, pv.version AS prompt_version,
+  sp.name AS section_prompt_name, spv.version AS section_prompt_version
+FROM extractions e
+JOIN prompt_versions pv ON pv.id = e.prompt_version_id
+JOIN prompts p ON p.id = pv.prompt_id
+LEFT JOIN prompt_versions spv ON spv.id = e.section_prompt_version_id
+LEFT JOIN prompts sp ON sp.id = spv.prompt_id
+WHERE e.protocol_id = $1
+ORDER BY e.created_at DESC;
+
+-- name: GetLatestExtractions :many
+SELECT DISTINCT ON (e.protocol_id) sqlc.embed(e), p.code
+FROM extractions e
+JOIN protocols p ON p.id = e.protocol_id
+WHERE cardinality(@codes::text[]) = 0 OR p.code = ANY(@codes::text[])
+ORDER BY e.protocol_id, e.created_at DESC;
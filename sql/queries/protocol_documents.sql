@@ -0,0 +1,30 @@
+-- name: UpsertProtocolDocument :one
+INSERT INTO protocol_documents (protocol_id, kind, title, url, document_hash, archive_path, content_type, size_bytes, fetched_at)
+VALUES (@protocol_id::uuid, @kind::document_kind_enum, @title::text, @url::text, sqlc.narg('document_hash')::text, @archive_path::text, @content_type::text, @size_bytes::bigint, sqlc.narg('fetched_at')::timestamptz)
+ON CONFLICT (protocol_id, url) DO UPDATE
+SET kind = EXCLUDED.kind,
+    title = EXCLUDED.title,
+    -- a failed download keeps the previously stored or archived copy
+    document_hash = COALESCE(EXCLUDED.document_hash, protocol_documents.document_hash),
+    archive_path = CASE WHEN EXCLUDED.archive_path = '' THEN protocol_documents.archive_path ELSE EXCLUDED.archive_path END,
+    content_type = CASE WHEN EXCLUDED.document_hash IS NULL AND EXCLUDED.archive_path = '' THEN protocol_documents.content_type ELSE EXCLUDED.content_type END,
+    size_bytes = CASE WHEN EXCLUDED.document_hash IS NULL AND EXCLUDED.archive_path = '' THEN protocol_documents.size_bytes ELSE EXCLUDED.size_bytes END,
+    fetched_at = COALESCE(EXCLUDED.fetched_at, protocol_documents.fetched_at),
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetProtocolDocuments :many
+SELECT * FROM protocol_documents
+WHERE protocol_id = $1
+ORDER BY kind ASC, title ASC;
+
+-- name: GetProtocolDocument :one
+SELECT * FROM protocol_documents
+WHERE id = @id::uuid AND protocol_id = @protocol_id::uuid;
+
+-- name: UpdateProtocolSourceLinks :exec
+UPDATE protocols
+SET protocol_url = CASE WHEN @protocol_url::text = '' THEN protocol_url ELSE @protocol_url::text END,
+    patient_handout_url = CASE WHEN @patient_handout_url::text = '' THEN patient_handout_url ELSE @patient_handout_url::text END,
+    updated_at = NOW()
+WHERE id = @id::uuid;
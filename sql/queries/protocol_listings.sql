@@ -0,0 +1,32 @@
+-- name: UpsertProtocolListing :one
+INSERT INTO protocol_listings (code, protocol_id, tumor_group, description, page_url)
+VALUES (@code::text, (SELECT id FROM protocols WHERE protocols.code = @code::text), @tumor_group::text, @description::text, @page_url::text)
+ON CONFLICT (code) DO UPDATE
+SET protocol_id = EXCLUDED.protocol_id,
+    tumor_group = EXCLUDED.tumor_group,
+    description = EXCLUDED.description,
+    page_url = EXCLUDED.page_url,
+    last_seen_at = NOW(),
+    retired_at = NULL,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: RetireMissingListings :many
+UPDATE protocol_listings
+SET retired_at = NOW(),
+    updated_at = NOW()
+WHERE retired_at IS NULL AND NOT (code = ANY(@seen_codes::text[]))
+RETURNING *;
+
+-- name: GetProtocolListings :many
+SELECT * FROM protocol_listings
+WHERE (@status::text = 'all')
+   OR (@status::text = 'retired' AND retired_at IS NOT NULL)
+   OR (@status::text = 'active' AND retired_at IS NULL)
+ORDER BY tumor_group ASC, code ASC;
+
+-- name: UpdateProtocolTumorGroup :exec
+UPDATE protocols
+SET tumor_group = @tumor_group::text,
+    updated_at = NOW()
+WHERE code = @code::text AND tumor_group <> @tumor_group::text;
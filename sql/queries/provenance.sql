@@ -0,0 +1,17 @@
+-- name: UpsertProvenance :exec
+INSERT INTO provenance (protocol_id, entity_type, entity_id, page, quote, extraction_id)
+VALUES (@protocol_id, @entity_type, @entity_id, sqlc.narg('page'), @quote, @extraction_id)
+ON CONFLICT (protocol_id, entity_type, entity_id) DO UPDATE SET
+    page = EXCLUDED.page,
+    quote = EXCLUDED.quote,
+    extraction_id = EXCLUDED.extraction_id,
+    updated_at = NOW();
+
+-- name: GetProtocolProvenance :many
+SELECT * FROM provenance
+WHERE protocol_id = @protocol_id
+ORDER BY entity_type, page NULLS LAST, created_at;
+
+-- name: DeleteProtocolProvenance :exec
+DELETE FROM provenance
+WHERE protocol_id = @protocol_id;
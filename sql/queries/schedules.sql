@@ -0,0 +1,61 @@
+-- name: GetSchedules :many
+SELECT * FROM schedules
+ORDER BY name ASC;
+
+-- name: GetSchedule :one
+SELECT * FROM schedules WHERE id = @id;
+
+-- name: GetDueSchedules :many
+SELECT * FROM schedules
+WHERE NOT paused AND (next_run_at IS NULL OR next_run_at <= NOW())
+ORDER BY next_run_at ASC NULLS FIRST;
+
+-- name: CreateSchedule :one
+INSERT INTO schedules (name, cron, action, target, next_run_at)
+VALUES (@name::text, @cron::text, @action, @target::text, @next_run_at::timestamptz)
+RETURNING *;
+
+-- name: SetScheduleNextRun :exec
+UPDATE schedules
+SET next_run_at = @next_run_at::timestamptz,
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: ClaimScheduleRun :execrows
+UPDATE schedules
+SET last_run_at = NOW(),
+    next_run_at = @next_run_at::timestamptz,
+    last_error = '',
+    updated_at = NOW()
+WHERE id = @id AND NOT paused AND next_run_at <= NOW();
+
+-- name: RecordScheduleRun :exec
+UPDATE schedules
+SET last_run_at = NOW(),
+    last_error = '',
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: SetScheduleError :exec
+UPDATE schedules
+SET last_error = @last_error::text,
+    next_run_at = sqlc.narg('next_run_at'),
+    paused = paused OR sqlc.narg('next_run_at') IS NULL,
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: SetSchedulePaused :one
+UPDATE schedules
+SET paused = @paused::boolean,
+    next_run_at = sqlc.narg('next_run_at'),
+    updated_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: TryAdvisoryXactLock :one
+SELECT pg_try_advisory_xact_lock(hashtextextended(@lock_key::text, 0))::boolean AS locked;
+
+-- name: GetActiveListingPages :many
+SELECT DISTINCT page_url FROM protocol_listings
+WHERE retired_at IS NULL AND page_url <> ''
+ORDER BY page_url ASC;
@@ -0,0 +1,32 @@
+-- name: UpsertSourceDocument :one
+INSERT INTO source_documents (hash, url, content_type, size_bytes, fetched_at, headers)
+VALUES (@hash::text, @url::text, @content_type::text, @size_bytes::bigint, @fetched_at::timestamptz, @headers::jsonb)
+ON CONFLICT (hash) DO UPDATE
+SET url = EXCLUDED.url,
+    fetched_at = EXCLUDED.fetched_at,
+    headers = EXCLUDED.headers,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetSourceDocument :one
+SELECT * FROM source_documents
+WHERE hash = $1;
+
+-- name: AddProtocolSource :exec
+INSERT INTO protocol_sources (protocol_id, document_hash, revised_on)
+VALUES (@protocol_id::uuid, @document_hash::text, @revised_on::text)
+ON CONFLICT (protocol_id, document_hash) DO UPDATE
+SET revised_on = EXCLUDED.revised_on;
+
+-- name: GetProtocolSources :many
+SELECT ps.id, ps.created_at, ps.protocol_id, ps.document_hash, ps.revised_on, sd.url, sd.content_type, sd.size_bytes, sd.fetched_at
+FROM protocol_sources ps
+JOIN source_documents sd ON sd.hash = ps.document_hash
+WHERE ps.protocol_id = $1
+ORDER BY ps.created_at DESC;
+
+-- name: IsDocumentExtracted :one
+SELECT EXISTS (
+  SELECT 1 FROM protocol_sources
+  WHERE document_hash = $1
+);
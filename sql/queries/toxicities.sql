@@ -1,6 +1,6 @@
 -- name: AddToxicity :one
-INSERT INTO toxicities (title, category, description)
-VALUES ($1, $2, $3)
+INSERT INTO toxicities (title, category, description, ctcae_term_id)
+VALUES ($1, $2, $3, $4)
 RETURNING *;
 
 -- name: GetToxicityByID :one
@@ -0,0 +1,52 @@
+-- name: CreateTreatmentPlan :one
+INSERT INTO treatment_plans (patient_ref, protocol_id, protocol_code, protocol_revised_on, start_date, planned_cycles, height_cm, weight_kg, bsa, baseline, notes)
+SELECT @patient_ref::text, p.id, p.code, p.revised_on, @start_date::date, @planned_cycles::int, @height_cm::float, @weight_kg::float, @bsa::float, @baseline::jsonb, @notes::text
+FROM protocols p
+WHERE p.id = @protocol_id::uuid
+RETURNING *;
+
+-- name: UpdateTreatmentPlan :one
+UPDATE treatment_plans
+SET status = @status::plan_status_enum,
+    planned_cycles = @planned_cycles::int,
+    height_cm = @height_cm::float,
+    weight_kg = @weight_kg::float,
+    bsa = @bsa::float,
+    baseline = @baseline::jsonb,
+    notes = @notes::text,
+    updated_at = NOW()
+WHERE id = @id::uuid
+RETURNING *;
+
+-- name: GetTreatmentPlans :many
+SELECT * FROM treatment_plans
+WHERE (@patient_ref::text = '' OR patient_ref = @patient_ref::text)
+ORDER BY created_at DESC;
+
+-- name: GetTreatmentPlanByID :one
+SELECT * FROM treatment_plans
+WHERE id = $1;
+
+-- name: RemoveTreatmentPlan :exec
+DELETE FROM treatment_plans
+WHERE id = $1;
+
+-- name: AddTreatmentPlanCycle :one
+INSERT INTO treatment_plan_cycles (plan_id, cycle_number, status, recorded_on, delay_days, dose_percent, reason, toxicity_grade_id, test_id, lab_result, notes)
+VALUES (@plan_id::uuid, @cycle_number::int, @status::plan_cycle_status_enum, @recorded_on::date, @delay_days::int, @dose_percent::float, @reason::text, sqlc.narg('toxicity_grade_id')::uuid, sqlc.narg('test_id')::uuid, @lab_result::text, @notes::text)
+RETURNING *;
+
+-- name: GetTreatmentPlanCycles :many
+SELECT c.id, c.created_at, c.updated_at, c.plan_id, c.cycle_number, c.status, c.recorded_on, c.delay_days, c.dose_percent, c.reason,
+  c.toxicity_grade_id, COALESCE(t.title, '')::text AS toxicity_title, COALESCE(tg.grade::text, '')::text AS toxicity_grade,
+  c.test_id, COALESCE(ts.name, '')::text AS test_name, c.lab_result, c.notes
+FROM treatment_plan_cycles c
+LEFT JOIN toxicity_grades tg ON tg.id = c.toxicity_grade_id
+LEFT JOIN toxicities t ON t.id = tg.toxicity_id
+LEFT JOIN tests ts ON ts.id = c.test_id
+WHERE c.plan_id = $1
+ORDER BY c.cycle_number ASC, c.recorded_on ASC, c.created_at ASC;
+
+-- name: RemoveTreatmentPlanCycle :exec
+DELETE FROM treatment_plan_cycles
+WHERE id = @id::uuid AND plan_id = @plan_id::uuid;
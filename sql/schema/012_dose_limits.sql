@@ -0,0 +1,37 @@
+-- +goose Up
+
+CREATE TYPE dose_basis_enum AS ENUM ('mg_m2', 'mg_kg', 'mg', 'units_m2', 'units', 'unknown');
+
+CREATE TABLE dose_equivalence_classes (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  updated_at timestamptz NOT NULL DEFAULT NOW(),
+  name TEXT NOT NULL UNIQUE,
+  reference_drug TEXT NOT NULL DEFAULT '',
+  max_cumulative_dose FLOAT NOT NULL DEFAULT 0,
+  dose_basis dose_basis_enum NOT NULL DEFAULT 'mg_m2',
+  warning_threshold FLOAT NOT NULL DEFAULT 0.8,
+  notes TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE medication_dose_limits (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  updated_at timestamptz NOT NULL DEFAULT NOW(),
+  medication_id UUID NOT NULL UNIQUE REFERENCES medications(id) ON DELETE CASCADE,
+  max_cumulative_dose FLOAT NOT NULL DEFAULT 0,
+  dose_basis dose_basis_enum NOT NULL DEFAULT 'mg_m2',
+  warning_threshold FLOAT NOT NULL DEFAULT 0.8,
+  equivalence_class_id UUID REFERENCES dose_equivalence_classes(id) ON DELETE SET NULL,
+  equivalence_factor FLOAT NOT NULL DEFAULT 1,
+  notes TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO dose_equivalence_classes (name, reference_drug, max_cumulative_dose, dose_basis, notes)
+VALUES ('anthracycline', 'doxorubicin', 450, 'mg_m2', 'Lifetime doxorubicin-equivalent dose. Set each anthracycline''s equivalence factor on its medication dose limit.');
+
+-- +goose Down
+
+DROP TABLE medication_dose_limits;
+DROP TABLE dose_equivalence_classes;
+DROP TYPE IF EXISTS dose_basis_enum CASCADE;
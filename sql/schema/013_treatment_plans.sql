@@ -0,0 +1,52 @@
+-- +goose Up
+
+CREATE TYPE plan_status_enum AS ENUM ('active', 'on_hold', 'completed', 'discontinued');
+CREATE TYPE plan_cycle_status_enum AS ENUM ('given', 'delayed', 'dose_reduced');
+
+-- patient_ref is an opaque reference chosen by the clinic, no other patient
+-- identifier is stored.
+CREATE TABLE treatment_plans (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  updated_at timestamptz NOT NULL DEFAULT NOW(),
+  patient_ref TEXT NOT NULL,
+  protocol_id UUID NOT NULL REFERENCES protocols(id) ON DELETE RESTRICT,
+  protocol_code TEXT NOT NULL DEFAULT '',
+  protocol_revised_on TEXT NOT NULL DEFAULT '',
+  start_date DATE NOT NULL,
+  planned_cycles INT NOT NULL DEFAULT 1,
+  status plan_status_enum NOT NULL DEFAULT 'active',
+  height_cm FLOAT NOT NULL DEFAULT 0,
+  weight_kg FLOAT NOT NULL DEFAULT 0,
+  bsa FLOAT NOT NULL DEFAULT 0,
+  baseline JSONB NOT NULL DEFAULT '{}',
+  notes TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX treatment_plans_patient_ref_idx ON treatment_plans (patient_ref);
+
+CREATE TABLE treatment_plan_cycles (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  updated_at timestamptz NOT NULL DEFAULT NOW(),
+  plan_id UUID NOT NULL REFERENCES treatment_plans(id) ON DELETE CASCADE,
+  cycle_number INT NOT NULL,
+  status plan_cycle_status_enum NOT NULL,
+  recorded_on DATE NOT NULL,
+  delay_days INT NOT NULL DEFAULT 0,
+  dose_percent FLOAT NOT NULL DEFAULT 100,
+  reason TEXT NOT NULL DEFAULT '',
+  toxicity_grade_id UUID REFERENCES toxicity_grades(id) ON DELETE SET NULL,
+  test_id UUID REFERENCES tests(id) ON DELETE SET NULL,
+  lab_result TEXT NOT NULL DEFAULT '',
+  notes TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX treatment_plan_cycles_plan_idx ON treatment_plan_cycles (plan_id, cycle_number);
+
+-- +goose Down
+
+DROP TABLE treatment_plan_cycles;
+DROP TABLE treatment_plans;
+DROP TYPE IF EXISTS plan_cycle_status_enum CASCADE;
+DROP TYPE IF EXISTS plan_status_enum CASCADE;
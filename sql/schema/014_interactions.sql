@@ -0,0 +1,28 @@
+-- +goose Up
+
+CREATE TYPE interaction_severity_enum AS ENUM ('contraindicated', 'major', 'moderate', 'minor', 'unknown');
+
+-- drug names are stored normalized (lower case) with drug_a < drug_b so each
+-- pair is stored once; they are matched against medication names and
+-- alternate names. The order is byte order, as the importer sorts them,
+-- whatever the database collation.
+CREATE TABLE interactions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  updated_at timestamptz NOT NULL DEFAULT NOW(),
+  drug_a TEXT NOT NULL,
+  drug_b TEXT NOT NULL,
+  severity interaction_severity_enum NOT NULL DEFAULT 'unknown',
+  description TEXT NOT NULL DEFAULT '',
+  management TEXT NOT NULL DEFAULT '',
+  source TEXT NOT NULL DEFAULT '',
+  UNIQUE (drug_a, drug_b),
+  CHECK (drug_a COLLATE "C" < drug_b COLLATE "C")
+);
+
+CREATE INDEX interactions_drug_b_idx ON interactions (drug_b);
+
+-- +goose Down
+
+DROP TABLE interactions;
+DROP TYPE IF EXISTS interaction_severity_enum CASCADE;
@@ -0,0 +1,35 @@
+-- +goose Up
+
+CREATE TYPE emetogenic_level_enum AS ENUM ('high', 'moderate', 'low', 'minimal', 'unknown');
+
+ALTER TABLE medications ADD COLUMN emetogenic_level emetogenic_level_enum NOT NULL DEFAULT 'unknown';
+
+-- agents by their MASCC/ESMO emetogenic level, intravenous and oral
+-- (capecitabine, temozolomide) alike; dose dependent agents (cyclophosphamide,
+-- cytarabine) are adjusted when a protocol is assessed. Medications created
+-- later are classified by medications.Resolve from emetogenic.LevelOf, and
+-- the classify_emetogenic command applies that list to stored medications.
+UPDATE medications SET emetogenic_level = 'high'
+WHERE lower(name) IN ('cisplatin', 'carmustine', 'dacarbazine', 'mechlorethamine', 'streptozocin');
+
+UPDATE medications SET emetogenic_level = 'moderate'
+WHERE lower(name) IN ('carboplatin', 'oxaliplatin', 'irinotecan', 'cyclophosphamide', 'doxorubicin',
+  'epirubicin', 'daunorubicin', 'idarubicin', 'ifosfamide', 'bendamustine', 'busulfan', 'clofarabine',
+  'melphalan', 'temozolomide', 'trabectedin', 'azacitidine', 'alemtuzumab', 'romidepsin', 'thiotepa',
+  'dactinomycin');
+
+UPDATE medications SET emetogenic_level = 'low'
+WHERE lower(name) IN ('docetaxel', 'paclitaxel', 'nab-paclitaxel', 'cabazitaxel', 'etoposide',
+  'fluorouracil', 'gemcitabine', 'pemetrexed', 'topotecan', 'mitomycin', 'mitoxantrone', 'ixabepilone',
+  'eribulin', 'cetuximab', 'panitumumab', 'trastuzumab emtansine', 'carfilzomib', 'cytarabine',
+  'methotrexate', 'capecitabine');
+
+UPDATE medications SET emetogenic_level = 'minimal'
+WHERE lower(name) IN ('bevacizumab', 'bleomycin', 'cladribine', 'fludarabine', 'nivolumab',
+  'pembrolizumab', 'ipilimumab', 'atezolizumab', 'durvalumab', 'rituximab', 'trastuzumab', 'pertuzumab',
+  'vinblastine', 'vincristine', 'vinorelbine', 'bortezomib');
+
+-- +goose Down
+
+ALTER TABLE medications DROP COLUMN emetogenic_level;
+DROP TYPE IF EXISTS emetogenic_level_enum CASCADE;
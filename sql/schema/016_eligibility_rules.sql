@@ -0,0 +1,18 @@
+-- +goose Up
+
+CREATE TYPE rule_author_enum AS ENUM ('ai', 'editor');
+
+-- optional machine-evaluable form of an eligibility criterion, written in the
+-- expression language of the eligibility package
+CREATE TABLE eligibility_rules (
+  criteria_id UUID PRIMARY KEY REFERENCES protocol_eligibility_criteria(id) ON DELETE CASCADE,
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  updated_at timestamptz NOT NULL DEFAULT NOW(),
+  expression TEXT NOT NULL,
+  author rule_author_enum NOT NULL DEFAULT 'editor'
+);
+
+-- +goose Down
+
+DROP TABLE eligibility_rules;
+DROP TYPE IF EXISTS rule_author_enum CASCADE;
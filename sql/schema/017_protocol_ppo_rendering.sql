@@ -0,0 +1,25 @@
+-- +goose Up
+
+CREATE TYPE ppo_format_enum AS ENUM ('pdf', 'html');
+
+-- rendered order sheets live next to the PPO links taken from the website;
+-- only one rendered copy per protocol and format is kept
+ALTER TABLE protocol_ppos
+  ADD COLUMN format ppo_format_enum NOT NULL DEFAULT 'pdf',
+  ADD COLUMN rendered BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN content BYTEA NOT NULL DEFAULT '',
+  ADD COLUMN content_hash TEXT NOT NULL DEFAULT '',
+  ADD COLUMN protocol_revised_on TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX protocol_ppos_rendered_idx ON protocol_ppos (protocol_id, format) WHERE rendered;
+
+-- +goose Down
+
+DROP INDEX IF EXISTS protocol_ppos_rendered_idx;
+ALTER TABLE protocol_ppos
+  DROP COLUMN format,
+  DROP COLUMN rendered,
+  DROP COLUMN content,
+  DROP COLUMN content_hash,
+  DROP COLUMN protocol_revised_on;
+DROP TYPE IF EXISTS ppo_format_enum CASCADE;
@@ -0,0 +1,29 @@
+-- +goose Up
+
+CREATE TYPE document_kind_enum AS ENUM ('protocol', 'ppo', 'patient_handout', 'other');
+
+-- every link found under a protocol on the BC Cancer site, with the local
+-- archive copy taken when it was crawled
+CREATE TABLE protocol_documents (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  updated_at timestamptz NOT NULL DEFAULT NOW(),
+  protocol_id UUID NOT NULL REFERENCES protocols(id) ON DELETE CASCADE,
+  kind document_kind_enum NOT NULL DEFAULT 'other',
+  title TEXT NOT NULL DEFAULT '',
+  url TEXT NOT NULL,
+  archive_path TEXT NOT NULL DEFAULT '',
+  content_type TEXT NOT NULL DEFAULT '',
+  size_bytes BIGINT NOT NULL DEFAULT 0,
+  fetched_at timestamptz,
+  UNIQUE (protocol_id, url)
+);
+
+-- PPO links taken from the website, one row per protocol and url
+CREATE UNIQUE INDEX protocol_ppos_link_idx ON protocol_ppos (protocol_id, url) WHERE NOT rendered;
+
+-- +goose Down
+
+DROP INDEX IF EXISTS protocol_ppos_link_idx;
+DROP TABLE protocol_documents;
+DROP TYPE IF EXISTS document_kind_enum CASCADE;
@@ -0,0 +1,36 @@
+-- +goose Up
+
+-- every downloaded source document, by the SHA-256 of its content; the
+-- content itself lives in the document store
+CREATE TABLE source_documents (
+  hash TEXT PRIMARY KEY,
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  updated_at timestamptz NOT NULL DEFAULT NOW(),
+  url TEXT NOT NULL DEFAULT '',
+  content_type TEXT NOT NULL DEFAULT '',
+  size_bytes BIGINT NOT NULL DEFAULT 0,
+  fetched_at timestamptz NOT NULL DEFAULT NOW(),
+  headers JSONB NOT NULL DEFAULT '{}'
+);
+
+-- the document each protocol revision was extracted from
+CREATE TABLE protocol_sources (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  protocol_id UUID NOT NULL REFERENCES protocols(id) ON DELETE CASCADE,
+  document_hash TEXT NOT NULL REFERENCES source_documents(hash),
+  revised_on TEXT NOT NULL DEFAULT '',
+  UNIQUE (protocol_id, document_hash)
+);
+
+-- crawled documents are kept in the document store; the archive folder
+-- copy is only taken when the store cannot be written
+ALTER TABLE protocol_documents
+  ADD COLUMN document_hash TEXT REFERENCES source_documents(hash);
+
+-- +goose Down
+
+ALTER TABLE protocol_documents
+  DROP COLUMN document_hash;
+DROP TABLE protocol_sources;
+DROP TABLE source_documents;
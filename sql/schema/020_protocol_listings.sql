@@ -0,0 +1,26 @@
+-- +goose Up
+
+-- protocols as listed on the BC Cancer tumor-group pages; a listing that a
+-- complete discovery crawl no longer finds is marked retired
+CREATE TABLE protocol_listings (
+  code TEXT PRIMARY KEY,
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  updated_at timestamptz NOT NULL DEFAULT NOW(),
+  protocol_id UUID REFERENCES protocols(id) ON DELETE SET NULL,
+  tumor_group TEXT NOT NULL DEFAULT 'unknown',
+  description TEXT NOT NULL DEFAULT '',
+  page_url TEXT NOT NULL DEFAULT '',
+  first_seen_at timestamptz NOT NULL DEFAULT NOW(),
+  last_seen_at timestamptz NOT NULL DEFAULT NOW(),
+  retired_at timestamptz
+);
+
+-- protocols crawled before discovery existed
+INSERT INTO protocol_listings (code, protocol_id, tumor_group, description, first_seen_at, last_seen_at)
+SELECT code, id, tumor_group, name, created_at, updated_at FROM protocols
+WHERE protocol_url <> ''
+ON CONFLICT (code) DO NOTHING;
+
+-- +goose Down
+
+DROP TABLE protocol_listings;
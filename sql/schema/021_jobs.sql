@@ -0,0 +1,35 @@
+-- +goose Up
+
+CREATE TYPE job_kind_enum AS ENUM ('crawl', 'extract');
+CREATE TYPE job_status_enum AS ENUM ('pending', 'running', 'succeeded', 'dead', 'cancelled');
+
+-- crawl and extraction work, claimed by workers with FOR UPDATE SKIP LOCKED.
+-- A running job whose lease expired (the worker died) is claimed again.
+-- Jobs that fail max_attempts times are dead until retried.
+CREATE TABLE jobs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  updated_at timestamptz NOT NULL DEFAULT NOW(),
+  kind job_kind_enum NOT NULL,
+  target TEXT NOT NULL,
+  payload JSONB NOT NULL DEFAULT '{}',
+  status job_status_enum NOT NULL DEFAULT 'pending',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  max_attempts INTEGER NOT NULL DEFAULT 5,
+  last_error TEXT NOT NULL DEFAULT '',
+  run_at timestamptz NOT NULL DEFAULT NOW(),
+  started_at timestamptz,
+  finished_at timestamptz,
+  locked_by TEXT NOT NULL DEFAULT '',
+  lease_expires_at timestamptz
+);
+
+CREATE INDEX jobs_claim_idx ON jobs (run_at) WHERE status IN ('pending', 'running');
+-- one live job per target
+CREATE UNIQUE INDEX jobs_active_target_idx ON jobs (kind, target) WHERE status IN ('pending', 'running');
+
+-- +goose Down
+
+DROP TABLE jobs;
+DROP TYPE IF EXISTS job_status_enum CASCADE;
+DROP TYPE IF EXISTS job_kind_enum CASCADE;
@@ -0,0 +1,32 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+
+ALTER TYPE job_kind_enum ADD VALUE IF NOT EXISTS 'discover';
+
+CREATE TYPE schedule_action_enum AS ENUM ('discover', 'crawl');
+
+-- cron schedules run by `serve --scheduler`, in UTC. A crawl schedule
+-- without a target re-crawls every active tumor-group page.
+CREATE TABLE schedules (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  updated_at timestamptz NOT NULL DEFAULT NOW(),
+  name TEXT NOT NULL UNIQUE,
+  cron TEXT NOT NULL,
+  action schedule_action_enum NOT NULL,
+  target TEXT NOT NULL DEFAULT '',
+  paused BOOLEAN NOT NULL DEFAULT FALSE,
+  next_run_at timestamptz,
+  last_run_at timestamptz,
+  last_error TEXT NOT NULL DEFAULT ''
+);
+
+-- seeded paused: an admin resumes them with /admin/schedules/{id}/resume
+INSERT INTO schedules (name, cron, action, paused) VALUES
+  ('weekly-discovery', '0 10 * * 1', 'discover', TRUE),
+  ('daily-recrawl', '0 11 * * *', 'crawl', TRUE);
+
+-- +goose Down
+
+DROP TABLE schedules;
+DROP TYPE IF EXISTS schedule_action_enum CASCADE;
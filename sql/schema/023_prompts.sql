@@ -0,0 +1,63 @@
+-- +goose Up
+
+-- LLM prompts, edited without a rebuild. Versions are never changed once
+-- written; a prompt's active_version is the one extractions use. Templates
+-- are Go text/template with {{.TumorGroup}} and {{.Section}}.
+CREATE TABLE prompts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  updated_at timestamptz NOT NULL DEFAULT NOW(),
+  name TEXT NOT NULL UNIQUE,
+  description TEXT NOT NULL DEFAULT '',
+  active_version INTEGER
+);
+
+CREATE TABLE prompt_versions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  prompt_id UUID NOT NULL REFERENCES prompts(id) ON DELETE CASCADE,
+  version INTEGER NOT NULL,
+  template TEXT NOT NULL,
+  notes TEXT NOT NULL DEFAULT '',
+  UNIQUE (prompt_id, version)
+);
+
+ALTER TABLE prompts
+  ADD CONSTRAINT prompts_active_version_fkey
+  FOREIGN KEY (id, active_version) REFERENCES prompt_versions (prompt_id, version);
+
+-- every extraction, with the prompt version and model that produced it
+CREATE TABLE extractions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  protocol_id UUID NOT NULL REFERENCES protocols(id) ON DELETE CASCADE,
+  document_hash TEXT NOT NULL REFERENCES source_documents(hash),
+  prompt_version_id UUID NOT NULL REFERENCES prompt_versions(id),
+  model TEXT NOT NULL
+);
+
+CREATE INDEX extractions_protocol_idx ON extractions (protocol_id, created_at);
+
+-- the prompt that was compiled in until now
+INSERT INTO prompts (name, description)
+VALUES ('protocol_extraction', 'Extracts a whole protocol PDF into the protocol schema.');
+
+INSERT INTO prompt_versions (prompt_id, version, template, notes)
+SELECT id, 1, 'You are a medical oncologist tasked with analyzing the joined PDF document and extracting structured information in JSON format.
+### Task:
+1. Parse the provided PDF thoroughly.
+2. Extract all relevant information, and complete the JSON object according to the provided schema.
+3. Ensure that the extracted information is accurate and complete it to the best of your expertise knowledge.
+3. Toxicities should be defined using the CTCAE v5 terminology. Generate only a toxicity with adjustment if there are suggested guidances.
+4. Each tests should be a single entity.
+5. Return the completed JSON object, ensuring all fields are validated for data type and consistency.', 'Initial prompt.'
+FROM prompts WHERE name = 'protocol_extraction';
+
+UPDATE prompts SET active_version = 1 WHERE name = 'protocol_extraction';
+
+-- +goose Down
+
+DROP TABLE extractions;
+ALTER TABLE prompts DROP CONSTRAINT prompts_active_version_fkey;
+DROP TABLE prompt_versions;
+DROP TABLE prompts;
@@ -0,0 +1,50 @@
+-- +goose Up
+
+-- A sectioned extraction reads a protocol in several calls: an overview pass
+-- for the summary and where each section is, then one pass per section.
+INSERT INTO prompts (name, description) VALUES
+  ('protocol_overview', 'First pass of a sectioned extraction: the protocol summary and where each section is.'),
+  ('protocol_section', 'Later passes of a sectioned extraction, one per section, with {{.Section}} and {{.Location}}.');
+
+INSERT INTO prompt_versions (prompt_id, version, template, notes)
+SELECT id, 1, 'You are a medical oncologist tasked with analyzing the joined PDF document and extracting structured information in JSON format.
+### Task:
+1. Parse the provided PDF thoroughly.
+2. Extract the protocol summary, the physicians and the article references.
+3. For each section of the protocol (cycles, prescriptions, tests, toxicities and eligibility), list the headings it appears under and the pages it spans. Leave out a section the protocol does not have.{{if .TumorGroup}}
+4. The protocol is listed under the {{.TumorGroup}} tumor group.{{end}}
+Return the completed JSON object, ensuring all fields are validated for data type and consistency.', 'Initial prompt.'
+FROM prompts WHERE name = 'protocol_overview';
+
+INSERT INTO prompt_versions (prompt_id, version, template, notes)
+SELECT id, 1, 'You are a medical oncologist tasked with analyzing the joined PDF document and extracting structured information in JSON format.
+### Task:
+1. Extract only the {{.Section}} of the protocol. The other sections are extracted separately.
+2. Extract every item of the section, however long it is; do not summarize or stop early.{{if .Location}}
+3. In this document the {{.Section}} section is {{.Location}}.{{end}}{{if eq .Section "toxicities"}}
+4. Toxicities should be defined using the CTCAE v5 terminology. Generate only a toxicity with adjustment if there are suggested guidances.{{end}}{{if eq .Section "tests"}}
+4. Each test should be a single entity.{{end}}
+Return the completed JSON object, ensuring all fields are validated for data type and consistency.', 'Initial prompt.'
+FROM prompts WHERE name = 'protocol_section';
+
+UPDATE prompts SET active_version = 1 WHERE name IN ('protocol_overview', 'protocol_section');
+
+CREATE TYPE extraction_mode_enum AS ENUM ('single', 'sectioned');
+
+-- a sectioned extraction also records its section prompt and what the
+-- passes disagreed on; prompt_version_id is then the overview prompt
+ALTER TABLE extractions
+  ADD COLUMN mode extraction_mode_enum NOT NULL DEFAULT 'single',
+  ADD COLUMN section_prompt_version_id UUID REFERENCES prompt_versions(id),
+  ADD COLUMN conflicts JSONB NOT NULL DEFAULT '[]';
+
+-- +goose Down
+
+DELETE FROM extractions WHERE mode = 'sectioned';
+ALTER TABLE extractions
+  DROP COLUMN conflicts,
+  DROP COLUMN section_prompt_version_id,
+  DROP COLUMN mode;
+DROP TYPE extraction_mode_enum;
+UPDATE prompts SET active_version = NULL WHERE name IN ('protocol_overview', 'protocol_section');
+DELETE FROM prompts WHERE name IN ('protocol_overview', 'protocol_section');
@@ -0,0 +1,28 @@
+-- +goose Up
+
+CREATE TYPE llm_call_outcome_enum AS ENUM ('success', 'api_error', 'invalid_response');
+
+-- every request to the model, for usage, cost and latency reporting and the
+-- LLM budget; cost_usd is priced when the call is made
+CREATE TABLE llm_calls (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  model TEXT NOT NULL,
+  purpose TEXT NOT NULL,
+  document_hash TEXT NOT NULL DEFAULT '',
+  attempt INTEGER NOT NULL DEFAULT 1,
+  outcome llm_call_outcome_enum NOT NULL,
+  error TEXT NOT NULL DEFAULT '',
+  prompt_tokens INTEGER NOT NULL DEFAULT 0,
+  response_tokens INTEGER NOT NULL DEFAULT 0,
+  total_tokens INTEGER NOT NULL DEFAULT 0,
+  latency_ms INTEGER NOT NULL DEFAULT 0,
+  cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0
+);
+
+CREATE INDEX llm_calls_created_idx ON llm_calls (created_at);
+
+-- +goose Down
+
+DROP TABLE llm_calls;
+DROP TYPE llm_call_outcome_enum;
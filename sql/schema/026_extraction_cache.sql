@@ -0,0 +1,22 @@
+-- +goose Up
+
+-- Model responses, reused instead of calling the model again for the same
+-- document, prompt version, model and response schema. The template
+-- variables are not part of the key: they come from the listing of the
+-- document, and a replay may not know them.
+CREATE TABLE extraction_cache (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  document_hash TEXT NOT NULL,
+  prompt_version_id UUID NOT NULL REFERENCES prompt_versions(id),
+  model TEXT NOT NULL,
+  schema_hash TEXT NOT NULL,
+  purpose TEXT NOT NULL,
+  raw_response TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  UNIQUE (document_hash, prompt_version_id, model, schema_hash)
+);
+
+-- +goose Down
+
+DROP TABLE extraction_cache;
@@ -0,0 +1,9 @@
+-- +goose Up
+
+-- the error of every failed attempt of a job, oldest first, with the model
+-- calls it made: {attempt, at, error, calls: [{attempt, class, error, retry_after_seconds}]}
+ALTER TABLE jobs ADD COLUMN attempt_errors JSONB NOT NULL DEFAULT '[]';
+
+-- +goose Down
+
+ALTER TABLE jobs DROP COLUMN attempt_errors;
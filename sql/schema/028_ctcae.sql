@@ -0,0 +1,34 @@
+-- +goose Up
+
+-- The CTCAE v5 term list, imported from the NCI spreadsheet. Extracted
+-- toxicities are mapped onto these terms, and their grade descriptions come
+-- from here rather than from the model.
+CREATE TABLE ctcae_terms (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  updated_at timestamptz NOT NULL DEFAULT NOW(),
+  meddra_code TEXT NOT NULL UNIQUE,
+  term TEXT NOT NULL,
+  soc TEXT NOT NULL,
+  definition TEXT NOT NULL DEFAULT '',
+  navigational_note TEXT NOT NULL DEFAULT '',
+  version TEXT NOT NULL DEFAULT '5.0'
+);
+
+CREATE INDEX ctcae_terms_term_idx ON ctcae_terms (lower(term));
+
+-- grade 5 is death; toxicity_grades has no grade 5, it is kept here only
+CREATE TABLE ctcae_grades (
+  term_id UUID NOT NULL REFERENCES ctcae_terms(id) ON DELETE CASCADE,
+  grade SMALLINT NOT NULL CHECK (grade BETWEEN 1 AND 5),
+  description TEXT NOT NULL,
+  PRIMARY KEY (term_id, grade)
+);
+
+ALTER TABLE toxicities ADD COLUMN ctcae_term_id UUID REFERENCES ctcae_terms(id) ON DELETE SET NULL;
+
+-- +goose Down
+
+ALTER TABLE toxicities DROP COLUMN ctcae_term_id;
+DROP TABLE ctcae_grades;
+DROP TABLE ctcae_terms;
@@ -0,0 +1,26 @@
+-- +goose Up
+
+CREATE TYPE provenance_entity_enum AS ENUM ('treatment', 'prescription', 'criterion', 'toxicity_adjustment', 'test');
+
+-- Where in the protocol PDF an extracted item was read: the page of the
+-- file and a short verbatim quote. Treatments, prescriptions, criteria and
+-- tests are shared between protocols, so a row belongs to one protocol and
+-- one item; a toxicity adjustment is keyed by its toxicity grade. The
+-- latest extraction of the protocol replaces the row.
+CREATE TABLE provenance (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  updated_at timestamptz NOT NULL DEFAULT NOW(),
+  protocol_id UUID NOT NULL REFERENCES protocols(id) ON DELETE CASCADE,
+  entity_type provenance_entity_enum NOT NULL,
+  entity_id UUID NOT NULL,
+  page INTEGER CHECK (page > 0),
+  quote TEXT NOT NULL DEFAULT '',
+  extraction_id UUID REFERENCES extractions(id) ON DELETE SET NULL,
+  UNIQUE (protocol_id, entity_type, entity_id)
+);
+
+-- +goose Down
+
+DROP TABLE provenance;
+DROP TYPE provenance_entity_enum;
@@ -0,0 +1,64 @@
+package usage
+
+import (
+	"bcca_crawler/internal/config"
+	"context"
+	"fmt"
+	"time"
+)
+
+// BudgetError is returned by CheckBudget when another extraction would go
+// over the budget. Until is when the period resets.
+type BudgetError struct {
+	Period string
+	Spent  float64
+	Budget float64
+	Until  time.Time
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("LLM %s budget reached: $%.2f of $%.2f spent, resumes at %s", e.Period, e.Spent, e.Budget, e.Until.Format(time.RFC3339))
+}
+
+// Periods returns the start of the current UTC day and month and of the
+// next ones.
+func Periods(now time.Time) (day, nextDay, month, nextMonth time.Time) {
+	now = now.UTC()
+	day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return day, day.AddDate(0, 0, 1), month, month.AddDate(0, 1, 0)
+}
+
+// CheckBudget returns a *BudgetError when the spend of the day or month,
+// plus what a document has cost on average this month, would exceed the
+// budget. It is checked before an extraction rather than after, so the
+// budget is not overshot by a whole document.
+func CheckBudget(c *config.Config, ctx context.Context) error {
+	b := c.LLMBudget
+	if b.Daily <= 0 && b.Monthly <= 0 {
+		return nil
+	}
+	day, nextDay, month, nextMonth := Periods(time.Now())
+	monthly, err := c.Db.GetLLMSpend(ctx, month)
+	if err != nil {
+		return fmt.Errorf("error getting LLM spend: %v", err)
+	}
+	estimate := 0.0
+	if monthly.Documents > 0 {
+		estimate = monthly.Spent / float64(monthly.Documents)
+	}
+
+	if b.Monthly > 0 && monthly.Spent+estimate > b.Monthly {
+		return &BudgetError{Period: "monthly", Spent: monthly.Spent, Budget: b.Monthly, Until: nextMonth}
+	}
+	if b.Daily > 0 {
+		daily, err := c.Db.GetLLMSpend(ctx, day)
+		if err != nil {
+			return fmt.Errorf("error getting LLM spend: %v", err)
+		}
+		if daily.Spent+estimate > b.Daily {
+			return &BudgetError{Period: "daily", Spent: daily.Spent, Budget: b.Daily, Until: nextDay}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Package usage records every request to the model with its tokens,
+// latency and cost, and enforces the LLM budget.
+package usage
+
+import (
+	"bcca_crawler/internal/config"
+	"bcca_crawler/internal/database"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPrices are the list prices of the Gemini models, in USD per
+// million tokens. LLM_PRICING overrides or adds models.
+var DefaultPrices = map[string]config.LLMPrice{
+	"gemini-2.5-pro":        {Input: 1.25, Output: 10},
+	"gemini-2.5-flash":      {Input: 0.30, Output: 2.50},
+	"gemini-2.5-flash-lite": {Input: 0.10, Output: 0.40},
+	"gemini-2.0-flash":      {Input: 0.10, Output: 0.40},
+}
+
+// Prices returns DefaultPrices with the overrides of spec, a comma
+// separated list of model=input/output, e.g.
+//
+//	gemini-2.5-pro=1.25/10,my-model=0.5/1.5
+func Prices(spec string) (map[string]config.LLMPrice, error) {
+	prices := make(map[string]config.LLMPrice, len(DefaultPrices))
+	for model, p := range DefaultPrices {
+		prices[model] = p
+	}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		model, rates, ok := strings.Cut(item, "=")
+		in, out, ok2 := strings.Cut(rates, "/")
+		if !ok || !ok2 || strings.TrimSpace(model) == "" {
+			return nil, fmt.Errorf("invalid price %q, want model=input/output", item)
+		}
+		input, err := strconv.ParseFloat(strings.TrimSpace(in), 64)
+		if err != nil || input < 0 {
+			return nil, fmt.Errorf("invalid input price in %q", item)
+		}
+		output, err := strconv.ParseFloat(strings.TrimSpace(out), 64)
+		if err != nil || output < 0 {
+			return nil, fmt.Errorf("invalid output price in %q", item)
+		}
+		prices[strings.TrimSpace(model)] = config.LLMPrice{Input: input, Output: output}
+	}
+	return prices, nil
+}
+
+// Cost prices a call. A model without a price costs nothing, which is
+// logged so the gap is noticed.
+func Cost(prices map[string]config.LLMPrice, model string, promptTokens, responseTokens int32) float64 {
+	p, ok := prices[model]
+	if !ok {
+		log.Printf("no price for model %s, its calls are counted as free", model)
+		return 0
+	}
+	return (float64(promptTokens)*p.Input + float64(responseTokens)*p.Output) / 1e6
+}
+
+// Call is one request to the model. Response tokens include the thinking
+// tokens, which are billed as output.
+type Call struct {
+	Model          string
+	Purpose        string
+	DocumentHash   string
+	Attempt        int
+	Outcome        database.LlmCallOutcomeEnum
+	Err            error
+	PromptTokens   int32
+	ResponseTokens int32
+	TotalTokens    int32
+	Latency        time.Duration
+}
+
+// Record saves a call. A failure is logged rather than returned; the
+// extraction it belongs to has already been paid for.
+func Record(c *config.Config, ctx context.Context, call Call) {
+	params := database.CreateLLMCallParams{
+		Model:          call.Model,
+		Purpose:        call.Purpose,
+		DocumentHash:   call.DocumentHash,
+		Attempt:        int32(call.Attempt),
+		Outcome:        call.Outcome,
+		PromptTokens:   call.PromptTokens,
+		ResponseTokens: call.ResponseTokens,
+		TotalTokens:    call.TotalTokens,
+		LatencyMs:      int32(call.Latency / time.Millisecond),
+		CostUsd:        Cost(c.LLMPrices, call.Model, call.PromptTokens, call.ResponseTokens),
+	}
+	if call.Err != nil {
+		params.Error = call.Err.Error()
+	}
+	if err := c.Db.CreateLLMCall(context.WithoutCancel(ctx), params); err != nil {
+		log.Printf("error recording LLM call: %s %s, with error: %v", call.Model, call.Purpose, err)
+	}
+}
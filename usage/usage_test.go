@@ -0,0 +1,59 @@
+package usage
+
+import (
+	"bcca_crawler/internal/config"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPrices(t *testing.T) {
+	prices, err := Prices(" gemini-2.5-pro=2/12, my-model=0.5/1.5 ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for model, want := range map[string]config.LLMPrice{
+		"gemini-2.5-pro":   {Input: 2, Output: 12},
+		"my-model":         {Input: 0.5, Output: 1.5},
+		"gemini-2.5-flash": DefaultPrices["gemini-2.5-flash"],
+	} {
+		if prices[model] != want {
+			t.Errorf("%s = %+v, want %+v", model, prices[model], want)
+		}
+	}
+	if DefaultPrices["gemini-2.5-pro"].Input != 1.25 {
+		t.Error("Prices changed DefaultPrices")
+	}
+
+	for _, spec := range []string{"gemini", "gemini=1", "=1/2", "gemini=a/2", "gemini=1/-2"} {
+		if _, err := Prices(spec); err == nil {
+			t.Errorf("Prices(%q) did not fail", spec)
+		}
+	}
+}
+
+func TestCost(t *testing.T) {
+	prices := map[string]config.LLMPrice{"m": {Input: 0.30, Output: 2.50}}
+	got := Cost(prices, "m", 100_000, 20_000)
+	if want := 0.03 + 0.05; math.Abs(got-want) > 1e-9 {
+		t.Errorf("cost = %v, want %v", got, want)
+	}
+	if got := Cost(prices, "unknown", 100_000, 20_000); got != 0 {
+		t.Errorf("cost of an unpriced model = %v, want 0", got)
+	}
+}
+
+func TestPeriods(t *testing.T) {
+	now := time.Date(2026, 12, 31, 23, 30, 0, 0, time.FixedZone("PST", -8*3600))
+	day, nextDay, month, nextMonth := Periods(now)
+	for name, tc := range map[string][2]time.Time{
+		"day":        {day, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		"next day":   {nextDay, time.Date(2027, 1, 2, 0, 0, 0, 0, time.UTC)},
+		"month":      {month, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		"next month": {nextMonth, time.Date(2027, 2, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		if !tc[0].Equal(tc[1]) {
+			t.Errorf("%s = %s, want %s", name, tc[0], tc[1])
+		}
+	}
+}